@@ -0,0 +1,95 @@
+package contracts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/build"
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/crust/build/docker"
+	"github.com/CoreumFoundation/crust/build/tools"
+)
+
+const (
+	// PathEnv is the name of environment variable pointing to the directory containing cargo project
+	// or workspace of the contracts.
+	PathEnv = "CRUST_CONTRACTS_PATH"
+
+	// https://github.com/CosmWasm/rust-optimizer
+	// Images bundle rust toolchain, wasm-opt and the build script, so the same version always produces the same
+	// byte code.
+	optimizerVersion          = "0.12.13"
+	rustOptimizerImage        = "cosmwasm/rust-optimizer"
+	workspaceOptimizerImage   = "cosmwasm/workspace-optimizer"
+	artifactsDir              = "artifacts"
+	artifactsChecksumFileName = "checksums.txt"
+)
+
+var workspaceRegexp = regexp.MustCompile(`(?m)^\s*\[workspace\]\s*$`)
+
+// Build builds optimized WASM artifacts of the contracts using CosmWasm optimizer. Artifacts together with their
+// checksums are stored in the `artifacts` directory of the contracts project.
+func Build(ctx context.Context, deps build.DepsFunc) error {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return errors.Wrap(err, "docker command is not available in PATH")
+	}
+
+	contractsPath := os.Getenv(PathEnv)
+	if contractsPath == "" {
+		return errors.Errorf("%s must be set to the directory containing contracts", PathEnv)
+	}
+	contractsPath, err := filepath.Abs(contractsPath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	cargoFile, err := os.ReadFile(filepath.Join(contractsPath, "Cargo.toml"))
+	if err != nil {
+		return errors.Wrapf(err, "reading Cargo.toml of contracts in %s failed", contractsPath)
+	}
+
+	image := rustOptimizerImage
+	if workspaceRegexp.Match(cargoFile) {
+		image = workspaceOptimizerImage
+	}
+	image += ":" + optimizerVersion
+	// Optimizer publishes arm64 images under separate tags. Byte code they produce differs from the amd64 one,
+	// so artifacts to be deployed on chains should always be built on amd64.
+	if tools.DockerPlatform.Arch == "arm64" {
+		image += "-arm64"
+	}
+
+	// Caches are kept in volumes, named by the path of the project, to speed up subsequent builds.
+	pathHash := sha256.Sum256([]byte(contractsPath))
+	cacheName := "crust-contracts-" + hex.EncodeToString(pathHash[:4])
+
+	log := logger.Get(ctx).With(zap.String("path", contractsPath), zap.String("image", image))
+	log.Info("Building contracts")
+	cmd := exec.Command("docker", "run", "--rm",
+		"--platform", docker.Platform,
+		"-v", contractsPath+":/code",
+		"--mount", "type=volume,source="+cacheName+"-target,target=/code/target",
+		"--mount", "type=volume,source=crust-contracts-registry,target=/usr/local/cargo/registry",
+		image,
+	)
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return errors.Wrapf(err, "building contracts in %s failed", contractsPath)
+	}
+
+	checksums, err := os.ReadFile(filepath.Join(contractsPath, artifactsDir, artifactsChecksumFileName))
+	if err != nil {
+		return errors.Wrap(err, "reading checksums of artifacts failed")
+	}
+	log.Info("Contracts built", zap.String("artifacts", filepath.Join(contractsPath, artifactsDir)),
+		zap.Strings("checksums", strings.Split(strings.TrimSpace(string(checksums)), "\n")))
+	return nil
+}
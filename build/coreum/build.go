@@ -26,6 +26,9 @@ const (
 
 	integrationTestBinaryModulePath  = "bin/.cache/integration-tests/coreum-modules"
 	integrationTestBinaryUpgradePath = "bin/.cache/integration-tests/coreum-upgrade"
+
+	integrationTestDockerBinaryModulePath  = "bin/.cache/docker/integration-tests/integration-tests/coreum-modules"
+	integrationTestDockerBinaryUpgradePath = "bin/.cache/docker/integration-tests/integration-tests/coreum-upgrade"
 )
 
 var (
@@ -59,7 +62,7 @@ func BuildCoredLocally(ctx context.Context, deps build.DepsFunc) error {
 
 // BuildCoredInDocker builds cored in docker.
 func BuildCoredInDocker(ctx context.Context, deps build.DepsFunc) error {
-	deps(golang.EnsureGo, golang.EnsureLibWASMVMMuslC, ensureRepo)
+	deps(golang.EnsureGo, ensureRepo)
 
 	parameters, err := coredVersionParams(ctx, tagsDocker)
 	if err != nil {
@@ -96,6 +99,27 @@ func BuildIntegrationTests(ctx context.Context, deps build.DepsFunc) error {
 	})
 }
 
+// BuildIntegrationTestsInDocker builds coreum integration tests in docker, to be packaged into docker image.
+func BuildIntegrationTestsInDocker(ctx context.Context, deps build.DepsFunc) error {
+	deps(golang.EnsureGo, ensureRepo)
+
+	tags := []string{"integrationtests", "muslc"}
+	err := golang.BuildTestsInDocker(ctx, golang.TestBuildConfig{
+		PackagePath:   "../coreum/integration-tests/modules",
+		BinOutputPath: integrationTestDockerBinaryModulePath,
+		Tags:          tags,
+	})
+	if err != nil {
+		return err
+	}
+
+	return golang.BuildTestsInDocker(ctx, golang.TestBuildConfig{
+		PackagePath:   "../coreum/integration-tests/upgrade",
+		BinOutputPath: integrationTestDockerBinaryUpgradePath,
+		Tags:          tags,
+	})
+}
+
 // Tidy runs `go mod tidy` for coreum repo.
 func Tidy(ctx context.Context, deps build.DepsFunc) error {
 	deps(ensureRepo)
@@ -21,7 +21,7 @@ func ReleaseCored(ctx context.Context, deps build.DepsFunc) error {
 		return errors.New("this task can be executed on linux/amd64 machine only")
 	}
 
-	deps(golang.EnsureGo, golang.EnsureLibWASMVMMuslC, ensureRepo)
+	deps(golang.EnsureGo, ensureRepo)
 
 	parameters, err := coredVersionParams(ctx, tagsDocker)
 	if err != nil {
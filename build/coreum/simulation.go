@@ -0,0 +1,175 @@
+package coreum
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/build"
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/crust/build/golang"
+	"github.com/CoreumFoundation/crust/build/tools"
+)
+
+const (
+	// SimulationSeedsEnv is the name of environment variable defining comma-separated list of seeds used to run
+	// the simulation.
+	SimulationSeedsEnv = "CRUST_SIMULATION_SEEDS"
+
+	// SimulationNumBlocksEnv is the name of environment variable defining number of blocks simulated for each seed.
+	SimulationNumBlocksEnv = "CRUST_SIMULATION_NUM_BLOCKS"
+
+	// SimulationTestEnv is the name of environment variable defining the simulation test to run.
+	SimulationTestEnv = "CRUST_SIMULATION_TEST"
+
+	// SimulationArtifactsDirEnv is the name of environment variable defining the directory where artifacts
+	// of failed simulations are stored.
+	SimulationArtifactsDirEnv = "CRUST_SIMULATION_ARTIFACTS_DIR"
+
+	simulationPackage          = "./app"
+	defaultSimulationSeeds     = "1,2,3,4,5"
+	defaultSimulationNumBlocks = 500
+	defaultSimulationTest      = "TestFullAppSimulation"
+	defaultSimulationArtifacts = "bin/.cache/simulation"
+	simulationBlockSize        = 200
+	simulationTimeout          = 24 * time.Hour
+)
+
+// RunSimulation runs the simulation of the coreum app, randomly executing operations of the modules, once for each
+// configured seed. Output of each failed run is stored in the artifacts directory together with the list of failed
+// seeds, so failures may be reproduced later.
+func RunSimulation(ctx context.Context, deps build.DepsFunc) error {
+	deps(golang.EnsureGo, ensureRepo)
+
+	seeds, err := simulationSeeds()
+	if err != nil {
+		return err
+	}
+	numBlocks, err := simulationNumBlocks()
+	if err != nil {
+		return err
+	}
+	testName := os.Getenv(SimulationTestEnv)
+	if testName == "" {
+		testName = defaultSimulationTest
+	}
+	artifactsDir := os.Getenv(SimulationArtifactsDirEnv)
+	if artifactsDir == "" {
+		artifactsDir = defaultSimulationArtifacts
+	}
+	artifactsDir, err = filepath.Abs(filepath.Join(artifactsDir, time.Now().UTC().Format("20060102-150405")))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	log := logger.Get(ctx)
+	var failedSeeds []string
+	for _, seed := range seeds {
+		log := log.With(zap.Int64("seed", seed), zap.Int("numBlocks", numBlocks))
+		log.Info("Running simulation")
+
+		output, err := runSimulation(ctx, testName, seed, numBlocks)
+		if err == nil {
+			log.Info("Simulation passed")
+			continue
+		}
+		if ctx.Err() != nil {
+			return errors.WithStack(ctx.Err())
+		}
+
+		log.Error("Simulation failed", zap.Error(err))
+		failedSeeds = append(failedSeeds, strconv.FormatInt(seed, 10))
+		if err := storeSimulationArtifacts(artifactsDir, seed, output, failedSeeds); err != nil {
+			return err
+		}
+	}
+
+	if len(failedSeeds) > 0 {
+		return errors.Errorf("simulation failed for seeds: %s, artifacts are stored in %s",
+			strings.Join(failedSeeds, ", "), artifactsDir)
+	}
+	return nil
+}
+
+func runSimulation(ctx context.Context, testName string, seed int64, numBlocks int) ([]byte, error) {
+	outputFile, err := os.CreateTemp("", "coreum-simulation-*.log")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer os.Remove(outputFile.Name())
+	defer outputFile.Close()
+
+	cmd := exec.Command(tools.PathLocal("go"), "test", simulationPackage,
+		"-run", "^"+testName+"$",
+		"-count=1",
+		"-timeout="+simulationTimeout.String(),
+		"-Enabled=true",
+		"-Commit=true",
+		"-Verbose=true",
+		"-Period=0",
+		fmt.Sprintf("-Seed=%d", seed),
+		fmt.Sprintf("-NumBlocks=%d", numBlocks),
+		fmt.Sprintf("-BlockSize=%d", simulationBlockSize),
+	)
+	cmd.Dir = repoPath
+	cmd.Stdout = outputFile
+	cmd.Stderr = outputFile
+	runErr := libexec.Exec(ctx, cmd)
+
+	output, err := os.ReadFile(outputFile.Name())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return output, runErr
+}
+
+// storeSimulationArtifacts stores output of the failed simulation and rewrites the list of failed seeds.
+func storeSimulationArtifacts(artifactsDir string, seed int64, output []byte, failedSeeds []string) error {
+	if err := os.MkdirAll(artifactsDir, 0o700); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.WriteFile(filepath.Join(artifactsDir, fmt.Sprintf("seed-%d.log", seed)), output, 0o600); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(filepath.Join(artifactsDir, "failed-seeds.txt"),
+		[]byte(strings.Join(failedSeeds, "\n")+"\n"), 0o600))
+}
+
+func simulationSeeds() ([]int64, error) {
+	seedsStr := os.Getenv(SimulationSeedsEnv)
+	if seedsStr == "" {
+		seedsStr = defaultSimulationSeeds
+	}
+	var seeds []int64
+	for _, s := range strings.Split(seedsStr, ",") {
+		seed, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid value of %s: %q, comma-separated list of integers is expected",
+				SimulationSeedsEnv, seedsStr)
+		}
+		seeds = append(seeds, seed)
+	}
+	return seeds, nil
+}
+
+func simulationNumBlocks() (int, error) {
+	numBlocksStr := os.Getenv(SimulationNumBlocksEnv)
+	if numBlocksStr == "" {
+		return defaultSimulationNumBlocks, nil
+	}
+	numBlocks, err := strconv.Atoi(numBlocksStr)
+	if err != nil || numBlocks <= 0 {
+		return 0, errors.Errorf("invalid value of %s: %q, positive integer is expected",
+			SimulationNumBlocksEnv, numBlocksStr)
+	}
+	return numBlocks, nil
+}
@@ -0,0 +1,40 @@
+package image
+
+import (
+	"bytes"
+	_ "embed"
+	"text/template"
+)
+
+var (
+	//go:embed Dockerfile.tmpl
+	tmpl       string
+	dockerfile = template.Must(template.New("dockerfile").Parse(tmpl))
+)
+
+// Data is the structure containing fields required by the template.
+type Data struct {
+	// From is the tag of the base image
+	From string
+
+	// ZNetBinary is the name of znet binary file to copy from build context
+	ZNetBinary string
+
+	// TestsDir is the name of the directory containing test binaries to copy from build context
+	TestsDir string
+
+	// BinDir is the directory where binaries are stored in the image
+	BinDir string
+
+	// NetworkFile is the path where file describing the network is expected to be mounted
+	NetworkFile string
+}
+
+// Execute executes dockerfile template and returns complete dockerfile.
+func Execute(data Data) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := dockerfile.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
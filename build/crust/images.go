@@ -0,0 +1,116 @@
+package crust
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/build"
+	"github.com/CoreumFoundation/crust/build/coreum"
+	"github.com/CoreumFoundation/crust/build/crust/image"
+	"github.com/CoreumFoundation/crust/build/docker"
+	dockerbasic "github.com/CoreumFoundation/crust/build/docker/basic"
+	"github.com/CoreumFoundation/crust/build/faucet"
+	"github.com/CoreumFoundation/crust/build/golang"
+)
+
+const (
+	invariantsDockerBinaryPath = "bin/.cache/docker/invariants/invariants"
+	indexerDockerBinaryPath    = "bin/.cache/docker/indexer/indexer"
+
+	integrationTestsDockerRootPath = "bin/.cache/docker/integration-tests"
+	znetDockerBinaryPath           = integrationTestsDockerRootPath + "/znet"
+)
+
+// BuildInvariants builds invariant verifier in docker.
+func BuildInvariants(ctx context.Context, deps build.DepsFunc) error {
+	deps(golang.EnsureGo)
+	return golang.BuildInDocker(ctx, golang.BinaryBuildConfig{
+		PackagePath:   "cmd/invariants",
+		BinOutputPath: invariantsDockerBinaryPath,
+	})
+}
+
+// BuildInvariantsDockerImage builds docker image of the invariant verifier.
+func BuildInvariantsDockerImage(ctx context.Context, deps build.DepsFunc) error {
+	deps(BuildInvariants)
+
+	dockerfile, err := dockerbasic.Execute(dockerbasic.Data{
+		From:   docker.AlpineImage,
+		Binary: filepath.Base(invariantsDockerBinaryPath),
+	})
+	if err != nil {
+		return err
+	}
+
+	return docker.BuildImage(ctx, docker.BuildImageConfig{
+		RepoPath:   repoPath,
+		ContextDir: filepath.Dir(invariantsDockerBinaryPath),
+		ImageName:  filepath.Base(invariantsDockerBinaryPath),
+		Dockerfile: dockerfile,
+	})
+}
+
+// BuildIndexer builds indexer in docker.
+func BuildIndexer(ctx context.Context, deps build.DepsFunc) error {
+	deps(golang.EnsureGo)
+	return golang.BuildInDocker(ctx, golang.BinaryBuildConfig{
+		PackagePath:   "cmd/indexer",
+		BinOutputPath: indexerDockerBinaryPath,
+	})
+}
+
+// BuildIndexerDockerImage builds docker image of the indexer.
+func BuildIndexerDockerImage(ctx context.Context, deps build.DepsFunc) error {
+	deps(BuildIndexer)
+
+	dockerfile, err := dockerbasic.Execute(dockerbasic.Data{
+		From:   docker.AlpineImage,
+		Binary: filepath.Base(indexerDockerBinaryPath),
+	})
+	if err != nil {
+		return err
+	}
+
+	return docker.BuildImage(ctx, docker.BuildImageConfig{
+		RepoPath:   repoPath,
+		ContextDir: filepath.Dir(indexerDockerBinaryPath),
+		ImageName:  filepath.Base(indexerDockerBinaryPath),
+		Dockerfile: dockerfile,
+	})
+}
+
+// BuildZNetInDocker builds znet in docker, to be packaged into the image running integration tests.
+func BuildZNetInDocker(ctx context.Context, deps build.DepsFunc) error {
+	deps(golang.EnsureGo)
+	return golang.BuildInDocker(ctx, golang.BinaryBuildConfig{
+		PackagePath:    "cmd/znet",
+		BinOutputPath:  znetDockerBinaryPath,
+		CGOEnabled:     true,
+		Tags:           []string{"muslc"},
+		LinkStatically: true,
+	})
+}
+
+// BuildIntegrationTestsDockerImage builds self-contained docker image running integration tests against the network
+// described by the mounted file, so tests may be executed on the machine other than the one which built them.
+func BuildIntegrationTestsDockerImage(ctx context.Context, deps build.DepsFunc) error {
+	deps(BuildZNetInDocker, coreum.BuildIntegrationTestsInDocker, faucet.BuildIntegrationTestsInDocker)
+
+	dockerfile, err := image.Execute(image.Data{
+		From:        docker.AlpineImage,
+		ZNetBinary:  filepath.Base(znetDockerBinaryPath),
+		TestsDir:    "integration-tests",
+		BinDir:      "/crust/bin",
+		NetworkFile: "/crust/network.yaml",
+	})
+	if err != nil {
+		return err
+	}
+
+	return docker.BuildImage(ctx, docker.BuildImageConfig{
+		RepoPath:   repoPath,
+		ContextDir: integrationTestsDockerRootPath,
+		ImageName:  "integration-tests",
+		Dockerfile: dockerfile,
+	})
+}
@@ -19,6 +19,10 @@ type Data struct {
 
 	// Binary is the name of binary file to copy from build context
 	Binary string
+
+	// ExtraBinaries are the names of additional binary files to copy from build context, e.g. other versions
+	// of the binary
+	ExtraBinaries []string
 }
 
 // Execute executes dockerfile template and returns complete dockerfile.
@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -14,11 +15,22 @@ import (
 	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
 	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
 	"github.com/CoreumFoundation/crust/build/git"
+	"github.com/CoreumFoundation/crust/build/tools"
 )
 
 // AlpineImage contains tag of alpine image used to build dockerfiles.
 const AlpineImage = "alpine:3.17.0"
 
+// Labels of the images built from source repositories, znet uses them to detect images older than the sources.
+const (
+	LabelRevision = "org.opencontainers.image.revision"
+	LabelRepoPath = "com.coreum.crust.repo-path"
+)
+
+// Platform is the platform images are built for. It always matches the architecture of binaries installed for docker,
+// so images run natively, even if docker is configured to use another default platform.
+var Platform = "linux/" + tools.DockerPlatform.Arch
+
 // BuildImageConfig contains the configuration required to build docker image.
 type BuildImageConfig struct {
 	// RepoPath is the path to the repo where binary comes from
@@ -37,9 +49,11 @@ type BuildImageConfig struct {
 // dockerBuildParamsInput is used to omit telescope antipattern.
 type dockerBuildParamsInput struct {
 	imageName  string
+	platform   string
 	contextDir string
 	commitHash string
 	tags       []string
+	labels     map[string]string
 }
 
 // BuildImage builds docker image.
@@ -62,11 +76,25 @@ func BuildImage(ctx context.Context, config BuildImageConfig) error {
 		return err
 	}
 
+	var labels map[string]string
+	if config.RepoPath != "" {
+		repoPath, err := filepath.Abs(config.RepoPath)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		labels = map[string]string{
+			LabelRevision: commitHash,
+			LabelRepoPath: repoPath,
+		}
+	}
+
 	buildParams := getDockerBuildParams(ctx, dockerBuildParamsInput{
 		imageName:  config.ImageName,
+		platform:   Platform,
 		contextDir: contextDir,
 		commitHash: commitHash,
 		tags:       tagsFromGit,
+		labels:     labels,
 	})
 
 	logger.Get(ctx).Info("Building docker images", zap.Any("build params", buildParams))
@@ -78,7 +106,7 @@ func BuildImage(ctx context.Context, config BuildImageConfig) error {
 
 // getTagsForDockerImage returns params for further use in "docker build" command.
 func getDockerBuildParams(ctx context.Context, input dockerBuildParamsInput) []string {
-	params := []string{"build", "-t", fmt.Sprintf("%s:znet", input.imageName)}
+	params := []string{"build", "--platform", input.platform, "-t", fmt.Sprintf("%s:znet", input.imageName)}
 
 	if input.commitHash != "" {
 		params = append(params, []string{"-t", fmt.Sprintf("%s:%s", input.imageName, input.commitHash[:7])}...)
@@ -93,6 +121,15 @@ func getDockerBuildParams(ctx context.Context, input dockerBuildParamsInput) []s
 		}
 	}
 
+	labelKeys := make([]string, 0, len(input.labels))
+	for k := range input.labels {
+		labelKeys = append(labelKeys, k)
+	}
+	sort.Strings(labelKeys)
+	for _, k := range labelKeys {
+		params = append(params, "--label", k+"="+input.labels[k])
+	}
+
 	params = append(params, []string{"-f", "-", input.contextDir}...)
 
 	return params
@@ -14,19 +14,26 @@ func TestGetTagsForDockerImage(t *testing.T) {
 		name                string
 		tagFromCommit       string
 		tagsFromGit         []string
+		labels              map[string]string
 		expectedBuildParams []string
 	}{
 		{
 			name:                "all_params",
 			tagFromCommit:       "35cca0686ef057d1325ad663958e3ab069d8379d",
 			tagsFromGit:         []string{"v0.0.1", "v0.0.1-rc"},
-			expectedBuildParams: []string{"build", "-t", "my-image:znet", "-t", "my-image:35cca06", "-t", "my-image:v0.0.1", "-t", "my-image:v0.0.1-rc", "-f", "-", "/app/"},
+			expectedBuildParams: []string{"build", "--platform", "linux/arm64", "-t", "my-image:znet", "-t", "my-image:35cca06", "-t", "my-image:v0.0.1", "-t", "my-image:v0.0.1-rc", "-f", "-", "/app/"},
 		},
 		{
 			name:                "onlyFromCommitAndZnet",
 			tagFromCommit:       "35cca0686ef057d1325ad663958e3ab069d8379d",
 			tagsFromGit:         []string{"allGitTagsMustBeSkipped", "v0.0.1-", "0.0.1", "v0.0.1-ra", "v0.0.1rc", "v0.0.1.rc"},
-			expectedBuildParams: []string{"build", "-t", "my-image:znet", "-t", "my-image:35cca06", "-f", "-", "/app/"},
+			expectedBuildParams: []string{"build", "--platform", "linux/arm64", "-t", "my-image:znet", "-t", "my-image:35cca06", "-f", "-", "/app/"},
+		},
+		{
+			name:                "withLabels",
+			tagFromCommit:       "35cca0686ef057d1325ad663958e3ab069d8379d",
+			labels:              map[string]string{LabelRevision: "35cca0686ef057d1325ad663958e3ab069d8379d", LabelRepoPath: "/src/coreum"},
+			expectedBuildParams: []string{"build", "--platform", "linux/arm64", "-t", "my-image:znet", "-t", "my-image:35cca06", "--label", "com.coreum.crust.repo-path=/src/coreum", "--label", "org.opencontainers.image.revision=35cca0686ef057d1325ad663958e3ab069d8379d", "-f", "-", "/app/"},
 		},
 	}
 
@@ -40,9 +47,11 @@ func TestGetTagsForDockerImage(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			tags = getDockerBuildParams(ctx, dockerBuildParamsInput{
 				imageName:  "my-image",
+				platform:   "linux/arm64",
 				contextDir: "/app/",
 				commitHash: tc.tagFromCommit, //nolint: scopelint
 				tags:       tc.tagsFromGit,   //nolint: scopelint
+				labels:     tc.labels,        //nolint: scopelint
 			})
 			assert.Equal(t, tc.expectedBuildParams, tags) //nolint: scopelint
 		})
@@ -13,6 +13,8 @@ const (
 	repoPath         = "../faucet"
 	dockerBinaryPath = "bin/.cache/docker/faucet/faucet"
 	testBinaryPath   = "bin/.cache/integration-tests/faucet"
+
+	testDockerBinaryPath = "bin/.cache/docker/integration-tests/integration-tests/faucet"
 )
 
 // Build builds faucet in docker.
@@ -36,6 +38,17 @@ func BuildIntegrationTests(ctx context.Context, deps build.DepsFunc) error {
 	})
 }
 
+// BuildIntegrationTestsInDocker builds faucet integration tests in docker, to be packaged into docker image.
+func BuildIntegrationTestsInDocker(ctx context.Context, deps build.DepsFunc) error {
+	deps(golang.EnsureGo, ensureRepo)
+
+	return golang.BuildTestsInDocker(ctx, golang.TestBuildConfig{
+		PackagePath:   "../faucet/integration-tests",
+		BinOutputPath: testDockerBinaryPath,
+		Tags:          []string{"integrationtests", "muslc"},
+	})
+}
+
 // Tidy runs `go mod tidy` for faucet repo.
 func Tidy(ctx context.Context, deps build.DepsFunc) error {
 	deps(ensureRepo)
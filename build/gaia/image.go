@@ -10,7 +10,8 @@ import (
 	"github.com/CoreumFoundation/crust/build/tools"
 )
 
-// BuildDockerImage builds docker image of the gaia.
+// BuildDockerImage builds docker image of the gaia. Besides the default version, binaries of all the versions
+// defined in the tools registry are included, named gaiad-<version>.
 func BuildDockerImage(ctx context.Context, deps build.DepsFunc) error {
 	const binaryName = "gaiad"
 
@@ -23,9 +24,23 @@ func BuildDockerImage(ctx context.Context, deps build.DepsFunc) error {
 		return err
 	}
 
+	var versionBinaries []string
+	for _, version := range tools.GaiaVersions() {
+		versionTool := tools.GaiaVersionTool(version)
+		versionBinary := binaryName + "-" + version
+		if err := tools.EnsureDocker(ctx, versionTool); err != nil {
+			return err
+		}
+		if err := tools.CopyToolBinaries(versionTool, gaiaLocalPath, versionBinary); err != nil {
+			return err
+		}
+		versionBinaries = append(versionBinaries, versionBinary)
+	}
+
 	dockerfile, err := dockerbasic.Execute(dockerbasic.Data{
-		From:   docker.AlpineImage,
-		Binary: binaryName,
+		From:          docker.AlpineImage,
+		Binary:        binaryName,
+		ExtraBinaries: versionBinaries,
 	})
 	if err != nil {
 		return err
@@ -10,8 +10,10 @@ require (
 	github.com/pkg/errors v0.9.1
 	github.com/samber/lo v1.37.0
 	github.com/stretchr/testify v1.8.1
+	github.com/ulikunitz/xz v0.5.11
 	go.uber.org/zap v1.23.0
 	golang.org/x/mod v0.6.0-dev.0.20220419223038-86c51ed26bb4
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -21,5 +23,4 @@ require (
 	go.uber.org/atomic v1.10.0 // indirect
 	go.uber.org/multierr v1.8.0 // indirect
 	golang.org/x/exp v0.0.0-20221019170559-20944726eadf // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -22,6 +22,7 @@ import (
 	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
 	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
 	"github.com/CoreumFoundation/coreum-tools/pkg/must"
+	"github.com/CoreumFoundation/crust/build/docker"
 	"github.com/CoreumFoundation/crust/build/tools"
 )
 
@@ -73,11 +74,6 @@ func EnsureGolangCI(ctx context.Context, deps build.DepsFunc) error {
 	return tools.EnsureLocal(ctx, tools.GolangCI)
 }
 
-// EnsureLibWASMVMMuslC ensures that libwasmvm_muslc is installed.
-func EnsureLibWASMVMMuslC(ctx context.Context, deps build.DepsFunc) error {
-	return tools.EnsureDocker(ctx, tools.LibWASMMuslC)
-}
-
 // BuildLocally builds binary locally.
 func BuildLocally(ctx context.Context, config BinaryBuildConfig) error {
 	logger.Get(ctx).Info("Building go package locally", zap.String("package", config.PackagePath),
@@ -99,11 +95,38 @@ func BuildLocally(ctx context.Context, config BinaryBuildConfig) error {
 
 // BuildInDocker builds binary inside docker container.
 func BuildInDocker(ctx context.Context, config BinaryBuildConfig) error {
-	// FIXME (wojciech): use docker API instead of docker executable
-
 	logger.Get(ctx).Info("Building go package in docker", zap.String("package", config.PackagePath),
 		zap.String("binary", config.BinOutputPath))
 
+	args, envs := buildArgsAndEnvs(config, "/crust/lib")
+	if err := runInBuildDocker(ctx, config.PackagePath, config.BinOutputPath, args, envs); err != nil {
+		return errors.Wrapf(err, "building package '%s' failed", config.PackagePath)
+	}
+	return nil
+}
+
+// BuildTestsInDocker builds tests inside docker container, so they may be executed in linux containers regardless
+// of the host platform. Tests are linked statically using the same toolchain and libraries as binaries.
+func BuildTestsInDocker(ctx context.Context, config TestBuildConfig) error {
+	logger.Get(ctx).Info("Building go tests in docker", zap.String("package", config.PackagePath),
+		zap.String("binary", config.BinOutputPath))
+
+	args, envs := buildArgsAndEnvs(BinaryBuildConfig{
+		Tags:           config.Tags,
+		CGOEnabled:     true,
+		LinkStatically: true,
+	}, "/crust/lib")
+	// `go build` is replaced by `go test -c`, with binaries instrumented like in BuildTests.
+	args = append([]string{"test", "-c", "-cover"}, args[1:]...)
+	if err := runInBuildDocker(ctx, config.PackagePath, config.BinOutputPath, args, envs); err != nil {
+		return errors.Wrapf(err, "building go tests '%s' failed", config.PackagePath)
+	}
+	return nil
+}
+
+func runInBuildDocker(ctx context.Context, packagePath, binOutputPath string, args, envs []string) error {
+	// FIXME (wojciech): use docker API instead of docker executable
+
 	if _, err := exec.LookPath("docker"); err != nil {
 		return errors.Wrap(err, "docker command is not available in PATH")
 	}
@@ -125,11 +148,10 @@ func BuildInDocker(ctx context.Context, config BinaryBuildConfig) error {
 	if err := os.MkdirAll(cacheDir, 0o700); err != nil {
 		return errors.WithStack(err)
 	}
-	workDir := filepath.Clean(filepath.Join("/src", "crust", config.PackagePath))
+	workDir := filepath.Clean(filepath.Join("/src", "crust", packagePath))
 	nameSuffix := make([]byte, 4)
 	must.Any(rand.Read(nameSuffix))
 
-	args, envs := buildArgsAndEnvs(config, "/crust-cache/lib")
 	runArgs := []string{
 		"run", "--rm",
 		"-v", srcDir + ":/src",
@@ -138,19 +160,17 @@ func BuildInDocker(ctx context.Context, config BinaryBuildConfig) error {
 		"--env", "GOPATH=/go",
 		"--env", "GOCACHE=/crust-cache/go-build",
 		"--workdir", workDir,
+		"--platform", docker.Platform,
 		"--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()),
-		"--name", "crust-build-" + filepath.Base(config.BinOutputPath) + "-" + hex.EncodeToString(nameSuffix),
+		"--name", "crust-build-" + filepath.Base(binOutputPath) + "-" + hex.EncodeToString(nameSuffix),
 	}
 	for _, env := range envs {
 		runArgs = append(runArgs, "--env", env)
 	}
 	runArgs = append(runArgs, image)
 	runArgs = append(runArgs, args...)
-	runArgs = append(runArgs, "-o", "/src/crust/"+config.BinOutputPath, ".")
-	if err := libexec.Exec(ctx, exec.Command("docker", runArgs...)); err != nil {
-		return errors.Wrapf(err, "building package '%s' failed", config.PackagePath)
-	}
-	return nil
+	runArgs = append(runArgs, "-o", "/src/crust/"+binOutputPath, ".")
+	return libexec.Exec(ctx, exec.Command("docker", runArgs...))
 }
 
 // BuildTests builds tests.
@@ -161,6 +181,8 @@ func BuildTests(ctx context.Context, config TestBuildConfig) error {
 	args := []string{
 		"test",
 		"-c",
+		// Binaries are instrumented, so coverage is collected whenever they are executed with -test.gocoverdir.
+		"-cover",
 		"-o", must.String(filepath.Abs(config.BinOutputPath)),
 	}
 	if len(config.Tags) > 0 {
@@ -183,12 +205,33 @@ var dockerfileTemplateParsed = template.Must(template.New("Dockerfile").Parse(do
 
 func ensureBuildDockerImage(ctx context.Context) (string, error) {
 	dockerfileBuf := &bytes.Buffer{}
+	type staticLib struct {
+		URL      string
+		Checksum string
+	}
+	libWASMVM := func(platform tools.Platform) staticLib {
+		source, exists := tools.ByName(tools.LibWASMMuslC).Sources[platform]
+		if !exists {
+			panic(errors.Errorf("tool %s is not configured for platform %s", tools.LibWASMMuslC, platform))
+		}
+		return staticLib{
+			URL:      source.URL,
+			Checksum: strings.TrimPrefix(source.Hash, "sha256:"),
+		}
+	}
+
 	err := dockerfileTemplateParsed.Execute(dockerfileBuf, struct {
-		GOVersion     string
-		AlpineVersion string
+		GOVersion      string
+		AlpineVersion  string
+		Arch           string
+		LibWASMVM      staticLib
+		LibWASMVMARM64 staticLib
 	}{
-		GOVersion:     tools.ByName(tools.Go).Version,
-		AlpineVersion: goAlpineVersion,
+		GOVersion:      tools.ByName(tools.Go).Version,
+		AlpineVersion:  goAlpineVersion,
+		Arch:           tools.DockerPlatform.Arch,
+		LibWASMVM:      libWASMVM(tools.DockerPlatform),
+		LibWASMVMARM64: libWASMVM(tools.Platform{OS: tools.DockerPlatform.OS, Arch: "arm64"}),
 	})
 	if err != nil {
 		return "", errors.Wrap(err, "executing Dockerfile template failed")
@@ -206,7 +249,8 @@ func ensureBuildDockerImage(ctx context.Context) (string, error) {
 		return image, nil
 	}
 
-	buildCmd := exec.Command("docker", "build", "--tag", image, "--tag", "crust-go-build:latest", "-")
+	buildCmd := exec.Command("docker", "build", "--platform", docker.Platform, "--tag", image,
+		"--tag", "crust-go-build:latest", "-")
 	buildCmd.Stdin = dockerfileBuf
 
 	if err := libexec.Exec(ctx, buildCmd); err != nil {
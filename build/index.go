@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/CoreumFoundation/coreum-tools/pkg/build"
+	"github.com/CoreumFoundation/crust/build/contracts"
 	"github.com/CoreumFoundation/crust/build/coreum"
 	"github.com/CoreumFoundation/crust/build/crust"
 	"github.com/CoreumFoundation/crust/build/faucet"
@@ -14,32 +15,42 @@ import (
 
 // Commands is a definition of commands available in build system.
 var Commands = map[string]build.CommandFunc{
-	"build":                   buildBinaries,
-	"build/crust":             crust.BuildCrust,
-	"build/cored":             coreum.BuildCored,
-	"build/faucet":            faucet.Build,
-	"build/znet":              crust.BuildZNet,
-	"build/integration-tests": buildIntegrationTests,
-	"images":                  buildDockerImages,
-	"images/cored":            coreum.BuildCoredDockerImage,
-	"images/faucet":           faucet.BuildDockerImage,
-	"images/gaiad":            gaia.BuildDockerImage,
-	"images/relayer":          relayer.BuildDockerImage,
-	"lint":                    lint,
-	"lint/coreum":             coreum.Lint,
-	"lint/crust":              crust.Lint,
-	"lint/faucet":             faucet.Lint,
-	"release":                 release,
-	"release/cored":           coreum.ReleaseCored,
-	"setup":                   tools.InstallAll,
-	"test":                    test,
-	"test/coreum":             coreum.Test,
-	"test/crust":              crust.Test,
-	"test/faucet":             faucet.Test,
-	"tidy":                    tidy,
-	"tidy/coreum":             coreum.Tidy,
-	"tidy/crust":              crust.Tidy,
-	"tidy/faucet":             faucet.Tidy,
+	"build":                    buildBinaries,
+	"build/crust":              crust.BuildCrust,
+	"build/contracts":          contracts.Build,
+	"build/cored":              coreum.BuildCored,
+	"build/faucet":             faucet.Build,
+	"build/indexer":            crust.BuildIndexer,
+	"build/invariants":         crust.BuildInvariants,
+	"build/znet":               crust.BuildZNet,
+	"build/integration-tests":  buildIntegrationTests,
+	"images":                   buildDockerImages,
+	"images/cored":             coreum.BuildCoredDockerImage,
+	"images/faucet":            faucet.BuildDockerImage,
+	"images/gaiad":             gaia.BuildDockerImage,
+	"images/indexer":           crust.BuildIndexerDockerImage,
+	"images/integration-tests": crust.BuildIntegrationTestsDockerImage,
+	"images/invariants":        crust.BuildInvariantsDockerImage,
+	"images/relayer":           relayer.BuildDockerImage,
+	"lint":                     lint,
+	"lint/coreum":              coreum.Lint,
+	"lint/crust":               crust.Lint,
+	"lint/faucet":              faucet.Lint,
+	"release":                  release,
+	"release/cored":            coreum.ReleaseCored,
+	"setup":                    tools.InstallAll,
+	"test":                     test,
+	"test/coreum":              coreum.Test,
+	"test/coreum-simulation":   coreum.RunSimulation,
+	"test/crust":               crust.Test,
+	"test/faucet":              faucet.Test,
+	"tidy":                     tidy,
+	"tidy/coreum":              coreum.Tidy,
+	"tidy/crust":               crust.Tidy,
+	"tidy/faucet":              faucet.Tidy,
+	"tools/list":               tools.List,
+	"tools/prune":              tools.Prune,
+	"tools/verify":             tools.Verify,
 }
 
 func tidy(ctx context.Context, deps build.DepsFunc) error {
@@ -68,7 +79,8 @@ func buildIntegrationTests(ctx context.Context, deps build.DepsFunc) error {
 }
 
 func buildDockerImages(ctx context.Context, deps build.DepsFunc) error {
-	deps(coreum.BuildCoredDockerImage, faucet.BuildDockerImage, gaia.BuildDockerImage, relayer.BuildDockerImage)
+	deps(coreum.BuildCoredDockerImage, faucet.BuildDockerImage, gaia.BuildDockerImage, relayer.BuildDockerImage,
+		crust.BuildInvariantsDockerImage, crust.BuildIndexerDockerImage)
 	return nil
 }
 
@@ -0,0 +1,190 @@
+package tools
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ulikunitz/xz"
+)
+
+// save stores the downloaded file in the tool directory, extracting it if it is an archive.
+func save(url string, file *os.File, path string) error {
+	switch {
+	case strings.HasSuffix(url, ".tar.gz"), strings.HasSuffix(url, ".tgz"):
+		reader, err := gzip.NewReader(file)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return untar(reader, path)
+	case strings.HasSuffix(url, ".tar.xz"):
+		reader, err := xz.NewReader(file)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return untar(reader, path)
+	case strings.HasSuffix(url, ".tar.bz2"):
+		return untar(bzip2.NewReader(file), path)
+	case strings.HasSuffix(url, ".zip"):
+		return unzip(file, path)
+	default:
+		f, err := os.OpenFile(filepath.Join(path, filepath.Base(url)), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o700)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer f.Close()
+		_, err = io.Copy(f, file)
+		return errors.WithStack(err)
+	}
+}
+
+func untar(reader io.Reader, path string) error {
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		switch {
+		case errors.Is(err, io.EOF):
+			return nil
+		case err != nil:
+			return errors.WithStack(err)
+		case header == nil:
+			continue
+		}
+		header.Name = path + "/" + header.Name
+
+		// We take mode from header.FileInfo().Mode(), not from header.Mode because they may be in different formats (meaning of bits may be different).
+		// header.FileInfo().Mode() returns compatible value.
+		mode := header.FileInfo().Mode()
+
+		switch {
+		case header.Typeflag == tar.TypeDir:
+			if err := os.MkdirAll(header.Name, mode); err != nil && !os.IsExist(err) {
+				return errors.WithStack(err)
+			}
+		case header.Typeflag == tar.TypeReg:
+			if err := ensureDir(header.Name); err != nil {
+				return err
+			}
+
+			f, err := os.OpenFile(header.Name, os.O_CREATE|os.O_WRONLY, mode)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			_, err = io.Copy(f, tr)
+			_ = f.Close()
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		case header.Typeflag == tar.TypeSymlink:
+			if err := ensureDir(header.Name); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, header.Name); err != nil {
+				return errors.WithStack(err)
+			}
+		case header.Typeflag == tar.TypeLink:
+			if err := ensureDir(header.Name); err != nil {
+				return err
+			}
+			// linked file may not exist yet, so let's create it - it will be overwritten later
+			f, err := os.OpenFile(header.Linkname, os.O_CREATE|os.O_EXCL, mode)
+			if err != nil {
+				if !os.IsExist(err) {
+					return errors.WithStack(err)
+				}
+			} else {
+				_ = f.Close()
+			}
+			if err := os.Link(header.Linkname, header.Name); err != nil {
+				return errors.WithStack(err)
+			}
+		default:
+			return errors.Errorf("unsupported file type: %d", header.Typeflag)
+		}
+	}
+}
+
+func unzip(file *os.File, path string) error {
+	info, err := file.Stat()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	zr, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, zf := range zr.File {
+		name := path + "/" + zf.Name
+		mode := zf.Mode()
+
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(name, mode.Perm()|0o700); err != nil && !os.IsExist(err) {
+				return errors.WithStack(err)
+			}
+		case mode&os.ModeSymlink != 0:
+			if err := ensureDir(name); err != nil {
+				return err
+			}
+			linkname, err := readZipFile(zf)
+			if err != nil {
+				return err
+			}
+			if err := os.Symlink(string(linkname), name); err != nil {
+				return errors.WithStack(err)
+			}
+		case mode.IsRegular():
+			if err := ensureDir(name); err != nil {
+				return err
+			}
+			if err := extractZipFile(zf, name); err != nil {
+				return err
+			}
+		default:
+			return errors.Errorf("unsupported file mode: %s", mode)
+		}
+	}
+	return nil
+}
+
+func extractZipFile(zf *zip.File, dst string) error {
+	r, err := zf.Open()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer r.Close()
+
+	f, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY, zf.Mode())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	return errors.WithStack(err)
+}
+
+func readZipFile(zf *zip.File) ([]byte, error) {
+	r, err := zf.Open()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
+	return content, errors.WithStack(err)
+}
+
+func ensureDir(file string) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); !os.IsExist(err) {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz"
+)
+
+func TestSaveZip(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "tool.zip")
+	f, err := os.Create(archivePath)
+	require.NoError(t, err)
+
+	zw := zip.NewWriter(f)
+	header := &zip.FileHeader{Name: "tool/bin/tool", Method: zip.Deflate}
+	header.SetMode(0o755)
+	w, err := zw.CreateHeader(header)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("binary"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	assertSaved(t, archivePath)
+}
+
+func TestSaveTarXZ(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "tool.tar.xz")
+	f, err := os.Create(archivePath)
+	require.NoError(t, err)
+
+	xw, err := xz.NewWriter(f)
+	require.NoError(t, err)
+	tw := tar.NewWriter(xw)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name:     "tool/bin/tool",
+		Typeflag: tar.TypeReg,
+		Mode:     0o755,
+		Size:     int64(len("binary")),
+	}))
+	_, err = tw.Write([]byte("binary"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, xw.Close())
+	require.NoError(t, f.Close())
+
+	assertSaved(t, archivePath)
+}
+
+func assertSaved(t *testing.T, archivePath string) {
+	t.Helper()
+
+	f, err := os.Open(archivePath)
+	require.NoError(t, err)
+	defer f.Close()
+
+	dstDir := t.TempDir()
+	require.NoError(t, save(archivePath, f, dstDir))
+
+	extracted, err := os.Open(filepath.Join(dstDir, "tool", "bin", "tool"))
+	require.NoError(t, err)
+	defer extracted.Close()
+
+	content, err := io.ReadAll(extracted)
+	require.NoError(t, err)
+	assert.Equal(t, "binary", string(content))
+}
@@ -0,0 +1,292 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/build"
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+)
+
+const (
+	// PruneAgeEnv is the name of environment variable defining the age in days after which installed tools are pruned
+	// from the cache even if they are still referenced.
+	PruneAgeEnv = "CRUST_TOOLS_PRUNE_AGE_DAYS"
+
+	// checksumsFile is the file stored in the directory of installed tool, containing checksums of its binaries.
+	checksumsFile = ".crust-checksums.json"
+)
+
+// installRecord is stored next to installed tool to verify its integrity later.
+type installRecord struct {
+	// Hash is the checksum of the source file tool has been installed from
+	Hash string `json:"hash"`
+
+	// Binaries maps paths of binaries, relative to tool directory, to their checksums
+	Binaries map[string]string `json:"binaries"`
+}
+
+// cacheEntry is a tool found in the cache.
+type cacheEntry struct {
+	Name     Name
+	Version  string
+	Platform Platform
+	Path     string
+	ModTime  time.Time
+}
+
+// Referenced returns true if entry is the version of tool defined by the registry.
+func (e cacheEntry) Referenced() bool {
+	tool, exists := tools[e.Name]
+	if !exists || tool.Version != e.Version {
+		return false
+	}
+	_, exists = tool.Sources[e.Platform]
+	return exists
+}
+
+// List prints tools installed in the cache.
+func List(ctx context.Context, deps build.DepsFunc) error {
+	if err := loadManifest(); err != nil {
+		return err
+	}
+	entries, err := scanCache(CacheDir())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TOOL\tVERSION\tPLATFORM\tSTATUS")
+	for _, e := range entries {
+		status := "installed"
+		if !e.Referenced() {
+			status = "unreferenced"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Name, e.Version, e.Platform, status)
+	}
+	return errors.WithStack(w.Flush())
+}
+
+// Verify checks that binaries of installed tools haven't been modified since installation and that they were installed
+// from the sources defined by the registry.
+func Verify(ctx context.Context, deps build.DepsFunc) error {
+	if err := loadManifest(); err != nil {
+		return err
+	}
+	entries, err := scanCache(CacheDir())
+	if err != nil {
+		return err
+	}
+
+	log := logger.Get(ctx)
+	var failed []string
+	for _, e := range entries {
+		if !e.Referenced() {
+			continue
+		}
+		if err := verifyInstalled(e); err != nil {
+			log.Error("Tool verification failed", zap.String("name", string(e.Name)), zap.Stringer("platform", e.Platform),
+				zap.Error(err))
+			failed = append(failed, string(e.Name)+" ("+e.Platform.String()+")")
+			continue
+		}
+		log.Info("Tool verified", zap.String("name", string(e.Name)), zap.Stringer("platform", e.Platform))
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("verification failed for tools: %s, remove them using `crust tools/prune` or reinstall",
+			strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// Prune removes from the cache the tools, and their unfinished downloads, which are not referenced by the registry
+// anymore. If PruneAgeEnv is set, tools installed earlier than the configured number of days ago are removed too.
+func Prune(ctx context.Context, deps build.DepsFunc) error {
+	if err := loadManifest(); err != nil {
+		return err
+	}
+
+	var maxAge time.Duration
+	if ageStr := os.Getenv(PruneAgeEnv); ageStr != "" {
+		days, err := strconv.Atoi(ageStr)
+		if err != nil || days < 0 {
+			return errors.Errorf("invalid value of %s: %q, non-negative integer is expected", PruneAgeEnv, ageStr)
+		}
+		maxAge = time.Duration(days) * 24 * time.Hour
+	}
+
+	for _, dir := range []string{CacheDir(), filepath.Join(CacheDir(), "downloads")} {
+		entries, err := scanCache(dir)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if e.Referenced() && (maxAge == 0 || time.Since(e.ModTime) < maxAge) {
+				continue
+			}
+			if err := pruneEntry(ctx, e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func pruneEntry(ctx context.Context, e cacheEntry) error {
+	unlock, err := lockTool(ctx, e.Name, e.Platform)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	logger.Get(ctx).Info("Pruning tool", zap.String("name", string(e.Name)), zap.String("version", e.Version),
+		zap.Stringer("platform", e.Platform), zap.String("path", e.Path))
+	return errors.WithStack(os.RemoveAll(e.Path))
+}
+
+// scanCache returns tools found in directories <dir>/<platform>/<name>-<version>. Only the tools known to the registry
+// are recognized.
+func scanCache(dir string) ([]cacheEntry, error) {
+	platformDirs, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// Longer names go first, so the tool is not matched with another one having the name being prefix of its name.
+	names := lo.Keys(tools)
+	sort.Slice(names, func(i, j int) bool {
+		return len(names[i]) > len(names[j])
+	})
+
+	var entries []cacheEntry
+	for _, platformDir := range platformDirs {
+		if !platformDir.IsDir() {
+			continue
+		}
+		platform, err := parsePlatform(platformDir.Name())
+		if err != nil {
+			continue
+		}
+
+		toolDirs, err := os.ReadDir(filepath.Join(dir, platformDir.Name()))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, toolDir := range toolDirs {
+			if !toolDir.IsDir() {
+				continue
+			}
+			name, found := lo.Find(names, func(name Name) bool {
+				return strings.HasPrefix(toolDir.Name(), string(name)+"-")
+			})
+			if !found {
+				continue
+			}
+			info, err := toolDir.Info()
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			entries = append(entries, cacheEntry{
+				Name:     name,
+				Version:  strings.TrimPrefix(toolDir.Name(), string(name)+"-"),
+				Platform: platform,
+				Path:     filepath.Join(dir, platformDir.Name(), toolDir.Name()),
+				ModTime:  info.ModTime(),
+			})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Name != entries[j].Name {
+			return entries[i].Name < entries[j].Name
+		}
+		if entries[i].Version != entries[j].Version {
+			return entries[i].Version < entries[j].Version
+		}
+		return entries[i].Platform.String() < entries[j].Platform.String()
+	})
+	return entries, nil
+}
+
+// saveInstallRecord stores checksums of tool binaries, so they may be verified later.
+func saveInstallRecord(toolDir string, source Source, binaries []string) error {
+	record := installRecord{
+		Hash:     source.Hash,
+		Binaries: map[string]string{},
+	}
+	for _, binary := range binaries {
+		checksum, err := fileChecksum(filepath.Join(toolDir, binary))
+		if err != nil {
+			return err
+		}
+		record.Binaries[binary] = checksum
+	}
+
+	content, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(filepath.Join(toolDir, checksumsFile), content, 0o600))
+}
+
+func verifyInstalled(e cacheEntry) error {
+	content, err := os.ReadFile(filepath.Join(e.Path, checksumsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return errors.New("checksums are not available, tool has been installed by an older version of crust")
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var record installRecord
+	if err := json.Unmarshal(content, &record); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if expectedHash := tools[e.Name].Sources[e.Platform].Hash; record.Hash != expectedHash {
+		return errors.Errorf("tool has been installed from different source, expected hash: %s, actual: %s",
+			expectedHash, record.Hash)
+	}
+	for binary, expectedChecksum := range record.Binaries {
+		checksum, err := fileChecksum(filepath.Join(e.Path, binary))
+		if err != nil {
+			return err
+		}
+		if checksum != expectedChecksum {
+			return errors.Errorf("binary %s has been modified, expected checksum: %s, actual: %s", binary,
+				expectedChecksum, checksum)
+		}
+	}
+	return nil
+}
+
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return fmt.Sprintf("sha256:%02x", hasher.Sum(nil)), nil
+}
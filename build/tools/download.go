@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum-tools/pkg/must"
+	"github.com/CoreumFoundation/coreum-tools/pkg/retry"
+)
+
+const (
+	// DownloadRetriesEnv is the name of environment variable used to configure number of retries done
+	// when downloading tool fails.
+	DownloadRetriesEnv = "CRUST_TOOLS_DOWNLOAD_RETRIES"
+
+	defaultDownloadRetries = 5
+	downloadInitialBackoff = time.Second
+	downloadMaxBackoff     = 30 * time.Second
+)
+
+// download downloads file from url and stores it under path.
+// Transient failures are retried with exponential backoff. If file exists already, download is resumed
+// from the point where the previous attempt finished.
+func download(ctx context.Context, url, path string) error {
+	retries, err := downloadRetries()
+	if err != nil {
+		return err
+	}
+
+	log := logger.Get(ctx)
+	// Proxy is configured using standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables. Credentials may be passed
+	// in the proxy URL.
+	proxyURL, err := http.ProxyFromEnvironment(must.HTTPRequest(http.NewRequestWithContext(ctx, http.MethodGet, url, nil)))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if proxyURL != nil {
+		log.Info("Downloading through proxy", zap.String("proxy", proxyURL.Redacted()))
+	}
+
+	backoff := downloadInitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := downloadAttempt(ctx, url, path)
+		if err == nil {
+			return nil
+		}
+
+		var retryableErr retry.RetryableError
+		if !errors.As(err, &retryableErr) || attempt > retries {
+			return err
+		}
+
+		log.Warn("Downloading tool failed, retrying", zap.Int("attempt", attempt), zap.Duration("backoff", backoff),
+			zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return errors.WithStack(ctx.Err())
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > downloadMaxBackoff {
+			backoff = downloadMaxBackoff
+		}
+	}
+}
+
+func downloadAttempt(ctx context.Context, url, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	req := must.HTTPRequest(http.NewRequestWithContext(ctx, http.MethodGet, url, nil))
+	if offset > 0 {
+		logger.Get(ctx).Info("Resuming download", zap.Int64("offset", offset))
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return errors.WithStack(ctx.Err())
+		}
+		return retry.Retryable(errors.WithStack(err))
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+	case resp.StatusCode == http.StatusOK:
+		// Server ignored the range request, so we have to start from scratch.
+		if offset > 0 {
+			if err := f.Truncate(0); err != nil {
+				return errors.WithStack(err)
+			}
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// File has been completely downloaded by the previous attempt, checksum is verified later.
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		return retry.Retryable(errors.Errorf("downloading %s failed, status code: %d", url, resp.StatusCode))
+	default:
+		return errors.Errorf("downloading %s failed, status code: %d", url, resp.StatusCode)
+	}
+
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		if ctx.Err() != nil {
+			return errors.WithStack(ctx.Err())
+		}
+		return retry.Retryable(errors.WithStack(err))
+	}
+	return nil
+}
+
+func downloadRetries() (int, error) {
+	retriesStr := os.Getenv(DownloadRetriesEnv)
+	if retriesStr == "" {
+		return defaultDownloadRetries, nil
+	}
+	retries, err := strconv.Atoi(retriesStr)
+	if err != nil || retries < 0 {
+		return 0, errors.Errorf("invalid value of %s: %q, non-negative integer is expected", DownloadRetriesEnv, retriesStr)
+	}
+	return retries, nil
+}
@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+)
+
+func TestDownloadResumes(t *testing.T) {
+	content := bytes.Repeat([]byte("crust"), 1000)
+
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "tool", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "tool")
+	require.NoError(t, os.WriteFile(path, content[:1234], 0o600))
+
+	require.NoError(t, download(testContext(), server.URL, path))
+
+	downloaded, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, downloaded)
+	assert.Equal(t, []string{"bytes=1234-"}, ranges)
+}
+
+func TestDownloadDoesNotRetryClientErrors(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	err := download(testContext(), server.URL, filepath.Join(t.TempDir(), "tool"))
+	require.Error(t, err)
+	assert.Equal(t, 1, requests)
+}
+
+func testContext() context.Context {
+	return logger.WithLogger(context.Background(), logger.New(logger.Config{
+		Format:  logger.FormatJSON,
+		Verbose: true,
+	}))
+}
@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum-tools/pkg/must"
+)
+
+// imagePlatform returns the platform of docker image containing binaries for the platform.
+func imagePlatform(platform Platform) string {
+	if platform.OS == dockerOS {
+		return "linux/" + platform.Arch
+	}
+	return platform.OS + "/" + platform.Arch
+}
+
+// imageMirrorFile returns the name of the file, created by `docker save`, containing docker image of the tool
+// in the mirror directory.
+func imageMirrorFile(name Name, version string) string {
+	return string(name) + "-" + version + ".tar"
+}
+
+// pullImage ensures that docker image containing binaries of the tool is available locally. Image is pulled only if it
+// doesn't exist yet. If mirror directory is set, image is loaded from the file stored there instead of being pulled.
+func pullImage(ctx context.Context, name Name, version, image string, platform Platform) error {
+	if !strings.Contains(image, "@sha256:") {
+		return errors.Errorf("image %s must be pinned by digest", image)
+	}
+	if _, err := exec.LookPath("docker"); err != nil {
+		return errors.Wrap(err, "docker command is not available in PATH")
+	}
+
+	exists, err := imageExists(ctx, image, platform)
+	if err != nil {
+		return err
+	}
+	if exists {
+		logger.Get(ctx).Info("Image exists already")
+		return nil
+	}
+
+	if mirrorDir := os.Getenv(MirrorDirEnv); mirrorDir != "" {
+		mirrorPath, err := findInMirror(mirrorDir, name, version, platform, imageMirrorFile(name, version))
+		if err != nil {
+			return err
+		}
+		logger.Get(ctx).Info("Loading image from mirror directory", zap.String("path", mirrorPath))
+		if err := libexec.Exec(ctx, exec.Command("docker", "load", "--input", mirrorPath)); err != nil {
+			return errors.Wrapf(err, "loading image from %s failed", mirrorPath)
+		}
+		// Digest is verified by docker when image is referenced by it, so image loaded from the file with different
+		// content or for different platform is not accepted.
+		exists, err := imageExists(ctx, image, platform)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errors.Errorf("file %s does not contain image %s for platform %s", mirrorPath, image,
+				imagePlatform(platform))
+		}
+		return nil
+	}
+
+	logger.Get(ctx).Info("Pulling image")
+	return libexec.Exec(ctx, exec.Command("docker", "pull", "--platform", imagePlatform(platform), image))
+}
+
+// imageExists returns true if docker image for the platform exists locally.
+func imageExists(ctx context.Context, image string, platform Platform) (bool, error) {
+	buf := &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, "docker", "image", "inspect", "--format", "{{.Os}}/{{.Architecture}}", image)
+	cmd.Stdout = buf
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// Image doesn't exist.
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "inspecting image %s failed", image)
+	}
+	return strings.TrimSpace(buf.String()) == imagePlatform(platform), nil
+}
+
+// copyFromImage copies binaries from docker image to the tool directory.
+func copyFromImage(ctx context.Context, image string, platform Platform, binaries []string, toolDir string) error {
+	nameSuffix := make([]byte, 4)
+	must.Any(rand.Read(nameSuffix))
+	containerName := "crust-tool-" + hex.EncodeToString(nameSuffix)
+
+	// Container is never started, it is created only to access the filesystem of the image.
+	if err := libexec.Exec(ctx, exec.Command("docker", "create", "--platform", imagePlatform(platform),
+		"--name", containerName, image)); err != nil {
+		return errors.Wrapf(err, "creating container from image %s failed", image)
+	}
+	defer func() {
+		// Context might be canceled already, but the container should be removed anyway.
+		if err := exec.Command("docker", "rm", containerName).Run(); err != nil {
+			logger.Get(ctx).Error("Removing container failed", zap.String("container", containerName), zap.Error(err))
+		}
+	}()
+
+	for _, binary := range binaries {
+		dstPath := filepath.Join(toolDir, binary)
+		if err := os.MkdirAll(filepath.Dir(dstPath), 0o700); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := libexec.Exec(ctx, exec.Command("docker", "cp", "--follow-link",
+			containerName+":/"+strings.TrimPrefix(binary, "/"), dstPath)); err != nil {
+			return errors.Wrapf(err, "copying %s from image %s failed", binary, image)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+)
+
+const lockPollInterval = 200 * time.Millisecond
+
+// lockTool acquires exclusive lock on the tool installed for the platform, so concurrent invocations of crust
+// don't install the same tool at the same time. Returned function releases the lock.
+func lockTool(ctx context.Context, name Name, platform Platform) (func(), error) {
+	lockDir := filepath.Join(CacheDir(), "locks")
+	if err := os.MkdirAll(lockDir, 0o700); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(lockDir, platform.String()+"-"+string(name)+".lock"), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	logged := false
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, syscall.EWOULDBLOCK) && !errors.Is(err, syscall.EINTR) {
+			_ = f.Close()
+			return nil, errors.WithStack(err)
+		}
+
+		if !logged {
+			logger.Get(ctx).Info("Waiting for another process to finish installing tool",
+				zap.String("name", string(name)), zap.Stringer("platform", platform))
+			logged = true
+		}
+		select {
+		case <-ctx.Done():
+			_ = f.Close()
+			return nil, errors.WithStack(ctx.Err())
+		case <-time.After(lockPollInterval):
+		}
+	}
+
+	return func() {
+		// Closing the file releases the lock.
+		_ = f.Close()
+	}, nil
+}
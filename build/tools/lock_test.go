@@ -0,0 +1,27 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockToolIsExclusive(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	unlock, err := lockTool(testContext(), Go, linuxAMD64)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(testContext(), time.Second)
+	defer cancel()
+	_, err = lockTool(ctx, Go, linuxAMD64)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	unlock()
+	unlock, err = lockTool(testContext(), Go, linuxAMD64)
+	require.NoError(t, err)
+	unlock()
+}
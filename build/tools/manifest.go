@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the name of the file, in the root directory of the repository, used to add or override tools.
+const ManifestFile = "crust-tools.yaml"
+
+var (
+	manifestOnce sync.Once
+	manifestErr  error
+)
+
+type manifest struct {
+	Tools map[Name]manifestTool `yaml:"tools"`
+}
+
+type manifestTool struct {
+	Version   string                    `yaml:"version"`
+	ForDocker bool                      `yaml:"forDocker"`
+	ForLocal  bool                      `yaml:"forLocal"`
+	Sources   map[string]manifestSource `yaml:"sources"`
+	Binaries  map[string]string         `yaml:"binaries"`
+}
+
+type manifestSource struct {
+	URL      string            `yaml:"url"`
+	Hash     string            `yaml:"hash"`
+	Image    string            `yaml:"image"`
+	Module   string            `yaml:"module"`
+	Package  string            `yaml:"package"`
+	Binaries map[string]string `yaml:"binaries"`
+}
+
+// loadManifest merges tools defined in the manifest file into the registry. It is done once, the first time any tool
+// is accessed.
+func loadManifest() error {
+	manifestOnce.Do(func() {
+		manifestErr = mergeManifest(ManifestFile)
+	})
+	return manifestErr
+}
+
+func mergeManifest(path string) error {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var m manifest
+	if err := yaml.Unmarshal(content, &m); err != nil {
+		return errors.Wrapf(err, "parsing tool manifest %s failed", path)
+	}
+
+	for name, mTool := range m.Tools {
+		tool, err := mTool.toTool()
+		if err != nil {
+			return errors.WithMessagef(err, "invalid definition of tool %s in %s", name, path)
+		}
+		tools[name] = tool
+	}
+	return nil
+}
+
+func (mt manifestTool) toTool() (Tool, error) {
+	if mt.Version == "" {
+		return Tool{}, errors.New("version is not set")
+	}
+	if !mt.ForLocal && !mt.ForDocker {
+		return Tool{}, errors.New("at least one of forLocal and forDocker must be set")
+	}
+	if len(mt.Sources) == 0 {
+		return Tool{}, errors.New("no sources defined")
+	}
+
+	tool := Tool{
+		Version:   mt.Version,
+		ForDocker: mt.ForDocker,
+		ForLocal:  mt.ForLocal,
+		Sources:   Sources{},
+		Binaries:  mt.Binaries,
+	}
+	for platformStr, mSource := range mt.Sources {
+		platform, err := parsePlatform(platformStr)
+		if err != nil {
+			return Tool{}, err
+		}
+		switch {
+		case mSource.Module != "":
+			if mSource.URL != "" || mSource.Image != "" {
+				return Tool{}, errors.Errorf("module can't be set together with url or image for platform %s",
+					platform)
+			}
+			if mSource.Package == "" {
+				return Tool{}, errors.Errorf("package of module is not set for platform %s", platform)
+			}
+			if !isModuleHash(mSource.Hash) {
+				return Tool{}, errors.Errorf("hash of module for platform %s must be in form h1:<checksum>", platform)
+			}
+		case mSource.Image != "":
+			if mSource.URL != "" {
+				return Tool{}, errors.Errorf("url and image can't be set together for platform %s", platform)
+			}
+			if !strings.Contains(mSource.Image, "@sha256:") {
+				return Tool{}, errors.Errorf("image for platform %s must be pinned by digest", platform)
+			}
+		case mSource.URL == "":
+			return Tool{}, errors.Errorf("neither url nor image is set for platform %s", platform)
+		case !strings.HasPrefix(mSource.Hash, "sha256:"):
+			return Tool{}, errors.Errorf("hash for platform %s must be in form sha256:<checksum>", platform)
+		}
+		tool.Sources[platform] = Source{
+			URL:      mSource.URL,
+			Hash:     mSource.Hash,
+			Image:    mSource.Image,
+			Module:   mSource.Module,
+			Package:  mSource.Package,
+			Binaries: mSource.Binaries,
+		}
+	}
+	return tool, nil
+}
+
+// parsePlatform parses platform in the same form as returned by Platform.String.
+func parsePlatform(platformStr string) (Platform, error) {
+	parts := strings.Split(platformStr, ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return Platform{}, errors.Errorf("invalid platform %q, expected <os>.<arch>, e.g. linux.amd64", platformStr)
+	}
+	return Platform{OS: parts[0], Arch: parts[1]}, nil
+}
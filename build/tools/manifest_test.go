@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeManifest(t *testing.T) {
+	const hermes Name = "hermes"
+	t.Cleanup(func() {
+		delete(tools, hermes)
+	})
+
+	path := filepath.Join(t.TempDir(), ManifestFile)
+	require.NoError(t, os.WriteFile(path, []byte(`
+tools:
+  hermes:
+    version: 1.4.0
+    forLocal: true
+    sources:
+      linux.amd64:
+        url: https://example.com/hermes-linux-amd64.tar.gz
+        hash: sha256:0000
+      linux.arm64:
+        module: example.com/hermes@v1.4.0
+        package: ./cmd/hermes
+        hash: h1:0000
+    binaries:
+      bin/hermes: hermes
+`), 0o600))
+
+	require.NoError(t, mergeManifest(path))
+	assert.Equal(t, Tool{
+		Version:  "1.4.0",
+		ForLocal: true,
+		Sources: Sources{
+			linuxAMD64: {
+				URL:  "https://example.com/hermes-linux-amd64.tar.gz",
+				Hash: "sha256:0000",
+			},
+			linuxARM64: {
+				Module:  "example.com/hermes@v1.4.0",
+				Package: "./cmd/hermes",
+				Hash:    "h1:0000",
+			},
+		},
+		Binaries: map[string]string{
+			"bin/hermes": "hermes",
+		},
+	}, tools[hermes])
+}
+
+func TestMergeManifestRejectsInvalidPlatform(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ManifestFile)
+	require.NoError(t, os.WriteFile(path, []byte(`
+tools:
+  hermes:
+    version: 1.4.0
+    forLocal: true
+    sources:
+      linux-amd64:
+        url: https://example.com/hermes.tar.gz
+        hash: sha256:0000
+`), 0o600))
+
+	require.Error(t, mergeManifest(path))
+	assert.NotContains(t, tools, Name("hermes"))
+}
+
+func TestMergeManifestRejectsModuleWithoutPackage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ManifestFile)
+	require.NoError(t, os.WriteFile(path, []byte(`
+tools:
+  hermes:
+    version: 1.4.0
+    forLocal: true
+    sources:
+      linux.arm64:
+        module: example.com/hermes@v1.4.0
+        hash: h1:0000
+`), 0o600))
+
+	require.Error(t, mergeManifest(path))
+	assert.NotContains(t, tools, Name("hermes"))
+}
@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MirrorDirEnv is the name of environment variable pointing to the directory containing pre-downloaded files
+	// of tools. Once it is set, tools are never downloaded from the internet.
+	MirrorDirEnv = "CRUST_TOOLS_MIRROR_DIR"
+
+	// MirrorEnv is the name of environment variable defining the mirror tools are downloaded from, instead of upstream
+	// servers. It is either the URL prefix (e.g. https://mirror.example.com/crust) to which the upstream URL without
+	// scheme is appended, or the comma-separated list of <host>=<URL prefix> pairs, rewriting only the listed hosts
+	// (e.g. github.com=https://mirror.example.com/github,go.dev=https://mirror.example.com/go).
+	MirrorEnv = "CRUST_TOOLS_MIRROR"
+)
+
+// findInMirror returns path to the file of the tool stored in the mirror directory.
+// File is looked up in the same layout as used by the download cache (<platform>/<name>-<version>/<file>),
+// and then directly in the mirror directory.
+func findInMirror(mirrorDir string, name Name, version string, platform Platform, fileName string) (string, error) {
+	candidates := []string{
+		filepath.Join(mirrorDir, platform.String(), string(name)+"-"+version, fileName),
+		filepath.Join(mirrorDir, fileName),
+	}
+	for _, path := range candidates {
+		info, err := os.Stat(path)
+		switch {
+		case err == nil && !info.IsDir():
+			return path, nil
+		case err != nil && !errors.Is(err, os.ErrNotExist):
+			return "", errors.WithStack(err)
+		}
+	}
+	return "", errors.Errorf("file of tool %s for platform %s does not exist in mirror directory, expected one of: %s",
+		name, platform, strings.Join(candidates, ", "))
+}
+
+// mirrorURL rewrites upstream URL of the tool according to the mirror configured using MirrorEnv.
+func mirrorURL(upstreamURL string) (string, error) {
+	mirror := strings.TrimSpace(os.Getenv(MirrorEnv))
+	if mirror == "" {
+		return upstreamURL, nil
+	}
+
+	u, err := url.Parse(upstreamURL)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	hostPath := u.Host + u.EscapedPath()
+	if u.RawQuery != "" {
+		hostPath += "?" + u.RawQuery
+	}
+
+	if !strings.Contains(mirror, "=") {
+		return strings.TrimSuffix(mirror, "/") + "/" + hostPath, nil
+	}
+
+	for _, pair := range strings.Split(mirror, ",") {
+		host, prefix, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || host == "" || prefix == "" {
+			return "", errors.Errorf("invalid value of %s: %q, expected <host>=<URL prefix> pairs", MirrorEnv, mirror)
+		}
+		if host == u.Host {
+			return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(hostPath, u.Host+"/"), nil
+		}
+	}
+	return upstreamURL, nil
+}
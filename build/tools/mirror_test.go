@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirrorURL(t *testing.T) {
+	const upstreamURL = "https://github.com/cosmos/relayer/releases/download/v2.2.0/Cosmos.Relayer_2.2.0_linux_amd64.tar.gz"
+
+	testCases := []struct {
+		mirror   string
+		expected string
+	}{
+		{
+			mirror:   "",
+			expected: upstreamURL,
+		},
+		{
+			mirror:   "https://mirror.example.com/crust/",
+			expected: "https://mirror.example.com/crust/github.com/cosmos/relayer/releases/download/v2.2.0/Cosmos.Relayer_2.2.0_linux_amd64.tar.gz",
+		},
+		{
+			mirror:   "go.dev=https://mirror.example.com/go, github.com=https://mirror.example.com/github",
+			expected: "https://mirror.example.com/github/cosmos/relayer/releases/download/v2.2.0/Cosmos.Relayer_2.2.0_linux_amd64.tar.gz",
+		},
+		{
+			mirror:   "go.dev=https://mirror.example.com/go",
+			expected: upstreamURL,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.mirror, func(t *testing.T) {
+			t.Setenv(MirrorEnv, tc.mirror)
+			mirrored, err := mirrorURL(upstreamURL)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, mirrored)
+		})
+	}
+}
+
+func TestFindImageInMirror(t *testing.T) {
+	mirrorDir := t.TempDir()
+	fileName := imageMirrorFile(Name("cored"), "v1.0.0")
+
+	_, err := findInMirror(mirrorDir, Name("cored"), "v1.0.0", dockerAMD64, fileName)
+	require.Error(t, err)
+
+	path := filepath.Join(mirrorDir, dockerAMD64.String(), "cored-v1.0.0", "cored-v1.0.0.tar")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+	found, err := findInMirror(mirrorDir, Name("cored"), "v1.0.0", dockerAMD64, fileName)
+	require.NoError(t, err)
+	assert.Equal(t, path, found)
+
+	_, err = findInMirror(mirrorDir, Name("cored"), "v1.0.0", dockerARM64, fileName)
+	require.Error(t, err)
+}
@@ -1,18 +1,17 @@
 package tools
 
 import (
-	"archive/tar"
-	"compress/gzip"
 	"context"
 	"crypto/sha256"
 	"fmt"
 	"hash"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
@@ -21,6 +20,7 @@ import (
 	"github.com/CoreumFoundation/coreum-tools/pkg/build"
 	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
 	"github.com/CoreumFoundation/coreum-tools/pkg/must"
+	"github.com/CoreumFoundation/coreum-tools/pkg/parallel"
 )
 
 // Tool names.
@@ -45,6 +45,10 @@ var tools = map[Name]Tool{
 				URL:  "https://go.dev/dl/go1.20.1.linux-amd64.tar.gz",
 				Hash: "sha256:000a5b1fca4f75895f78befeb2eecf10bfff3c428597f3f1e69133b63b911b02",
 			},
+			linuxARM64: {
+				URL:  "https://go.dev/dl/go1.20.1.linux-arm64.tar.gz",
+				Hash: "sha256:5e5e2926733595e6f3c5b5ad1089afac11c1490351855e87849d0e7702b1ec2e",
+			},
 			darwinAMD64: {
 				URL:  "https://go.dev/dl/go1.20.1.darwin-amd64.tar.gz",
 				Hash: "sha256:a300a45e801ab459f3008aae5bb9efbe9a6de9bcd12388f5ca9bbd14f70236de",
@@ -72,6 +76,13 @@ var tools = map[Name]Tool{
 					"bin/golangci-lint": "golangci-lint-1.51.2-linux-amd64/golangci-lint",
 				},
 			},
+			linuxARM64: {
+				URL:  "https://github.com/golangci/golangci-lint/releases/download/v1.51.2/golangci-lint-1.51.2-linux-arm64.tar.gz",
+				Hash: "sha256:9e03c47b7628d49f950445d74881a0e3cb3a1e6b3c5ac3b67672d600124c1b08",
+				Binaries: map[string]string{
+					"bin/golangci-lint": "golangci-lint-1.51.2-linux-arm64/golangci-lint",
+				},
+			},
 			darwinAMD64: {
 				URL:  "https://github.com/golangci/golangci-lint/releases/download/v1.51.2/golangci-lint-1.51.2-darwin-amd64.tar.gz",
 				Hash: "sha256:0549cbaa2df451cf3a2011a9d73a9cb127784d26749d9cd14c9f4818af104d44",
@@ -99,6 +110,12 @@ var tools = map[Name]Tool{
 				URL:  "https://github.com/ignite/cli/releases/download/v0.23.0/ignite_0.23.0_linux_amd64.tar.gz",
 				Hash: "sha256:915a96eb366fbf9c353af32d0ddb01796a30b86343ac77d613cc8a8af3dd395a",
 			},
+			// Release of ignite doesn't contain linux/arm64 binary, so it is built from the source code.
+			linuxARM64: {
+				Module:  "github.com/ignite/cli@v0.23.0",
+				Package: "./ignite/cmd/ignite",
+				Hash:    "h1:k8jDwG+hMd54KDvSrnEbo76ANM4NT9B9ReuuK/3md20=",
+			},
 			darwinAMD64: {
 				URL:  "https://github.com/ignite/cli/releases/download/v0.23.0/ignite_0.23.0_darwin_amd64.tar.gz",
 				Hash: "sha256:b9ca67a70f4d1b43609c4289a7e83dc2174754d35f30fb43f1518c0434361c4e",
@@ -137,9 +154,9 @@ var tools = map[Name]Tool{
 
 	// https://github.com/CosmWasm/wasmvm/releases
 	// Check compatibility with wasmd beore upgrading: https://github.com/CosmWasm/wasmd
+	// Library is not installed to the cache, it is baked into the go builder image instead.
 	LibWASMMuslC: {
-		Version:   "v1.1.1",
-		ForDocker: true,
+		Version: "v1.1.1",
 		Sources: Sources{
 			dockerAMD64: {
 				URL:  "https://github.com/CosmWasm/wasmvm/releases/download/v1.1.1/libwasmvm_muslc.x86_64.a",
@@ -238,10 +255,16 @@ func (p Platform) String() string {
 	return p.OS + "." + p.Arch
 }
 
-const dockerOS = "docker"
+const (
+	dockerOS = "docker"
+
+	// maxParallelInstalls is the maximum number of tools downloaded at the same time.
+	maxParallelInstalls = 4
+)
 
 var (
 	linuxAMD64  = Platform{OS: "linux", Arch: "amd64"}
+	linuxARM64  = Platform{OS: "linux", Arch: "arm64"}
 	darwinAMD64 = Platform{OS: "darwin", Arch: "amd64"}
 	darwinARM64 = Platform{OS: "darwin", Arch: "arm64"}
 	dockerAMD64 = Platform{OS: dockerOS, Arch: "amd64"}
@@ -265,8 +288,20 @@ type Tool struct {
 
 // Source represents source where tool is fetched from.
 type Source struct {
-	URL      string
-	Hash     string
+	URL  string
+	Hash string
+
+	// Image is the docker image, pinned by digest, binaries are copied from instead of downloading them from URL.
+	// Paths of binaries are relative to the root of image filesystem.
+	Image string
+
+	// Module is the Go module, in the form of <path>@<version>, the binary is built from, if tool doesn't publish it
+	// for the platform. Hash is the checksum of the module then, in the same form as in go.sum.
+	Module string
+
+	// Package is the path of the main package inside Module, binary is named after its last element.
+	Package string
+
 	Binaries map[string]string
 }
 
@@ -274,33 +309,93 @@ type Source struct {
 type Sources map[Platform]Source
 
 // InstallAll installs all the tools.
+// Tools are downloaded concurrently. Failure of one tool doesn't interrupt installation of the others,
+// each failed tool is rolled back independently and all the errors are reported at the end.
 func InstallAll(ctx context.Context, deps build.DepsFunc) error {
-	for tool := range tools {
-		if tools[tool].ForLocal {
-			if err := EnsureLocal(ctx, tool); err != nil {
-				return err
-			}
+	if err := loadManifest(); err != nil {
+		return err
+	}
+
+	type installation struct {
+		Tool     Name
+		Platform Platform
+	}
+
+	var installations []installation
+	for tool, info := range tools {
+		if info.ForLocal {
+			installations = append(installations, installation{Tool: tool, Platform: localPlatform()})
+		}
+		if info.ForDocker {
+			installations = append(installations, installation{Tool: tool, Platform: DockerPlatform})
+		}
+	}
+
+	var mu sync.Mutex
+	var failed []string
+	err := parallel.Run(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
+		slots := make(chan struct{}, maxParallelInstalls)
+		for i := 0; i < cap(slots); i++ {
+			slots <- struct{}{}
 		}
-		if tools[tool].ForDocker {
-			if err := EnsureDocker(ctx, tool); err != nil {
-				return err
-			}
+
+		for _, inst := range installations {
+			inst := inst
+			spawn("install."+string(inst.Tool)+"."+inst.Platform.String(), parallel.Continue, func(ctx context.Context) error {
+				select {
+				case <-ctx.Done():
+					return errors.WithStack(ctx.Err())
+				case <-slots:
+				}
+				defer func() {
+					slots <- struct{}{}
+				}()
+
+				if err := ensurePlatform(ctx, inst.Tool, inst.Platform); err != nil {
+					if errors.Is(err, ctx.Err()) {
+						return err
+					}
+					logger.Get(ctx).Error("Installing tool failed", zap.String("name", string(inst.Tool)),
+						zap.Stringer("platform", inst.Platform), zap.Error(err))
+
+					mu.Lock()
+					defer mu.Unlock()
+					failed = append(failed, string(inst.Tool)+" ("+inst.Platform.String()+")")
+				}
+				return nil
+			})
 		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return errors.Errorf("installing tools failed: %s", strings.Join(failed, ", "))
 	}
 	return nil
 }
 
 // EnsureLocal ensures that tool is installed locally.
 func EnsureLocal(ctx context.Context, tool Name) error {
-	return ensurePlatform(ctx, tool, Platform{OS: runtime.GOOS, Arch: runtime.GOARCH})
+	return ensurePlatform(ctx, tool, localPlatform())
+}
+
+func localPlatform() Platform {
+	return Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
 }
 
 // EnsureDocker ensures that tool is installed for docker.
 func EnsureDocker(ctx context.Context, tool Name) error {
-	return ensurePlatform(ctx, tool, Platform{OS: dockerOS, Arch: runtime.GOARCH})
+	return ensurePlatform(ctx, tool, DockerPlatform)
 }
 
 func ensurePlatform(ctx context.Context, tool Name, platform Platform) error {
+	if err := loadManifest(); err != nil {
+		return err
+	}
+
 	info, exists := tools[tool]
 	if !exists {
 		return errors.Errorf("tool %s is not defined", tool)
@@ -308,9 +403,21 @@ func ensurePlatform(ctx context.Context, tool Name, platform Platform) error {
 
 	source, exists := info.Sources[platform]
 	if !exists {
-		panic(errors.Errorf("tool %s is not configured for platform %s", tool, platform))
+		platforms := make([]string, 0, len(info.Sources))
+		for p := range info.Sources {
+			platforms = append(platforms, p.String())
+		}
+		sort.Strings(platforms)
+		return errors.Errorf("tool %s is not available for platform %s, supported platforms: %s", tool, platform,
+			strings.Join(platforms, ", "))
 	}
 
+	unlock, err := lockTool(ctx, tool, platform)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	toolDir := toolDir(tool, platform)
 	for dst, src := range lo.Assign(info.Binaries, source.Binaries) {
 		srcPath, err := filepath.Abs(toolDir + "/" + src)
@@ -349,18 +456,28 @@ func install(ctx context.Context, name Name, info Tool, platform Platform) (retE
 		panic(errors.Errorf("tool %s is not configured for platform %s", name, platform))
 	}
 	ctx = logger.With(ctx, zap.String("name", string(name)), zap.String("version", info.Version),
-		zap.String("url", source.URL), zap.Stringer("platform", platform))
+		zap.String("url", source.URL), zap.String("image", source.Image), zap.String("module", source.Module),
+		zap.Stringer("platform", platform))
 	log := logger.Get(ctx)
 	log.Info("Installing tool")
 
-	resp, err := http.DefaultClient.Do(must.HTTPRequest(http.NewRequestWithContext(ctx, http.MethodGet, source.URL, nil)))
+	binaries := lo.Assign(info.Binaries, source.Binaries)
+
+	var archivePath string
+	var downloaded bool
+	var err error
+	switch {
+	case source.Image != "":
+		err = pullImage(ctx, name, info.Version, source.Image, platform)
+	case source.Module != "":
+		// Module is downloaded and built once the directory of the tool is created.
+	default:
+		archivePath, downloaded, err = fetch(ctx, name, info.Version, platform, source)
+	}
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
-	defer resp.Body.Close()
 
-	hasher, expectedChecksum := hasher(source.Hash)
-	reader := io.TeeReader(resp.Body, hasher)
 	toolDir := toolDir(name, platform)
 	if err := os.RemoveAll(toolDir); err != nil && !os.IsNotExist(err) {
 		panic(err)
@@ -374,21 +491,35 @@ func install(ctx context.Context, name Name, info Tool, platform Platform) (retE
 		}
 	}()
 
-	if err := save(source.URL, reader, toolDir); err != nil {
-		return err
-	}
+	switch {
+	case source.Image != "":
+		if err := copyFromImage(ctx, source.Image, platform, lo.Values(binaries), toolDir); err != nil {
+			return err
+		}
+	case source.Module != "":
+		if err := buildModule(ctx, source, platform, toolDir); err != nil {
+			return err
+		}
+	default:
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer f.Close()
 
-	actualChecksum := fmt.Sprintf("%02x", hasher.Sum(nil))
-	if actualChecksum != expectedChecksum {
-		return errors.Errorf("checksum does not match for tool %s, expected: %s, actual: %s, url: %s", name,
-			expectedChecksum, actualChecksum, source.URL)
+		if err := save(source.URL, f, toolDir); err != nil {
+			return err
+		}
+		if downloaded {
+			must.OK(os.Remove(archivePath))
+		}
 	}
 
 	dstDir := "."
 	if platform.OS == dockerOS {
 		dstDir = filepath.Join(CacheDir(), platform.String())
 	}
-	for dst, src := range lo.Assign(info.Binaries, source.Binaries) {
+	for dst, src := range binaries {
 		srcPath := toolDir + "/" + src
 		dstPath := dstDir + "/" + dst
 		if err := os.Remove(dstPath); err != nil && !os.IsNotExist(err) {
@@ -406,10 +537,53 @@ func install(ctx context.Context, name Name, info Tool, platform Platform) (retE
 		log.Info("Tool installed to path", zap.String("path", dstPath))
 	}
 
+	if err := saveInstallRecord(toolDir, source, lo.Values(binaries)); err != nil {
+		return err
+	}
+
 	log.Info("Tool installed")
 	return nil
 }
 
+// fetch returns path to the verified file of the tool. If the mirror directory is configured, file is taken from there,
+// otherwise it is downloaded and the returned flag is set to true.
+func fetch(ctx context.Context, name Name, version string, platform Platform, source Source) (string, bool, error) {
+	if mirrorDir := os.Getenv(MirrorDirEnv); mirrorDir != "" {
+		mirrorPath, err := findInMirror(mirrorDir, name, version, platform, filepath.Base(source.URL))
+		if err != nil {
+			return "", false, err
+		}
+		logger.Get(ctx).Info("Using file from mirror directory", zap.String("path", mirrorPath))
+		if err := verifyChecksum(mirrorPath, source.Hash); err != nil {
+			return "", false, errors.WithMessagef(err, "verifying tool %s failed, path: %s", name, mirrorPath)
+		}
+		return mirrorPath, false, nil
+	}
+
+	downloadPath := filepath.Join(CacheDir(), "downloads", platform.String(), string(name)+"-"+version,
+		filepath.Base(source.URL))
+	if err := os.MkdirAll(filepath.Dir(downloadPath), 0o700); err != nil {
+		return "", false, errors.WithStack(err)
+	}
+	downloadURL, err := mirrorURL(source.URL)
+	if err != nil {
+		return "", false, err
+	}
+	if downloadURL != source.URL {
+		logger.Get(ctx).Info("Downloading tool from mirror", zap.String("mirrorURL", downloadURL))
+	}
+	if err := download(ctx, downloadURL, downloadPath); err != nil {
+		return "", false, err
+	}
+
+	if err := verifyChecksum(downloadPath, source.Hash); err != nil {
+		// Downloaded file is broken, so it can't be used to resume the next download.
+		must.OK(os.Remove(downloadPath))
+		return "", false, errors.WithMessagef(err, "verifying tool %s failed, url: %s", name, source.URL)
+	}
+	return downloadPath, true, nil
+}
+
 func hasher(hashStr string) (hash.Hash, string) {
 	parts := strings.SplitN(hashStr, ":", 2)
 	if len(parts) != 2 {
@@ -429,90 +603,23 @@ func hasher(hashStr string) (hash.Hash, string) {
 	return hasher, strings.ToLower(checksum)
 }
 
-func save(url string, reader io.Reader, path string) error {
-	switch {
-	case strings.HasSuffix(url, ".tar.gz"):
-		var err error
-		reader, err = gzip.NewReader(reader)
-		if err != nil {
-			return errors.WithStack(err)
-		}
-		return untar(reader, path)
-	default:
-		f, err := os.OpenFile(filepath.Join(path, filepath.Base(url)), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o700)
-		if err != nil {
-			return errors.WithStack(err)
-		}
-		defer f.Close()
-		_, err = io.Copy(f, reader)
+func verifyChecksum(path, hashStr string) error {
+	f, err := os.Open(path)
+	if err != nil {
 		return errors.WithStack(err)
 	}
-}
+	defer f.Close()
 
-func untar(reader io.Reader, path string) error {
-	tr := tar.NewReader(reader)
-	for {
-		header, err := tr.Next()
-		switch {
-		case errors.Is(err, io.EOF):
-			return nil
-		case err != nil:
-			return errors.WithStack(err)
-		case header == nil:
-			continue
-		}
-		header.Name = path + "/" + header.Name
-
-		// We take mode from header.FileInfo().Mode(), not from header.Mode because they may be in different formats (meaning of bits may be different).
-		// header.FileInfo().Mode() returns compatible value.
-		mode := header.FileInfo().Mode()
-
-		switch {
-		case header.Typeflag == tar.TypeDir:
-			if err := os.MkdirAll(header.Name, mode); err != nil && !os.IsExist(err) {
-				return errors.WithStack(err)
-			}
-		case header.Typeflag == tar.TypeReg:
-			if err := ensureDir(header.Name); err != nil {
-				return err
-			}
-
-			f, err := os.OpenFile(header.Name, os.O_CREATE|os.O_WRONLY, mode)
-			if err != nil {
-				return errors.WithStack(err)
-			}
-			_, err = io.Copy(f, tr)
-			_ = f.Close()
-			if err != nil {
-				return errors.WithStack(err)
-			}
-		case header.Typeflag == tar.TypeSymlink:
-			if err := ensureDir(header.Name); err != nil {
-				return err
-			}
-			if err := os.Symlink(header.Linkname, header.Name); err != nil {
-				return errors.WithStack(err)
-			}
-		case header.Typeflag == tar.TypeLink:
-			if err := ensureDir(header.Name); err != nil {
-				return err
-			}
-			// linked file may not exist yet, so let's create it - it will be overwritten later
-			f, err := os.OpenFile(header.Linkname, os.O_CREATE|os.O_EXCL, mode)
-			if err != nil {
-				if !os.IsExist(err) {
-					return errors.WithStack(err)
-				}
-			} else {
-				_ = f.Close()
-			}
-			if err := os.Link(header.Linkname, header.Name); err != nil {
-				return errors.WithStack(err)
-			}
-		default:
-			return errors.Errorf("unsupported file type: %d", header.Typeflag)
-		}
+	hasher, expectedChecksum := hasher(hashStr)
+	if _, err := io.Copy(hasher, f); err != nil {
+		return errors.WithStack(err)
 	}
+
+	actualChecksum := fmt.Sprintf("%02x", hasher.Sum(nil))
+	if actualChecksum != expectedChecksum {
+		return errors.Errorf("checksum does not match, expected: %s, actual: %s", expectedChecksum, actualChecksum)
+	}
+	return nil
 }
 
 // CacheDir returns path to cache directory.
@@ -529,18 +636,32 @@ func toolDir(name Name, platform Platform) string {
 	return filepath.Join(CacheDir(), platform.String(), string(name)+"-"+info.Version)
 }
 
-func ensureDir(file string) error {
-	if err := os.MkdirAll(filepath.Dir(file), 0o755); !os.IsExist(err) {
-		return errors.WithStack(err)
-	}
-	return nil
-}
-
 // ByName returns tool definition by its name.
 func ByName(name Name) Tool {
+	must.OK(loadManifest())
 	return tools[name]
 }
 
+// GaiaVersions returns versions of gaia available in addition to the default one. They are defined, e.g. in the
+// manifest, as tools named gaia-<version>, providing binary bin/gaiad-<version>.
+func GaiaVersions() []string {
+	must.OK(loadManifest())
+	prefix := string(Gaia) + "-"
+	var versions []string
+	for name := range tools {
+		if strings.HasPrefix(string(name), prefix) {
+			versions = append(versions, strings.TrimPrefix(string(name), prefix))
+		}
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// GaiaVersionTool returns the name of the tool providing the version of gaia.
+func GaiaVersionTool(version string) Name {
+	return Gaia + Name("-"+version)
+}
+
 // CopyToolBinaries moves the tools artifacts form the local cache to the target local location.
 // In case the binPath doesn't exist the method will create it.
 func CopyToolBinaries(tool Name, path string, binaryNames ...string) error {
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum-tools/pkg/run"
+	"github.com/CoreumFoundation/crust/pkg/indexer"
+)
+
+func main() {
+	run.Service("indexer", func(ctx context.Context) error {
+		var config indexer.Config
+		rootCmd := &cobra.Command{
+			Use:           "indexer",
+			SilenceUsage:  true,
+			SilenceErrors: true,
+			Short:         "Stores blocks, transactions and events produced by the cored node in postgres database",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return indexer.Run(ctx, config)
+			},
+		}
+		logger.AddFlags(logger.ServiceDefaultConfig, rootCmd.Flags())
+		rootCmd.Flags().StringVar(&config.Node, "node", "http://localhost:26657", "RPC address of the cored node")
+		rootCmd.Flags().StringVar(&config.Database, "database", "postgres://postgres@localhost:5432/db", "Connection string of postgres database")
+		rootCmd.Flags().StringVar(&config.Address, "address", ":8091", "Address the status server listens on")
+		rootCmd.Flags().DurationVar(&config.PollInterval, "poll-interval", 5*time.Second, "Interval between queries for new blocks, used if new block event is missed")
+		return rootCmd.Execute()
+	})
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum-tools/pkg/run"
+	"github.com/CoreumFoundation/crust/pkg/invariants"
+)
+
+func main() {
+	run.Service("invariants", func(ctx context.Context) error {
+		var config invariants.Config
+		rootCmd := &cobra.Command{
+			Use:           "invariants",
+			SilenceUsage:  true,
+			SilenceErrors: true,
+			Short:         "Verifies invariants of each new block produced by the cored nodes",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return invariants.Run(ctx, config)
+			},
+		}
+		logger.AddFlags(logger.ServiceDefaultConfig, rootCmd.Flags())
+		rootCmd.Flags().StringSliceVar(&config.Nodes, "node", nil, "RPC address of the cored node, may be passed many times")
+		rootCmd.Flags().StringVar(&config.Address, "address", ":8097", "Address the status server listens on")
+		rootCmd.Flags().DurationVar(&config.PollInterval, "poll-interval", 500*time.Millisecond, "Interval between queries for new blocks")
+		return rootCmd.Execute()
+	})
+}
@@ -2,11 +2,18 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+	"github.com/spf13/pflag"
 
 	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
 	"github.com/CoreumFoundation/coreum-tools/pkg/must"
@@ -15,6 +22,8 @@ import (
 	"github.com/CoreumFoundation/coreum/pkg/config/constant"
 	"github.com/CoreumFoundation/crust/infra"
 	"github.com/CoreumFoundation/crust/infra/apps"
+	"github.com/CoreumFoundation/crust/infra/apps/faucet"
+	"github.com/CoreumFoundation/crust/infra/targets"
 	"github.com/CoreumFoundation/crust/pkg/znet"
 )
 
@@ -28,40 +37,117 @@ func main() {
 		rootCmd.AddCommand(stopCmd(ctx, configF, cmdF))
 		rootCmd.AddCommand(removeCmd(ctx, configF, cmdF))
 		rootCmd.AddCommand(testCmd(ctx, configF, cmdF))
-		rootCmd.AddCommand(specCmd(configF, cmdF))
+		rootCmd.AddCommand(specCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(statusCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(rebuildCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(devCmd(ctx, configF, cmdF))
 		rootCmd.AddCommand(consoleCmd(ctx, configF, cmdF))
 		rootCmd.AddCommand(pingPongCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(benchCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(ibcCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(queryCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(fundCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(contractsCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(historyCmd(configF))
+		rootCmd.AddCommand(doctorCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(pullCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(pruneCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(upgradeCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(stateDiffCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(chaosCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(freezeCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(thawCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(backupCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(restoreCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(exportCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(serveCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(hostsCmd(ctx, configF, cmdF))
+		rootCmd.AddCommand(qrCmd(configF, cmdF))
+		rootCmd.AddCommand(accountsCmd(configF, cmdF))
+		rootCmd.AddCommand(docsCmd(rootCmd))
+		rootCmd.AddCommand(completionCmd(rootCmd))
+		rootCmd.AddCommand(ideCmd(configF, rootCmd))
+		rootCmd.AddCommand(ciMatrixCmd(configF))
 
-		return rootCmd.Execute()
+		znet.DescribeEnv(rootCmd)
+		return rootCmd.ExecuteContext(ctx)
 	})
 }
 
 func rootCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
 	rootCmd := &cobra.Command{
+		Use:           "znet",
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		Short:         "Creates preconfigured session for environment",
+		// Config is resolved for all the commands, including the ones not using CmdFactory.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return znet.ResolveConfig(configF, cmd)
+		},
 		RunE: cmdF.Cmd(func() error {
 			spec := infra.NewSpec(configF)
 			config := znet.NewConfig(configF, spec)
 			return znet.Activate(ctx, configF, config)
 		}),
 	}
+	// Completion command is defined explicitly, so only supported shells are offered.
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
 	logger.AddFlags(logger.ToolDefaultConfig, rootCmd.PersistentFlags())
-	rootCmd.PersistentFlags().StringVar(&configF.EnvName, "env", defaultString("CRUST_ZNET_ENV", "znet"), "Name of the environment to run in")
-	rootCmd.PersistentFlags().StringVar(&configF.HomeDir, "home", defaultString("CRUST_ZNET_HOME", must.String(os.UserCacheDir())+"/crust/znet"), "Directory where all files created automatically by znet are stored")
+	stringFlag(rootCmd.PersistentFlags(), &configF.EnvName, "env", "CRUST_ZNET_ENV", "znet", "Name of the environment to run in")
+	stringFlag(rootCmd.PersistentFlags(), &configF.EnvFile, "env-file", "CRUST_ZNET_ENV_FILE", "", "Path to the YAML file defining the environment, values set by flags take precedence over the file, which takes precedence over environment variables, "+infra.DefaultEnvFile+" is loaded from the current directory if it exists and path is not set")
+	stringFlag(rootCmd.PersistentFlags(), &configF.HomeDir, "home", "CRUST_ZNET_HOME", defaultHomeDir(ctx), "Directory where all files created automatically by znet are stored")
+	stringFlag(rootCmd.PersistentFlags(), &configF.ContainerRuntime, "container-runtime", "CRUST_ZNET_CONTAINER_RUNTIME", "", "CLI used to manage containers, docker or podman, docker is used if installed, podman otherwise")
+	stringFlag(rootCmd.PersistentFlags(), &configF.ImagePullPolicy, "image-pull-policy", "CRUST_ZNET_IMAGE_PULL_POLICY", string(infra.PullPolicyIfNotPresent), "Defines when images of the apps are pulled: always, if-not-present or never, images built by crust are never pulled")
+	stringFlag(rootCmd.PersistentFlags(), &configF.RegistryMirror, "registry-mirror", "CRUST_ZNET_REGISTRY_MIRROR", "", "Registry, optionally followed by the path, prefixing all the pulled images, e.g. mirror.example.com/dockerhub, Docker Hub images are expanded to their full path, e.g. library/postgres")
+	stringFlag(rootCmd.PersistentFlags(), &configF.RemoteHomeDir, "remote-home", "CRUST_ZNET_REMOTE_HOME", "", "Path on the remote docker host, selected by DOCKER_HOST or docker context, where the directory passed to --home is shared, e.g. using sshfs or NFS")
 	addBinDirFlag(rootCmd, configF)
 	addProfileFlag(rootCmd, configF)
 	addCoredVersionFlag(rootCmd, configF)
+	addGaiaVersionFlag(rootCmd, configF)
 	addFilterFlag(rootCmd, configF)
+	addSubnetFlag(rootCmd, configF)
+	addNetworkFlags(rootCmd, configF)
+	addAutoPortsFlag(rootCmd, configF)
+	addKeyFlags(rootCmd, configF)
+	addGenesisDenomsFlag(rootCmd, configF)
+	addGenesisParamsFlag(rootCmd, configF)
+	addGenesisPatchFlag(rootCmd, configF)
+	addSentriesFlag(rootCmd, configF)
+	addAccountPoolFlag(rootCmd, configF)
+	addContractsFlag(rootCmd, configF)
+	addResourcesFlag(rootCmd, configF)
+	addFaucetFlags(rootCmd, configF)
+	addMockServerFlag(rootCmd, configF)
+	addRelayerFlags(rootCmd, configF)
 	return rootCmd
 }
 
+// defaultHomeDir returns the default home directory of znet. If crust runs in the devcontainer using docker daemon
+// of the host, the cache directory is not visible to the daemon, so home directory is relocated to the workspace
+// mounted from the host.
+func defaultHomeDir(ctx context.Context) string {
+	homeDir := must.String(os.UserCacheDir()) + "/crust/znet"
+	if !targets.InDevContainer() {
+		return homeDir
+	}
+	devContainer, err := targets.DetectDevContainer(ctx)
+	if err != nil {
+		return homeDir
+	}
+	if _, err := devContainer.HostPath(homeDir); err == nil {
+		return homeDir
+	}
+	if workspaceDir := devContainer.WorkspaceDir(must.String(os.Getwd())); workspaceDir != "" {
+		return filepath.Join(workspaceDir, ".crust", "znet")
+	}
+	return homeDir
+}
+
 func startCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
 	startCmd := &cobra.Command{
 		Use:   "start",
 		Short: "Starts environment",
-		RunE: cmdF.Cmd(func() error {
+		RunE: cmdF.LockedCmd(func() error {
 			spec := infra.NewSpec(configF)
 			config := znet.NewConfig(configF, spec)
 			return znet.Start(ctx, config, spec)
@@ -70,6 +156,21 @@ func startCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdF
 	addBinDirFlag(startCmd, configF)
 	addProfileFlag(startCmd, configF)
 	addCoredVersionFlag(startCmd, configF)
+	addGaiaVersionFlag(startCmd, configF)
+	addSubnetFlag(startCmd, configF)
+	addNetworkFlags(startCmd, configF)
+	addAutoPortsFlag(startCmd, configF)
+	addKeyFlags(startCmd, configF)
+	addGenesisDenomsFlag(startCmd, configF)
+	addGenesisParamsFlag(startCmd, configF)
+	addGenesisPatchFlag(startCmd, configF)
+	addSentriesFlag(startCmd, configF)
+	addAccountPoolFlag(startCmd, configF)
+	addContractsFlag(startCmd, configF)
+	addResourcesFlag(startCmd, configF)
+	addFaucetFlags(startCmd, configF)
+	addMockServerFlag(startCmd, configF)
+	addRelayerFlags(startCmd, configF)
 
 	return startCmd
 }
@@ -78,7 +179,7 @@ func stopCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFa
 	return &cobra.Command{
 		Use:   "stop",
 		Short: "Stops environment",
-		RunE: cmdF.Cmd(func() error {
+		RunE: cmdF.LockedCmd(func() error {
 			spec := infra.NewSpec(configF)
 			config := znet.NewConfig(configF, spec)
 			return znet.Stop(ctx, config, spec)
@@ -90,7 +191,7 @@ func removeCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.Cmd
 	return &cobra.Command{
 		Use:   "remove",
 		Short: "Removes environment",
-		RunE: cmdF.Cmd(func() error {
+		RunE: cmdF.LockedCmd(func() error {
 			spec := infra.NewSpec(configF)
 			config := znet.NewConfig(configF, spec)
 			return znet.Remove(ctx, config, spec)
@@ -102,50 +203,502 @@ func testCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFa
 	testCmd := &cobra.Command{
 		Use:   "test",
 		Short: "Runs integration tests for all repos",
-		RunE: cmdF.Cmd(func() error {
+		RunE: cmdF.LockedCmd(func() error {
 			configF.Profiles = apps.IntegrationTestsProfiles()
 			spec := infra.NewSpec(configF)
 			config := znet.NewConfig(configF, spec)
-			return znet.Test(ctx, config, spec)
+			return znet.Test(ctx, configF, config, spec)
 		}),
 	}
 	addTestGroupFlag(testCmd, configF)
 	addBinDirFlag(testCmd, configF)
 	addFilterFlag(testCmd, configF)
 	addCoredVersionFlag(testCmd, configF)
+	addReportDirFlag(testCmd, configF)
+	addCoverageDirFlag(testCmd, configF)
+	addRecordTrafficFlags(testCmd, configF)
+	addTestParallelismFlag(testCmd, configF)
+	addResourcesFlag(testCmd, configF)
+	addNetworkFileFlag(testCmd, configF)
 	return testCmd
 }
 
-func specCmd(configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
-	return &cobra.Command{
+func specCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	var output string
+	var filter znet.SpecFilter
+	specCmd := &cobra.Command{
 		Use:   "spec",
 		Short: "Prints specification of running environment",
 		RunE: cmdF.Cmd(func() error {
+			networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+			if err != nil {
+				return err
+			}
 			spec := infra.NewSpec(configF)
-			return znet.Spec(spec)
+			znetConfig := znet.NewConfig(configF, spec)
+			appSet, err := apps.BuildAppSet(apps.NewFactory(znetConfig, spec, networkConfig), znetConfig.Profiles,
+				znetConfig.CoredVersion)
+			if err != nil {
+				return err
+			}
+			return znet.Spec(ctx, spec, appSet, output, filter)
 		}),
 	}
+	specCmd.Flags().StringVar(&output, "output", znet.SpecOutputTable, "Format of the spec: "+strings.Join(znet.SpecOutputs(), " | ")+", json and yaml follow the versioned schema, raw prints the internal state file")
+	specCmd.Flags().StringSliceVar(&filter.Apps, "app", nil, "Names of the apps to include, all the apps are included if not set")
+	specCmd.Flags().StringSliceVar(&filter.Types, "type", nil, "Types of the apps to include, e.g. cored, all the apps are included if not set")
+	must.OK(specCmd.RegisterFlagCompletionFunc("app", completeAppNames(configF, true)))
+	addBinDirFlag(specCmd, configF)
+	addProfileFlag(specCmd, configF)
+	return specCmd
 }
 
-func consoleCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+func statusCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+	must.OK(err)
+
 	return &cobra.Command{
+		Use:   "status",
+		Short: "Prints status of the applications and warns if images are older than their source repositories",
+		RunE: cmdF.Cmd(func() error {
+			spec := infra.NewSpec(configF)
+			znetConfig := znet.NewConfig(configF, spec)
+			appSet, err := apps.BuildAppSet(apps.NewFactory(znetConfig, spec, networkConfig), znetConfig.Profiles,
+				znetConfig.CoredVersion)
+			if err != nil {
+				return err
+			}
+			return znet.Status(ctx, appSet)
+		}),
+	}
+}
+
+func rebuildCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+	must.OK(err)
+
+	rebuildCmd := &cobra.Command{
+		Use:               "rebuild <app>",
+		Short:             "Rebuilds the image used by the application and recreates containers using it, preserving their state",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeAppNames(configF, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdF.LockedCmd(func() error {
+				spec := infra.NewSpec(configF)
+				znetConfig := znet.NewConfig(configF, spec)
+				appSet, err := apps.BuildAppSet(apps.NewFactory(znetConfig, spec, networkConfig), znetConfig.Profiles,
+					znetConfig.CoredVersion)
+				if err != nil {
+					return err
+				}
+				return znet.Rebuild(ctx, znetConfig, spec, appSet, args[0])
+			})(cmd, args)
+		},
+	}
+	addResourcesFlag(rebuildCmd, configF)
+	return rebuildCmd
+}
+
+func devCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	devCmd := &cobra.Command{
+		Use:               "dev [app...]",
+		Short:             "Watches source repositories of the images used by the applications, rebuilds images and recreates containers whenever sources change, preserving their state",
+		ValidArgsFunction: completeAppNames(configF, true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdF.Cmd(func() error {
+				return znet.Dev(ctx, configF, args)
+			})(cmd, args)
+		},
+	}
+	addBinDirFlag(devCmd, configF)
+	addResourcesFlag(devCmd, configF)
+	return devCmd
+}
+
+func consoleCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	var web bool
+	var address string
+	consoleCmd := &cobra.Command{
 		Use:   "console",
 		Short: "Starts tmux console on top of running environment",
 		RunE: cmdF.Cmd(func() error {
+			if web {
+				return znet.WebConsole(ctx, configF, address)
+			}
 			spec := infra.NewSpec(configF)
 			config := znet.NewConfig(configF, spec)
 			return znet.Console(ctx, config, spec)
 		}),
 	}
+	consoleCmd.Flags().BoolVar(&web, "web", false, "Serves web UI streaming logs, showing health and endpoints of the apps and allowing to restart them, instead of starting tmux")
+	consoleCmd.Flags().StringVar(&address, "address", znet.DefaultWebConsoleAddress, "Address web console listens on")
+	return consoleCmd
 }
 
 func pingPongCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
 	networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
 	must.OK(err)
 
-	return &cobra.Command{
+	config := znet.DefaultPingPongConfig
+	pingPongCmd := &cobra.Command{
 		Use:   "ping-pong",
-		Short: "Sends tokens back and forth to generate transactions",
+		Short: "Sends transactions between generated accounts to generate traffic and reports achieved TPS",
+		RunE: cmdF.Cmd(func() error {
+			spec := infra.NewSpec(configF)
+			znetConfig := znet.NewConfig(configF, spec)
+			appF := apps.NewFactory(znetConfig, spec, networkConfig)
+			appSet, err := apps.BuildAppSet(appF, znetConfig.Profiles, znetConfig.CoredVersion)
+			if err != nil {
+				return err
+			}
+			return znet.PingPong(ctx, appSet, config)
+		}),
+	}
+	pingPongCmd.Flags().Float64Var(&config.TPS, "tps", config.TPS, "Number of transactions broadcast per second")
+	pingPongCmd.Flags().IntVar(&config.Accounts, "accounts", config.Accounts, "Number of accounts generated to send transactions from, each one broadcasts one transaction at a time")
+	pingPongCmd.Flags().StringToIntVar(&config.MsgMix, "msg-mix", config.MsgMix, "Weights of message types sent, e.g. send=8,multi-send=1,delegate=1, supported types: "+strings.Join(znet.PingPongMsgTypes(), " | "))
+	pingPongCmd.Flags().DurationVar(&config.Duration, "duration", 0, "Time after which ping-pong stops, it runs until interrupted if not set")
+	pingPongCmd.Flags().BoolVar(&config.IBC, "ibc", false, "Transfers tokens between cored and gaia over the relayer, verifying balances on both chains, instead of sending them between accounts of cored, requires ibc profile")
+	return pingPongCmd
+}
+
+func benchCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+	must.OK(err)
+
+	config := znet.DefaultBenchConfig
+	benchCmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Broadcasts transactions signed offline concurrently across all cored nodes and reports TPS and inclusion latency",
+		RunE: cmdF.Cmd(func() error {
+			spec := infra.NewSpec(configF)
+			znetConfig := znet.NewConfig(configF, spec)
+			appF := apps.NewFactory(znetConfig, spec, networkConfig)
+			appSet, err := apps.BuildAppSet(appF, znetConfig.Profiles, znetConfig.CoredVersion)
+			if err != nil {
+				return err
+			}
+			return znet.Bench(ctx, appSet, config)
+		}),
+	}
+	benchCmd.Flags().IntVar(&config.Accounts, "accounts", config.Accounts, "Number of accounts generated to broadcast transactions concurrently")
+	benchCmd.Flags().IntVar(&config.Txs, "txs", config.Txs, "Number of transactions signed and broadcast")
+	benchCmd.Flags().Float64Var(&config.TPS, "tps", config.TPS, "Mean rate of arriving transactions, 0 means all of them arrive at once")
+	benchCmd.Flags().StringVar(&config.Arrival, "arrival", config.Arrival, "Distribution of intervals between arriving transactions: "+strings.Join(znet.BenchArrivals(), " | "))
+	benchCmd.Flags().Float64Var(&config.RateLimit, "rate-limit", config.RateLimit, "Maximum sustained rate of broadcasting transactions, 0 means no limit")
+	benchCmd.Flags().IntVar(&config.Burst, "burst", config.Burst, "Number of transactions which may be broadcast at once above the rate limit")
+	benchCmd.Flags().Float64Var(&config.AccountReuse, "account-reuse", config.AccountReuse, "Probability that transaction is sent by the account which has already sent one")
+	benchCmd.Flags().StringToIntVar(&config.MsgMix, "msg-mix", config.MsgMix, "Weights of message types sent, e.g. send=8,multi-send=1,delegate=1, supported types: "+strings.Join(znet.PingPongMsgTypes(), " | "))
+	benchCmd.Flags().StringVar(&config.MsgMixFrom, "msg-mix-from", config.MsgMixFrom, "RPC address of the node, e.g. of mainnet, the message mix is sampled from, it overrides --msg-mix")
+	benchCmd.Flags().StringVar(&config.OutputDir, "output-dir", config.OutputDir, "Directory where the report is stored")
+	return benchCmd
+}
+
+func queryCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+	must.OK(err)
+
+	var height int64
+	queryCmd := &cobra.Command{
+		Use:   "query [flags] -- <cored query args>",
+		Short: "Queries the state of the chain, use --height to query historical state",
+		Args:  cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdF.Cmd(func() error {
+				spec := infra.NewSpec(configF)
+				znetConfig := znet.NewConfig(configF, spec)
+				appF := apps.NewFactory(znetConfig, spec, networkConfig)
+				appSet, err := apps.BuildAppSet(appF, znetConfig.Profiles, znetConfig.CoredVersion)
+				if err != nil {
+					return err
+				}
+				return znet.Query(ctx, appSet, height, args)
+			})(cmd, args)
+		},
+	}
+	queryCmd.Flags().Int64Var(&height, "height", 0, "Height to query the state at, latest one is used if not set")
+	return queryCmd
+}
+
+func fundCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	var useFaucet bool
+	fundCmd := &cobra.Command{
+		Use:   "fund [address] [amount]",
+		Short: "Sends tokens to the address from the funding account or through the faucet",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdF.Cmd(func() error {
+				var amount sdk.Coins
+				if len(args) == 2 {
+					if useFaucet {
+						return errors.New("amount can't be set if faucet is used, faucet always sends the same amount")
+					}
+					var err error
+					amount, err = sdk.ParseCoinsNormalized(args[1])
+					if err != nil {
+						return errors.Wrapf(err, "invalid amount %q", args[1])
+					}
+				}
+
+				networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+				if err != nil {
+					return err
+				}
+				spec := infra.NewSpec(configF)
+				znetConfig := znet.NewConfig(configF, spec)
+				appSet, err := apps.BuildAppSet(apps.NewFactory(znetConfig, spec, networkConfig), znetConfig.Profiles,
+					znetConfig.CoredVersion)
+				if err != nil {
+					return err
+				}
+				if useFaucet {
+					return znet.FundFromFaucet(ctx, appSet, args[0])
+				}
+				_, err = znet.Fund(ctx, appSet, args[0], amount)
+				return err
+			})(cmd, args)
+		},
+	}
+	fundCmd.Flags().BoolVar(&useFaucet, "faucet", false, "Requests tokens from the faucet instead of sending them from the funding account")
+	return fundCmd
+}
+
+func contractsCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	contractsCmd := &cobra.Command{
+		Use:   "contracts",
+		Short: "Manages WASM contracts deployed on the chain",
+	}
+
+	var instantiateMsgFile, label, funds string
+	deployCmd := &cobra.Command{
+		Use:   "deploy [wasm-file]",
+		Short: "Stores and instantiates WASM contract, code ID and address of the contract are printed",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdF.Cmd(func() error {
+				fundsCoins, err := sdk.ParseCoinsNormalized(funds)
+				if err != nil {
+					return errors.Wrapf(err, "invalid funds %q", funds)
+				}
+
+				networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+				if err != nil {
+					return err
+				}
+				spec := infra.NewSpec(configF)
+				znetConfig := znet.NewConfig(configF, spec)
+				appSet, err := apps.BuildAppSet(apps.NewFactory(znetConfig, spec, networkConfig), znetConfig.Profiles,
+					znetConfig.CoredVersion)
+				if err != nil {
+					return err
+				}
+
+				return znet.ContractsDeploy(ctx, appSet, znet.ContractDeployment{
+					WASMFile:           args[0],
+					InstantiateMsgFile: instantiateMsgFile,
+					Label:              label,
+					Funds:              fundsCoins,
+				})
+			})(cmd, args)
+		},
+	}
+	deployCmd.Flags().StringVar(&instantiateMsgFile, "instantiate-msg", "", "Path to the JSON file containing instantiate message, empty object is used if not set")
+	deployCmd.Flags().StringVar(&label, "label", "", "Label of the contract, name of the WASM file is used if not set")
+	deployCmd.Flags().StringVar(&funds, "funds", "", "Tokens sent to the contract on instantiation, e.g. 1000000udevcore")
+
+	contractsCmd.AddCommand(deployCmd)
+	return contractsCmd
+}
+
+func upgradeCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	var upgradeName string
+	upgradeCmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Starts environment on the old version of cored and upgrades it using governance proposal",
+		RunE: cmdF.LockedCmd(func() error {
+			if configF.CoredVersion == "" {
+				configF.CoredVersion = znet.DefaultUpgradeFromVersion
+			}
+			spec := infra.NewSpec(configF)
+			config := znet.NewConfig(configF, spec)
+			return znet.Upgrade(ctx, config, spec, upgradeName)
+		}),
+	}
+	addBinDirFlag(upgradeCmd, configF)
+	addProfileFlag(upgradeCmd, configF)
+	addCoredVersionFlag(upgradeCmd, configF)
+	addResourcesFlag(upgradeCmd, configF)
+	upgradeCmd.Flags().StringVar(&upgradeName, "upgrade-name", znet.DefaultUpgradeName, "Name of the upgrade to propose, it must be handled by the new version of cored")
+
+	return upgradeCmd
+}
+
+func stateDiffCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	var diffConfig znet.StateDiffConfig
+	stateDiffCmd := &cobra.Command{
+		Use:   "state-diff",
+		Short: "Runs the same scenario on two versions of cored and compares exported states of the modules",
+		RunE: cmdF.Cmd(func() error {
+			return znet.StateDiff(ctx, configF, diffConfig)
+		}),
+	}
+	addBinDirFlag(stateDiffCmd, configF)
+	addProfileFlag(stateDiffCmd, configF)
+	stateDiffCmd.Flags().StringVar(&diffConfig.BaseVersion, "base-version", znet.DefaultUpgradeFromVersion, "Version of cored producing the reference state, empty means the current one")
+	stateDiffCmd.Flags().StringVar(&diffConfig.TargetVersion, "target-version", "", "Version of cored compared to the base one, empty means the current one")
+	stateDiffCmd.Flags().StringVar(&diffConfig.Scenario, "scenario", "", "Path to the bash script executed in both environments, znet commands are available there")
+	stateDiffCmd.Flags().IntVar(&diffConfig.Blocks, "blocks", znet.DefaultStateDiffBlocks, "Number of blocks produced after the scenario, before state is exported")
+	stateDiffCmd.Flags().StringVar(&diffConfig.OutputDir, "output-dir", "state-diff", "Directory where exported states and differences are stored")
+	stateDiffCmd.Flags().StringSliceVar(&diffConfig.Ignore, "ignore", nil, "Paths excluded from comparison, e.g. mint.minter")
+
+	return stateDiffCmd
+}
+
+func doctorCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+	must.OK(err)
+
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnoses problems with the setup of the host, like docker version, disk space, limits and ports required by the profiles",
+		RunE: cmdF.Cmd(func() error {
+			spec := infra.NewSpec(configF)
+			config := znet.NewConfig(configF, spec)
+			appSet, err := apps.BuildAppSet(apps.NewFactory(config, spec, networkConfig), config.Profiles,
+				config.CoredVersion)
+			if err != nil {
+				return err
+			}
+			return znet.Doctor(ctx, config, appSet)
+		}),
+	}
+	addProfileFlag(doctorCmd, configF)
+	return doctorCmd
+}
+
+func pullCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+	must.OK(err)
+
+	pullCmd := &cobra.Command{
+		Use:   "pull",
+		Short: "Pulls images of all the applications in the profiles, so environment may be started without access to the registry",
+		RunE: cmdF.Cmd(func() error {
+			spec := infra.NewSpec(configF)
+			config := znet.NewConfig(configF, spec)
+			appSet, err := apps.BuildAppSet(apps.NewFactory(config, spec, networkConfig), config.Profiles,
+				config.CoredVersion)
+			if err != nil {
+				return err
+			}
+			return znet.Pull(ctx, appSet)
+		}),
+	}
+	addProfileFlag(pullCmd, configF)
+	return pullCmd
+}
+
+func pruneCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	var dryRun, all bool
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Removes docker containers, networks and volumes of environments which home directories don't exist anymore",
+		RunE: cmdF.Cmd(func() error {
+			return znet.Prune(ctx, configF, dryRun, all)
+		}),
+	}
+	pruneCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Prints orphaned resources without removing them")
+	pruneCmd.Flags().BoolVar(&all, "all", false, "Prunes resources created by other hosts sharing the docker daemon too")
+	return pruneCmd
+}
+
+func historyCmd(configF *infra.ConfigFactory) *cobra.Command {
+	var limit int
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "Prints commands executed in the environment",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return znet.History(configF, limit)
+		},
+	}
+	historyCmd.Flags().IntVar(&limit, "limit", 50, "Number of the most recent commands to print, 0 means all")
+	return historyCmd
+}
+
+func ibcCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+	must.OK(err)
+
+	ibcCmd := &cobra.Command{
+		Use:   "ibc",
+		Short: "Manages IBC setup of running environment",
+	}
+	ibcCmd.AddCommand(&cobra.Command{
+		Use:   "reset",
+		Short: "Regenerates relayer paths, use it after IBC chain has been recreated",
+		RunE: cmdF.LockedCmd(func() error {
+			spec := infra.NewSpec(configF)
+			config := znet.NewConfig(configF, spec)
+			return znet.IBCReset(ctx, config, spec)
+		}),
+	})
+	ibcCmd.AddCommand(&cobra.Command{
+		Use:       "mock [scenario...]",
+		Short:     "Acts as mock IBC counterparty sending malformed and unexpected handshake messages and packets to cored, and verifies they are handled safely",
+		ValidArgs: znet.IBCMockScenarios(),
+		Args:      cobra.OnlyValidArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdF.Cmd(func() error {
+				spec := infra.NewSpec(configF)
+				znetConfig := znet.NewConfig(configF, spec)
+				appF := apps.NewFactory(znetConfig, spec, networkConfig)
+				appSet, err := apps.BuildAppSet(appF, znetConfig.Profiles, znetConfig.CoredVersion)
+				if err != nil {
+					return err
+				}
+				return znet.IBCMock(ctx, appSet, args)
+			})(cmd, args)
+		},
+	})
+	return ibcCmd
+}
+
+func freezeCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "freeze",
+		Short: "Pauses all the applications, use it before suspending the host",
+		RunE: cmdF.LockedCmd(func() error {
+			spec := infra.NewSpec(configF)
+			return znet.Freeze(ctx, spec)
+		}),
+	}
+}
+
+func thawCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "thaw",
+		Short: "Resumes applications paused by freeze and waits until chain produces new block",
+		RunE: cmdF.LockedCmd(func() error {
+			spec := infra.NewSpec(configF)
+			config := znet.NewConfig(configF, spec)
+			return znet.Thaw(ctx, config, spec)
+		}),
+	}
+}
+
+func exportCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+	must.OK(err)
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Exports the environment to the formats understood by other tools",
+	}
+
+	var output string
+	composeCmd := &cobra.Command{
+		Use:   "compose",
+		Short: "Renders applications of the environment into docker-compose file",
 		RunE: cmdF.Cmd(func() error {
 			spec := infra.NewSpec(configF)
 			znetConfig := znet.NewConfig(configF, spec)
@@ -154,9 +707,371 @@ func pingPongCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.C
 			if err != nil {
 				return err
 			}
-			return znet.PingPong(ctx, appSet)
+			return znet.ExportCompose(ctx, znetConfig, appSet, output)
 		}),
 	}
+	composeCmd.Flags().StringVar(&output, "output", "docker-compose.yaml", "File the compose file is written to, - means stdout")
+	exportCmd.AddCommand(composeCmd)
+	return exportCmd
+}
+
+func backupCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	var name string
+	var list bool
+	backupCmd := &cobra.Command{
+		Use:               "backup <app>",
+		Short:             "Backs up state of the non-chain app, like postgres database of the explorer",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeAppNames(configF, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdF.Cmd(func() error {
+				if list {
+					backups, err := znet.Backups(configF, args[0])
+					if err != nil {
+						return err
+					}
+					for _, backup := range backups {
+						fmt.Println(backup)
+					}
+					return nil
+				}
+				spec := infra.NewSpec(configF)
+				config := znet.NewConfig(configF, spec)
+				return znet.Backup(ctx, configF, config, spec, args[0], name)
+			})(cmd, args)
+		},
+	}
+	backupCmd.Flags().StringVar(&name, "name", "", "Name of the backup, current time is used if empty")
+	backupCmd.Flags().BoolVar(&list, "list", false, "Lists existing backups of the app, from the oldest to the latest one")
+	return backupCmd
+}
+
+func restoreCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	var name string
+	restoreCmd := &cobra.Command{
+		Use:               "restore <app>",
+		Short:             "Restores state of the non-chain app from the backup",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeAppNames(configF, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdF.LockedCmd(func() error {
+				spec := infra.NewSpec(configF)
+				config := znet.NewConfig(configF, spec)
+				return znet.Restore(ctx, configF, config, spec, args[0], name)
+			})(cmd, args)
+		},
+	}
+	restoreCmd.Flags().StringVar(&name, "name", "", "Name of the backup, the latest one is used if empty")
+	return restoreCmd
+}
+
+func qrCmd(configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	var config znet.QRConfig
+	buildAppSet := func() (infra.AppSet, error) {
+		networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+		if err != nil {
+			return nil, err
+		}
+		spec := infra.NewSpec(configF)
+		znetConfig := znet.NewConfig(configF, spec)
+		return apps.BuildAppSet(apps.NewFactory(znetConfig, spec, networkConfig), znetConfig.Profiles,
+			znetConfig.CoredVersion)
+	}
+	qrCmd := &cobra.Command{
+		Use:   "qr [account] [amount]",
+		Short: "Prints QR code and payment URI of the account, to be scanned by mobile wallet",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdF.Cmd(func() error {
+				if len(args) == 2 {
+					var err error
+					config.Amount, err = sdk.ParseCoinsNormalized(args[1])
+					if err != nil {
+						return errors.Wrapf(err, "invalid amount %q", args[1])
+					}
+				}
+				appSet, err := buildAppSet()
+				if err != nil {
+					return err
+				}
+				return znet.QR(appSet, args[0], config)
+			})(cmd, args)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			appSet, err := buildAppSet()
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+			return znet.QRAccounts(appSet), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	qrCmd.Flags().BoolVar(&config.Mnemonic, "mnemonic", false, "Encodes mnemonic of the account, so it may be imported by the wallet, only for the accounts passed by name")
+	qrCmd.Flags().StringVar(&config.Host, "host", "", "Host printed in the endpoints of the chain, set it to the address of the machine reachable by the phone, localhost is used if not set")
+	addBinDirFlag(qrCmd, configF)
+	addProfileFlag(qrCmd, configF)
+	return qrCmd
+}
+
+func hostsCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	var hostsFile string
+	hostsCmd := &cobra.Command{
+		Use:   "hosts",
+		Short: "Manages host entries mapping <app>.<env>.local hostnames to applications",
+	}
+	hostsCmd.PersistentFlags().StringVar(&hostsFile, "hosts-file", znet.DefaultHostsFile, "File where host entries are stored")
+
+	addCmd := &cobra.Command{
+		Use:   "add",
+		Short: "Adds or updates host entries of the applications",
+		RunE: cmdF.Cmd(func() error {
+			spec := infra.NewSpec(configF)
+			config := znet.NewConfig(configF, spec)
+			return znet.HostsAdd(ctx, config, spec, hostsFile)
+		}),
+	}
+	addBinDirFlag(addCmd, configF)
+	addProfileFlag(addCmd, configF)
+	addCoredVersionFlag(addCmd, configF)
+
+	removeCmd := &cobra.Command{
+		Use:   "remove",
+		Short: "Removes host entries of the applications",
+		RunE: cmdF.Cmd(func() error {
+			spec := infra.NewSpec(configF)
+			config := znet.NewConfig(configF, spec)
+			return znet.HostsRemove(ctx, config, hostsFile)
+		}),
+	}
+
+	hostsCmd.AddCommand(addCmd, removeCmd)
+	return hostsCmd
+}
+
+func accountsCmd(configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	accountsCmd := &cobra.Command{
+		Use:   "accounts",
+		Short: "Manages leases of the accounts funded in genesis",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "Prints accounts of the pool together with their leases",
+		RunE: cmdF.Cmd(func() error {
+			return znet.AccountsList(infra.NewSpec(configF))
+		}),
+	}
+
+	var ttl time.Duration
+	leaseCmd := &cobra.Command{
+		Use:   "lease",
+		Short: "Leases free account from the pool and prints its address and mnemonic",
+		RunE: cmdF.Cmd(func() error {
+			return znet.AccountsLease(infra.NewSpec(configF), ttl)
+		}),
+	}
+	leaseCmd.Flags().DurationVar(&ttl, "ttl", znet.DefaultAccountLeaseTTL, "Time after which account returns to the pool if it hasn't been released")
+
+	releaseCmd := &cobra.Command{
+		Use:   "release [address]",
+		Short: "Returns leased account to the pool",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdF.Cmd(func() error {
+				return znet.AccountsRelease(infra.NewSpec(configF), args[0])
+			})(cmd, args)
+		},
+	}
+
+	accountsCmd.AddCommand(listCmd, leaseCmd, releaseCmd)
+	return accountsCmd
+}
+
+func serveCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	var address, token string
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Starts HTTP API server exposing environment management",
+		RunE: cmdF.Cmd(func() error {
+			return znet.Serve(ctx, configF, address, token)
+		}),
+	}
+	addBinDirFlag(serveCmd, configF)
+	addProfileFlag(serveCmd, configF)
+	addCoredVersionFlag(serveCmd, configF)
+	stringFlag(serveCmd.Flags(), &address, "address", "CRUST_ZNET_SERVE_ADDRESS", znet.DefaultServeAddress, "Address API server listens on")
+	secretStringFlag(serveCmd.Flags(), &token, "token", "CRUST_ZNET_SERVE_TOKEN", "", "Token required in Authorization header of API requests, random one is generated and stored in the directory of the environment if not set")
+	return serveCmd
+}
+
+func chaosCmd(ctx context.Context, configF *infra.ConfigFactory, cmdF *znet.CmdFactory) *cobra.Command {
+	chaosCmd := &cobra.Command{
+		Use:   "chaos",
+		Short: "Injects failures into running environment to test liveness and recovery",
+	}
+	chaosCmd.AddCommand(&cobra.Command{
+		Use:               "kill [apps...]",
+		Short:             "Kills apps, random validator is selected if none is specified, use start to run them again",
+		ValidArgsFunction: completeAppNames(configF, true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdF.Cmd(func() error {
+				spec := infra.NewSpec(configF)
+				config := znet.NewConfig(configF, spec)
+				return znet.ChaosKill(ctx, config, spec, args)
+			})(cmd, args)
+		},
+	})
+	chaosCmd.AddCommand(&cobra.Command{
+		Use:               "pause [apps...]",
+		Short:             "Pauses apps, random validator is selected if none is specified, use heal to resume them",
+		ValidArgsFunction: completeAppNames(configF, true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdF.Cmd(func() error {
+				spec := infra.NewSpec(configF)
+				config := znet.NewConfig(configF, spec)
+				return znet.ChaosPause(ctx, config, spec, args)
+			})(cmd, args)
+		},
+	})
+
+	var delay, loss string
+	netemCmd := &cobra.Command{
+		Use:               "netem [apps...]",
+		Short:             "Adds latency and packet loss to the traffic sent by apps, random validator is selected if none is specified",
+		ValidArgsFunction: completeAppNames(configF, true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdF.Cmd(func() error {
+				spec := infra.NewSpec(configF)
+				config := znet.NewConfig(configF, spec)
+				return znet.ChaosNetem(ctx, config, spec, args, delay, loss)
+			})(cmd, args)
+		},
+	}
+	netemCmd.Flags().StringVar(&delay, "delay", "", "Latency added to sent packets, e.g. 200ms")
+	netemCmd.Flags().StringVar(&loss, "loss", "", "Percentage of sent packets which are dropped, e.g. 10%")
+	chaosCmd.AddCommand(netemCmd)
+
+	chaosCmd.AddCommand(&cobra.Command{
+		Use:   "partition [group1 group2...]",
+		Short: "Partitions network into groups of apps, e.g. cored-00,cored-01 cored-02, validators are split randomly into two groups if none is specified",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdF.Cmd(func() error {
+				spec := infra.NewSpec(configF)
+				config := znet.NewConfig(configF, spec)
+				groups := make([][]string, 0, len(args))
+				for _, arg := range args {
+					groups = append(groups, strings.Split(arg, ","))
+				}
+				return znet.ChaosPartition(ctx, config, spec, groups)
+			})(cmd, args)
+		},
+	})
+	chaosCmd.AddCommand(&cobra.Command{
+		Use:   "heal",
+		Short: "Resumes paused apps and removes network conditions and partitions",
+		RunE: cmdF.Cmd(func() error {
+			spec := infra.NewSpec(configF)
+			return znet.ChaosHeal(ctx, spec)
+		}),
+	})
+	return chaosCmd
+}
+
+func docsCmd(rootCmd *cobra.Command) *cobra.Command {
+	var format, outputDir string
+	docsCmd := &cobra.Command{
+		Use:   "docs",
+		Short: "Generates reference documentation of all the commands, flags and environment variables",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := os.MkdirAll(outputDir, 0o700); err != nil {
+				return errors.WithStack(err)
+			}
+
+			rootCmd.DisableAutoGenTag = true
+			switch format {
+			case "markdown":
+				return errors.WithStack(doc.GenMarkdownTree(rootCmd, outputDir))
+			case "man":
+				return errors.WithStack(doc.GenManTree(rootCmd, &doc.GenManHeader{
+					Title:   "ZNET",
+					Section: "1",
+				}, outputDir))
+			default:
+				return errors.Errorf("unknown format %q, supported formats: markdown | man", format)
+			}
+		},
+	}
+	docsCmd.Flags().StringVar(&format, "format", "markdown", "Format of generated documentation: markdown | man")
+	docsCmd.Flags().StringVar(&outputDir, "output-dir", "docs", "Directory where documentation is stored")
+	return docsCmd
+}
+
+func completionCmd(rootCmd *cobra.Command) *cobra.Command {
+	return &cobra.Command{
+		Use:       "completion bash|zsh|fish",
+		Short:     "Generates shell completion script covering commands, flags, profiles, cored versions and names of the apps",
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch args[0] {
+			case "bash":
+				return errors.WithStack(rootCmd.GenBashCompletionV2(os.Stdout, true))
+			case "zsh":
+				return errors.WithStack(rootCmd.GenZshCompletion(os.Stdout))
+			default:
+				return errors.WithStack(rootCmd.GenFishCompletion(os.Stdout, true))
+			}
+		},
+	}
+}
+
+// completeAppNames completes names of the apps deployed in the environment. If multiple is false, only one app
+// is completed.
+func completeAppNames(configF *infra.ConfigFactory, multiple bool) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if !multiple && len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		// Hooks are not executed during completion, so config is resolved here.
+		if err := znet.ResolveConfig(configF, cmd); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		names := lo.Filter(znet.AppNames(infra.NewSpec(configF)), func(name string, _ int) bool {
+			return !lo.Contains(args, name)
+		})
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func ideCmd(configF *infra.ConfigFactory, rootCmd *cobra.Command) *cobra.Command {
+	var ide, outputDir string
+	var force bool
+	ideCmd := &cobra.Command{
+		Use:   "ide",
+		Short: "Generates IDE tasks and run configurations for all the commands",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return znet.GenerateIDEConfig(rootCmd, configF.BinDir, ide, outputDir, force)
+		},
+	}
+	addBinDirFlag(ideCmd, configF)
+	ideCmd.Flags().StringVar(&ide, "ide", znet.IDEVSCode, "IDE to generate configuration for: "+znet.IDEVSCode+" | "+znet.IDEJetBrains)
+	ideCmd.Flags().StringVar(&outputDir, "output-dir", ".", "Directory of the project where configuration is stored")
+	ideCmd.Flags().BoolVar(&force, "force", false, "Overwrite existing configuration files")
+	return ideCmd
+}
+
+func ciMatrixCmd(configF *infra.ConfigFactory) *cobra.Command {
+	ciMatrixCmd := &cobra.Command{
+		Use:   "ci-matrix",
+		Short: "Prints CI matrix running each integration test group in a separate job",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return znet.CIMatrix(configF.BinDir)
+		},
+	}
+	addBinDirFlag(ciMatrixCmd, configF)
+	return ciMatrixCmd
 }
 
 func addTestGroupFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
@@ -169,43 +1084,167 @@ func addTestGroupFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
 }
 
 func addBinDirFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
-	cmd.Flags().StringVar(&configF.BinDir, "bin-dir", defaultString("CRUST_ZNET_BIN_DIR",
-		filepath.Dir(filepath.Dir(must.String(filepath.EvalSymlinks(must.String(os.Executable())))))),
+	stringFlag(cmd.Flags(), &configF.BinDir, "bin-dir", "CRUST_ZNET_BIN_DIR",
+		filepath.Dir(filepath.Dir(must.String(filepath.EvalSymlinks(must.String(os.Executable()))))),
 		"Path to directory where executables exist")
 }
 
 func addProfileFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
-	cmd.Flags().StringSliceVar(&configF.Profiles, "profiles", defaultStrings("CRUST_ZNET_PROFILES", apps.DefaultProfiles()), "List of application profiles to deploy: "+strings.Join(apps.Profiles(), " | "))
+	stringSliceFlag(cmd.Flags(), &configF.Profiles, "profiles", "CRUST_ZNET_PROFILES", apps.DefaultProfiles(), "List of application profiles to deploy: "+strings.Join(apps.Profiles(), " | "))
+	must.OK(cmd.RegisterFlagCompletionFunc("profiles", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		// Profiles are completed one by one within the comma-separated list.
+		prefix := ""
+		if i := strings.LastIndex(toComplete, ","); i >= 0 {
+			prefix = toComplete[:i+1]
+		}
+		selected := strings.Split(prefix, ",")
+		var completions []string
+		for _, profile := range apps.Profiles() {
+			if !lo.Contains(selected, profile) {
+				completions = append(completions, prefix+profile)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}))
+	intFlag(cmd.Flags(), &configF.Validators, "validators", "CRUST_ZNET_VALIDATORS", 0, "Number of cored validators, it replaces the cored profile selected by --profiles, e.g. 3 selects 3cored, 0 means profile is not replaced")
+}
+
+func addGaiaVersionFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	stringFlag(cmd.Flags(), &configF.GaiaVersion, "gaia-version", "CRUST_ZNET_GAIA_VERSION", "", "The version of gaia used by gaia chains of the ibc profiles, e.g. v8.0.1, it must be defined in the tools registry of the build system when gaia image is built, default version is used if not set")
 }
 
 func addCoredVersionFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
-	cmd.Flags().StringVar(&configF.CoredVersion, "cored-version", defaultString("CRUST_ZNET_CORED_VERSION", ""), "The version of the binary to be used for deployment")
+	stringFlag(cmd.Flags(), &configF.CoredVersion, "cored-version", "CRUST_ZNET_CORED_VERSION", "", "The version of the binary to be used for deployment")
+	must.OK(cmd.RegisterFlagCompletionFunc("cored-version", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return znet.CoredVersions(configF.BinDir), cobra.ShellCompDirectiveNoFileComp
+	}))
 }
 
 func addFilterFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
-	cmd.Flags().StringVar(&configF.TestFilter, "filter", defaultString("CRUST_ZNET_FILTER", ""), "Regular expression used to filter tests to run")
+	stringFlag(cmd.Flags(), &configF.TestFilter, "filter", "CRUST_ZNET_FILTER", "", "Regular expression used to filter tests to run")
 }
 
-func defaultString(env, def string) string {
-	val := os.Getenv(env)
-	if val == "" {
-		val = def
-	}
-	return val
+func addReportDirFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	stringFlag(cmd.Flags(), &configF.ReportDir, "report-dir", "CRUST_ZNET_REPORT_DIR", "", "Directory where JUnit XML (report.xml) and JSON (report.json) reports of integration tests are stored, reports are not generated if not set")
 }
 
-func defaultStrings(env string, def []string) []string {
-	val := os.Getenv(env)
-	if val == "" {
-		return def
-	}
+func addCoverageDirFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	stringFlag(cmd.Flags(), &configF.CoverageDir, "coverage-dir", "CRUST_ZNET_COVERAGE_DIR", "", "Directory where coverage of integration tests is stored as Go profile (coverage.out), HTML (coverage.html) and lcov (lcov.info) reports, coverage is not collected if not set")
+}
 
-	parts := strings.Split(val, ",")
-	def = make([]string, 0, len(parts))
-	for _, p := range parts {
-		if p = strings.TrimSpace(p); p != "" {
-			def = append(def, p)
-		}
-	}
-	return def
+func addRecordTrafficFlags(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	boolFlag(cmd.Flags(), &configF.RecordTraffic, "record-traffic", "CRUST_ZNET_RECORD_TRAFFIC", false, "Records gRPC requests sent by integration tests to cored and the responses, records are stored in <home>/<env>/traffic/<group>.jsonl and included in artifacts of failed tests")
+	intFlag(cmd.Flags(), &configF.RecordBodyLimit, "record-body-limit", "CRUST_ZNET_RECORD_BODY_LIMIT", 4096, "Maximum number of bytes of each request and response body recorded by --record-traffic")
+}
+
+func addTestParallelismFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	intFlag(cmd.Flags(), &configF.TestParallelism, "test-parallelism", "CRUST_ZNET_TEST_PARALLELISM", 1, "Maximum number of independent test groups executed in parallel")
+}
+
+func addNetworkFileFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	stringFlag(cmd.Flags(), &configF.NetworkFile, "network-file", "CRUST_ZNET_NETWORK_FILE", "", "Path to the YAML file describing external network (e.g. testnet) to run tests against, instead of the local environment")
+}
+
+func addSubnetFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	stringFlag(cmd.Flags(), &configF.Subnet, "subnet", "CRUST_ZNET_SUBNET", "", "Subnet of the docker network created for the environment, e.g. 172.30.0.0/16, free one not colliding with host routes (including VPN ones) is selected if not set")
+}
+
+func addNetworkFlags(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	stringFlag(cmd.Flags(), &configF.NetworkName, "network-name", "CRUST_ZNET_NETWORK_NAME", "", "Name of the docker network created for the environment, name of the environment is used if not set")
+	boolFlag(cmd.Flags(), &configF.IPv6, "ipv6", "CRUST_ZNET_IPV6", false, "Enables IPv6 in the docker network created for the environment, unique local /64 subnet derived from the network name is assigned")
+	stringSliceFlag(cmd.Flags(), &configF.NetworkAliases, "network-aliases", "CRUST_ZNET_NETWORK_ALIASES", []string{}, "Additional names, in the form of <app>=<alias>, apps are reachable under from other containers in the docker network, e.g. cored-00=node.coreum.local")
+}
+
+func addAutoPortsFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	boolFlag(cmd.Flags(), &configF.AutoPorts, "auto-ports", "CRUST_ZNET_AUTO_PORTS", false, "Shifts ports of the fresh environment by an offset, stored in the spec, if default ones are in use by other processes")
+}
+
+func addKeyFlags(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	stringFlag(cmd.Flags(), &configF.KeyBackup, "key-backup", "CRUST_ZNET_KEY_BACKUP", "", "File where node and validator keys of cored nodes are stored, encrypted using passphrase taken from CRUST_ZNET_KEY_PASSPHRASE or the terminal, when environment is created")
+	stringFlag(cmd.Flags(), &configF.KeyRestore, "key-restore", "CRUST_ZNET_KEY_RESTORE", "", "File created by --key-backup, containing keys of cored nodes used when environment is created, so validators have the same identity as in the backed up environment")
+}
+
+func addGenesisDenomsFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	stringSliceFlag(cmd.Flags(), &configF.GenesisDenoms, "genesis-denoms", "CRUST_ZNET_GENESIS_DENOMS", []string{}, "Additional denoms, in the form of <base>:<display>:<exponent>, with metadata created at genesis and minted to the standard accounts, e.g. uatom:atom:6")
+}
+
+// stringFlag defines string flag which may be set by environment variable.
+func stringFlag(flags *pflag.FlagSet, p *string, name, env, def, usage string) {
+	flags.StringVar(p, name, def, usage)
+	annotateEnv(flags, name, env)
+}
+
+// secretStringFlag defines string flag which value is secret, so it is not stored in the history of commands.
+func secretStringFlag(flags *pflag.FlagSet, p *string, name, env, def, usage string) {
+	stringFlag(flags, p, name, env, def, usage)
+	must.OK(flags.SetAnnotation(name, znet.SecretAnnotation, []string{"true"}))
+}
+
+// stringSliceFlag defines string slice flag which may be set by environment variable.
+func stringSliceFlag(flags *pflag.FlagSet, p *[]string, name, env string, def []string, usage string) {
+	flags.StringSliceVar(p, name, def, usage)
+	annotateEnv(flags, name, env)
+}
+
+func addGenesisParamsFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	stringSliceFlag(cmd.Flags(), &configF.GenesisParams, "genesis-params", "CRUST_ZNET_GENESIS_PARAMS", []string{}, "Overrides of governance and staking parameters, in the form of <param>=<value>, applied to genesis, available params: "+strings.Join(apps.GenesisParams(), ", ")+", e.g. voting-period=20s,inflation=0")
+}
+
+func addGenesisPatchFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	stringFlag(cmd.Flags(), &configF.GenesisPatch, "genesis-patch", "CRUST_ZNET_GENESIS_PATCH", "", "Path to the file containing JSON merge patch applied to the generated genesis before the first start")
+}
+
+func addSentriesFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	intFlag(cmd.Flags(), &configF.CoredSentries, "sentries", "CRUST_ZNET_SENTRIES", 0, "Number of sentry nodes fronting each cored validator, 0 means validators are connected directly")
+}
+
+func addAccountPoolFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	intFlag(cmd.Flags(), &configF.AccountPoolSize, "account-pool", "CRUST_ZNET_ACCOUNT_POOL", 0, "Number of accounts funded in genesis which may be leased by tests and tools, so they don't compete for sequence numbers")
+}
+
+func addResourcesFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	stringSliceFlag(cmd.Flags(), &configF.Resources, "resources", "CRUST_ZNET_RESOURCES", []string{}, "Limits of resources available to containers created for the apps, in the form of <app>.<resource>=<value>, where <app> is the name or the type of the app, overriding defaults of the profiles, 0 set for the type removes the limit, available resources: "+strings.Join(apps.ResourceNames(), ", ")+", e.g. cored.memory=2g,cored.cpus=1.5,cored-00.pids=2048")
+}
+
+func addFaucetFlags(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	intFlag(cmd.Flags(), &configF.FaucetTransferAmount, "faucet-transfer-amount", "CRUST_ZNET_FAUCET_TRANSFER_AMOUNT", 0, fmt.Sprintf("Amount of tokens transferred by the faucet on each request, 0 means the default one: %d, or %d if integration-tests profile is used", faucet.DefaultTransferAmount, faucet.IntegrationTestsTransferAmount))
+	stringFlag(cmd.Flags(), &configF.FaucetDenom, "faucet-denom", "CRUST_ZNET_FAUCET_DENOM", "", "Denom of tokens transferred by the faucet, the default denom of the chain is used if empty, the denom must be funded in genesis, see --genesis-denoms")
+	intFlag(cmd.Flags(), &configF.FaucetRateLimit, "faucet-rate-limit", "CRUST_ZNET_FAUCET_RATE_LIMIT", 0, "Maximum number of requests served by the faucet for each IP address per minute, 0 means there is no limit")
+}
+
+func addMockServerFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	stringFlag(cmd.Flags(), &configF.MockServerMappings, "mockserver-mappings", "CRUST_ZNET_MOCKSERVER_MAPPINGS", "", "Path to the directory containing stub mappings, in wiremock layout of mappings and __files subdirectories, served by the mock server deployed by the mockserver profile")
+}
+
+func addRelayerFlags(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	float64Flag(cmd.Flags(), &configF.RelayerGasPriceMultiplier, "relayer-gas-price-multiplier", "CRUST_ZNET_RELAYER_GAS_PRICE_MULTIPLIER", 1, "Multiplies gas prices paid by IBC relayers on all the chains, e.g. 1.5")
+	stringFlag(cmd.Flags(), &configF.RelayerMemo, "relayer-memo", "CRUST_ZNET_RELAYER_MEMO", "", "Memo attached to transactions broadcast by IBC relayers")
+	stringSliceFlag(cmd.Flags(), &configF.RelayerChannels, "relayer-channels", "CRUST_ZNET_RELAYER_CHANNELS", []string{}, "Channels of the source chain, in the form of <relayer>=<channel>, relayers relay packets from, other channels are ignored by the relayer, relayers not listed relay packets from all the channels, e.g. ibc-relayer-gaia=channel-0")
+}
+
+func addContractsFlag(cmd *cobra.Command, configF *infra.ConfigFactory) {
+	stringSliceFlag(cmd.Flags(), &configF.Contracts, "contracts", "CRUST_ZNET_CONTRACTS", []string{}, "WASM contracts, in the form of <wasm-file>[:<instantiate-msg-file>], deployed when environment is started for the first time")
+}
+
+// intFlag defines int flag which may be set by environment variable.
+func intFlag(flags *pflag.FlagSet, p *int, name, env string, def int, usage string) {
+	flags.IntVar(p, name, def, usage)
+	annotateEnv(flags, name, env)
+}
+
+// float64Flag defines float64 flag which may be set by environment variable.
+func float64Flag(flags *pflag.FlagSet, p *float64, name, env string, def float64, usage string) {
+	flags.Float64Var(p, name, def, usage)
+	annotateEnv(flags, name, env)
+}
+
+// boolFlag defines bool flag which may be set by environment variable.
+func boolFlag(flags *pflag.FlagSet, p *bool, name, env string, def bool, usage string) {
+	flags.BoolVar(p, name, def, usage)
+	annotateEnv(flags, name, env)
+}
+
+// annotateEnv sets the name of the environment variable setting the flag, see znet.ResolveConfig.
+func annotateEnv(flags *pflag.FlagSet, name, env string) {
+	must.OK(flags.SetAnnotation(name, znet.EnvAnnotation, []string{env}))
 }
@@ -2,9 +2,111 @@ package exec
 
 import (
 	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Container runtimes supported by znet.
+const (
+	ContainerRuntimeDocker = "docker"
+	ContainerRuntimePodman = "podman"
 )
 
-// Docker runs docker command.
+var containerRuntime = struct {
+	binary string
+	podman bool
+}{binary: ContainerRuntimeDocker}
+
+// localImageSuffix is the suffix of the images built locally by crust, they are never pulled.
+const localImageSuffix = ":znet"
+
+var registryMirror string
+
+// SetContainerRuntime selects the CLI used to manage containers. If runtime is empty, docker is used if it is
+// installed, otherwise podman. Podman installed under the name of docker, e.g. by the podman-docker package,
+// is detected too, so features missing in podman are handled.
+func SetContainerRuntime(runtime string) error {
+	switch runtime {
+	case "":
+		runtime = ContainerRuntimeDocker
+		if _, err := exec.LookPath(ContainerRuntimeDocker); err != nil {
+			if _, err := exec.LookPath(ContainerRuntimePodman); err == nil {
+				runtime = ContainerRuntimePodman
+			}
+		}
+	case ContainerRuntimeDocker, ContainerRuntimePodman:
+	default:
+		return errors.Errorf("unknown container runtime %q, supported ones: %s, %s", runtime, ContainerRuntimeDocker,
+			ContainerRuntimePodman)
+	}
+
+	podman := runtime == ContainerRuntimePodman
+	if !podman {
+		if out, err := exec.Command(runtime, "--version").Output(); err == nil {
+			podman = strings.HasPrefix(strings.ToLower(string(out)), "podman")
+		}
+	}
+	containerRuntime.binary = runtime
+	containerRuntime.podman = podman
+	return nil
+}
+
+// ContainerRuntime returns the name of the CLI used to manage containers.
+func ContainerRuntime() string {
+	return containerRuntime.binary
+}
+
+// IsPodman returns true if containers are managed by podman.
+func IsPodman() bool {
+	return containerRuntime.podman
+}
+
+// Docker runs docker command, using the selected container runtime, CLI of podman is compatible with docker.
 func Docker(args ...string) *exec.Cmd {
-	return toolCmd("docker", args)
+	return toolCmd(containerRuntime.binary, args)
+}
+
+// SetRegistryMirror sets the registry, optionally followed by the path, e.g. mirror.example.com/dockerhub, which
+// prefixes all the pulled images, so they are pulled from the mirror instead of their original registries.
+func SetRegistryMirror(mirror string) {
+	registryMirror = strings.TrimSuffix(mirror, "/")
+}
+
+// IsLocalImage returns true if image is built locally by crust, instead of being pulled from the registry.
+func IsLocalImage(image string) bool {
+	return strings.HasSuffix(image, localImageSuffix)
+}
+
+// QualifiedImage returns the name of the image pulled by the container runtime. If registry mirror is set, it
+// prefixes the image, Docker Hub images are expanded to their full path, e.g. library/postgres, as expected by
+// the mirrors of Docker Hub. Podman, unlike docker, doesn't assume Docker Hub for images without the registry,
+// and depending on the host config it asks for the registry interactively or fails, so Docker Hub is set
+// explicitly. Images built locally are returned unchanged.
+func QualifiedImage(image string) string {
+	if IsLocalImage(image) || (!containerRuntime.podman && registryMirror == "") {
+		return image
+	}
+
+	first, _, found := strings.Cut(image, "/")
+	if found && first == "localhost" {
+		return image
+	}
+	if found && strings.ContainsAny(first, ".:") {
+		if registryMirror == "" {
+			return image
+		}
+		// Mirror keeps the original registry in the path, so images of different registries don't collide.
+		return registryMirror + "/" + image
+	}
+
+	path := image
+	if !found {
+		path = "library/" + image
+	}
+	registry := "docker.io"
+	if registryMirror != "" {
+		registry = registryMirror
+	}
+	return registry + "/" + path
 }
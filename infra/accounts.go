@@ -0,0 +1,155 @@
+package infra
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// accountLeasesFile is the file in the directory of the environment where leases of pool accounts are stored.
+const accountLeasesFile = "account-leases.json"
+
+// PoolAccount is the account funded in genesis. It is leased by a single user at a time, so users running
+// in parallel don't compete for the sequence number of the same account.
+type PoolAccount struct {
+	Address  string `json:"address"`
+	Mnemonic string `json:"mnemonic"`
+}
+
+// AccountLease describes lease of the pool account.
+type AccountLease struct {
+	Address   string    `json:"address"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// SetAccountPool stores accounts of the pool in the spec. Accounts are funded in genesis, so the pool can't be changed
+// once the environment is deployed.
+func (s *Spec) SetAccountPool(accounts []PoolAccount) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !accountPoolsEqual(s.AccountPool, accounts) {
+		for _, app := range s.Apps {
+			if app.Info().Status != AppStatusNotDeployed {
+				return errors.Errorf("account pool of deployed environment can't be changed from %d to %d accounts, "+
+					"remove the environment first", len(s.AccountPool), len(accounts))
+			}
+		}
+	}
+	s.AccountPool = accounts
+	return nil
+}
+
+// LeaseAccount leases free account from the pool for the ttl. Lease is held until it is released or it expires,
+// so accounts leased by crashed processes are eventually returned to the pool.
+func (s *Spec) LeaseAccount(ttl time.Duration) (PoolAccount, error) {
+	if len(s.AccountPool) == 0 {
+		return PoolAccount{}, errors.New("account pool is empty, start the environment with --account-pool")
+	}
+
+	var leased PoolAccount
+	err := s.updateAccountLeases(func(leases map[string]AccountLease) error {
+		for _, account := range s.AccountPool {
+			if _, exists := leases[account.Address]; exists {
+				continue
+			}
+			leases[account.Address] = AccountLease{Address: account.Address, ExpiresAt: time.Now().Add(ttl).UTC()}
+			leased = account
+			return nil
+		}
+		return errors.Errorf("all %d accounts of the pool are leased", len(s.AccountPool))
+	})
+	return leased, err
+}
+
+// ReleaseAccount returns leased account to the pool.
+func (s *Spec) ReleaseAccount(address string) error {
+	return s.updateAccountLeases(func(leases map[string]AccountLease) error {
+		if _, exists := leases[address]; !exists {
+			return errors.Errorf("account %s is not leased", address)
+		}
+		delete(leases, address)
+		return nil
+	})
+}
+
+// AccountLeases returns active leases of the pool accounts.
+func (s *Spec) AccountLeases() ([]AccountLease, error) {
+	var result []AccountLease
+	err := s.updateAccountLeases(func(leases map[string]AccountLease) error {
+		for _, account := range s.AccountPool {
+			if lease, exists := leases[account.Address]; exists {
+				result = append(result, lease)
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// updateAccountLeases executes fn on the active leases and stores them afterwards. File lock is held meanwhile,
+// because leases are shared by all the processes using the environment.
+func (s *Spec) updateAccountLeases(fn func(leases map[string]AccountLease) error) error {
+	dir := filepath.Dir(s.specFile)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return errors.WithStack(err)
+	}
+	leasesFile := filepath.Join(dir, accountLeasesFile)
+	// Lock is released when file is closed.
+	f, err := os.OpenFile(leasesFile+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return errors.WithStack(err)
+	}
+
+	leases := map[string]AccountLease{}
+	content, err := os.ReadFile(leasesFile)
+	switch {
+	case err == nil:
+		var stored []AccountLease
+		if err := json.Unmarshal(content, &stored); err != nil {
+			return errors.Wrapf(err, "decoding %s failed", leasesFile)
+		}
+		now := time.Now()
+		for _, lease := range stored {
+			if lease.ExpiresAt.After(now) {
+				leases[lease.Address] = lease
+			}
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return errors.WithStack(err)
+	}
+
+	if err := fn(leases); err != nil {
+		return err
+	}
+
+	stored := make([]AccountLease, 0, len(leases))
+	for _, lease := range leases {
+		stored = append(stored, lease)
+	}
+	content, err = json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(leasesFile, content, 0o600))
+}
+
+func accountPoolsEqual(a, b []PoolAccount) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package apps
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/cosmos/go-bip39"
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum/pkg/config"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+)
+
+// accountPool generates accounts of the pool funded in genesis. Mnemonics are derived from the name
+// of the environment, so the same accounts are generated each time the app set is built.
+func accountPool(envName string, network config.Network, size int) ([]infra.PoolAccount, error) {
+	if size < 0 {
+		return nil, errors.Errorf("invalid account pool size: %d", size)
+	}
+
+	accounts := make([]infra.PoolAccount, 0, size)
+	for i := 0; i < size; i++ {
+		entropy := sha256.Sum256([]byte(fmt.Sprintf("znet/%s/account-pool/%d", envName, i)))
+		mnemonic, err := bip39.NewMnemonic(entropy[:])
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		privKey, err := cored.PrivateKeyFromMnemonic(mnemonic)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		address, err := bech32.ConvertAndEncode(network.AddressPrefix(), privKey.PubKey().Address())
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		accounts = append(accounts, infra.PoolAccount{
+			Address:  address,
+			Mnemonic: mnemonic,
+		})
+	}
+	return accounts, nil
+}
@@ -2,9 +2,11 @@ package apps
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
 	"github.com/pkg/errors"
 
 	"github.com/CoreumFoundation/coreum-tools/pkg/must"
@@ -15,13 +17,21 @@ import (
 	"github.com/CoreumFoundation/crust/infra/apps/blockexplorer"
 	"github.com/CoreumFoundation/crust/infra/apps/cored"
 	"github.com/CoreumFoundation/crust/infra/apps/faucet"
+	"github.com/CoreumFoundation/crust/infra/apps/faucetui"
 	"github.com/CoreumFoundation/crust/infra/apps/gaiad"
 	"github.com/CoreumFoundation/crust/infra/apps/grafana"
+	"github.com/CoreumFoundation/crust/infra/apps/haproxy"
 	"github.com/CoreumFoundation/crust/infra/apps/hasura"
-	"github.com/CoreumFoundation/crust/infra/apps/osmosis"
+	"github.com/CoreumFoundation/crust/infra/apps/indexer"
+	"github.com/CoreumFoundation/crust/infra/apps/invariants"
+	"github.com/CoreumFoundation/crust/infra/apps/mockserver"
+	"github.com/CoreumFoundation/crust/infra/apps/nginx"
+	"github.com/CoreumFoundation/crust/infra/apps/osmosisd"
+	"github.com/CoreumFoundation/crust/infra/apps/pingpub"
 	"github.com/CoreumFoundation/crust/infra/apps/postgres"
 	"github.com/CoreumFoundation/crust/infra/apps/prometheus"
 	"github.com/CoreumFoundation/crust/infra/apps/relayercosmos"
+	"github.com/CoreumFoundation/crust/infra/apps/sockets"
 	"github.com/CoreumFoundation/crust/infra/cosmoschain"
 )
 
@@ -39,9 +49,33 @@ type Factory struct {
 	config        infra.Config
 	spec          *infra.Spec
 	networkConfig config.NetworkConfig
+	nodeKeys      map[string]cored.NodeKeys
 }
 
-// CoredNetwork creates new network of cored nodes.
+// WithNodeKeys sets private keys of cored nodes, mapped by their names, used instead of generating random ones.
+func (f *Factory) WithNodeKeys(nodeKeys map[string]cored.NodeKeys) *Factory {
+	f.nodeKeys = nodeKeys
+	return f
+}
+
+// newCored creates cored app using the keys set for the node.
+func (f *Factory) newCored(cfg cored.Config) cored.Cored {
+	if keys, exists := f.nodeKeys[cfg.Name]; exists {
+		cfg.Keys = &keys
+	}
+	return cored.New(cfg)
+}
+
+// port returns the port shifted by the offset of the environment.
+func (f *Factory) port(port int) int {
+	return port + f.spec.PortOffset
+}
+
+// sentryPortDelta is the shift of ports used by the first sentry node, so they don't collide with the validators.
+const sentryPortDelta = 2000
+
+// CoredNetwork creates new network of cored nodes. If sentriesCount is positive, each validator is fronted by that
+// number of sentry nodes and connects to the rest of the network only through them.
 func (f *Factory) CoredNetwork(
 	name string,
 	firstPorts cored.Ports,
@@ -51,9 +85,31 @@ func (f *Factory) CoredNetwork(
 	if validatorsCount > len(cored.StakerMnemonics) {
 		return cored.Cored{}, nil, errors.Errorf("unsupported validators count: %d, max: %d", validatorsCount, len(cored.StakerMnemonics))
 	}
+	if sentriesCount < 0 {
+		return cored.Cored{}, nil, errors.Errorf("invalid sentries count: %d", sentriesCount)
+	}
+
+	denomMetadata, denomBalances, err := parseGenesisDenoms(f.config.GenesisDenoms, f.networkConfig.Denom)
+	if err != nil {
+		return cored.Cored{}, nil, err
+	}
+
+	networkConfig := f.networkConfig
+	overrides, err := applyGenesisParams(&networkConfig, f.config.GenesisParams)
+	if err != nil {
+		return cored.Cored{}, nil, err
+	}
+
+	var genesisPatch []byte
+	if f.config.GenesisPatch != "" {
+		genesisPatch, err = os.ReadFile(f.config.GenesisPatch)
+		if err != nil {
+			return cored.Cored{}, nil, errors.Wrap(err, "reading genesis patch failed")
+		}
+	}
 
-	network := config.NewNetwork(f.networkConfig)
-	initialBalance := sdk.NewCoins(sdk.NewInt64Coin(f.networkConfig.Denom, 500_000_000_000_000))
+	network := config.NewNetwork(networkConfig)
+	initialBalance := sdk.NewCoins(sdk.NewInt64Coin(f.networkConfig.Denom, genesisStakingAmount)).Add(denomBalances...)
 
 	for _, mnemonic := range []string{
 		cored.AliceMnemonic,
@@ -70,37 +126,43 @@ func (f *Factory) CoredNetwork(
 		must.OK(network.FundAccount(sdk.AccAddress(privKey.PubKey().Address()), initialBalance))
 	}
 
-	nodes := make([]cored.Cored, 0, validatorsCount+sentriesCount)
+	pool, err := accountPool(f.config.EnvName, network, f.config.AccountPoolSize)
+	if err != nil {
+		return cored.Cored{}, nil, err
+	}
+	for _, account := range pool {
+		_, address, err := bech32.DecodeAndConvert(account.Address)
+		if err != nil {
+			return cored.Cored{}, nil, errors.WithStack(err)
+		}
+		must.OK(network.FundAccount(address, initialBalance))
+	}
+	if err := f.spec.SetAccountPool(pool); err != nil {
+		return cored.Cored{}, nil, err
+	}
+
+	nodes := make([]cored.Cored, 0, validatorsCount*(sentriesCount+1))
 	var node0 *cored.Cored
 	var lastNode cored.Cored
-	for i := 0; i < cap(nodes); i++ {
+	for i := 0; i < validatorsCount; i++ {
 		name := name + fmt.Sprintf("-%02d", i)
 		portDelta := i * 100
-		isValidator := i < validatorsCount
-		node := cored.New(cored.Config{
-			Name:       name,
-			HomeDir:    filepath.Join(f.config.AppDir, name, string(network.ChainID())),
-			BinDir:     f.config.BinDir,
-			WrapperDir: f.config.WrapperDir,
-			Network:    &network,
-			AppInfo:    f.spec.DescribeApp(cored.AppType, name),
-			Ports: cored.Ports{
-				RPC:        firstPorts.RPC + portDelta,
-				P2P:        firstPorts.P2P + portDelta,
-				GRPC:       firstPorts.GRPC + portDelta,
-				GRPCWeb:    firstPorts.GRPCWeb + portDelta,
-				API:        firstPorts.API + portDelta,
-				PProf:      firstPorts.PProf + portDelta,
-				Prometheus: firstPorts.Prometheus + portDelta,
-			},
-			IsValidator: isValidator,
-			StakerMnemonic: func() string {
-				if isValidator {
-					return cored.StakerMnemonics[i]
-				}
-				return ""
-			}(),
-			RootNode: node0,
+		var rootNode *cored.Cored
+		if sentriesCount == 0 {
+			rootNode = node0
+		}
+		node := f.newCored(cored.Config{
+			Name:           name,
+			HomeDir:        filepath.Join(f.config.AppDir, name, string(network.ChainID())),
+			BinDir:         f.config.BinDir,
+			WrapperDir:     f.config.WrapperDir,
+			Network:        &network,
+			AppInfo:        f.spec.DescribeApp(cored.AppType, name),
+			Ports:          shiftCoredPorts(firstPorts, portDelta),
+			IsValidator:    true,
+			HasSentries:    sentriesCount > 0,
+			StakerMnemonic: cored.StakerMnemonics[i],
+			RootNode:       rootNode,
 			ImportedMnemonics: map[string]string{
 				"alice":   cored.AliceMnemonic,
 				"bob":     cored.BobMnemonic,
@@ -110,6 +172,10 @@ func (f *Factory) CoredNetwork(
 			FaucetMnemonic:  cored.FaucetMnemonic,
 			RelayerMnemonic: cored.RelayerMnemonic,
 			BinaryVersion:   binaryVersion,
+			DenomMetadata:   denomMetadata,
+			Inflation:       overrides.Inflation,
+			CommissionRate:  overrides.CommissionRate(i),
+			GenesisPatch:    genesisPatch,
 		})
 		if node0 == nil {
 			node0 = &node
@@ -117,19 +183,107 @@ func (f *Factory) CoredNetwork(
 		lastNode = node
 		nodes = append(nodes, node)
 	}
+
+	// Sentries of all the validators are connected to the first one, the same way validators are connected to
+	// the root node if there are no sentries.
+	var sentry0 *cored.Cored
+	for i, validator := range nodes[:validatorsCount] {
+		validator := validator
+		for j := 0; j < sentriesCount; j++ {
+			cfg := f.fullNodeConfig(validator, fmt.Sprintf("%s-%02d-sentry-%02d", name, i, j),
+				shiftCoredPorts(firstPorts, sentryPortDelta+(i*sentriesCount+j)*100))
+			cfg.RootNode = sentry0
+			cfg.SentryOf = &validator
+			node := f.newCored(cfg)
+			if sentry0 == nil {
+				sentry0 = &node
+			}
+			lastNode = node
+			nodes = append(nodes, node)
+		}
+	}
 	return lastNode, nodes, nil
 }
 
-// Faucet creates new faucet.
-func (f *Factory) Faucet(name string, coredApp cored.Cored) faucet.Faucet {
+// CoredStateSync creates pair of cored nodes used to verify state sync. The first one serves snapshots and the second
+// one joins the network using them instead of replaying all the blocks.
+func (f *Factory) CoredStateSync(name string, firstPorts cored.Ports, rootNode cored.Cored) []cored.Cored {
+	// Ports are shifted far enough not to collide with the validators.
+	const portDelta = 1000
+
+	snapshotConfig := f.fullNodeConfig(rootNode, name+"-snapshot", shiftCoredPorts(firstPorts, portDelta))
+	snapshotConfig.RootNode = &rootNode
+	snapshotConfig.SnapshotInterval = cored.DefaultSnapshotInterval
+	snapshotNode := f.newCored(snapshotConfig)
+
+	stateSyncConfig := f.fullNodeConfig(rootNode, name+"-statesync", shiftCoredPorts(firstPorts, portDelta+100))
+	stateSyncConfig.RootNode = &rootNode
+	stateSyncConfig.StateSyncFrom = &snapshotNode
+	return []cored.Cored{snapshotNode, f.newCored(stateSyncConfig)}
+}
+
+// CoredArchive creates non-validator cored node which doesn't prune any state, so historical states may be queried.
+func (f *Factory) CoredArchive(name string, firstPorts cored.Ports, rootNode cored.Cored) cored.Cored {
+	// Ports are shifted far enough not to collide with the validators and state sync nodes.
+	const portDelta = 1200
+
+	cfg := f.fullNodeConfig(rootNode, name+"-archive", shiftCoredPorts(firstPorts, portDelta))
+	cfg.RootNode = &rootNode
+	cfg.IsArchive = true
+	return f.newCored(cfg)
+}
+
+// fullNodeConfig returns the config of non-validator node derived from the config of the existing node.
+func (f *Factory) fullNodeConfig(node cored.Cored, name string, ports cored.Ports) cored.Config {
+	cfg := node.Config()
+	cfg.Name = name
+	cfg.HomeDir = filepath.Join(f.config.AppDir, name, string(cfg.Network.ChainID()))
+	cfg.AppInfo = f.spec.DescribeApp(cored.AppType, name)
+	cfg.Ports = ports
+	cfg.IsValidator = false
+	cfg.IsArchive = false
+	cfg.HasSentries = false
+	cfg.StakerMnemonic = ""
+	cfg.RootNode = nil
+	return cfg
+}
+
+func shiftCoredPorts(ports cored.Ports, delta int) cored.Ports {
+	return cored.Ports{
+		RPC:        ports.RPC + delta,
+		P2P:        ports.P2P + delta,
+		GRPC:       ports.GRPC + delta,
+		GRPCWeb:    ports.GRPCWeb + delta,
+		API:        ports.API + delta,
+		PProf:      ports.PProf + delta,
+		Prometheus: ports.Prometheus + delta,
+	}
+}
+
+// Faucet creates new faucet transferring transferAmount tokens on each request.
+func (f *Factory) Faucet(name string, coredApp cored.Cored, transferAmount int64) faucet.Faucet {
 	return faucet.New(faucet.Config{
 		Name:    name,
 		HomeDir: filepath.Join(f.config.AppDir, name),
 		BinDir:  f.config.BinDir,
 		ChainID: f.networkConfig.ChainID,
 		AppInfo: f.spec.DescribeApp(faucet.AppType, name),
-		Port:    faucet.DefaultPort,
+		Port:    f.port(faucet.DefaultPort),
 		Cored:   coredApp,
+
+		TransferAmount: transferAmount,
+		Denom:          f.config.FaucetDenom,
+		RateLimit:      f.config.FaucetRateLimit,
+	})
+}
+
+// FaucetUI creates web UI of the faucet.
+func (f *Factory) FaucetUI(name string, faucetApp faucet.Faucet) faucetui.FaucetUI {
+	return faucetui.New(faucetui.Config{
+		Name:    name,
+		AppInfo: f.spec.DescribeApp(faucetui.AppType, name),
+		Port:    f.port(faucetui.DefaultPort),
+		Faucet:  faucetApp,
 	})
 }
 
@@ -143,15 +297,15 @@ func (f *Factory) BlockExplorer(name string, coredApp cored.Cored) blockexplorer
 	postgresApp := postgres.New(postgres.Config{
 		Name:             namePostgres,
 		AppInfo:          f.spec.DescribeApp(postgres.AppType, namePostgres),
-		Port:             blockexplorer.DefaultPorts.Postgres,
+		Port:             f.port(blockexplorer.DefaultPorts.Postgres),
 		SchemaLoaderFunc: blockexplorer.LoadPostgresSchema,
 	})
 	bdjunoApp := bdjuno.New(bdjuno.Config{
 		Name:           nameBDJuno,
 		HomeDir:        filepath.Join(f.config.AppDir, nameBDJuno),
 		AppInfo:        f.spec.DescribeApp(bdjuno.AppType, nameBDJuno),
-		Port:           blockexplorer.DefaultPorts.BDJuno,
-		TelemetryPort:  blockexplorer.DefaultPorts.BDJunoTelemetry,
+		Port:           f.port(blockexplorer.DefaultPorts.BDJuno),
+		TelemetryPort:  f.port(blockexplorer.DefaultPorts.BDJunoTelemetry),
 		ConfigTemplate: blockexplorer.BDJunoConfigTemplate,
 		Cored:          coredApp,
 		Postgres:       postgresApp,
@@ -159,14 +313,14 @@ func (f *Factory) BlockExplorer(name string, coredApp cored.Cored) blockexplorer
 	hasuraApp := hasura.New(hasura.Config{
 		Name:     nameHasura,
 		AppInfo:  f.spec.DescribeApp(hasura.AppType, nameHasura),
-		Port:     blockexplorer.DefaultPorts.Hasura,
+		Port:     f.port(blockexplorer.DefaultPorts.Hasura),
 		Postgres: postgresApp,
 		BDJuno:   bdjunoApp,
 	})
 	bigDipperApp := bigdipper.New(bigdipper.Config{
 		Name:    nameBigDipper,
 		AppInfo: f.spec.DescribeApp(bigdipper.AppType, nameBigDipper),
-		Port:    blockexplorer.DefaultPorts.BigDipper,
+		Port:    f.port(blockexplorer.DefaultPorts.BigDipper),
 		Cored:   coredApp,
 		Hasura:  hasuraApp,
 	})
@@ -179,53 +333,149 @@ func (f *Factory) BlockExplorer(name string, coredApp cored.Cored) blockexplorer
 	}
 }
 
-// IBC creates set of applications required to test IBC.
-func (f *Factory) IBC(name string, coredApp cored.Cored) infra.AppSet {
+// Indexer creates indexer storing blocks, transactions and events produced by cored in its own postgres database.
+func (f *Factory) Indexer(name string, coredApp cored.Cored) infra.AppSet {
+	namePostgres := name + "-postgres"
+
+	postgresApp := postgres.New(postgres.Config{
+		Name:    namePostgres,
+		AppInfo: f.spec.DescribeApp(postgres.AppType, namePostgres),
+		Port:    f.port(indexer.DefaultPostgresPort),
+	})
+	indexerApp := indexer.New(indexer.Config{
+		Name:     name,
+		AppInfo:  f.spec.DescribeApp(indexer.AppType, name),
+		Port:     f.port(indexer.DefaultPort),
+		Cored:    coredApp,
+		Postgres: postgresApp,
+	})
+
+	return infra.AppSet{
+		postgresApp,
+		indexerApp,
+	}
+}
+
+// PingPub creates ping.pub explorer querying cored directly, lighter alternative to the block explorer.
+func (f *Factory) PingPub(name string, coredApp cored.Cored) pingpub.PingPub {
+	return pingpub.New(pingpub.Config{
+		Name:    name,
+		HomeDir: filepath.Join(f.config.AppDir, name),
+		AppInfo: f.spec.DescribeApp(pingpub.AppType, name),
+		Port:    f.port(pingpub.DefaultPort),
+		Cored:   coredApp,
+	})
+}
+
+// MockServer creates mock server serving stubs of external HTTP APIs, so apps and tests calling them run hermetically.
+func (f *Factory) MockServer(name string) mockserver.MockServer {
+	return mockserver.New(mockserver.Config{
+		Name:        name,
+		HomeDir:     filepath.Join(f.config.AppDir, name),
+		AppInfo:     f.spec.DescribeApp(mockserver.AppType, name),
+		Port:        f.port(mockserver.DefaultPort),
+		MappingsDir: f.config.MockServerMappings,
+	})
+}
+
+// IBC creates set of applications required to test IBC. If multiHop is true, second gaia chain is created,
+// connected to the first one, so packets may be forwarded from cored through the first gaia chain to the second one.
+// If ica is true, gaia hosts interchain accounts controlled by other chains.
+func (f *Factory) IBC(name string, coredApp cored.Cored, multiHop, ica bool) infra.AppSet {
 	nameGaia := name + "-gaia"
-	nameOsmosis := name + "-osmosis"
 	nameRelayerGaia := name + "-relayer-gaia"
-	nameRelayerOsmosis := name + "-relayer-osmosis"
+
+	var icaHostAllowMessages []string
+	if ica {
+		icaHostAllowMessages = gaiad.ICAHostAllowMessages
+	}
 
 	gaiaApp := gaiad.New(cosmoschain.AppConfig{
-		Name:            nameGaia,
-		HomeDir:         filepath.Join(f.config.AppDir, nameGaia),
-		ChainID:         gaiad.DefaultChainID,
-		AppInfo:         f.spec.DescribeApp(gaiad.AppType, nameGaia),
-		Ports:           gaiad.DefaultPorts,
+		Name:                 nameGaia,
+		HomeDir:              filepath.Join(f.config.AppDir, nameGaia),
+		ChainID:              gaiad.DefaultChainID,
+		AppInfo:              f.spec.DescribeApp(gaiad.AppType, nameGaia),
+		Ports:                infra.ShiftPorts(gaiad.DefaultPorts, f.spec.PortOffset),
+		RelayerMnemonic:      gaiad.RelayerMnemonic,
+		ICAHostAllowMessages: icaHostAllowMessages,
+	}, f.config.GaiaVersion)
+
+	relayerGaiaApp := relayercosmos.New(relayercosmos.Config{
+		Name:               nameRelayerGaia,
+		HomeDir:            filepath.Join(f.config.AppDir, nameRelayerGaia),
+		AppInfo:            f.spec.DescribeApp(relayercosmos.AppType, nameRelayerGaia),
+		DebugPort:          f.port(relayercosmos.DefaultDebugPort),
+		Cored:              coredApp,
+		PeeredChain:        gaiaApp,
+		GasPriceMultiplier: f.config.RelayerGasPriceMultiplier,
+		Memo:               f.config.RelayerMemo,
+		Channels:           f.config.RelayerChannels[nameRelayerGaia],
+	})
+
+	appSet := append(infra.AppSet{
+		gaiaApp,
+		relayerGaiaApp,
+	}, f.Osmosis(name, coredApp)...)
+	if !multiHop {
+		return appSet
+	}
+
+	nameGaiaB := name + "-gaia-b"
+	nameRelayerGaiaB := name + "-relayer-gaia-b"
+
+	gaiaBApp := gaiad.New(cosmoschain.AppConfig{
+		Name:            nameGaiaB,
+		HomeDir:         filepath.Join(f.config.AppDir, nameGaiaB),
+		ChainID:         gaiad.SecondChainID,
+		AppInfo:         f.spec.DescribeApp(gaiad.AppType, nameGaiaB),
+		Ports:           infra.ShiftPorts(gaiad.SecondPorts, f.spec.PortOffset),
 		RelayerMnemonic: gaiad.RelayerMnemonic,
+	}, f.config.GaiaVersion)
+
+	relayerGaiaBApp := relayercosmos.New(relayercosmos.Config{
+		Name:               nameRelayerGaiaB,
+		HomeDir:            filepath.Join(f.config.AppDir, nameRelayerGaiaB),
+		AppInfo:            f.spec.DescribeApp(relayercosmos.AppType, nameRelayerGaiaB),
+		DebugPort:          f.port(relayercosmos.DefaultDebugPort + 2),
+		SourceChain:        &gaiaApp,
+		PeeredChain:        gaiaBApp,
+		GasPriceMultiplier: f.config.RelayerGasPriceMultiplier,
+		Memo:               f.config.RelayerMemo,
+		Channels:           f.config.RelayerChannels[nameRelayerGaiaB],
 	})
 
-	osmosisApp := osmosis.New(cosmoschain.AppConfig{
+	return append(appSet, gaiaBApp, relayerGaiaBApp)
+}
+
+// Osmosis creates osmosis chain and the relayer connecting it to cored.
+func (f *Factory) Osmosis(name string, coredApp cored.Cored) infra.AppSet {
+	nameOsmosis := name + "-osmosis"
+	nameRelayerOsmosis := name + "-relayer-osmosis"
+
+	osmosisApp := osmosisd.New(cosmoschain.AppConfig{
 		Name:            nameOsmosis,
 		HomeDir:         filepath.Join(f.config.AppDir, nameOsmosis),
-		ChainID:         osmosis.DefaultChainID,
-		AppInfo:         f.spec.DescribeApp(osmosis.AppType, nameOsmosis),
-		Ports:           osmosis.DefaultPorts,
-		RelayerMnemonic: osmosis.RelayerMnemonic,
-	})
-
-	relayerGaiaApp := relayercosmos.New(relayercosmos.Config{
-		Name:        nameRelayerGaia,
-		HomeDir:     filepath.Join(f.config.AppDir, nameRelayerGaia),
-		AppInfo:     f.spec.DescribeApp(relayercosmos.AppType, nameRelayerGaia),
-		DebugPort:   relayercosmos.DefaultDebugPort,
-		Cored:       coredApp,
-		PeeredChain: gaiaApp,
+		ChainID:         osmosisd.DefaultChainID,
+		AppInfo:         f.spec.DescribeApp(osmosisd.AppType, nameOsmosis),
+		Ports:           infra.ShiftPorts(osmosisd.DefaultPorts, f.spec.PortOffset),
+		RelayerMnemonic: osmosisd.RelayerMnemonic,
+		FaucetMnemonic:  osmosisd.FaucetMnemonic,
 	})
 
 	relayerOsmosisApp := relayercosmos.New(relayercosmos.Config{
-		Name:        nameRelayerOsmosis,
-		HomeDir:     filepath.Join(f.config.AppDir, nameRelayerOsmosis),
-		AppInfo:     f.spec.DescribeApp(relayercosmos.AppType, nameRelayerOsmosis),
-		DebugPort:   relayercosmos.DefaultDebugPort + 1,
-		Cored:       coredApp,
-		PeeredChain: osmosisApp,
+		Name:               nameRelayerOsmosis,
+		HomeDir:            filepath.Join(f.config.AppDir, nameRelayerOsmosis),
+		AppInfo:            f.spec.DescribeApp(relayercosmos.AppType, nameRelayerOsmosis),
+		DebugPort:          f.port(relayercosmos.DefaultDebugPort + 1),
+		Cored:              coredApp,
+		PeeredChain:        osmosisApp,
+		GasPriceMultiplier: f.config.RelayerGasPriceMultiplier,
+		Memo:               f.config.RelayerMemo,
+		Channels:           f.config.RelayerChannels[nameRelayerOsmosis],
 	})
 
 	return infra.AppSet{
-		gaiaApp,
 		osmosisApp,
-		relayerGaiaApp,
 		relayerOsmosisApp,
 	}
 }
@@ -238,7 +488,7 @@ func (f *Factory) Monitoring(name string, coredNodes []cored.Cored, bdJuno bdjun
 	prometheusApp := prometheus.New(prometheus.Config{
 		Name:       namePrometheus,
 		HomeDir:    filepath.Join(f.config.AppDir, namePrometheus),
-		Port:       prometheus.DefaultPort,
+		Port:       f.port(prometheus.DefaultPort),
 		AppInfo:    f.spec.DescribeApp(prometheus.AppType, namePrometheus),
 		CoredNodes: coredNodes,
 		BDJuno:     bdJuno,
@@ -249,7 +499,7 @@ func (f *Factory) Monitoring(name string, coredNodes []cored.Cored, bdJuno bdjun
 		HomeDir:    filepath.Join(f.config.AppDir, nameGrafana),
 		AppInfo:    f.spec.DescribeApp(grafana.AppType, nameGrafana),
 		CoredNodes: coredNodes,
-		Port:       grafana.DefaultPort,
+		Port:       f.port(grafana.DefaultPort),
 		Prometheus: prometheusApp,
 	})
 
@@ -258,3 +508,51 @@ func (f *Factory) Monitoring(name string, coredNodes []cored.Cored, bdJuno bdjun
 		grafanaApp,
 	}
 }
+
+// LoadBalancer returns haproxy load-balancing RPC, gRPC and REST API traffic across cored nodes.
+func (f *Factory) LoadBalancer(name string, coredNodes []cored.Cored) haproxy.HAProxy {
+	return haproxy.New(haproxy.Config{
+		Name:       name,
+		HomeDir:    filepath.Join(f.config.AppDir, name),
+		Ports:      infra.ShiftPorts(haproxy.DefaultPorts, f.spec.PortOffset),
+		AppInfo:    f.spec.DescribeApp(haproxy.AppType, name),
+		CoredNodes: coredNodes,
+	})
+}
+
+// Proxy returns nginx terminating TLS and exposing endpoints of cored and other deployed apps under stable paths
+// of single host. Faucet, hasura and UI are optional.
+func (f *Factory) Proxy(name string, coredApp cored.Cored, faucetApp, hasuraApp, uiApp *nginx.Upstream) nginx.Nginx {
+	return nginx.New(nginx.Config{
+		Name:    name,
+		HomeDir: filepath.Join(f.config.AppDir, name),
+		// Certificate authority is shared by all the environments, so devices have to trust it only once.
+		CADir:   filepath.Join(filepath.Dir(f.config.HomeDir), "tls"),
+		Ports:   infra.ShiftPorts(nginx.DefaultPorts, f.spec.PortOffset),
+		AppInfo: f.spec.DescribeApp(nginx.AppType, name),
+		Cored:   coredApp,
+		Faucet:  faucetApp,
+		Hasura:  hasuraApp,
+		UI:      uiApp,
+	})
+}
+
+// Invariants returns the sidecar verifying invariants of each new block produced by the cored nodes.
+func (f *Factory) Invariants(name string, coredNodes []cored.Cored) invariants.Invariants {
+	return invariants.New(invariants.Config{
+		Name:       name,
+		AppInfo:    f.spec.DescribeApp(invariants.AppType, name),
+		Port:       f.port(invariants.DefaultPort),
+		CoredNodes: coredNodes,
+	})
+}
+
+// Sockets returns the proxy exposing RPC and gRPC of the cored nodes over unix sockets.
+func (f *Factory) Sockets(name string, coredNodes []cored.Cored) sockets.Sockets {
+	return sockets.New(sockets.Config{
+		Name:       name,
+		HomeDir:    filepath.Join(f.config.AppDir, name),
+		AppInfo:    f.spec.DescribeApp(sockets.AppType, name),
+		CoredNodes: coredNodes,
+	})
+}
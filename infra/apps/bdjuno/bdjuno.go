@@ -110,13 +110,15 @@ func (j BDJuno) Deployment() infra.Deployment {
 				j.config.Postgres,
 			},
 		},
-		PrepareFunc: func() error {
-			if err := j.config.Cored.Config().Network.SaveGenesis(j.config.HomeDir); err != nil {
+		PrepareFunc: func(ctx context.Context) error {
+			if err := j.config.Cored.SaveGenesis(j.config.HomeDir); err != nil {
 				return err
 			}
 
 			return os.WriteFile(j.config.HomeDir+"/config.yaml", j.prepareConfig(), 0o644)
 		},
+		// It crashes if connection to the chain is lost, e.g. when node is restarted.
+		RestartPolicy: infra.RestartPolicyOnFailure,
 	}
 }
 
@@ -12,7 +12,8 @@ const (
 	// AppType is the type of big dipper application.
 	AppType infra.AppType = "bigdipper"
 
-	// DefaultPort is the default port big dipper listens on for client connections.
+	// DefaultPort is the default port big dipper listens on for client connections. It is built into the image,
+	// so big dipper always listens on it inside the container.
 	DefaultPort = 3000
 )
 
@@ -47,6 +48,16 @@ func (bd BigDipper) Name() string {
 	return bd.config.Name
 }
 
+// Port returns port published on the host, it is mapped to DefaultPort inside the container.
+func (bd BigDipper) Port() int {
+	return bd.config.Port
+}
+
+// ContainerPort returns port the application listens on inside the container, it is used by other containers.
+func (bd BigDipper) ContainerPort() int {
+	return DefaultPort
+}
+
 // Info returns deployment info.
 func (bd BigDipper) Info() infra.DeploymentInfo {
 	return bd.config.AppInfo.Info()
@@ -61,6 +72,9 @@ func (bd BigDipper) Deployment() infra.Deployment {
 		Ports: map[string]int{
 			"web": bd.config.Port,
 		},
+		ContainerPorts: map[string]int{
+			"web": DefaultPort,
+		},
 		Requires: infra.Prerequisites{
 			Timeout: 20 * time.Second,
 			Dependencies: []infra.HealthCheckCapable{
@@ -3,11 +3,13 @@ package cored
 import (
 	"path/filepath"
 
+	tmconfig "github.com/tendermint/tendermint/config"
+
 	"github.com/CoreumFoundation/coreum-tools/pkg/must"
 	"github.com/CoreumFoundation/coreum/pkg/config"
 )
 
-func saveTendermintConfig(nodeConfig config.NodeConfig, homeDir string) {
+func saveTendermintConfig(nodeConfig config.NodeConfig, homeDir string, customize func(cfg *tmconfig.Config)) {
 	err := nodeConfig.SavePrivateKeys(homeDir)
 	must.OK(err)
 	cfg := nodeConfig.TendermintNodeConfig(nil)
@@ -22,6 +24,7 @@ func saveTendermintConfig(nodeConfig config.NodeConfig, homeDir string) {
 	cfg.RPC.MaxSubscriptionsPerClient = 10000
 	cfg.Mempool.Size = 50000
 	cfg.Mempool.MaxTxsBytes = 5368709120
+	customize(cfg)
 
 	must.OK(config.WriteTendermintConfigToFile(filepath.Join(homeDir, config.DefaultNodeConfigPath), cfg))
 }
@@ -1,13 +1,16 @@
 package cored
 
 import (
+	"bytes"
 	"context"
 	"crypto/ed25519"
 	"crypto/rand"
+	"encoding/json"
 	"io"
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,16 +23,20 @@ import (
 	cosmosed25519 "github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
 	cosmossecp256k1 "github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
 	srvconfig "github.com/cosmos/cosmos-sdk/server/config"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/x/auth"
 	"github.com/cosmos/cosmos-sdk/x/bank"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	"github.com/cosmos/cosmos-sdk/x/staking"
 	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
 	"github.com/pkg/errors"
+	tmconfig "github.com/tendermint/tendermint/config"
 	"google.golang.org/grpc"
 
 	"github.com/CoreumFoundation/coreum-tools/pkg/must"
+	"github.com/CoreumFoundation/coreum-tools/pkg/retry"
 	"github.com/CoreumFoundation/coreum/pkg/client"
 	"github.com/CoreumFoundation/coreum/pkg/config"
 	"github.com/CoreumFoundation/coreum/pkg/config/constant"
@@ -40,6 +47,14 @@ import (
 // AppType is the type of cored application.
 const AppType infra.AppType = "cored"
 
+const (
+	// DefaultSnapshotInterval is the number of blocks between state sync snapshots taken by the node serving them.
+	DefaultSnapshotInterval = 20
+
+	// snapshotKeepRecent is the number of recent state sync snapshots kept by the node.
+	snapshotKeepRecent = 2
+)
+
 // Config stores cored app config.
 type Config struct {
 	Name              string
@@ -50,6 +65,7 @@ type Config struct {
 	AppInfo           *infra.AppInfo
 	Ports             Ports
 	IsValidator       bool
+	IsArchive         bool
 	StakerMnemonic    string
 	FundingMnemonic   string
 	FaucetMnemonic    string
@@ -57,12 +73,43 @@ type Config struct {
 	RootNode          *Cored
 	ImportedMnemonics map[string]string
 	BinaryVersion     string
+	DenomMetadata     []banktypes.Metadata
+	// Inflation is the fixed inflation set in genesis, nil means default one.
+	Inflation *sdk.Dec
+	// CommissionRate is the commission rate of the validator, nil means default one.
+	CommissionRate *sdk.Dec
+	// GenesisPatch is the JSON merge patch applied to the generated genesis.
+	GenesisPatch []byte
+	// HasSentries means that validator is fronted by sentry nodes, so peer exchange is disabled and it connects
+	// to the rest of the network only through them.
+	HasSentries bool
+	// SentryOf is the validator fronted by the node. Validator is kept private, so its address is not gossiped.
+	SentryOf *Cored
+	// SnapshotInterval is the number of blocks between state sync snapshots taken by the node, 0 disables them.
+	SnapshotInterval uint64
+	// StateSyncFrom is the node serving state sync snapshots. If set, node joins the network using the snapshot
+	// instead of replaying all the blocks.
+	StateSyncFrom *Cored
+	// Keys are the private keys of the node, used to reproduce identity of the node, random ones are generated if nil.
+	Keys *NodeKeys
+}
+
+// NodeKeys are the private keys defining identity of the node.
+type NodeKeys struct {
+	// NodeKey is the key identifying the node in p2p network
+	NodeKey ed25519.PrivateKey
+	// ValidatorKey is the key used to sign blocks, nil if node is not a validator
+	ValidatorKey ed25519.PrivateKey
 }
 
 // New creates new cored app.
 func New(cfg Config) Cored {
 	nodePublicKey, nodePrivateKey, err := ed25519.GenerateKey(rand.Reader)
 	must.OK(err)
+	if cfg.Keys != nil && cfg.Keys.NodeKey != nil {
+		nodePrivateKey = cfg.Keys.NodeKey
+		nodePublicKey = nodePrivateKey.Public().(ed25519.PublicKey)
+	}
 
 	var valPrivateKey ed25519.PrivateKey
 	if cfg.IsValidator {
@@ -72,6 +119,10 @@ func New(cfg Config) Cored {
 		)
 		valPublicKey, valPrivateKey, err = ed25519.GenerateKey(rand.Reader)
 		must.OK(err)
+		if cfg.Keys != nil && cfg.Keys.ValidatorKey != nil {
+			valPrivateKey = cfg.Keys.ValidatorKey
+			valPublicKey = valPrivateKey.Public().(ed25519.PublicKey)
+		}
 
 		stakerPrivKey, err := PrivateKeyFromMnemonic(cfg.StakerMnemonic)
 		must.OK(err)
@@ -90,7 +141,7 @@ func New(cfg Config) Cored {
 
 		clientCtx := client.NewContext(client.DefaultContextConfig(), newBasicManager()).WithChainID(string(cfg.Network.ChainID()))
 
-		createValidatorTx, err := prepareTxStakingCreateValidator(cfg.Network.ChainID(), clientCtx.TxConfig(), valPublicKey, stakerPrivKey, stake, minimumSelfDelegation.Amount)
+		createValidatorTx, err := prepareTxStakingCreateValidator(cfg.Network.ChainID(), clientCtx.TxConfig(), valPublicKey, stakerPrivKey, stake, minimumSelfDelegation.Amount, cfg.CommissionRate)
 		must.OK(err)
 		cfg.Network.AddGenesisTx(createValidatorTx)
 	}
@@ -113,6 +164,7 @@ func prepareTxStakingCreateValidator(
 	stakerPrivateKey cosmossecp256k1.PrivKey,
 	stakedBalance sdk.Coin,
 	selfDelegation sdk.Int,
+	commissionRate *sdk.Dec,
 ) ([]byte, error) {
 	// the passphrase here is the trick to import the private key into the keyring
 	const passphrase = "tmp"
@@ -122,6 +174,13 @@ func prepareTxStakingCreateValidator(
 		MaxRate:       sdk.MustNewDecFromStr("0.2"),
 		MaxChangeRate: sdk.MustNewDecFromStr("0.01"),
 	}
+	if commissionRate != nil {
+		commission.Rate = *commissionRate
+		// Max rate can't be lower than the rate.
+		if commission.MaxRate.LT(commission.Rate) {
+			commission.MaxRate = commission.Rate
+		}
+	}
 
 	stakerAddress := sdk.AccAddress(stakerPrivateKey.PubKey().Address())
 	msg, err := stakingtypes.NewMsgCreateValidator(
@@ -229,9 +288,30 @@ func (c Cored) TxFactory(clientCtx client.Context) tx.Factory {
 		WithTxConfig(clientCtx.TxConfig())
 }
 
-// HealthCheck checks if cored chain is ready to accept transactions.
+// HealthCheck checks if cored chain is ready to accept transactions. If node joins the network using state sync,
+// it is also verified that it caught up with the chain using the snapshot.
 func (c Cored) HealthCheck(ctx context.Context) error {
-	return infra.CheckCosmosNodeHealth(ctx, c.ClientContext(), c.Info())
+	if err := infra.CheckCosmosNodeHealth(ctx, c.ClientContext(), c.Info()); err != nil {
+		return err
+	}
+	if c.config.StateSyncFrom == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	status, err := c.ClientContext().RPCClient().Status(ctx)
+	if err != nil {
+		return retry.Retryable(errors.Wrap(err, "retrieving node status failed"))
+	}
+	if status.SyncInfo.CatchingUp || status.SyncInfo.EarliestBlockHeight == 0 {
+		return retry.Retryable(errors.New("node hasn't caught up with the chain yet"))
+	}
+	if status.SyncInfo.EarliestBlockHeight == 1 {
+		return errors.New("node replayed all the blocks instead of using state sync")
+	}
+	return nil
 }
 
 // Deployment returns deployment of cored.
@@ -285,10 +365,19 @@ func (c Cored) Deployment() infra.Deployment {
 				"--inv-check-period", "1",
 				"--chain-id", string(c.config.Network.ChainID()),
 			}
+			var peers []string
 			if c.config.RootNode != nil {
-				args = append(args,
-					"--p2p.persistent_peers", c.config.RootNode.NodeID()+"@"+infra.JoinNetAddr("", c.config.RootNode.Info().HostFromContainer, c.config.RootNode.Config().Ports.P2P),
-				)
+				peers = append(peers, c.config.RootNode.peerAddress())
+			}
+			if c.config.SentryOf != nil {
+				peers = append(peers, c.config.SentryOf.peerAddress())
+			}
+			if c.config.StateSyncFrom != nil {
+				// Snapshots are fetched over p2p, so node must be connected to the one serving them.
+				peers = append(peers, c.config.StateSyncFrom.peerAddress())
+			}
+			if len(peers) > 0 {
+				args = append(args, "--p2p.persistent_peers", strings.Join(peers, ","))
 			}
 
 			return args
@@ -307,19 +396,100 @@ func (c Cored) Deployment() infra.Deployment {
 			},
 		}
 	}
+	if c.config.SentryOf != nil {
+		if deployment.Requires.Timeout == 0 {
+			deployment.Requires.Timeout = 20 * time.Second
+		}
+		deployment.Requires.Dependencies = append(deployment.Requires.Dependencies, infra.IsRunning(*c.config.SentryOf))
+	}
+	if c.config.StateSyncFrom != nil {
+		// Trusted block is taken from the node serving snapshots, so it must be healthy before the node is prepared.
+		deployment.Requires.Timeout = time.Minute
+		deployment.Requires.Dependencies = append(deployment.Requires.Dependencies, *c.config.StateSyncFrom)
+	}
 	return deployment
 }
 
-func (c Cored) prepare() error {
+// ExportState exports the state of the stopped node in the form of genesis JSON, using the binary the node runs.
+func (c Cored) ExportState(ctx context.Context) ([]byte, error) {
+	output := &bytes.Buffer{}
+	if err := targets.RunOnce(ctx, c.Deployment(), []string{
+		"export",
+		"--home", targets.AppHomeDir,
+		"--chain-id", string(c.config.Network.ChainID()),
+	}, output); err != nil {
+		return nil, err
+	}
+
+	// Depending on the version, state is printed either to stdout or stderr, mixed with logs of cosmovisor,
+	// so the line containing JSON object with the state of the app is taken.
+	lines := bytes.Split(output.Bytes(), []byte("\n"))
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := bytes.TrimSpace(lines[i])
+		if bytes.HasPrefix(line, []byte("{")) && bytes.Contains(line, []byte(`"app_state"`)) && json.Valid(line) {
+			return line, nil
+		}
+	}
+	return nil, errors.Errorf("exported state of %s not found in the output", c.Name())
+}
+
+func (c Cored) peerAddress() string {
+	return c.NodeID() + "@" + infra.JoinNetAddr("", c.Info().HostFromContainer, c.Config().Ports.P2P)
+}
+
+// stateSyncConfig returns the config making the node join the network using snapshots served by StateSyncFrom node.
+// The latest block of that node is trusted.
+func (c Cored) stateSyncConfig(ctx context.Context) (*tmconfig.StateSyncConfig, error) {
+	source := *c.config.StateSyncFrom
+	block, err := source.ClientContext().RPCClient().Block(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching trusted block from %q failed", source.Name())
+	}
+
+	rpcServer := infra.JoinNetAddr("http", source.Info().HostFromContainer, source.Config().Ports.RPC)
+	cfg := tmconfig.DefaultStateSyncConfig()
+	cfg.Enable = true
+	// Tendermint requires at least two RPC servers, the same one is used twice because it is the only one we trust.
+	cfg.RPCServers = []string{rpcServer, rpcServer}
+	cfg.TrustHeight = block.Block.Height
+	cfg.TrustHash = block.BlockID.Hash.String()
+	return cfg, nil
+}
+
+func (c Cored) prepare(ctx context.Context) error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	var stateSync *tmconfig.StateSyncConfig
+	if c.config.StateSyncFrom != nil {
+		var err error
+		stateSync, err = c.stateSyncConfig(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
 	saveTendermintConfig(config.NodeConfig{
 		Name:           c.config.Name,
 		PrometheusPort: c.config.Ports.Prometheus,
 		NodeKey:        c.nodePrivateKey,
 		ValidatorKey:   c.validatorPrivateKey,
-	}, c.config.HomeDir)
+	}, c.config.HomeDir, func(cfg *tmconfig.Config) {
+		// RPC is queried directly from the browser by ping.pub.
+		cfg.RPC.CORSAllowedOrigins = []string{"*"}
+		if stateSync != nil {
+			cfg.StateSync = stateSync
+		}
+		if c.config.HasSentries {
+			// Validator is connected only to its sentries, it doesn't look for other peers.
+			cfg.P2P.PexReactor = false
+		}
+		if c.config.SentryOf != nil {
+			// Address of the validator is not gossiped and sentry always accepts connection from it.
+			cfg.P2P.PrivatePeerIDs = c.config.SentryOf.NodeID()
+			cfg.P2P.UnconditionalPeerIDs = c.config.SentryOf.NodeID()
+		}
+	})
 
 	appCfg := srvconfig.DefaultConfig()
 	appCfg.API.Enable = true
@@ -331,13 +501,21 @@ func (c Cored) prepare() error {
 	appCfg.GRPCWeb.EnableUnsafeCORS = true
 	appCfg.Telemetry.Enabled = true
 	appCfg.Telemetry.PrometheusRetentionTime = 600
+	if c.config.IsArchive {
+		// Archive node keeps all the historical states, so queries at any height may be served.
+		appCfg.Pruning = storetypes.PruningOptionNothing
+	}
+	if c.config.SnapshotInterval > 0 {
+		appCfg.StateSync.SnapshotInterval = c.config.SnapshotInterval
+		appCfg.StateSync.SnapshotKeepRecent = snapshotKeepRecent
+	}
 	srvconfig.WriteConfigFile(filepath.Join(c.config.HomeDir, "config", "app.toml"), appCfg)
 
 	if err := importMnemonicsToKeyring(c.config.HomeDir, c.importedMnemonics); err != nil {
 		return err
 	}
 
-	if err := c.config.Network.SaveGenesis(c.config.HomeDir); err != nil {
+	if err := c.SaveGenesis(c.config.HomeDir); err != nil {
 		return errors.WithStack(err)
 	}
 
@@ -359,20 +537,26 @@ func (c Cored) prepare() error {
 		"v1": "cored", // TODO(dhil) update to v1.0.0 once the binary is ready
 	}
 	for upgrade, binary := range upgrades {
-		return copyFile(filepath.Join(c.config.BinDir, ".cache", "docker", "cored", binary),
-			filepath.Join(c.config.HomeDir, "cosmovisor", "upgrades", upgrade, "bin", "cored"), 0o755)
+		return c.SaveUpgradeBinary(upgrade, binary)
 	}
 
 	return nil
 }
 
+// SaveUpgradeBinary places the binary in the cosmovisor's directory of the upgrade, so cosmovisor starts it once
+// the upgrade height is reached.
+func (c Cored) SaveUpgradeBinary(upgrade, binary string) error {
+	return copyFile(filepath.Join(c.config.BinDir, ".cache", "docker", "cored", binary),
+		filepath.Join(c.config.HomeDir, "cosmovisor", "upgrades", upgrade, "bin", "cored"), 0o755)
+}
+
 func (c Cored) saveClientWrapper(wrapperDir, hostname string) error {
-	client := `#!/bin/bash
+	client := `#!/bin/sh
 OPTS=""
-if [ "$1" == "tx" ] || [ "$1" == "q" ] || [ "$1" == "query" ]; then
+if [ "$1" = "tx" ] || [ "$1" = "q" ] || [ "$1" = "query" ]; then
 	OPTS="$OPTS --node ""` + infra.JoinNetAddr("tcp", hostname, c.config.Ports.RPC) + `"""
 fi
-if [ "$1" == "tx" ] || [ "$1" == "keys" ]; then
+if [ "$1" = "tx" ] || [ "$1" = "keys" ]; then
 	OPTS="$OPTS --keyring-backend ""test"""
 fi
 
@@ -0,0 +1,130 @@
+package cored
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+	"github.com/pkg/errors"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	tmtypes "github.com/tendermint/tendermint/types"
+
+	"github.com/CoreumFoundation/coreum/pkg/config"
+)
+
+// NewDenomMetadata returns bank metadata of the denom having base and display units.
+func NewDenomMetadata(base, display string, exponent uint32) banktypes.Metadata {
+	return banktypes.Metadata{
+		Description: "Test denom " + display,
+		DenomUnits: []*banktypes.DenomUnit{
+			{Denom: base},
+			{Denom: display, Exponent: exponent},
+		},
+		Base:    base,
+		Display: display,
+		Name:    strings.ToUpper(display),
+		Symbol:  strings.ToUpper(display),
+	}
+}
+
+// SaveGenesis saves genesis of the network in the home directory. Genesis contains additional denom metadata
+// and inflation configured for the node, and the genesis patch is applied to it.
+func (c Cored) SaveGenesis(homeDir string) error {
+	genesisDoc, err := c.config.Network.GenesisDoc()
+	if err != nil {
+		return err
+	}
+
+	if len(c.config.DenomMetadata) > 0 || c.config.Inflation != nil {
+		var appState map[string]json.RawMessage
+		if err := json.Unmarshal(genesisDoc.AppState, &appState); err != nil {
+			return errors.WithStack(err)
+		}
+
+		codec := config.NewEncodingConfig(newBasicManager()).Codec
+		if len(c.config.DenomMetadata) > 0 {
+			bankState := banktypes.GetGenesisStateFromAppState(codec, appState)
+			bankState.DenomMetadata = append(bankState.DenomMetadata, c.config.DenomMetadata...)
+			appState[banktypes.ModuleName] = codec.MustMarshalJSON(bankState)
+		}
+		if c.config.Inflation != nil {
+			var mintState minttypes.GenesisState
+			codec.MustUnmarshalJSON(appState[minttypes.ModuleName], &mintState)
+			// Inflation is fixed by setting the same min and max value.
+			mintState.Minter.Inflation = *c.config.Inflation
+			mintState.Params.InflationMin = *c.config.Inflation
+			mintState.Params.InflationMax = *c.config.Inflation
+			appState[minttypes.ModuleName] = codec.MustMarshalJSON(&mintState)
+		}
+
+		genesisDoc.AppState, err = json.MarshalIndent(appState, "", "  ")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	genesis, err := tmjson.MarshalIndent(genesisDoc, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(c.config.GenesisPatch) > 0 {
+		genesis, err = patchGenesis(genesis, c.config.GenesisPatch)
+		if err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(homeDir, "config"), 0o700); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(filepath.Join(homeDir, "config", "genesis.json"), genesis, 0o644))
+}
+
+// patchGenesis applies JSON merge patch (RFC 7386) to the genesis and verifies that the result is valid.
+func patchGenesis(genesis, patch []byte) ([]byte, error) {
+	var genesisValue, patchValue interface{}
+	if err := decodeJSON(genesis, &genesisValue); err != nil {
+		return nil, err
+	}
+	if err := decodeJSON(patch, &patchValue); err != nil {
+		return nil, errors.Wrap(err, "invalid genesis patch")
+	}
+
+	patched, err := json.MarshalIndent(mergePatch(genesisValue, patchValue), "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if _, err := tmtypes.GenesisDocFromJSON(patched); err != nil {
+		return nil, errors.Wrap(err, "patched genesis is invalid")
+	}
+	return patched, nil
+}
+
+// decodeJSON decodes JSON keeping numbers as they are, so big integers are not rounded.
+func decodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return errors.WithStack(decoder.Decode(v))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
@@ -0,0 +1,71 @@
+package apps
+
+import (
+	"strconv"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+)
+
+// genesisStakingAmount is the amount of staking denom minted at genesis to each of the standard accounts.
+const genesisStakingAmount = 500_000_000_000_000
+
+// genesisDenomDisplayAmount is the amount of each additional denom, in display units, minted at genesis to each
+// of the standard accounts.
+const genesisDenomDisplayAmount = 1_000_000
+
+// parseGenesisDenoms parses definitions of additional denoms in the form of <base>:<display>:<exponent>.
+func parseGenesisDenoms(definitions []string, stakingDenom string) ([]banktypes.Metadata, sdk.Coins, error) {
+	var (
+		metadata []banktypes.Metadata
+		balances sdk.Coins
+	)
+	for _, definition := range definitions {
+		parts := strings.Split(definition, ":")
+		if len(parts) != 3 {
+			return nil, nil, errors.Errorf("invalid denom %q, expected <base>:<display>:<exponent>, e.g. uatom:atom:6",
+				definition)
+		}
+		exponent, err := strconv.ParseUint(parts[2], 10, 32)
+		if err != nil || exponent == 0 {
+			return nil, nil, errors.Errorf("invalid exponent of denom %q, positive integer is expected", definition)
+		}
+		if parts[0] == stakingDenom {
+			return nil, nil, errors.Errorf("denom %q is the staking denom", parts[0])
+		}
+		if balances.AmountOf(parts[0]).IsPositive() {
+			return nil, nil, errors.Errorf("denom %q is defined more than once", parts[0])
+		}
+
+		m := cored.NewDenomMetadata(parts[0], parts[1], uint32(exponent))
+		if err := m.Validate(); err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid denom %q", definition)
+		}
+		metadata = append(metadata, m)
+		balances = balances.Add(sdk.NewCoin(m.Base, sdk.NewIntWithDecimal(genesisDenomDisplayAmount, int(exponent))))
+	}
+	return metadata, balances, nil
+}
+
+// GenesisBalance returns the amount of the denom minted at genesis to each of the standard accounts, including
+// the faucet one. Empty denom means the staking denom. Nil value is returned if the denom is not minted by znet,
+// e.g. because it is added by the genesis patch.
+func GenesisBalance(genesisDenoms []string, denom string) (sdk.Int, error) {
+	if denom == "" {
+		return sdk.NewInt(genesisStakingAmount), nil
+	}
+	// Staking denom is verified when the network is created.
+	_, balances, err := parseGenesisDenoms(genesisDenoms, "")
+	if err != nil {
+		return sdk.Int{}, err
+	}
+	amount := balances.AmountOf(denom)
+	if !amount.IsPositive() {
+		return sdk.Int{}, nil
+	}
+	return amount, nil
+}
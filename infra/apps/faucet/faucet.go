@@ -1,14 +1,19 @@
 package faucet
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/pkg/errors"
 
 	"github.com/CoreumFoundation/coreum-tools/pkg/must"
@@ -25,8 +30,41 @@ const (
 
 	// DefaultPort is the default port faucet listens on for client connections.
 	DefaultPort = 8090
+
+	// DefaultTransferAmount is the default amount of tokens transferred by faucet on each request.
+	// It is small, so the faucet isn't drained by many requests.
+	DefaultTransferAmount = 100_000_000
+
+	// IntegrationTestsTransferAmount is the default amount of tokens transferred by faucet on each request if
+	// integration tests are run. Test groups fund their accounts using several requests.
+	IntegrationTestsTransferAmount = 1_000_000_000_000
+
+	// minTransfers is the minimum number of transfers the faucet must be able to execute using its genesis balance.
+	minTransfers = 100
 )
 
+// ValidateSettings verifies that settings of the faucet are valid. Balance is the amount of tokens funded to the faucet
+// in genesis, nil value means it is unknown.
+func ValidateSettings(transferAmount int64, denom string, rateLimit int, balance sdk.Int) error {
+	if transferAmount <= 0 {
+		return errors.Errorf("invalid faucet transfer amount %d, positive number is expected", transferAmount)
+	}
+	if !balance.IsNil() && balance.LT(sdk.NewInt(transferAmount).MulRaw(minTransfers)) {
+		return errors.Errorf("faucet transfer amount %d is too high, genesis balance %s of the faucet allows "+
+			"only %s transfers, at least %d are required", transferAmount, balance, balance.QuoRaw(transferAmount),
+			minTransfers)
+	}
+	if denom != "" {
+		if err := sdk.ValidateDenom(denom); err != nil {
+			return errors.Wrapf(err, "invalid faucet denom %q", denom)
+		}
+	}
+	if rateLimit < 0 {
+		return errors.Errorf("invalid faucet rate limit %d, 0 or positive number is expected", rateLimit)
+	}
+	return nil
+}
+
 // Config stores faucet app config.
 type Config struct {
 	Name    string
@@ -36,6 +74,15 @@ type Config struct {
 	AppInfo *infra.AppInfo
 	Port    int
 	Cored   cored.Cored
+
+	// TransferAmount is the amount of tokens transferred on each request
+	TransferAmount int64
+
+	// Denom is the denom of tokens transferred on each request, empty means the default denom of the chain
+	Denom string
+
+	// RateLimit is the maximum number of requests served for each IP address per minute, 0 means there is no limit
+	RateLimit int
 }
 
 // New creates new faucet app.
@@ -65,6 +112,19 @@ func (f Faucet) Port() int {
 	return f.config.Port
 }
 
+// TransferAmount returns the amount of tokens transferred on each request.
+func (f Faucet) TransferAmount() int64 {
+	return f.config.TransferAmount
+}
+
+// Denom returns the denom of tokens transferred on each request.
+func (f Faucet) Denom() string {
+	if f.config.Denom != "" {
+		return f.config.Denom
+	}
+	return f.config.Cored.Config().Network.Denom()
+}
+
 // Info returns deployment info.
 func (f Faucet) Info() infra.DeploymentInfo {
 	return f.config.AppInfo.Info()
@@ -90,6 +150,30 @@ func (f Faucet) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
+// Fund requests faucet to transfer tokens to the address.
+func (f Faucet) Fund(ctx context.Context, address string) error {
+	body := must.Bytes(json.Marshal(struct {
+		Address string `json:"address"`
+	}{
+		Address: address,
+	}))
+
+	fundURL := url.URL{Scheme: "http", Host: infra.JoinNetAddr("", f.Info().HostFromHost, f.config.Port), Path: "/api/faucet/v1/fund"}
+	req := must.HTTPRequest(http.NewRequestWithContext(ctx, http.MethodPost, fundURL.String(), bytes.NewReader(body)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return errors.Errorf("funding address %s failed, status code: %d, response: %s", address, resp.StatusCode, respBody)
+	}
+	return nil
+}
+
 // Deployment returns deployment of cored.
 func (f Faucet) Deployment() infra.Deployment {
 	return infra.Deployment{
@@ -104,13 +188,21 @@ func (f Faucet) Deployment() infra.Deployment {
 			},
 		},
 		ArgsFunc: func() []string {
-			return []string{
+			args := []string{
 				"--address", infra.JoinNetAddrIP("", net.IPv4zero, f.config.Port),
 				"--chain-id", string(f.config.ChainID),
 				"--key-path-mnemonic", filepath.Join(targets.AppHomeDir, "mnemonic-key"),
 				"--node", infra.JoinNetAddr("", f.config.Cored.Info().HostFromContainer, f.config.Cored.Config().Ports.GRPC),
+				"--transfer-amount", strconv.FormatInt(f.config.TransferAmount, 10),
 				"--log-format", "yaml",
 			}
+			if f.config.Denom != "" {
+				args = append(args, "--denom", f.config.Denom)
+			}
+			if f.config.RateLimit > 0 {
+				args = append(args, "--ip-rate-limit-per-minute", strconv.Itoa(f.config.RateLimit))
+			}
+			return args
 		},
 		Ports: map[string]int{
 			"server": f.config.Port,
@@ -121,8 +213,10 @@ func (f Faucet) Deployment() infra.Deployment {
 				f.config.Cored,
 			},
 		},
-		PrepareFunc: func() error {
+		PrepareFunc: func(ctx context.Context) error {
 			return errors.WithStack(os.WriteFile(filepath.Join(f.config.HomeDir, "mnemonic-key"), []byte(f.config.Cored.Config().FaucetMnemonic), 0o400))
 		},
+		// It crashes if connection to the chain is lost, e.g. when node is restarted.
+		RestartPolicy: infra.RestartPolicyOnFailure,
 	}
 }
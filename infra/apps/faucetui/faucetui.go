@@ -0,0 +1,92 @@
+package faucetui
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/faucet"
+)
+
+const (
+	// AppType is the type of faucet UI application.
+	AppType infra.AppType = "faucetui"
+
+	// DefaultPort is the default port faucet UI serves the web UI on.
+	DefaultPort = 8099
+)
+
+// Config stores faucet UI app configuration.
+type Config struct {
+	Name    string
+	AppInfo *infra.AppInfo
+	Port    int
+	Faucet  faucet.Faucet
+}
+
+// New creates new faucet UI app.
+func New(config Config) FaucetUI {
+	return FaucetUI{
+		config: config,
+	}
+}
+
+// FaucetUI represents web UI of the faucet.
+type FaucetUI struct {
+	config Config
+}
+
+// Type returns type of application.
+func (f FaucetUI) Type() infra.AppType {
+	return AppType
+}
+
+// Name returns name of app.
+func (f FaucetUI) Name() string {
+	return f.config.Name
+}
+
+// Info returns deployment info.
+func (f FaucetUI) Info() infra.DeploymentInfo {
+	return f.config.AppInfo.Info()
+}
+
+// Deployment returns deployment of faucet UI.
+func (f FaucetUI) Deployment() infra.Deployment {
+	return infra.Deployment{
+		Image:     "coreumfoundation/faucet-ui:znet-latest",
+		RunAsUser: true,
+		Name:      f.Name(),
+		Info:      f.config.AppInfo,
+		EnvVarsFunc: func() []infra.EnvVar {
+			return []infra.EnvVar{
+				{
+					Name:  "PORT",
+					Value: strconv.Itoa(f.config.Port),
+				},
+				{
+					// UI runs in the browser, so faucet must be reachable from the host.
+					Name:  "FAUCET_URL",
+					Value: infra.JoinNetAddr("http", f.config.Faucet.Info().HostFromHost, f.config.Faucet.Port()),
+				},
+				{
+					Name:  "FAUCET_DENOM",
+					Value: f.config.Faucet.Denom(),
+				},
+				{
+					Name:  "FAUCET_TRANSFER_AMOUNT",
+					Value: strconv.FormatInt(f.config.Faucet.TransferAmount(), 10),
+				},
+			}
+		},
+		Ports: map[string]int{
+			"web": f.config.Port,
+		},
+		Requires: infra.Prerequisites{
+			Timeout: 20 * time.Second,
+			Dependencies: []infra.HealthCheckCapable{
+				f.config.Faucet,
+			},
+		},
+	}
+}
@@ -1,6 +1,10 @@
 package gaiad
 
 import (
+	"regexp"
+
+	"github.com/pkg/errors"
+
 	"github.com/CoreumFoundation/crust/infra"
 	"github.com/CoreumFoundation/crust/infra/cosmoschain"
 )
@@ -9,14 +13,31 @@ const (
 	dockerImage   = "gaiad:znet"
 	accountPrefix = "cosmos"
 	execName      = "gaiad"
+	gasPrices     = "0.01stake"
 
 	// AppType is the type of gaia application.
 	AppType infra.AppType = "gaiad"
 
 	// DefaultChainID is the gaia's default chain id.
 	DefaultChainID = "gaia-localnet-1"
+
+	// SecondChainID is the chain id of the second gaia chain, used by multi-hop IBC topologies.
+	SecondChainID = "gaia-localnet-2"
 )
 
+// ICAHostAllowMessages are the messages interchain accounts hosted by gaia may execute, if ICA is enabled.
+var ICAHostAllowMessages = []string{
+	"/cosmos.bank.v1beta1.MsgSend",
+	"/cosmos.staking.v1beta1.MsgDelegate",
+	"/cosmos.staking.v1beta1.MsgUndelegate",
+	"/cosmos.staking.v1beta1.MsgBeginRedelegate",
+	"/cosmos.distribution.v1beta1.MsgWithdrawDelegatorReward",
+	"/cosmos.gov.v1beta1.MsgVote",
+	"/ibc.applications.transfer.v1.MsgTransfer",
+}
+
+var versionRegexp = regexp.MustCompile(`^v[0-9]+\.[0-9]+\.[0-9]+([-.][0-9A-Za-z.-]+)?$`)
+
 // DefaultPorts are the default ports listens on.
 var DefaultPorts = cosmoschain.Ports{
 	RPC:     26557,
@@ -26,12 +47,36 @@ var DefaultPorts = cosmoschain.Ports{
 	PProf:   6050,
 }
 
-// New creates new gaia blockchain.
-func New(config cosmoschain.AppConfig) cosmoschain.BaseApp {
+// SecondPorts are the ports the second gaia chain listens on.
+var SecondPorts = cosmoschain.Ports{
+	RPC:     26257,
+	P2P:     26256,
+	GRPC:    9050,
+	GRPCWeb: 9051,
+	PProf:   6020,
+}
+
+// New creates new gaia blockchain. Version selects the binary of gaia, empty one means the default version.
+// Binaries of other versions are included in the image, named gaiad-<version>, if they are defined in the tools
+// registry of the build system when the image is built.
+func New(config cosmoschain.AppConfig, version string) cosmoschain.BaseApp {
+	binary := execName
+	if version != "" {
+		binary += "-" + version
+	}
 	return cosmoschain.New(cosmoschain.AppTypeConfig{
 		AppType:       AppType,
 		DockerImage:   dockerImage,
 		AccountPrefix: accountPrefix,
-		ExecName:      execName,
+		ExecName:      binary,
+		GasPrices:     gasPrices,
 	}, config)
 }
+
+// ValidateVersion verifies that the version of gaia is well-formed.
+func ValidateVersion(version string) error {
+	if version != "" && !versionRegexp.MatchString(version) {
+		return errors.Errorf("invalid gaia version %q, expected e.g. v8.0.1", version)
+	}
+	return nil
+}
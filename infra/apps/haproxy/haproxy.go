@@ -0,0 +1,178 @@
+package haproxy
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/must"
+	"github.com/CoreumFoundation/coreum-tools/pkg/retry"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+)
+
+var (
+	//go:embed config/haproxy.tmpl
+	configTmpl     string
+	configTemplate = template.Must(template.New("").Parse(configTmpl))
+)
+
+const (
+	// AppType is the type of haproxy application.
+	AppType infra.AppType = "haproxy"
+
+	configFileName = "haproxy.cfg"
+)
+
+// Ports defines ports used by haproxy application.
+type Ports struct {
+	RPC   int `json:"rpc"`
+	GRPC  int `json:"grpc"`
+	API   int `json:"api"`
+	Stats int `json:"stats"`
+}
+
+// DefaultPorts are the default ports haproxy listens on.
+var DefaultPorts = Ports{
+	RPC:   26357,
+	GRPC:  9060,
+	API:   1217,
+	Stats: 8404,
+}
+
+// Config stores haproxy app config.
+type Config struct {
+	Name       string
+	HomeDir    string
+	Ports      Ports
+	AppInfo    *infra.AppInfo
+	CoredNodes []cored.Cored
+}
+
+// New creates new haproxy app.
+func New(config Config) HAProxy {
+	return HAProxy{
+		config: config,
+	}
+}
+
+// HAProxy represents haproxy load-balancing traffic across cored nodes.
+type HAProxy struct {
+	config Config
+}
+
+// Type returns type of application.
+func (h HAProxy) Type() infra.AppType {
+	return AppType
+}
+
+// Name returns name of app.
+func (h HAProxy) Name() string {
+	return h.config.Name
+}
+
+// Info returns deployment info.
+func (h HAProxy) Info() infra.DeploymentInfo {
+	return h.config.AppInfo.Info()
+}
+
+// Config returns haproxy config.
+func (h HAProxy) Config() Config {
+	return h.config
+}
+
+// HealthCheck checks if haproxy is operating.
+func (h HAProxy) HealthCheck(ctx context.Context) error {
+	if h.config.AppInfo.Info().Status != infra.AppStatusRunning {
+		return retry.Retryable(errors.Errorf("haproxy hasn't started yet"))
+	}
+
+	statsURL := url.URL{
+		Scheme: "http",
+		Host:   infra.JoinNetAddr("", h.Info().HostFromHost, h.config.Ports.Stats),
+		Path:   "/stats",
+	}
+	req := must.HTTPRequest(http.NewRequestWithContext(ctx, http.MethodGet, statsURL.String(), nil))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return retry.Retryable(errors.WithStack(err))
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return retry.Retryable(errors.Errorf("health check failed, status code: %d", resp.StatusCode))
+	}
+	return nil
+}
+
+// Deployment returns deployment of haproxy.
+func (h HAProxy) Deployment() infra.Deployment {
+	return infra.Deployment{
+		Image:     "haproxy:2.7",
+		RunAsUser: true,
+		Name:      h.Name(),
+		Info:      h.config.AppInfo,
+		Volumes: []infra.Volume{
+			{
+				Source:      h.config.HomeDir,
+				Destination: "/usr/local/etc/haproxy",
+			},
+		},
+		Ports: infra.PortsToMap(h.config.Ports),
+		Requires: infra.Prerequisites{
+			Timeout: 20 * time.Second,
+			Dependencies: func() []infra.HealthCheckCapable {
+				// Nodes only need to be running to resolve their addresses, unhealthy ones are ejected by haproxy.
+				containers := make([]infra.HealthCheckCapable, 0, len(h.config.CoredNodes))
+				for _, node := range h.config.CoredNodes {
+					containers = append(containers, infra.IsRunning(node))
+				}
+				return containers
+			}(),
+		},
+		PrepareFunc: h.saveConfigFile,
+	}
+}
+
+func (h HAProxy) saveConfigFile(ctx context.Context) error {
+	type nodeConfigArgs struct {
+		Name  string
+		Host  string
+		Ports cored.Ports
+	}
+
+	nodesConfig := make([]nodeConfigArgs, 0, len(h.config.CoredNodes))
+	for _, node := range h.config.CoredNodes {
+		nodesConfig = append(nodesConfig, nodeConfigArgs{
+			Name:  node.Name(),
+			Host:  node.Info().HostFromContainer,
+			Ports: node.Config().Ports,
+		})
+	}
+
+	configArgs := struct {
+		Ports Ports
+		Nodes []nodeConfigArgs
+	}{
+		Ports: h.config.Ports,
+		Nodes: nodesConfig,
+	}
+
+	buf := &bytes.Buffer{}
+	if err := configTemplate.Execute(buf, configArgs); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(h.config.HomeDir, configFileName), buf.Bytes(), 0o600); err != nil {
+		return errors.Wrapf(err, "can't write haproxy %s file", configFileName)
+	}
+	return nil
+}
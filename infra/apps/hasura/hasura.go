@@ -96,5 +96,7 @@ func (h Hasura) Deployment() infra.Deployment {
 				h.config.Postgres,
 			},
 		},
+		// It crashes if connection to the database is lost, e.g. when postgres is restarted.
+		RestartPolicy: infra.RestartPolicyOnFailure,
 	}
 }
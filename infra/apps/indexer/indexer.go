@@ -0,0 +1,121 @@
+package indexer
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/must"
+	"github.com/CoreumFoundation/coreum-tools/pkg/retry"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+	"github.com/CoreumFoundation/crust/infra/apps/postgres"
+)
+
+const (
+	// AppType is the type of indexer application.
+	AppType infra.AppType = "indexer"
+
+	// DefaultPort is the default port the status server of the indexer listens on.
+	DefaultPort = 8091
+
+	// DefaultPostgresPort is the default port of postgres storing data of the indexer, it differs from the one used
+	// by block explorer, so both of them may run at the same time.
+	DefaultPostgresPort = 5433
+)
+
+// Config stores indexer app config.
+type Config struct {
+	Name     string
+	AppInfo  *infra.AppInfo
+	Port     int
+	Cored    cored.Cored
+	Postgres postgres.Postgres
+}
+
+// New creates new indexer app.
+func New(config Config) Indexer {
+	return Indexer{
+		config: config,
+	}
+}
+
+// Indexer represents the app storing blocks, transactions and events produced by cored in postgres database.
+type Indexer struct {
+	config Config
+}
+
+// Type returns type of application.
+func (i Indexer) Type() infra.AppType {
+	return AppType
+}
+
+// Name returns name of app.
+func (i Indexer) Name() string {
+	return i.config.Name
+}
+
+// Port returns port used by the application.
+func (i Indexer) Port() int {
+	return i.config.Port
+}
+
+// Info returns deployment info.
+func (i Indexer) Info() infra.DeploymentInfo {
+	return i.config.AppInfo.Info()
+}
+
+// HealthCheck checks if the indexer is operating.
+func (i Indexer) HealthCheck(ctx context.Context) error {
+	if i.config.AppInfo.Info().Status != infra.AppStatusRunning {
+		return retry.Retryable(errors.Errorf("indexer hasn't started yet"))
+	}
+
+	statusURL := url.URL{Scheme: "http", Host: infra.JoinNetAddr("", i.Info().HostFromHost, i.config.Port), Path: "/status"}
+	req := must.HTTPRequest(http.NewRequestWithContext(ctx, http.MethodGet, statusURL.String(), nil))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return retry.Retryable(errors.WithStack(err))
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return retry.Retryable(errors.Errorf("health check failed, status code: %d", resp.StatusCode))
+	}
+	return nil
+}
+
+// Deployment returns deployment of the indexer.
+func (i Indexer) Deployment() infra.Deployment {
+	return infra.Deployment{
+		Image: "indexer:znet",
+		Name:  i.Name(),
+		Info:  i.config.AppInfo,
+		ArgsFunc: func() []string {
+			return []string{
+				"--address", infra.JoinNetAddrIP("", net.IPv4zero, i.config.Port),
+				"--node", infra.JoinNetAddr("http", i.config.Cored.Info().HostFromContainer,
+					i.config.Cored.Config().Ports.RPC),
+				"--database", "postgres://" + postgres.User + "@" + infra.JoinNetAddr("",
+					i.config.Postgres.Info().HostFromContainer, i.config.Postgres.Port()) + "/" + postgres.DB,
+				"--log-format", "yaml",
+			}
+		},
+		Ports: map[string]int{
+			"server": i.config.Port,
+		},
+		Requires: infra.Prerequisites{
+			Timeout: 20 * time.Second,
+			Dependencies: []infra.HealthCheckCapable{
+				i.config.Cored,
+				i.config.Postgres,
+			},
+		},
+		// It crashes if connection to the database is lost, e.g. when postgres is restarted.
+		RestartPolicy: infra.RestartPolicyOnFailure,
+	}
+}
@@ -0,0 +1,117 @@
+package invariants
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/must"
+	"github.com/CoreumFoundation/coreum-tools/pkg/retry"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+)
+
+const (
+	// AppType is the type of invariant verifier application.
+	AppType infra.AppType = "invariants"
+
+	// DefaultPort is the default port the status server of the verifier listens on.
+	DefaultPort = 8097
+)
+
+// Config stores invariant verifier app config.
+type Config struct {
+	Name       string
+	AppInfo    *infra.AppInfo
+	Port       int
+	CoredNodes []cored.Cored
+}
+
+// New creates new invariant verifier app.
+func New(config Config) Invariants {
+	return Invariants{
+		config: config,
+	}
+}
+
+// Invariants represents the sidecar verifying invariants of each new block produced by the cored nodes.
+type Invariants struct {
+	config Config
+}
+
+// Type returns type of application.
+func (i Invariants) Type() infra.AppType {
+	return AppType
+}
+
+// Name returns name of app.
+func (i Invariants) Name() string {
+	return i.config.Name
+}
+
+// Port returns port used by the application.
+func (i Invariants) Port() int {
+	return i.config.Port
+}
+
+// Info returns deployment info.
+func (i Invariants) Info() infra.DeploymentInfo {
+	return i.config.AppInfo.Info()
+}
+
+// HealthCheck checks if the verifier is operating.
+func (i Invariants) HealthCheck(ctx context.Context) error {
+	if i.config.AppInfo.Info().Status != infra.AppStatusRunning {
+		return retry.Retryable(errors.Errorf("invariant verifier hasn't started yet"))
+	}
+
+	statusURL := url.URL{Scheme: "http", Host: infra.JoinNetAddr("", i.Info().HostFromHost, i.config.Port), Path: "/status"}
+	req := must.HTTPRequest(http.NewRequestWithContext(ctx, http.MethodGet, statusURL.String(), nil))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return retry.Retryable(errors.WithStack(err))
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return retry.Retryable(errors.Errorf("health check failed, status code: %d", resp.StatusCode))
+	}
+	return nil
+}
+
+// Deployment returns deployment of the invariant verifier.
+func (i Invariants) Deployment() infra.Deployment {
+	return infra.Deployment{
+		Image: "invariants:znet",
+		Name:  i.Name(),
+		Info:  i.config.AppInfo,
+		ArgsFunc: func() []string {
+			args := []string{
+				"--address", infra.JoinNetAddrIP("", net.IPv4zero, i.config.Port),
+				"--log-format", "yaml",
+			}
+			for _, node := range i.config.CoredNodes {
+				args = append(args, "--node", infra.JoinNetAddr("http", node.Info().HostFromContainer,
+					node.Config().Ports.RPC))
+			}
+			return args
+		},
+		Ports: map[string]int{
+			"server": i.config.Port,
+		},
+		Requires: infra.Prerequisites{
+			Timeout: 20 * time.Second,
+			Dependencies: func() []infra.HealthCheckCapable {
+				containers := make([]infra.HealthCheckCapable, 0, len(i.config.CoredNodes))
+				for _, node := range i.config.CoredNodes {
+					containers = append(containers, node)
+				}
+				return containers
+			}(),
+		},
+	}
+}
@@ -0,0 +1,187 @@
+package mockserver
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/must"
+	"github.com/CoreumFoundation/coreum-tools/pkg/retry"
+	"github.com/CoreumFoundation/crust/infra"
+)
+
+const (
+	// AppType is the type of mock server application.
+	AppType infra.AppType = "mockserver"
+
+	// DefaultPort is the default port mock server listens on.
+	DefaultPort = 8089
+
+	// rootDir is the directory inside container mock server loads stub mappings (`mappings`) and files of response
+	// bodies (`__files`) from.
+	rootDir = "/home/wiremock"
+)
+
+// ValidateMappings verifies that the directory containing stub mappings exists.
+func ValidateMappings(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return errors.Wrapf(err, "invalid mock server mappings directory %q", dir)
+	}
+	if !info.IsDir() {
+		return errors.Errorf("invalid mock server mappings directory %q, it is not a directory", dir)
+	}
+	return nil
+}
+
+// Config stores mock server app config.
+type Config struct {
+	Name    string
+	HomeDir string
+	AppInfo *infra.AppInfo
+	Port    int
+
+	// MappingsDir is the directory containing stub mappings in `mappings` and files of response bodies in `__files`,
+	// empty means mock server starts without stubs
+	MappingsDir string
+}
+
+// New creates new mock server app.
+func New(config Config) MockServer {
+	return MockServer{
+		config: config,
+	}
+}
+
+// MockServer represents wiremock server stubbing external HTTP APIs called by chain components and tests.
+type MockServer struct {
+	config Config
+}
+
+// Type returns type of application.
+func (m MockServer) Type() infra.AppType {
+	return AppType
+}
+
+// Name returns name of app.
+func (m MockServer) Name() string {
+	return m.config.Name
+}
+
+// Port returns port used by the application.
+func (m MockServer) Port() int {
+	return m.config.Port
+}
+
+// Info returns deployment info.
+func (m MockServer) Info() infra.DeploymentInfo {
+	return m.config.AppInfo.Info()
+}
+
+// HealthCheck checks if mock server is ready to serve stubs.
+func (m MockServer) HealthCheck(ctx context.Context) error {
+	if m.config.AppInfo.Info().Status != infra.AppStatusRunning {
+		return retry.Retryable(errors.Errorf("mock server hasn't started yet"))
+	}
+
+	mappingsURL := url.URL{
+		Scheme: "http",
+		Host:   infra.JoinNetAddr("", m.Info().HostFromHost, m.config.Port),
+		Path:   "/__admin/mappings",
+	}
+	req := must.HTTPRequest(http.NewRequestWithContext(ctx, http.MethodGet, mappingsURL.String(), nil))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return retry.Retryable(errors.WithStack(err))
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return retry.Retryable(errors.Errorf("health check failed, status code: %d", resp.StatusCode))
+	}
+	return nil
+}
+
+// Deployment returns deployment of mock server.
+func (m MockServer) Deployment() infra.Deployment {
+	return infra.Deployment{
+		Image:     "wiremock/wiremock:2.35.0",
+		RunAsUser: true,
+		Name:      m.Name(),
+		Info:      m.config.AppInfo,
+		Volumes: []infra.Volume{
+			{
+				Source:      m.config.HomeDir,
+				Destination: rootDir,
+			},
+		},
+		ArgsFunc: func() []string {
+			return []string{
+				"--port", strconv.Itoa(m.config.Port),
+				"--root-dir", rootDir,
+			}
+		},
+		Ports: map[string]int{
+			"http": m.config.Port,
+		},
+		PrepareFunc: m.prepare,
+	}
+}
+
+// prepare copies stub mappings to the home directory, so environment doesn't depend on the directory after it is
+// created.
+func (m MockServer) prepare(_ context.Context) error {
+	for _, dir := range []string{"mappings", "__files"} {
+		if err := os.MkdirAll(filepath.Join(m.config.HomeDir, dir), 0o700); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if m.config.MappingsDir == "" {
+		return nil
+	}
+	return errors.Wrap(copyDir(m.config.MappingsDir, m.config.HomeDir), "copying mock server mappings failed")
+}
+
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		target := filepath.Join(dst, relPath)
+		if d.IsDir() {
+			return errors.WithStack(os.MkdirAll(target, 0o700))
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		fr, err := os.Open(path)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer fr.Close()
+
+		fw, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer fw.Close()
+
+		_, err = io.Copy(fw, fr)
+		return errors.WithStack(err)
+	})
+}
@@ -0,0 +1,165 @@
+package nginx
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// CACertFile is the name of the file containing certificate of the authority signing certificates of the proxy.
+	CACertFile = "ca.crt"
+
+	caKeyFile = "ca.key"
+
+	caValidity = 10 * 365 * 24 * time.Hour
+
+	// certValidity is the maximum validity of the server certificate accepted by Apple devices.
+	certValidity = 825 * 24 * time.Hour
+)
+
+// ensureCA loads the certificate authority from the directory, it is generated if it doesn't exist yet. It is shared
+// by all the environments, so devices trusting it once accept certificates of all of them.
+func ensureCA(dir string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certPath := filepath.Join(dir, CACertFile)
+	keyPath := filepath.Join(dir, caKeyFile)
+
+	certPEM, err := os.ReadFile(certPath)
+	switch {
+	case err == nil:
+		keyPEM, err := os.ReadFile(keyPath)
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		return parseCA(certPEM, keyPEM)
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, nil, errors.WithStack(err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"crust znet"}, CommonName: "crust znet CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	if err := writeKey(keyPath, key); err != nil {
+		return nil, nil, err
+	}
+	if err := writeCert(certPath, der); err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+func parseCA(certPEM, keyPEM []byte) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, nil, errors.New("decoding CA certificate failed")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, nil, errors.New("decoding CA key failed")
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	return cert, key, nil
+}
+
+// issueCert issues the server certificate valid for the hosts, which are host names or IP addresses.
+func issueCert(caCert *x509.Certificate, caKey *ecdsa.PrivateKey, hosts []string, certPath, keyPath string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{"crust znet"}, CommonName: hosts[0]},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := writeKey(keyPath, key); err != nil {
+		return err
+	}
+	return writeCert(certPath, der)
+}
+
+func serialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return serial, nil
+}
+
+func writeKey(path string, key *ecdsa.PrivateKey) error {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}),
+		0o600))
+}
+
+func writeCert(path string, der []byte) error {
+	return errors.WithStack(os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		0o644))
+}
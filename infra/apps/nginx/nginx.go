@@ -0,0 +1,217 @@
+package nginx
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"net"
+	"os"
+	"path/filepath"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+)
+
+var (
+	//go:embed config/nginx.tmpl
+	configTmpl     string
+	configTemplate = template.Must(template.New("").Parse(configTmpl))
+)
+
+const (
+	// AppType is the type of nginx application.
+	AppType infra.AppType = "nginx"
+
+	configDir      = "/etc/nginx/conf.d"
+	configFileName = "znet.conf"
+	certFileName   = "server.crt"
+	keyFileName    = "server.key"
+)
+
+// Ports defines ports used by nginx application.
+type Ports struct {
+	HTTPS int `json:"https"`
+	GRPC  int `json:"grpc"`
+}
+
+// DefaultPorts are the default ports nginx listens on.
+var DefaultPorts = Ports{
+	HTTPS: 8443,
+	GRPC:  9443,
+}
+
+// Upstream is the app traffic is proxied to.
+type Upstream struct {
+	App  infra.AppWithInfo
+	Port int
+}
+
+// Config stores nginx app config.
+type Config struct {
+	Name    string
+	HomeDir string
+	// CADir is the directory where certificate authority signing certificates of the proxy is stored
+	CADir   string
+	Ports   Ports
+	AppInfo *infra.AppInfo
+	Cored   cored.Cored
+
+	// Faucet, Hasura and UI are proxied if they are set, UI is served at the root path
+	Faucet *Upstream
+	Hasura *Upstream
+	UI     *Upstream
+}
+
+// New creates new nginx app.
+func New(config Config) Nginx {
+	return Nginx{
+		config: config,
+	}
+}
+
+// Nginx represents nginx terminating TLS and exposing endpoints of the apps under stable paths of single host.
+type Nginx struct {
+	config Config
+}
+
+// Type returns type of application.
+func (n Nginx) Type() infra.AppType {
+	return AppType
+}
+
+// Name returns name of app.
+func (n Nginx) Name() string {
+	return n.config.Name
+}
+
+// Info returns deployment info.
+func (n Nginx) Info() infra.DeploymentInfo {
+	return n.config.AppInfo.Info()
+}
+
+// Config returns nginx config.
+func (n Nginx) Config() Config {
+	return n.config
+}
+
+// Deployment returns deployment of nginx.
+func (n Nginx) Deployment() infra.Deployment {
+	return infra.Deployment{
+		Image: "nginx:1.23-alpine",
+		Name:  n.Name(),
+		Info:  n.config.AppInfo,
+		Volumes: []infra.Volume{
+			{
+				Source:      n.config.HomeDir,
+				Destination: configDir,
+			},
+		},
+		Ports: infra.PortsToMap(n.config.Ports),
+		// Endpoints are tested from other devices, like phones, so they must be reachable from the network.
+		PublishOnAllInterfaces: true,
+		Requires: infra.Prerequisites{
+			Timeout: 20 * time.Second,
+			Dependencies: func() []infra.HealthCheckCapable {
+				// Apps only need to be running to resolve their addresses.
+				dependencies := []infra.HealthCheckCapable{infra.IsRunning(n.config.Cored)}
+				for _, upstream := range n.upstreams() {
+					dependencies = append(dependencies, infra.IsRunning(upstream.App))
+				}
+				return dependencies
+			}(),
+		},
+		PrepareFunc: n.prepare,
+	}
+}
+
+func (n Nginx) upstreams() []*Upstream {
+	return lo.Filter([]*Upstream{n.config.Faucet, n.config.Hasura, n.config.UI}, func(u *Upstream, _ int) bool {
+		return u != nil
+	})
+}
+
+func (n Nginx) prepare(_ context.Context) error {
+	caCert, caKey, err := ensureCA(n.config.CADir)
+	if err != nil {
+		return errors.Wrap(err, "preparing certificate authority failed")
+	}
+	// Server certificate is issued each time, so it is valid for the current addresses of the host.
+	if err := issueCert(caCert, caKey, n.hosts(), filepath.Join(n.config.HomeDir, certFileName),
+		filepath.Join(n.config.HomeDir, keyFileName)); err != nil {
+		return errors.Wrap(err, "issuing server certificate failed")
+	}
+	return n.saveConfigFile()
+}
+
+// hosts returns host names and IP addresses the proxy is reachable under.
+func (n Nginx) hosts() []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1", n.config.Cored.Info().HostFromHost}
+	if hostname, err := os.Hostname(); err == nil {
+		hosts = append(hosts, hostname, hostname+".local")
+	}
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && !ipNet.IP.IsLinkLocalUnicast() {
+				hosts = append(hosts, ipNet.IP.String())
+			}
+		}
+	}
+	return lo.Uniq(lo.Compact(hosts))
+}
+
+func (n Nginx) saveConfigFile() error {
+	type location struct {
+		Path     string
+		Upstream string
+	}
+
+	upstreamURL := func(proto string, upstream Upstream) string {
+		return infra.JoinNetAddr(proto, upstream.App.Info().HostFromContainer, upstream.Port)
+	}
+
+	coredPorts := n.config.Cored.Config().Ports
+	locations := []location{
+		{Path: "rpc", Upstream: upstreamURL("http", Upstream{App: n.config.Cored, Port: coredPorts.RPC})},
+		{Path: "api", Upstream: upstreamURL("http", Upstream{App: n.config.Cored, Port: coredPorts.API})},
+		{Path: "grpc-web", Upstream: upstreamURL("http", Upstream{App: n.config.Cored, Port: coredPorts.GRPCWeb})},
+	}
+	if n.config.Faucet != nil {
+		locations = append(locations, location{Path: "faucet", Upstream: upstreamURL("http", *n.config.Faucet)})
+	}
+	if n.config.Hasura != nil {
+		locations = append(locations, location{Path: "hasura", Upstream: upstreamURL("http", *n.config.Hasura)})
+	}
+
+	configArgs := struct {
+		Ports     Ports
+		CertFile  string
+		KeyFile   string
+		Locations []location
+		UI        string
+		GRPC      string
+	}{
+		Ports:     n.config.Ports,
+		CertFile:  filepath.Join(configDir, certFileName),
+		KeyFile:   filepath.Join(configDir, keyFileName),
+		Locations: locations,
+		GRPC:      upstreamURL("grpc", Upstream{App: n.config.Cored, Port: coredPorts.GRPC}),
+	}
+	if n.config.UI != nil {
+		configArgs.UI = upstreamURL("http", *n.config.UI)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := configTemplate.Execute(buf, configArgs); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(n.config.HomeDir, configFileName), buf.Bytes(), 0o644); err != nil {
+		return errors.Wrapf(err, "can't write nginx %s file", configFileName)
+	}
+	return nil
+}
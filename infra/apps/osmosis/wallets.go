@@ -1,4 +0,0 @@
-package osmosis
-
-// RelayerMnemonic is mnemonic used be the relayer.
-const RelayerMnemonic = "gas december mango eager element proof budget polar layer worth there eight delay conduct ring wing hover fury flip shield task dismiss ahead olive"
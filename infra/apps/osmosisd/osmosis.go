@@ -1,6 +1,9 @@
-package osmosis
+package osmosisd
 
 import (
+	_ "embed"
+	"text/template"
+
 	"github.com/CoreumFoundation/crust/infra"
 	"github.com/CoreumFoundation/crust/infra/cosmoschain"
 )
@@ -9,12 +12,22 @@ const (
 	dockerImage   = "osmolabs/osmosis:14.0.0-alpine"
 	accountPrefix = "osmo"
 	execName      = "osmosisd"
+	gasPrices     = "0.025" + Denom
 
 	// AppType is the type of osmosis application.
 	AppType infra.AppType = "osmosis"
 
 	// DefaultChainID is the osmosis default chain id.
 	DefaultChainID = "osmosis-localnet-1"
+
+	// Denom is the denom of the osmosis native token, used to pay fees.
+	Denom = "uosmo"
+)
+
+var (
+	//go:embed run.tmpl
+	tmpl              string
+	runScriptTemplate = template.Must(template.New("").Parse(tmpl))
 )
 
 // DefaultPorts are the default ports listens on.
@@ -29,9 +42,11 @@ var DefaultPorts = cosmoschain.Ports{
 // New creates new osmosis blockchain.
 func New(config cosmoschain.AppConfig) cosmoschain.BaseApp {
 	return cosmoschain.New(cosmoschain.AppTypeConfig{
-		AppType:       AppType,
-		DockerImage:   dockerImage,
-		AccountPrefix: accountPrefix,
-		ExecName:      execName,
+		AppType:           AppType,
+		DockerImage:       dockerImage,
+		AccountPrefix:     accountPrefix,
+		ExecName:          execName,
+		GasPrices:         gasPrices,
+		RunScriptTemplate: runScriptTemplate,
 	}, config)
 }
@@ -0,0 +1,7 @@
+package osmosisd
+
+// RelayerMnemonic is mnemonic used be the relayer.
+const RelayerMnemonic = "gas december mango eager element proof budget polar layer worth there eight delay conduct ring wing hover fury flip shield task dismiss ahead olive"
+
+// FaucetMnemonic is mnemonic of the account funded in genesis, used by tests to get osmosis tokens.
+const FaucetMnemonic = "boss grain manage term chair round globe buddy pelican earn census funny fork idea alpha hurdle frequent young body culture february seed rule secret"
@@ -0,0 +1,123 @@
+package apps
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum/pkg/config"
+)
+
+// Genesis parameters which may be overridden.
+const (
+	genesisParamVotingPeriod  = "voting-period"
+	genesisParamMinDeposit    = "min-deposit"
+	genesisParamUnbondingTime = "unbonding-time"
+	genesisParamMaxValidators = "max-validators"
+	genesisParamInflation     = "inflation"
+	genesisParamCommission    = "commission-rates"
+)
+
+// GenesisParams returns the list of genesis parameters which may be overridden.
+func GenesisParams() []string {
+	return []string{
+		genesisParamVotingPeriod,
+		genesisParamMinDeposit,
+		genesisParamUnbondingTime,
+		genesisParamMaxValidators,
+		genesisParamInflation,
+		genesisParamCommission,
+	}
+}
+
+// genesisOverrides are the overridden parameters which are not a part of the network config.
+type genesisOverrides struct {
+	// Inflation is the fixed inflation, nil means it is not overridden.
+	Inflation *sdk.Dec
+	// CommissionRates are the commission rates of validators, assigned in order and repeated if there are more
+	// validators than rates, empty means default one.
+	CommissionRates []sdk.Dec
+}
+
+// CommissionRate returns the commission rate of the i-th validator, nil means default one.
+func (o genesisOverrides) CommissionRate(i int) *sdk.Dec {
+	if len(o.CommissionRates) == 0 {
+		return nil
+	}
+	rate := o.CommissionRates[i%len(o.CommissionRates)]
+	return &rate
+}
+
+// applyGenesisParams applies overrides, in the form of <param>=<value>, of governance and staking parameters
+// to the network config. Parameters which are not a part of the network config are returned separately.
+func applyGenesisParams(networkConfig *config.NetworkConfig, overrides []string) (genesisOverrides, error) {
+	var result genesisOverrides
+	for _, override := range overrides {
+		param, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return genesisOverrides{}, errors.Errorf(
+				"invalid genesis param %q, expected <param>=<value>, e.g. voting-period=20s", override)
+		}
+
+		switch param {
+		case genesisParamVotingPeriod:
+			period, err := parsePositiveDuration(param, value)
+			if err != nil {
+				return genesisOverrides{}, err
+			}
+			networkConfig.GovConfig.ProposalConfig.VotingPeriod = period.String()
+		case genesisParamMinDeposit:
+			amount, ok := sdk.NewIntFromString(value)
+			if !ok || amount.IsNegative() {
+				return genesisOverrides{}, errors.Errorf("invalid %s %q, non-negative integer amount of %s is expected", param,
+					value, networkConfig.Denom)
+			}
+			networkConfig.GovConfig.ProposalConfig.MinDepositAmount = amount.String()
+		case genesisParamUnbondingTime:
+			unbondingTime, err := parsePositiveDuration(param, value)
+			if err != nil {
+				return genesisOverrides{}, err
+			}
+			networkConfig.StakingConfig.UnbondingTime = unbondingTime.String()
+		case genesisParamMaxValidators:
+			maxValidators, err := strconv.ParseUint(value, 10, 31)
+			if err != nil || maxValidators == 0 {
+				return genesisOverrides{}, errors.Errorf("invalid %s %q, positive integer is expected", param, value)
+			}
+			networkConfig.StakingConfig.MaxValidators = int(maxValidators)
+		case genesisParamInflation:
+			dec, err := sdk.NewDecFromStr(value)
+			if err != nil || dec.IsNegative() || dec.GT(sdk.OneDec()) {
+				return genesisOverrides{}, errors.Errorf("invalid %s %q, decimal between 0 and 1 is expected, e.g. 0.1",
+					param, value)
+			}
+			result.Inflation = &dec
+		case genesisParamCommission:
+			// Rates are separated by colons, because commas separate the params.
+			for _, rateStr := range strings.Split(value, ":") {
+				rate, err := sdk.NewDecFromStr(rateStr)
+				if err != nil || rate.IsNegative() || rate.GT(sdk.OneDec()) {
+					return genesisOverrides{}, errors.Errorf(
+						"invalid %s %q, colon-separated decimals between 0 and 1 are expected, e.g. 0.05:0.1:0.5",
+						param, value)
+				}
+				result.CommissionRates = append(result.CommissionRates, rate)
+			}
+		default:
+			return genesisOverrides{}, errors.Errorf("unknown genesis param %q, available params: %s", param,
+				strings.Join(GenesisParams(), ", "))
+		}
+	}
+	return result, nil
+}
+
+func parsePositiveDuration(param, value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, errors.Errorf("invalid %s %q, positive duration is expected, e.g. 20s", param, value)
+	}
+	return d, nil
+}
@@ -0,0 +1,165 @@
+package pingpub
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum/pkg/config/constant"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+)
+
+const (
+	// AppType is the type of ping.pub application.
+	AppType infra.AppType = "pingpub"
+
+	// DefaultPort is the default port ping.pub serves the web UI on.
+	DefaultPort = 8098
+
+	// chainsDir is the directory inside container ping.pub loads chain configs from.
+	chainsDir = "/chains"
+
+	// sdkVersion is the version of cosmos SDK used by cored, ping.pub selects the endpoints to query based on it.
+	sdkVersion = "0.45"
+
+	// feeGas is the amount of gas used to compute the minimum fee displayed by the wallet of ping.pub.
+	feeGas = 200_000
+)
+
+// Config stores ping.pub app configuration.
+type Config struct {
+	Name    string
+	HomeDir string
+	AppInfo *infra.AppInfo
+	Port    int
+	Cored   cored.Cored
+}
+
+// New creates new ping.pub app.
+func New(config Config) PingPub {
+	return PingPub{
+		config: config,
+	}
+}
+
+// PingPub represents ping.pub, the lightweight explorer querying cored directly from the browser.
+type PingPub struct {
+	config Config
+}
+
+// Type returns type of application.
+func (p PingPub) Type() infra.AppType {
+	return AppType
+}
+
+// Name returns name of app.
+func (p PingPub) Name() string {
+	return p.config.Name
+}
+
+// Port returns port used by the application.
+func (p PingPub) Port() int {
+	return p.config.Port
+}
+
+// Info returns deployment info.
+func (p PingPub) Info() infra.DeploymentInfo {
+	return p.config.AppInfo.Info()
+}
+
+// Deployment returns deployment of ping.pub.
+func (p PingPub) Deployment() infra.Deployment {
+	return infra.Deployment{
+		Image:     "coreumfoundation/ping-pub:znet-latest",
+		RunAsUser: true,
+		Name:      p.Name(),
+		Info:      p.config.AppInfo,
+		EnvVarsFunc: func() []infra.EnvVar {
+			return []infra.EnvVar{
+				{
+					Name:  "PORT",
+					Value: strconv.Itoa(p.config.Port),
+				},
+			}
+		},
+		Volumes: []infra.Volume{
+			{
+				Source:      p.config.HomeDir,
+				Destination: chainsDir,
+			},
+		},
+		Ports: map[string]int{
+			"web": p.config.Port,
+		},
+		Requires: infra.Prerequisites{
+			Timeout: 20 * time.Second,
+			Dependencies: []infra.HealthCheckCapable{
+				p.config.Cored,
+			},
+		},
+		PrepareFunc: p.saveChainConfig,
+	}
+}
+
+type assetConfig struct {
+	Base        string `json:"base"`
+	Symbol      string `json:"symbol"`
+	Exponent    string `json:"exponent"`
+	CoingeckoID string `json:"coingecko_id"`
+	Logo        string `json:"logo"`
+}
+
+type chainConfig struct {
+	ChainName  string        `json:"chain_name"`
+	API        []string      `json:"api"`
+	RPC        []string      `json:"rpc"`
+	SDKVersion string        `json:"sdk_version"`
+	CoinType   string        `json:"coin_type"`
+	MinTxFee   string        `json:"min_tx_fee"`
+	AddrPrefix string        `json:"addr_prefix"`
+	Logo       string        `json:"logo"`
+	Assets     []assetConfig `json:"assets"`
+}
+
+// saveChainConfig generates the config of the local chain. Ping.pub runs in the browser, so endpoints of cored must be
+// reachable from the host.
+func (p PingPub) saveChainConfig(_ context.Context) error {
+	coredConfig := p.config.Cored.Config()
+	network := coredConfig.Network
+	host := p.config.Cored.Info().HostFromHost
+	denom := network.Denom()
+
+	minTxFee := network.FeeModel().Params().InitialGasPrice.MulInt64(feeGas).Ceil().TruncateInt()
+	cfg := chainConfig{
+		ChainName:  string(network.ChainID()),
+		API:        []string{infra.JoinNetAddr("http", host, coredConfig.Ports.API)},
+		RPC:        []string{infra.JoinNetAddr("http", host, coredConfig.Ports.RPC)},
+		SDKVersion: sdkVersion,
+		CoinType:   strconv.FormatUint(uint64(constant.CoinType), 10),
+		MinTxFee:   minTxFee.String(),
+		AddrPrefix: network.AddressPrefix(),
+		Assets: []assetConfig{
+			{
+				Base:     denom,
+				Symbol:   strings.ToUpper(strings.TrimPrefix(denom, "u")),
+				Exponent: "6",
+			},
+		},
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.WriteFile(filepath.Join(p.config.HomeDir, string(network.ChainID())+".json"), data, 0o600); err != nil {
+		return errors.Wrap(err, "writing ping.pub chain config failed")
+	}
+	return nil
+}
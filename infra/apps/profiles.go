@@ -1,109 +1,210 @@
 package apps
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
 
 	"github.com/CoreumFoundation/crust/infra"
 	"github.com/CoreumFoundation/crust/infra/apps/cored"
+	"github.com/CoreumFoundation/crust/infra/apps/faucet"
+	"github.com/CoreumFoundation/crust/infra/apps/nginx"
 )
 
+// Profile is the name of the set of applications to deploy.
+type Profile string
+
+// Available profiles.
 const (
-	profile1Cored           = "1cored"
-	profile3Cored           = "3cored"
-	profile5Cored           = "5cored"
-	profileIBC              = "ibc"
-	profileFaucet           = "faucet"
-	profileExplorer         = "explorer"
-	profileMonitoring       = "monitoring"
-	profileIntegrationTests = "integration-tests"
+	Profile1Cored           Profile = "1cored"
+	Profile3Cored           Profile = "3cored"
+	Profile5Cored           Profile = "5cored"
+	ProfileIBC              Profile = "ibc"
+	ProfileIBCMultiHop      Profile = "ibc-multihop"
+	ProfileOsmosis          Profile = "osmosis"
+	ProfileICA              Profile = "ica"
+	ProfileFaucet           Profile = "faucet"
+	ProfileFaucetUI         Profile = "faucet-ui"
+	ProfileExplorer         Profile = "explorer"
+	ProfilePingPub          Profile = "pingpub"
+	ProfileIndexer          Profile = "indexer"
+	ProfileProxy            Profile = "proxy"
+	ProfileMockServer       Profile = "mockserver"
+	ProfileMonitoring       Profile = "monitoring"
+	ProfileStateSync        Profile = "statesync"
+	ProfileArchive          Profile = "archive"
+	ProfileLoadBalancer     Profile = "loadbalancer"
+	ProfileInvariants       Profile = "invariants"
+	ProfileUnixSockets      Profile = "unix-sockets"
+	ProfileIntegrationTests Profile = "integration-tests"
 )
 
-var profiles = []string{
-	profile1Cored,
-	profile3Cored,
-	profile5Cored,
-	profileIBC,
-	profileFaucet,
-	profileExplorer,
-	profileMonitoring,
-	profileIntegrationTests,
+var profiles = []Profile{
+	Profile1Cored,
+	Profile3Cored,
+	Profile5Cored,
+	ProfileIBC,
+	ProfileIBCMultiHop,
+	ProfileOsmosis,
+	ProfileICA,
+	ProfileFaucet,
+	ProfileFaucetUI,
+	ProfileExplorer,
+	ProfilePingPub,
+	ProfileIndexer,
+	ProfileProxy,
+	ProfileMockServer,
+	ProfileMonitoring,
+	ProfileStateSync,
+	ProfileArchive,
+	ProfileLoadBalancer,
+	ProfileInvariants,
+	ProfileUnixSockets,
+	ProfileIntegrationTests,
 }
 
+// coredProfiles are the profiles defining the number of cored validators, only one of them may be used.
+var coredProfiles = []Profile{Profile1Cored, Profile3Cored, Profile5Cored}
+
 var (
-	defaultProfiles          = []string{profile1Cored}
-	integrationTestsProfiles = []string{profileIntegrationTests}
+	defaultProfiles          = []Profile{Profile1Cored}
+	integrationTestsProfiles = []Profile{ProfileIntegrationTests}
 )
 
-var availableProfiles = func() map[string]struct{} {
-	v := map[string]struct{}{}
-	for _, p := range profiles {
-		v[p] = struct{}{}
-	}
-	return v
-}()
-
 // Profiles returns the list of available profiles.
 func Profiles() []string {
-	return profiles
+	return profileStrings(profiles)
 }
 
 // DefaultProfiles returns the list of default profiles started if user didn't provide anything else.
 func DefaultProfiles() []string {
-	return defaultProfiles
+	return profileStrings(defaultProfiles)
 }
 
 // IntegrationTestsProfiles returns the list of profiles started for integration tests.
 func IntegrationTestsProfiles() []string {
-	return integrationTestsProfiles
+	return profileStrings(integrationTestsProfiles)
 }
 
-// BuildAppSet builds the application set to deploy based on provided profiles.
-func BuildAppSet(appF *Factory, profiles []string, coredVersion string) (infra.AppSet, error) {
-	pMap := map[string]bool{}
-	coredProfilePresent := false
-	for _, p := range profiles {
-		if _, ok := availableProfiles[p]; !ok {
-			return nil, errors.Errorf("profile %s does not exist", p)
+// ValidateProfiles verifies that all the profiles exist and that they may be used together.
+func ValidateProfiles(profileNames []string) error {
+	_, err := parseProfiles(profileNames)
+	return err
+}
+
+// WithValidators replaces the cored profile in the list with the one running the requested number of validators.
+func WithValidators(profileNames []string, validators int) ([]string, error) {
+	profile := Profile(fmt.Sprintf("%dcored", validators))
+	if !lo.Contains(coredProfiles, profile) {
+		return nil, errors.Errorf("unsupported number of validators %d, available profiles: %s", validators,
+			strings.Join(profileStrings(coredProfiles), ", "))
+	}
+	result := []string{string(profile)}
+	for _, name := range profileNames {
+		if !lo.Contains(coredProfiles, Profile(name)) {
+			result = append(result, name)
 		}
-		if p == profile1Cored || p == profile3Cored || p == profile5Cored {
-			if coredProfilePresent {
-				return nil, errors.Errorf("profiles 1cored, 3cored and 5cored are mutually exclusive")
-			}
-			coredProfilePresent = true
+	}
+	return result, nil
+}
+
+func parseProfiles(profileNames []string) (map[Profile]bool, error) {
+	pMap := map[Profile]bool{}
+	for _, name := range profileNames {
+		p := Profile(name)
+		if !lo.Contains(profiles, p) {
+			return nil, errors.Errorf("profile %q does not exist, available profiles: %s", name,
+				strings.Join(Profiles(), ", "))
 		}
 		pMap[p] = true
 	}
 
-	if pMap[profileIntegrationTests] {
-		if pMap[profile1Cored] {
-			return nil, errors.Errorf("profile 1cored can't be used together with integration-tests as it requires 3cored or 5cored")
-		}
-		if !pMap[profile5Cored] {
-			pMap[profile3Cored] = true
+	if len(pMap) == 0 {
+		return nil, errors.Errorf("no profile selected, available profiles: %s", strings.Join(Profiles(), ", "))
+	}
+	if selected := lo.Filter(coredProfiles, func(p Profile, _ int) bool { return pMap[p] }); len(selected) > 1 {
+		return nil, errors.Errorf("profiles %s are mutually exclusive, selected: %s",
+			strings.Join(profileStrings(coredProfiles), ", "), strings.Join(profileStrings(selected), ", "))
+	}
+	if pMap[ProfileIntegrationTests] && pMap[Profile1Cored] {
+		return nil, errors.Errorf("profile %s can't be used together with %s as it requires %s or %s",
+			Profile1Cored, ProfileIntegrationTests, Profile3Cored, Profile5Cored)
+	}
+	return pMap, nil
+}
+
+// FaucetTransferAmount returns the amount of tokens transferred by the faucet on each request. If transferAmount is 0,
+// the default one for the profiles is returned.
+func FaucetTransferAmount(profileNames []string, transferAmount int64) int64 {
+	return faucetTransferAmount(lo.SliceToMap(profileNames, func(name string) (Profile, bool) {
+		return Profile(name), true
+	}), transferAmount)
+}
+
+func faucetTransferAmount(pMap map[Profile]bool, transferAmount int64) int64 {
+	switch {
+	case transferAmount != 0:
+		return transferAmount
+	case pMap[ProfileIntegrationTests]:
+		// Test groups fund their accounts using the faucet, so it must transfer more tokens.
+		return faucet.IntegrationTestsTransferAmount
+	default:
+		return faucet.DefaultTransferAmount
+	}
+}
+
+func profileStrings(profiles []Profile) []string {
+	return lo.Map(profiles, func(p Profile, _ int) string {
+		return string(p)
+	})
+}
+
+// BuildAppSet builds the application set to deploy based on provided profiles.
+func BuildAppSet(appF *Factory, profiles []string, coredVersion string) (infra.AppSet, error) {
+	pMap, err := parseProfiles(profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	if pMap[ProfileIntegrationTests] {
+		if !pMap[Profile5Cored] {
+			pMap[Profile3Cored] = true
 		}
-		// pMap[profileIBC] = true
-		pMap[profileFaucet] = true
+		// pMap[ProfileIBC] = true
+		pMap[ProfileFaucet] = true
+	}
+
+	if pMap[ProfileFaucetUI] {
+		pMap[ProfileFaucet] = true
+	}
+
+	if pMap[ProfileIBCMultiHop] || pMap[ProfileICA] {
+		pMap[ProfileIBC] = true
 	}
 
-	if (pMap[profileIBC] || pMap[profileFaucet] || pMap[profileExplorer] || pMap[profileMonitoring]) && !pMap[profile3Cored] && !pMap[profile5Cored] {
-		pMap[profile1Cored] = true
+	if (pMap[ProfileIBC] || pMap[ProfileOsmosis] || pMap[ProfileFaucet] || pMap[ProfileExplorer] || pMap[ProfilePingPub] || pMap[ProfileIndexer] || pMap[ProfileProxy] || pMap[ProfileMockServer] || pMap[ProfileMonitoring] || pMap[ProfileStateSync] ||
+		pMap[ProfileArchive] || pMap[ProfileLoadBalancer] || pMap[ProfileInvariants] || pMap[ProfileUnixSockets]) && !pMap[Profile3Cored] && !pMap[Profile5Cored] {
+		pMap[Profile1Cored] = true
 	}
 
 	var numOfCoredValidators int
 	switch {
-	case pMap[profile1Cored]:
+	case pMap[Profile1Cored]:
 		numOfCoredValidators = 1
-	case pMap[profile3Cored]:
+	case pMap[Profile3Cored]:
 		numOfCoredValidators = 3
-	case pMap[profile5Cored]:
+	case pMap[Profile5Cored]:
 		numOfCoredValidators = 5
 	}
 
 	var coredApp cored.Cored
 	var appSet infra.AppSet
 
-	var err error
-	coredApp, coredNodes, err := appF.CoredNetwork("cored", cored.DefaultPorts, numOfCoredValidators, 0, coredVersion)
+	coredPorts := infra.ShiftPorts(cored.DefaultPorts, appF.spec.PortOffset)
+	coredApp, coredNodes, err := appF.CoredNetwork("cored", coredPorts, numOfCoredValidators,
+		appF.config.CoredSentries, coredVersion)
 	if err != nil {
 		return nil, err
 	}
@@ -111,20 +212,79 @@ func BuildAppSet(appF *Factory, profiles []string, coredVersion string) (infra.A
 		appSet = append(appSet, coredNode)
 	}
 
-	if pMap[profileIBC] {
-		appSet = append(appSet, appF.IBC("ibc", coredApp)...)
+	if pMap[ProfileStateSync] {
+		for _, coredNode := range appF.CoredStateSync("cored", coredPorts, coredNodes[0]) {
+			appSet = append(appSet, coredNode)
+		}
 	}
 
-	if pMap[profileFaucet] {
-		appSet = append(appSet, appF.Faucet("faucet", coredApp))
+	if pMap[ProfileArchive] {
+		appSet = append(appSet, appF.CoredArchive("cored", coredPorts, coredNodes[0]))
+	}
+
+	if pMap[ProfileLoadBalancer] {
+		// Like in production, validators are not exposed if they are hidden behind sentries.
+		lbNodes := lo.Filter(coredNodes, func(node cored.Cored, _ int) bool {
+			return !node.Config().HasSentries
+		})
+		appSet = append(appSet, appF.LoadBalancer("loadbalancer", lbNodes))
+	}
+
+	if pMap[ProfileInvariants] {
+		appSet = append(appSet, appF.Invariants("invariants", coredNodes))
+	}
+
+	if pMap[ProfileUnixSockets] {
+		appSet = append(appSet, appF.Sockets("sockets", coredNodes))
+	}
+
+	if pMap[ProfileIBC] {
+		appSet = append(appSet, appF.IBC("ibc", coredApp, pMap[ProfileIBCMultiHop], pMap[ProfileICA])...)
+	} else if pMap[ProfileOsmosis] {
+		appSet = append(appSet, appF.Osmosis("ibc", coredApp)...)
+	}
+
+	// Apps exposed by the proxy, if they are deployed.
+	var proxyFaucet, proxyHasura, proxyUI *nginx.Upstream
+
+	if pMap[ProfileFaucet] {
+		faucetApp := appF.Faucet("faucet", coredApp,
+			faucetTransferAmount(pMap, appF.config.FaucetTransferAmount))
+		appSet = append(appSet, faucetApp)
+		proxyFaucet = &nginx.Upstream{App: faucetApp, Port: faucetApp.Port()}
+		if pMap[ProfileFaucetUI] {
+			appSet = append(appSet, appF.FaucetUI("faucet-ui", faucetApp))
+		}
 	}
 
 	explorerApp := appF.BlockExplorer("explorer", coredApp)
-	if pMap[profileExplorer] {
+	if pMap[ProfileExplorer] {
 		appSet = append(appSet, explorerApp.ToAppSet()...)
+		proxyHasura = &nginx.Upstream{App: explorerApp.Hasura, Port: explorerApp.Hasura.Port()}
+		proxyUI = &nginx.Upstream{App: explorerApp.BigDipper, Port: explorerApp.BigDipper.ContainerPort()}
+	}
+
+	if pMap[ProfilePingPub] {
+		pingPubApp := appF.PingPub("pingpub", coredApp)
+		appSet = append(appSet, pingPubApp)
+		if proxyUI == nil {
+			proxyUI = &nginx.Upstream{App: pingPubApp, Port: pingPubApp.Port()}
+		}
+	}
+
+	if pMap[ProfileProxy] {
+		appSet = append(appSet, appF.Proxy("proxy", coredApp, proxyFaucet, proxyHasura, proxyUI))
+	}
+
+	if pMap[ProfileIndexer] {
+		appSet = append(appSet, appF.Indexer("indexer", coredApp)...)
+	}
+
+	if pMap[ProfileMockServer] {
+		appSet = append(appSet, appF.MockServer("mockserver"))
 	}
 
-	if pMap[profileMonitoring] {
+	if pMap[ProfileMonitoring] {
 		appSet = append(appSet, appF.Monitoring("monitoring", coredNodes, explorerApp.BDJuno)...)
 	}
 
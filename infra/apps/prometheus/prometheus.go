@@ -101,7 +101,7 @@ func (p Prometheus) Info() infra.DeploymentInfo {
 
 // DataSourcePort returns the data source port of the Prometheus.
 func (p Prometheus) DataSourcePort() int {
-	return DefaultPort
+	return p.config.Port
 }
 
 // Deployment returns deployment of prometheus.
@@ -150,7 +150,7 @@ func (p Prometheus) Deployment() infra.Deployment {
 	}
 }
 
-func (p Prometheus) saveConfigFile() error {
+func (p Prometheus) saveConfigFile(ctx context.Context) error {
 	type nodesConfigArgs struct {
 		Host string
 		Port int
@@ -0,0 +1,67 @@
+package relayercosmos
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/must"
+)
+
+// maxMemoLength is the maximum length of the memo accepted by default by cosmos chains.
+const maxMemoLength = 256
+
+var channelRegexp = regexp.MustCompile(`^channel-[0-9]+$`)
+
+// ValidateGasPriceMultiplier verifies that gas price multiplier is valid, 0 means gas prices are not changed.
+func ValidateGasPriceMultiplier(multiplier float64) error {
+	if multiplier < 0 {
+		return errors.Errorf("invalid relayer gas price multiplier %v, positive number is expected", multiplier)
+	}
+	return nil
+}
+
+// ValidateMemo verifies that memo may be attached to transactions broadcast by the relayer.
+func ValidateMemo(memo string) error {
+	if len(memo) > maxMemoLength {
+		return errors.Errorf("relayer memo is too long, maximum length is %d", maxMemoLength)
+	}
+	if strings.IndexFunc(memo, func(r rune) bool { return !unicode.IsPrint(r) }) >= 0 {
+		return errors.New("relayer memo contains non-printable characters")
+	}
+	return nil
+}
+
+// ParseChannels parses channels, in the form of <relayer>=<channel>, relayers relay packets from.
+func ParseChannels(defs []string) (map[string][]string, error) {
+	result := map[string][]string{}
+	for _, def := range defs {
+		relayer, channel, ok := strings.Cut(def, "=")
+		if !ok || relayer == "" {
+			return nil, errors.Errorf("invalid relayer channel %q, expected <relayer>=<channel>, "+
+				"e.g. ibc-relayer-gaia=channel-0", def)
+		}
+		if !channelRegexp.MatchString(channel) {
+			return nil, errors.Errorf("invalid channel %q, expected channel-<sequence>, e.g. channel-0", channel)
+		}
+		result[relayer] = append(result[relayer], channel)
+	}
+	return result, nil
+}
+
+// multiplyGasPrices multiplies amounts of gas prices, formatted as decimal coins, e.g. 0.01stake.
+func multiplyGasPrices(gasPrices string, multiplier float64) string {
+	if multiplier == 0 || multiplier == 1 {
+		return gasPrices
+	}
+	// Gas prices are defined by the app types, so they are always valid.
+	prices, err := sdk.ParseDecCoins(gasPrices)
+	must.OK(err)
+	factor, err := sdk.NewDecFromStr(strconv.FormatFloat(multiplier, 'f', -1, 64))
+	must.OK(err)
+	return prices.MulDec(factor).String()
+}
@@ -0,0 +1,113 @@
+package relayercosmos
+
+import (
+	"context"
+	"time"
+
+	transfertypes "github.com/cosmos/ibc-go/v4/modules/apps/transfer/types"
+	connectiontypes "github.com/cosmos/ibc-go/v4/modules/core/03-connection/types"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+	ibctmtypes "github.com/cosmos/ibc-go/v4/modules/light-clients/07-tendermint/types"
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum/pkg/client"
+	"github.com/CoreumFoundation/crust/infra"
+)
+
+// tendermintClientStateTypeURL is the type URL of the client state of tendermint light client.
+const tendermintClientStateTypeURL = "/ibc.lightclients.tendermint.v1.ClientState"
+
+// IBCPath returns identifiers of the client, connection and transfer channel established by the relayer. They are
+// queried from the source chain, the most recent open channel to the peer chain is taken, because stale ones remain
+// there if the peer chain has been recreated.
+func (r Relayer) IBCPath(ctx context.Context) (infra.IBCPath, error) {
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+
+	source, peer := r.chains()
+	clientCtx := source.clientContext()
+
+	connections, err := connectiontypes.NewQueryClient(clientCtx).Connections(ctx,
+		&connectiontypes.QueryConnectionsRequest{})
+	if err != nil {
+		return infra.IBCPath{}, errors.Wrapf(err, "querying connections of %s failed", source.ChainID)
+	}
+
+	var path *infra.IBCPath
+	var pathSequence uint64
+	for _, connection := range connections.Connections {
+		if connection.State != connectiontypes.OPEN {
+			continue
+		}
+		chainID, err := counterpartyChainID(ctx, clientCtx, connection.Id)
+		if err != nil {
+			return infra.IBCPath{}, err
+		}
+		if chainID != peer.ChainID {
+			continue
+		}
+
+		channels, err := channeltypes.NewQueryClient(clientCtx).ConnectionChannels(ctx,
+			&channeltypes.QueryConnectionChannelsRequest{Connection: connection.Id})
+		if err != nil {
+			return infra.IBCPath{}, errors.Wrapf(err, "querying channels of connection %s failed", connection.Id)
+		}
+		for _, channel := range channels.Channels {
+			if channel.State != channeltypes.OPEN || channel.PortId != transfertypes.PortID {
+				continue
+			}
+			// Sequences of channels are increasing for the whole chain, IDs can't be compared because they are strings.
+			sequence, err := channeltypes.ParseChannelSequence(channel.ChannelId)
+			if err != nil {
+				return infra.IBCPath{}, errors.WithStack(err)
+			}
+			if path != nil && sequence < pathSequence {
+				continue
+			}
+			pathSequence = sequence
+			path = &infra.IBCPath{
+				Source: infra.IBCPathEnd{
+					ChainID:      source.ChainID,
+					ClientID:     connection.ClientId,
+					ConnectionID: connection.Id,
+					PortID:       channel.PortId,
+					ChannelID:    channel.ChannelId,
+				},
+				Destination: infra.IBCPathEnd{
+					ChainID:      peer.ChainID,
+					ClientID:     connection.Counterparty.ClientId,
+					ConnectionID: connection.Counterparty.ConnectionId,
+					PortID:       channel.Counterparty.PortId,
+					ChannelID:    channel.Counterparty.ChannelId,
+				},
+			}
+		}
+	}
+	if path == nil {
+		return infra.IBCPath{}, errors.Errorf("no open transfer channel from %s to %s", source.ChainID, peer.ChainID)
+	}
+	return *path, nil
+}
+
+// counterpartyChainID returns the ID of the chain tracked by the client of the connection.
+func counterpartyChainID(ctx context.Context, clientCtx client.Context, connectionID string) (string, error) {
+	res, err := connectiontypes.NewQueryClient(clientCtx).ConnectionClientState(ctx,
+		&connectiontypes.QueryConnectionClientStateRequest{ConnectionId: connectionID})
+	if err != nil {
+		return "", errors.Wrapf(err, "querying client state of connection %s failed", connectionID)
+	}
+	if res.IdentifiedClientState == nil || res.IdentifiedClientState.ClientState == nil {
+		return "", errors.Errorf("no client state returned for connection %s", connectionID)
+	}
+
+	// Clients of other types, e.g. the solo machine one used by `ibc mock`, don't track chains connected
+	// by the relayer.
+	if res.IdentifiedClientState.ClientState.TypeUrl != tendermintClientStateTypeURL {
+		return "", nil
+	}
+	var clientState ibctmtypes.ClientState
+	if err := clientState.Unmarshal(res.IdentifiedClientState.ClientState.Value); err != nil {
+		return "", errors.Wrapf(err, "decoding client state of connection %s failed", connectionID)
+	}
+	return clientState.ChainId, nil
+}
@@ -4,19 +4,24 @@ import (
 	"bytes"
 	"context"
 	_ "embed"
+	"encoding/json"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"text/template"
 	"time"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/pkg/errors"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/expfmt"
 
 	"github.com/CoreumFoundation/coreum-tools/pkg/must"
 	"github.com/CoreumFoundation/coreum-tools/pkg/retry"
+	"github.com/CoreumFoundation/coreum/pkg/client"
 	coreumconstant "github.com/CoreumFoundation/coreum/pkg/config/constant"
 	"github.com/CoreumFoundation/crust/infra"
 	"github.com/CoreumFoundation/crust/infra/apps/cored"
@@ -42,16 +47,50 @@ const (
 	DefaultDebugPort = 7597
 
 	dockerEntrypoint = "run.sh"
+
+	// fingerprintFile is the file storing identity of the chains relayer was initialized for.
+	fingerprintFile = "chains.fingerprint"
+
+	// maxHeightLag is the number of blocks the relayer may lag behind the chain before it is considered wedged.
+	maxHeightLag = 50
+
+	// watchdogInterval is the interval between checks of the watchdog running next to the relayer in the container.
+	watchdogInterval = 15 * time.Second
+
+	// watchdogChecks is the number of consecutive checks relayer must lag in before watchdog restarts it.
+	watchdogChecks = 4
 )
 
 // Config stores relayer app config.
 type Config struct {
-	Name        string
-	HomeDir     string
-	AppInfo     *infra.AppInfo
-	DebugPort   int
-	Cored       cored.Cored
+	Name      string
+	HomeDir   string
+	AppInfo   *infra.AppInfo
+	DebugPort int
+	Cored     cored.Cored
+	// SourceChain is connected to the peered chain instead of cored if it is set
+	SourceChain *cosmoschain.BaseApp
 	PeeredChain cosmoschain.BaseApp
+	// GasPriceMultiplier multiplies gas prices paid on both chains, 0 means they are not changed
+	GasPriceMultiplier float64
+	// Memo is attached to transactions broadcast by the relayer
+	Memo string
+	// Channels of the source chain packets are relayed from, packets from all the channels are relayed if it is empty
+	Channels []string
+}
+
+// chain describes the chain connected by the relayer.
+type chain struct {
+	App             infra.HealthCheckCapable
+	Name            string
+	ChainID         string
+	RPCURL          string
+	AccountPrefix   string
+	RelayerMnemonic string
+	CoinType        uint32
+	GasPrices       string
+
+	clientContext func() client.Context
 }
 
 // New creates new relayer app.
@@ -81,6 +120,11 @@ func (r Relayer) Info() infra.DeploymentInfo {
 	return r.config.AppInfo.Info()
 }
 
+// DebugPort returns port relayer serves debug and metric requests on.
+func (r Relayer) DebugPort() int {
+	return r.config.DebugPort
+}
+
 // HealthCheck checks if relayer is operating.
 func (r Relayer) HealthCheck(ctx context.Context) error {
 	const cosmosHeightMetricName = "cosmos_relayer_chain_latest_height"
@@ -111,37 +155,46 @@ func (r Relayer) HealthCheck(ctx context.Context) error {
 		return retry.Retryable(errors.Errorf("health check failed, no %q metric in the response", cosmosHeightMetricName))
 	}
 
-	chainIDs := map[string]struct{}{
-		r.config.PeeredChain.AppConfig().ChainID:          {},
-		string(r.config.Cored.Config().Network.ChainID()): {},
+	source, peer := r.chains()
+	for _, c := range []chain{source, peer} {
+		relayerHeight := latestHeight(cosmosHeightMF, c.ChainID)
+		if relayerHeight == 0 {
+			return retry.Retryable(errors.Errorf("relayer is still syncing chain %s", c.ChainID))
+		}
+
+		status, err := c.clientContext().RPCClient().Status(ctx)
+		if err != nil {
+			return retry.Retryable(errors.Wrapf(err, "querying status of chain %s failed", c.ChainID))
+		}
+		// Relayer is wedged if it doesn't follow new blocks, even though it still serves metrics.
+		chainHeight := status.SyncInfo.LatestBlockHeight
+		if chainHeight-relayerHeight > maxHeightLag {
+			return retry.Retryable(errors.Errorf("relayer lags behind chain %s, chain height: %d, relayer height: %d",
+				c.ChainID, chainHeight, relayerHeight))
+		}
 	}
 
-	for _, metricItem := range cosmosHeightMF.Metric {
+	return nil
+}
+
+// latestHeight returns the latest height of the chain seen by the relayer, 0 if it isn't reported.
+func latestHeight(heightMF *dto.MetricFamily, chainID string) int64 {
+	for _, metricItem := range heightMF.Metric {
+		if metricItem.Gauge == nil || metricItem.Gauge.Value == nil {
+			continue
+		}
 		for _, label := range metricItem.Label {
-			if label.Value == nil {
-				continue
-			}
-			if _, found := chainIDs[*label.Value]; found {
-				metricGauge := metricItem.Gauge
-				if metricGauge.Value == nil {
-					continue
-				}
-				if *metricGauge.Value > 0 {
-					delete(chainIDs, *label.Value)
-				}
+			if label.Value != nil && *label.Value == chainID {
+				return int64(*metricItem.Gauge.Value)
 			}
 		}
 	}
-
-	if len(chainIDs) != 0 {
-		return errors.Wrapf(err, "the relayer chains %v are still syncing", chainIDs)
-	}
-
-	return nil
+	return 0
 }
 
 // Deployment returns deployment of relayer.
 func (r Relayer) Deployment() infra.Deployment {
+	source, peer := r.chains()
 	return infra.Deployment{
 		RunAsUser: true,
 		Image:     "relayer:znet",
@@ -159,16 +212,18 @@ func (r Relayer) Deployment() infra.Deployment {
 		Requires: infra.Prerequisites{
 			Timeout: 20 * time.Second,
 			Dependencies: []infra.HealthCheckCapable{
-				r.config.Cored,
-				r.config.PeeredChain,
+				source.App,
+				peer.App,
 			},
 		},
 		PrepareFunc: r.prepare,
 		Entrypoint:  filepath.Join(targets.AppHomeDir, dockerEntrypoint),
+		// It crashes if connection to the chain is lost, e.g. when node is restarted.
+		RestartPolicy: infra.RestartPolicyOnFailure,
 	}
 }
 
-func (r Relayer) prepare() error {
+func (r Relayer) prepare(ctx context.Context) error {
 	if err := r.saveConfigFile(); err != nil {
 		return err
 	}
@@ -177,22 +232,16 @@ func (r Relayer) prepare() error {
 }
 
 func (r Relayer) saveConfigFile() error {
+	source, peer := r.chains()
 	configArgs := struct {
-		CoreumChanID        string
-		CoreumRPCUrl        string
-		CoreumAccountPrefix string
-
-		PeerChanID        string
-		PeerRPCUrl        string
-		PeerAccountPrefix string
+		Memo   string
+		Source chain
+		Peer   chain
 	}{
-		CoreumChanID:        string(r.config.Cored.Config().Network.ChainID()),
-		CoreumRPCUrl:        infra.JoinNetAddr("http", r.config.Cored.Info().HostFromContainer, r.config.Cored.Config().Ports.RPC),
-		CoreumAccountPrefix: r.config.Cored.Config().Network.AddressPrefix(),
-
-		PeerChanID:        r.config.PeeredChain.AppConfig().ChainID,
-		PeerRPCUrl:        infra.JoinNetAddr("http", r.config.PeeredChain.Info().HostFromContainer, r.config.PeeredChain.AppConfig().Ports.RPC),
-		PeerAccountPrefix: r.config.PeeredChain.AppTypeConfig().AccountPrefix,
+		// Memo is JSON-encoded, so it is valid YAML string regardless of its content.
+		Memo:   string(must.Bytes(json.Marshal(r.config.Memo))),
+		Source: source,
+		Peer:   peer,
 	}
 
 	buf := &bytes.Buffer{}
@@ -215,28 +264,27 @@ func (r Relayer) saveConfigFile() error {
 }
 
 func (r Relayer) saveRunScriptFile() error {
+	source, peer := r.chains()
 	scriptArgs := struct {
-		HomePath string
-
-		CoreumChanID          string
-		CoreumRelayerMnemonic string
-		CoreumRelayerCoinType uint32
-
-		PeerChanID          string
-		PeerRelayerMnemonic string
-
-		DebugPort int
+		HomePath         string
+		FingerprintFile  string
+		Source           chain
+		Peer             chain
+		DebugPort        int
+		MaxHeightLag     int
+		WatchdogInterval int
+		WatchdogChecks   int
+		Channels         string
 	}{
-		HomePath: targets.AppHomeDir,
-
-		CoreumChanID:          string(r.config.Cored.Config().Network.ChainID()),
-		CoreumRelayerMnemonic: r.config.Cored.Config().RelayerMnemonic,
-		CoreumRelayerCoinType: coreumconstant.CoinType,
-
-		PeerChanID:          r.config.PeeredChain.AppConfig().ChainID,
-		PeerRelayerMnemonic: r.config.PeeredChain.AppConfig().RelayerMnemonic,
-
-		DebugPort: r.config.DebugPort,
+		HomePath:         targets.AppHomeDir,
+		FingerprintFile:  fingerprintFile,
+		Source:           source,
+		Peer:             peer,
+		DebugPort:        r.config.DebugPort,
+		MaxHeightLag:     maxHeightLag,
+		WatchdogInterval: int(watchdogInterval.Seconds()),
+		WatchdogChecks:   watchdogChecks,
+		Channels:         strings.Join(r.config.Channels, ","),
 	}
 
 	buf := &bytes.Buffer{}
@@ -251,3 +299,51 @@ func (r Relayer) saveRunScriptFile() error {
 
 	return nil
 }
+
+// chains returns the chains connected by the relayer. Cored chain is named `coreum` in the config of the relayer,
+// and the other source chain `source`, so paths stored by relayers initialized before are still valid.
+func (r Relayer) chains() (chain, chain) {
+	source, peer := r.baseChains()
+	source.GasPrices = multiplyGasPrices(source.GasPrices, r.config.GasPriceMultiplier)
+	peer.GasPrices = multiplyGasPrices(peer.GasPrices, r.config.GasPriceMultiplier)
+	return source, peer
+}
+
+func (r Relayer) baseChains() (chain, chain) {
+	peer := cosmosChain("peer", r.config.PeeredChain)
+	if r.config.SourceChain != nil {
+		return cosmosChain("source", *r.config.SourceChain), peer
+	}
+
+	coredConfig := r.config.Cored.Config()
+	return chain{
+		App:             r.config.Cored,
+		Name:            "coreum",
+		ChainID:         string(coredConfig.Network.ChainID()),
+		RPCURL:          infra.JoinNetAddr("http", r.config.Cored.Info().HostFromContainer, coredConfig.Ports.RPC),
+		AccountPrefix:   coredConfig.Network.AddressPrefix(),
+		RelayerMnemonic: coredConfig.RelayerMnemonic,
+		CoinType:        coreumconstant.CoinType,
+		GasPrices:       "0.0625" + coredConfig.Network.Denom(), // initial gas price
+		clientContext:   r.config.Cored.ClientContext,
+	}, peer
+}
+
+func cosmosChain(name string, app cosmoschain.BaseApp) chain {
+	return chain{
+		App:             app,
+		Name:            name,
+		ChainID:         app.AppConfig().ChainID,
+		RPCURL:          infra.JoinNetAddr("http", app.Info().HostFromContainer, app.AppConfig().Ports.RPC),
+		AccountPrefix:   app.AppTypeConfig().AccountPrefix,
+		RelayerMnemonic: app.AppConfig().RelayerMnemonic,
+		CoinType:        sdk.CoinType,
+		GasPrices:       app.AppTypeConfig().GasPrices,
+		clientContext:   app.ClientContext,
+	}
+}
+
+// ResetPaths marks relayer paths stored in home directory as stale, so they are regenerated on next start of the relayer.
+func ResetPaths(homeDir string) error {
+	return errors.WithStack(os.WriteFile(filepath.Join(homeDir, fingerprintFile), []byte("reset\n"), 0o600))
+}
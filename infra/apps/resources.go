@@ -0,0 +1,138 @@
+package apps
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+	"github.com/CoreumFoundation/crust/infra/apps/postgres"
+)
+
+// Resources which may be limited.
+const (
+	resourceCPUShares = "cpu-shares"
+	resourceCPUs      = "cpus"
+	resourceMemory    = "memory"
+	resourcePids      = "pids"
+)
+
+const (
+	mebibyte = 1024 * 1024
+	gibibyte = 1024 * mebibyte
+)
+
+// defaultPidsLimit protects the host against apps leaking processes or threads.
+const defaultPidsLimit = 4096
+
+// ResourceNames returns the list of resources which may be limited.
+func ResourceNames() []string {
+	return []string{resourceCPUShares, resourceCPUs, resourceMemory, resourcePids}
+}
+
+// Resources returns limits of resources available to the apps. Defaults depend on the number of cored validators
+// selected by profiles, so environments don't starve the host, and they are changed by overrides, in the form
+// of <app>.<resource>=<value>, where <app> is the name or the type of the app.
+func Resources(profileNames []string, overrides []string) (infra.AppResources, error) {
+	pMap, err := parseProfiles(profileNames)
+	if err != nil {
+		return nil, err
+	}
+
+	coredResources := infra.Resources{CPUs: 2, Memory: 4 * gibibyte, Pids: defaultPidsLimit}
+	switch {
+	case pMap[Profile5Cored]:
+		coredResources = infra.Resources{CPUs: 1, Memory: 1536 * mebibyte, Pids: defaultPidsLimit}
+	case pMap[Profile3Cored] || pMap[ProfileIntegrationTests]:
+		coredResources = infra.Resources{CPUs: 1.5, Memory: 2 * gibibyte, Pids: defaultPidsLimit}
+	}
+	resources := infra.AppResources{
+		string(cored.AppType):    coredResources,
+		string(postgres.AppType): {CPUs: 1, Memory: gibibyte, Pids: defaultPidsLimit},
+	}
+
+	for _, override := range overrides {
+		if err := applyResourceOverride(resources, override); err != nil {
+			return nil, err
+		}
+	}
+	return resources, nil
+}
+
+// ValidateResources verifies that overrides of resource limits are valid.
+func ValidateResources(overrides []string) error {
+	resources := infra.AppResources{}
+	for _, override := range overrides {
+		if err := applyResourceOverride(resources, override); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyResourceOverride(resources infra.AppResources, override string) error {
+	key, value, ok := strings.Cut(override, "=")
+	app, resource, ok2 := strings.Cut(key, ".")
+	if !ok || !ok2 || app == "" {
+		return errors.Errorf("invalid resource limit %q, expected <app>.<resource>=<value>, e.g. cored.memory=2g",
+			override)
+	}
+
+	r := resources[app]
+	switch resource {
+	case resourceCPUShares:
+		shares, err := strconv.Atoi(value)
+		if err != nil || shares < 0 {
+			return errors.Errorf("invalid %s %q, non-negative integer is expected", resource, value)
+		}
+		r.CPUShares = shares
+	case resourceCPUs:
+		cpus, err := strconv.ParseFloat(value, 64)
+		if err != nil || cpus < 0 {
+			return errors.Errorf("invalid %s %q, non-negative number is expected, e.g. 1.5", resource, value)
+		}
+		r.CPUs = cpus
+	case resourceMemory:
+		memory, err := parseMemory(value)
+		if err != nil {
+			return err
+		}
+		r.Memory = memory
+	case resourcePids:
+		pids, err := strconv.Atoi(value)
+		if err != nil || pids < 0 {
+			return errors.Errorf("invalid %s %q, non-negative integer is expected", resource, value)
+		}
+		r.Pids = pids
+	default:
+		return errors.Errorf("unknown resource %q, available resources: %s", resource,
+			strings.Join(ResourceNames(), ", "))
+	}
+	resources[app] = r
+	return nil
+}
+
+// parseMemory parses amount of memory in bytes, optionally followed by unit: k, m or g, like docker does.
+func parseMemory(value string) (int64, error) {
+	multiplier := int64(1)
+	number := strings.ToLower(value)
+	switch {
+	case strings.HasSuffix(number, "k"):
+		multiplier = 1024
+	case strings.HasSuffix(number, "m"):
+		multiplier = mebibyte
+	case strings.HasSuffix(number, "g"):
+		multiplier = gibibyte
+	}
+	if multiplier != 1 {
+		number = number[:len(number)-1]
+	}
+	amount, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || amount < 0 {
+		return 0, errors.Errorf("invalid memory %q, non-negative number of bytes, optionally followed by unit: "+
+			"k, m or g, is expected, e.g. 512m", value)
+	}
+	return amount * multiplier, nil
+}
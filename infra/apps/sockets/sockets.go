@@ -0,0 +1,138 @@
+package sockets
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/retry"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+)
+
+// AppType is the type of unix socket proxy application.
+const AppType infra.AppType = "sockets"
+
+const socketsDir = "/sockets"
+
+// Config stores unix socket proxy app config.
+type Config struct {
+	Name       string
+	HomeDir    string
+	AppInfo    *infra.AppInfo
+	CoredNodes []cored.Cored
+}
+
+// New creates new unix socket proxy app.
+func New(config Config) Sockets {
+	return Sockets{
+		config: config,
+	}
+}
+
+// Sockets represents the proxy exposing RPC and gRPC of the cored nodes over unix sockets created in its home
+// directory. Connections are forwarded to the nodes inside the docker network, so clients using sockets don't consume
+// TCP ports of the host.
+type Sockets struct {
+	config Config
+}
+
+// Type returns type of application.
+func (s Sockets) Type() infra.AppType {
+	return AppType
+}
+
+// Name returns name of app.
+func (s Sockets) Name() string {
+	return s.config.Name
+}
+
+// Info returns deployment info.
+func (s Sockets) Info() infra.DeploymentInfo {
+	return s.config.AppInfo.Info()
+}
+
+// RPCSocket returns path on the host of the unix socket exposing RPC of the node.
+func (s Sockets) RPCSocket(node cored.Cored) string {
+	return filepath.Join(s.config.HomeDir, rpcSocketName(node))
+}
+
+// GRPCSocket returns path on the host of the unix socket exposing gRPC of the node.
+func (s Sockets) GRPCSocket(node cored.Cored) string {
+	return filepath.Join(s.config.HomeDir, grpcSocketName(node))
+}
+
+// HealthCheck checks if all the sockets accept connections.
+func (s Sockets) HealthCheck(ctx context.Context) error {
+	if s.config.AppInfo.Info().Status != infra.AppStatusRunning {
+		return retry.Retryable(errors.Errorf("unix socket proxy hasn't started yet"))
+	}
+
+	dialer := net.Dialer{}
+	for _, node := range s.config.CoredNodes {
+		for _, socket := range []string{s.RPCSocket(node), s.GRPCSocket(node)} {
+			conn, err := dialer.DialContext(ctx, "unix", socket)
+			if err != nil {
+				return retry.Retryable(errors.WithStack(err))
+			}
+			_ = conn.Close()
+		}
+	}
+	return nil
+}
+
+// Deployment returns deployment of the unix socket proxy.
+func (s Sockets) Deployment() infra.Deployment {
+	return infra.Deployment{
+		Image:      "alpine/socat:1.7.4.4",
+		RunAsUser:  true,
+		Name:       s.Name(),
+		Info:       s.config.AppInfo,
+		Entrypoint: "sh",
+		Volumes: []infra.Volume{
+			{
+				Source:      s.config.HomeDir,
+				Destination: socketsDir,
+			},
+		},
+		ArgsFunc: func() []string {
+			// One socat process is started per socket.
+			commands := make([]string, 0, 2*len(s.config.CoredNodes))
+			for _, node := range s.config.CoredNodes {
+				host := node.Info().HostFromContainer
+				commands = append(commands,
+					socatCommand(rpcSocketName(node), infra.JoinNetAddr("", host, node.Config().Ports.RPC)),
+					socatCommand(grpcSocketName(node), infra.JoinNetAddr("", host, node.Config().Ports.GRPC)),
+				)
+			}
+			return []string{"-c", strings.Join(commands, " & ") + " & wait"}
+		},
+		Requires: infra.Prerequisites{
+			Timeout: 20 * time.Second,
+			Dependencies: func() []infra.HealthCheckCapable {
+				containers := make([]infra.HealthCheckCapable, 0, len(s.config.CoredNodes))
+				for _, node := range s.config.CoredNodes {
+					containers = append(containers, infra.IsRunning(node))
+				}
+				return containers
+			}(),
+		},
+	}
+}
+
+func socatCommand(socket, address string) string {
+	return fmt.Sprintf("socat UNIX-LISTEN:%s,fork,unlink-early TCP:%s", filepath.Join(socketsDir, socket), address)
+}
+
+func rpcSocketName(node cored.Cored) string {
+	return node.Name() + "-rpc.sock"
+}
+
+func grpcSocketName(node cored.Cored) string {
+	return node.Name() + "-grpc.sock"
+}
@@ -11,6 +11,30 @@ type Config struct {
 	// CoredVersion defines the version of the cored to be used on start
 	CoredVersion string
 
+	// GaiaVersion defines the version of gaia to be used on start, empty means the default one
+	GaiaVersion string
+
+	// GenesisDenoms defines additional denoms, in the form of <base>:<display>:<exponent>, created at genesis
+	GenesisDenoms []string
+
+	// GenesisParams defines overrides of governance and staking parameters, in the form of <param>=<value>, applied
+	// to genesis
+	GenesisParams []string
+
+	// GenesisPatch is the path to the file containing JSON merge patch applied to the generated genesis
+	GenesisPatch string
+
+	// CoredSentries is the number of sentry nodes fronting each cored validator, 0 means validators are connected
+	// directly
+	CoredSentries int
+
+	// AccountPoolSize is the number of accounts funded in genesis which may be leased by tests and tools
+	AccountPoolSize int
+
+	// Contracts are the WASM contracts, in the form of <wasm-file>[:<instantiate-msg-file>], deployed when environment
+	// is started for the first time
+	Contracts []string
+
 	// HomeDir is the path where all the files are kept
 	HomeDir string
 
@@ -29,9 +53,82 @@ type Config struct {
 	// TestGroups limits running integration tests on selected repository test group, empty means no filter
 	TestGroups []string
 
+	// TestParallelism is the maximum number of independent test groups executed in parallel
+	TestParallelism int
+
+	// NetworkFile is the path to the file describing external network tests are executed against, empty means tests
+	// are executed against local environment
+	NetworkFile string
+
+	// ReportDir is the directory where JUnit XML and JSON reports of integration tests are stored, empty means
+	// reports are not generated
+	ReportDir string
+
+	// CoverageDir is the directory where coverage of integration tests is stored, empty means coverage is not
+	// collected
+	CoverageDir string
+
+	// RecordTraffic enables recording of gRPC traffic between integration tests and cored
+	RecordTraffic bool
+
+	// RecordBodyLimit is the maximum number of bytes of each request and response body recorded
+	RecordBodyLimit int
+
 	// VerboseLogging turns on verbose logging
 	VerboseLogging bool
 
 	// LogFormat is the format used to encode logs
 	LogFormat string
+
+	// Subnet is the subnet of docker network created for the environment, empty means it is selected automatically
+	Subnet string
+
+	// NetworkName is the name of docker network created for the environment
+	NetworkName string
+
+	// IPv6 enables IPv6 in docker network created for the environment
+	IPv6 bool
+
+	// NetworkAliases maps names of the apps to additional names they are reachable under in docker network
+	NetworkAliases map[string][]string
+
+	// ImagePullPolicy defines when images of the apps are pulled
+	ImagePullPolicy PullPolicy
+
+	// MockServerMappings is the path to the directory containing stub mappings served by mock server
+	MockServerMappings string
+
+	// FaucetTransferAmount is the amount of tokens transferred by faucet on each request
+	FaucetTransferAmount int64
+
+	// FaucetDenom is the denom of tokens transferred by faucet, empty means the default denom of the chain
+	FaucetDenom string
+
+	// FaucetRateLimit is the maximum number of requests served by faucet for each IP address per minute, 0 means
+	// there is no limit
+	FaucetRateLimit int
+
+	// RelayerGasPriceMultiplier multiplies gas prices paid by relayers on all the chains
+	RelayerGasPriceMultiplier float64
+
+	// RelayerMemo is the memo attached to transactions broadcast by relayers
+	RelayerMemo string
+
+	// RelayerChannels maps names of the relayers to the channels of the source chain they relay packets from,
+	// relayers not present in the map relay packets from all the channels
+	RelayerChannels map[string][]string
+
+	// Resources are the limits of resources available to the containers of the apps
+	Resources AppResources
+
+	// AutoPorts enables shifting ports of fresh environment if default ones are in use
+	AutoPorts bool
+
+	// KeyBackup is the file where encrypted keys of cored nodes are stored when environment is created, empty means
+	// keys are not backed up
+	KeyBackup string
+
+	// KeyRestore is the file containing encrypted keys of cored nodes used when environment is created, empty means
+	// random keys are generated
+	KeyRestore string
 }
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"text/template"
 
 	cosmosclient "github.com/cosmos/cosmos-sdk/client"
@@ -51,6 +52,11 @@ type AppConfig struct {
 	AppInfo         *infra.AppInfo
 	Ports           Ports
 	RelayerMnemonic string
+	FaucetMnemonic  string
+
+	// ICAHostAllowMessages are the type URLs of messages interchain accounts hosted by the chain may execute,
+	// interchain accounts host is configured only if the list is not empty.
+	ICAHostAllowMessages []string
 }
 
 // AppTypeConfig defines configuration of the application type.
@@ -59,6 +65,13 @@ type AppTypeConfig struct {
 	DockerImage   string
 	AccountPrefix string
 	ExecName      string
+
+	// GasPrices are the gas prices paid by the relayer for transactions broadcast to the chain.
+	GasPrices string
+
+	// RunScriptTemplate is the template of the script initializing and starting the chain, default one is used
+	// if it is nil.
+	RunScriptTemplate *template.Template
 }
 
 // New creates new gaia app.
@@ -145,12 +158,14 @@ func (ba BaseApp) Deployment() infra.Deployment {
 	}
 }
 
-func (ba BaseApp) prepare() error {
+func (ba BaseApp) prepare(ctx context.Context) error {
 	args := struct {
 		ExecName        string
 		HomePath        string
 		ChainID         string
 		RelayerMnemonic string
+		FaucetMnemonic  string
+		ICAHostMessages string
 		RPCLaddr        string
 		P2PLaddr        string
 		GRPCAddress     string
@@ -161,6 +176,8 @@ func (ba BaseApp) prepare() error {
 		HomePath:        targets.AppHomeDir,
 		ChainID:         ba.appConfig.ChainID,
 		RelayerMnemonic: ba.appConfig.RelayerMnemonic,
+		FaucetMnemonic:  ba.appConfig.FaucetMnemonic,
+		ICAHostMessages: jsonList(ba.appConfig.ICAHostAllowMessages),
 		RPCLaddr:        infra.JoinNetAddrIP("tcp", net.IPv4zero, ba.appConfig.Ports.RPC),
 		P2PLaddr:        infra.JoinNetAddrIP("tcp", net.IPv4zero, ba.appConfig.Ports.P2P),
 		GRPCAddress:     infra.JoinNetAddrIP("", net.IPv4zero, ba.appConfig.Ports.GRPC),
@@ -168,8 +185,13 @@ func (ba BaseApp) prepare() error {
 		RPCPprofLaddr:   infra.JoinNetAddrIP("", net.IPv4zero, ba.appConfig.Ports.PProf),
 	}
 
+	scriptTemplate := runScriptTemplate
+	if ba.appTypeConfig.RunScriptTemplate != nil {
+		scriptTemplate = ba.appTypeConfig.RunScriptTemplate
+	}
+
 	buf := &bytes.Buffer{}
-	if err := runScriptTemplate.Execute(buf, args); err != nil {
+	if err := scriptTemplate.Execute(buf, args); err != nil {
 		return errors.WithStack(err)
 	}
 
@@ -181,6 +203,15 @@ func (ba BaseApp) prepare() error {
 	return nil
 }
 
+// jsonList formats strings as the content of JSON array, to be put into genesis by the run script.
+func jsonList(items []string) string {
+	quoted := make([]string, 0, len(items))
+	for _, item := range items {
+		quoted = append(quoted, `"`+item+`"`)
+	}
+	return strings.Join(quoted, ",")
+}
+
 func newBasicManager() module.BasicManager {
 	return module.NewBasicManager(
 		auth.AppModuleBasic{},
@@ -0,0 +1,248 @@
+package infra
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultEnvFile is the name of the environment file loaded from the current directory if it exists.
+const DefaultEnvFile = "znet.yaml"
+
+// EnvFile is the declarative definition of the environment, shared e.g. by committing it to the repository.
+// Fields correspond to the flags, precedence of the file is resolved by ConfigFactory.Resolve.
+type EnvFile struct {
+	// Env is the name of the environment
+	Env string `yaml:"env"`
+
+	// Profiles is the list of application profiles to deploy
+	Profiles []string `yaml:"profiles"`
+
+	// CoredVersion is the version of cored to deploy
+	CoredVersion string `yaml:"coredVersion"`
+
+	// GaiaVersion is the version of gaia to deploy
+	GaiaVersion string `yaml:"gaiaVersion"`
+
+	// Validators is the number of cored validators, it selects the cored profile
+	Validators int `yaml:"validators"`
+
+	// Sentries is the number of sentry nodes fronting each validator
+	Sentries *int `yaml:"sentries"`
+
+	// AccountPool is the number of accounts funded in genesis which may be leased by tests and tools
+	AccountPool *int `yaml:"accountPool"`
+
+	// Genesis defines overrides applied to the generated genesis
+	Genesis EnvFileGenesis `yaml:"genesis"`
+
+	// Contracts are the WASM contracts, in the form of <wasm-file>[:<instantiate-msg-file>], deployed when
+	// environment is started for the first time, relative paths are resolved against the directory of the file
+	Contracts []string `yaml:"contracts"`
+
+	// Subnet is the subnet of docker network created for the environment
+	Subnet string `yaml:"subnet"`
+
+	// Network configures docker network created for the environment
+	Network EnvFileNetwork `yaml:"network"`
+
+	// AutoPorts enables shifting ports of the environment if default ones are in use
+	AutoPorts *bool `yaml:"autoPorts"`
+
+	// ImagePullPolicy defines when images of the apps are pulled: always, if-not-present or never
+	ImagePullPolicy string `yaml:"imagePullPolicy"`
+
+	// RegistryMirror is the registry, optionally followed by the path, prefixing all the pulled images
+	RegistryMirror string `yaml:"registryMirror"`
+
+	// Resources maps names or types of the apps to limits of their resources, keyed by resource names
+	Resources map[string]map[string]string `yaml:"resources"`
+
+	// Faucet configures the faucet
+	Faucet EnvFileFaucet `yaml:"faucet"`
+
+	// MockServer configures the mock server
+	MockServer EnvFileMockServer `yaml:"mockServer"`
+
+	// Relayer configures IBC relayers
+	Relayer EnvFileRelayer `yaml:"relayer"`
+}
+
+// EnvFileGenesis defines overrides applied to the generated genesis.
+type EnvFileGenesis struct {
+	// Denoms are the additional denoms, in the form of <base>:<display>:<exponent>
+	Denoms []string `yaml:"denoms"`
+
+	// Params maps names of governance and staking parameters to their values
+	Params map[string]string `yaml:"params"`
+
+	// Patch is the path to the file containing JSON merge patch, relative to the directory of the file
+	Patch string `yaml:"patch"`
+}
+
+// EnvFileNetwork configures docker network created for the environment.
+type EnvFileNetwork struct {
+	// Name is the name of docker network
+	Name string `yaml:"name"`
+
+	// IPv6 enables IPv6 in docker network
+	IPv6 *bool `yaml:"ipv6"`
+
+	// Aliases maps names of the apps to additional names they are reachable under in docker network
+	Aliases map[string][]string `yaml:"aliases"`
+}
+
+// EnvFileFaucet configures the faucet.
+type EnvFileFaucet struct {
+	// TransferAmount is the amount of tokens transferred on each request
+	TransferAmount *int `yaml:"transferAmount"`
+
+	// Denom is the denom of tokens transferred on each request
+	Denom string `yaml:"denom"`
+
+	// RateLimit is the maximum number of requests served for each IP address per minute
+	RateLimit *int `yaml:"rateLimit"`
+}
+
+// EnvFileMockServer configures the mock server.
+type EnvFileMockServer struct {
+	// Mappings is the path to the directory containing stub mappings, relative to the directory of the file
+	Mappings string `yaml:"mappings"`
+}
+
+// EnvFileRelayer configures IBC relayers.
+type EnvFileRelayer struct {
+	// GasPriceMultiplier multiplies gas prices paid by relayers on all the chains
+	GasPriceMultiplier *float64 `yaml:"gasPriceMultiplier"`
+
+	// Memo is the memo attached to transactions broadcast by relayers
+	Memo string `yaml:"memo"`
+
+	// Channels maps names of the relayers to the channels of the source chain they relay packets from
+	Channels map[string][]string `yaml:"channels"`
+}
+
+// LoadEnvFile loads the environment file. Unknown fields are reported, so typos are not silently ignored.
+func LoadEnvFile(file string) (EnvFile, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return EnvFile{}, errors.WithStack(err)
+	}
+
+	var envFile EnvFile
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&envFile); err != nil {
+		return EnvFile{}, errors.Wrapf(err, "parsing environment file %s failed", file)
+	}
+
+	// Paths are relative to the file, so it works regardless of the directory znet is executed from.
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return EnvFile{}, errors.WithStack(err)
+	}
+	if envFile.Genesis.Patch != "" {
+		envFile.Genesis.Patch = resolveEnvFilePath(dir, envFile.Genesis.Patch)
+	}
+	if envFile.MockServer.Mappings != "" {
+		envFile.MockServer.Mappings = resolveEnvFilePath(dir, envFile.MockServer.Mappings)
+	}
+	for i, contract := range envFile.Contracts {
+		parts := strings.Split(contract, ":")
+		for j, part := range parts {
+			parts[j] = resolveEnvFilePath(dir, part)
+		}
+		envFile.Contracts[i] = strings.Join(parts, ":")
+	}
+
+	return envFile, nil
+}
+
+// apply sets fields of config factory to the values defined in the file. Field is set only if applicable returns true
+// for the name of the corresponding flag.
+func (f EnvFile) apply(configF *ConfigFactory, applicable func(flag string) bool) {
+	apply := func(flag string, defined bool, set func()) {
+		if defined && applicable(flag) {
+			set()
+		}
+	}
+
+	apply("env", f.Env != "", func() { configF.EnvName = f.Env })
+	apply("profiles", len(f.Profiles) > 0, func() { configF.Profiles = f.Profiles })
+	apply("validators", f.Validators != 0, func() { configF.Validators = f.Validators })
+	apply("cored-version", f.CoredVersion != "", func() { configF.CoredVersion = f.CoredVersion })
+	apply("gaia-version", f.GaiaVersion != "", func() { configF.GaiaVersion = f.GaiaVersion })
+	apply("sentries", f.Sentries != nil, func() { configF.CoredSentries = *f.Sentries })
+	apply("account-pool", f.AccountPool != nil, func() { configF.AccountPoolSize = *f.AccountPool })
+	apply("genesis-denoms", len(f.Genesis.Denoms) > 0, func() { configF.GenesisDenoms = f.Genesis.Denoms })
+	apply("genesis-params", len(f.Genesis.Params) > 0, func() {
+		params := make([]string, 0, len(f.Genesis.Params))
+		for param, value := range f.Genesis.Params {
+			params = append(params, param+"="+value)
+		}
+		sort.Strings(params)
+		configF.GenesisParams = params
+	})
+	apply("genesis-patch", f.Genesis.Patch != "", func() { configF.GenesisPatch = f.Genesis.Patch })
+	apply("contracts", len(f.Contracts) > 0, func() { configF.Contracts = f.Contracts })
+	apply("subnet", f.Subnet != "", func() { configF.Subnet = f.Subnet })
+	apply("network-name", f.Network.Name != "", func() { configF.NetworkName = f.Network.Name })
+	apply("ipv6", f.Network.IPv6 != nil, func() { configF.IPv6 = *f.Network.IPv6 })
+	apply("network-aliases", len(f.Network.Aliases) > 0, func() {
+		var aliases []string
+		for app, appAliases := range f.Network.Aliases {
+			for _, alias := range appAliases {
+				aliases = append(aliases, app+"="+alias)
+			}
+		}
+		sort.Strings(aliases)
+		configF.NetworkAliases = aliases
+	})
+	apply("auto-ports", f.AutoPorts != nil, func() { configF.AutoPorts = *f.AutoPorts })
+	apply("image-pull-policy", f.ImagePullPolicy != "", func() { configF.ImagePullPolicy = f.ImagePullPolicy })
+	apply("registry-mirror", f.RegistryMirror != "", func() { configF.RegistryMirror = f.RegistryMirror })
+	apply("faucet-transfer-amount", f.Faucet.TransferAmount != nil, func() {
+		configF.FaucetTransferAmount = *f.Faucet.TransferAmount
+	})
+	apply("faucet-denom", f.Faucet.Denom != "", func() { configF.FaucetDenom = f.Faucet.Denom })
+	apply("faucet-rate-limit", f.Faucet.RateLimit != nil, func() { configF.FaucetRateLimit = *f.Faucet.RateLimit })
+	apply("mockserver-mappings", f.MockServer.Mappings != "", func() {
+		configF.MockServerMappings = f.MockServer.Mappings
+	})
+	apply("relayer-gas-price-multiplier", f.Relayer.GasPriceMultiplier != nil, func() {
+		configF.RelayerGasPriceMultiplier = *f.Relayer.GasPriceMultiplier
+	})
+	apply("relayer-memo", f.Relayer.Memo != "", func() { configF.RelayerMemo = f.Relayer.Memo })
+	apply("relayer-channels", len(f.Relayer.Channels) > 0, func() {
+		var channels []string
+		for relayer, relayerChannels := range f.Relayer.Channels {
+			for _, channel := range relayerChannels {
+				channels = append(channels, relayer+"="+channel)
+			}
+		}
+		sort.Strings(channels)
+		configF.RelayerChannels = channels
+	})
+	apply("resources", len(f.Resources) > 0, func() {
+		var resources []string
+		for app, limits := range f.Resources {
+			for resource, value := range limits {
+				resources = append(resources, app+"."+resource+"="+value)
+			}
+		}
+		sort.Strings(resources)
+		configF.Resources = resources
+	})
+}
+
+func resolveEnvFilePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,129 @@
+package infra
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeEnvFile(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), DefaultEnvFile)
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	return file
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	file := writeEnvFile(t, `
+env: team
+profiles: [faucet, explorer]
+validators: 3
+sentries: 0
+genesis:
+  patch: genesis-patch.json
+contracts:
+  - contracts/cw20.wasm:contracts/cw20.json
+  - /abs/nft.wasm
+mockServer:
+  mappings: mocks
+faucet:
+  transferAmount: 5000
+`)
+	dir := filepath.Dir(file)
+
+	envFile, err := LoadEnvFile(file)
+	require.NoError(t, err)
+
+	sentries := 0
+	transferAmount := 5000
+	assert.Equal(t, EnvFile{
+		Env:        "team",
+		Profiles:   []string{"faucet", "explorer"},
+		Validators: 3,
+		Sentries:   &sentries,
+		Genesis: EnvFileGenesis{
+			Patch: filepath.Join(dir, "genesis-patch.json"),
+		},
+		Contracts: []string{
+			filepath.Join(dir, "contracts/cw20.wasm") + ":" + filepath.Join(dir, "contracts/cw20.json"),
+			"/abs/nft.wasm",
+		},
+		MockServer: EnvFileMockServer{
+			Mappings: filepath.Join(dir, "mocks"),
+		},
+		Faucet: EnvFileFaucet{
+			TransferAmount: &transferAmount,
+		},
+	}, envFile)
+}
+
+func TestLoadEnvFileRejectsUnknownFields(t *testing.T) {
+	_, err := LoadEnvFile(writeEnvFile(t, "env: team\nprofile: [faucet]\n"))
+	require.Error(t, err)
+}
+
+func TestLoadEnvFileMissing(t *testing.T) {
+	_, err := LoadEnvFile(filepath.Join(t.TempDir(), DefaultEnvFile))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestEnvFileApply(t *testing.T) {
+	envFile, err := LoadEnvFile(writeEnvFile(t, `
+env: team
+sentries: 0
+autoPorts: false
+genesis:
+  params:
+    voting-period: 20s
+    inflation: "0"
+network:
+  aliases:
+    cored-01: [node-b]
+    cored-00: [node-a, rpc]
+resources:
+  cored:
+    memory: 2g
+relayer:
+  channels:
+    ibc-relayer-gaia: [channel-1, channel-0]
+`))
+	require.NoError(t, err)
+
+	configF := &ConfigFactory{
+		EnvName:       "znet",
+		CoredSentries: 2,
+		AutoPorts:     true,
+	}
+	envFile.apply(configF, func(flag string) bool {
+		return true
+	})
+
+	assert.Equal(t, "team", configF.EnvName)
+	// Values set to zero explicitly are applied too.
+	assert.Equal(t, 0, configF.CoredSentries)
+	assert.False(t, configF.AutoPorts)
+	// Maps are converted to the sorted lists, in the format used by the flags.
+	assert.Equal(t, []string{"inflation=0", "voting-period=20s"}, configF.GenesisParams)
+	assert.Equal(t, []string{"cored-00=node-a", "cored-00=rpc", "cored-01=node-b"}, configF.NetworkAliases)
+	assert.Equal(t, []string{"cored.memory=2g"}, configF.Resources)
+	assert.Equal(t, []string{"ibc-relayer-gaia=channel-0", "ibc-relayer-gaia=channel-1"}, configF.RelayerChannels)
+}
+
+func TestEnvFileApplySkipsNotApplicableFlags(t *testing.T) {
+	envFile, err := LoadEnvFile(writeEnvFile(t, "env: team\nsubnet: 172.30.0.0/16\ncoredVersion: v1.0.0\n"))
+	require.NoError(t, err)
+
+	configF := &ConfigFactory{
+		EnvName:      "znet",
+		CoredVersion: "v2.0.0",
+	}
+	envFile.apply(configF, func(flag string) bool {
+		return flag == "env"
+	})
+
+	assert.Equal(t, "team", configF.EnvName)
+	assert.Empty(t, configF.Subnet)
+	assert.Equal(t, "v2.0.0", configF.CoredVersion)
+}
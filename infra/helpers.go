@@ -3,8 +3,13 @@ package infra
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
@@ -13,10 +18,20 @@ import (
 
 	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
 	"github.com/CoreumFoundation/coreum-tools/pkg/must"
+	"github.com/CoreumFoundation/coreum-tools/pkg/parallel"
 	"github.com/CoreumFoundation/coreum-tools/pkg/retry"
 	"github.com/CoreumFoundation/coreum/pkg/client"
 )
 
+// cleanupTimeout is the time given to clean up resources after operation has been cancelled.
+const cleanupTimeout = 30 * time.Second
+
+// CleanupContext returns context used to clean up resources created by the operation executed in ctx. It is not
+// cancelled together with ctx, so cleanup is done even if operation has been interrupted, e.g. by Ctrl-C.
+func CleanupContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(logger.WithLogger(context.Background(), logger.Get(ctx)), cleanupTimeout)
+}
+
 // HealthCheckCapable represents application exposing health check endpoint.
 type HealthCheckCapable interface {
 	// Name returns name of app
@@ -26,18 +41,64 @@ type HealthCheckCapable interface {
 	HealthCheck(ctx context.Context) error
 }
 
-// WaitUntilHealthy waits until app is healthy or context expires.
+// UnhealthyApp describes application which didn't become healthy.
+type UnhealthyApp struct {
+	// Name is the name of app
+	Name string
+
+	// Err is the error returned by the last health check
+	Err error
+}
+
+// UnhealthyAppsError is returned if some applications didn't become healthy.
+type UnhealthyAppsError struct {
+	Apps []UnhealthyApp
+}
+
+// Error returns the report listing all unhealthy apps.
+func (e UnhealthyAppsError) Error() string {
+	report := make([]string, 0, len(e.Apps))
+	for _, app := range e.Apps {
+		report = append(report, fmt.Sprintf("%s: %s", app.Name, app.Err))
+	}
+	return fmt.Sprintf("%d application(s) are unhealthy: %s", len(e.Apps), strings.Join(report, "; "))
+}
+
+// WaitUntilHealthy waits until apps are healthy or context expires. Apps are checked concurrently and if any of them
+// doesn't become healthy, UnhealthyAppsError is returned, listing all of them with the error of the last check.
 func WaitUntilHealthy(ctx context.Context, apps ...HealthCheckCapable) error {
-	for _, app := range apps {
-		app := app
-		ctx = logger.With(ctx, zap.String("app", app.Name()))
-		if err := retry.Do(ctx, time.Second, func() error {
-			return app.HealthCheck(ctx)
-		}); err != nil {
-			return err
+	var (
+		mu        sync.Mutex
+		unhealthy []UnhealthyApp
+	)
+	err := parallel.Run(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
+		for _, app := range apps {
+			app := app
+			spawn(app.Name(), parallel.Continue, func(ctx context.Context) error {
+				ctx = logger.With(ctx, zap.String("app", app.Name()))
+				if err := retry.Do(ctx, time.Second, func() error {
+					return app.HealthCheck(ctx)
+				}); err != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					unhealthy = append(unhealthy, UnhealthyApp{Name: app.Name(), Err: err})
+				}
+				return nil
+			})
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-	return nil
+	if len(unhealthy) == 0 {
+		return nil
+	}
+
+	sort.Slice(unhealthy, func(i, j int) bool {
+		return unhealthy[i].Name < unhealthy[j].Name
+	})
+	return errors.WithStack(UnhealthyAppsError{Apps: unhealthy})
 }
 
 // AppWithInfo represents application which is able to return information about its deployment.
@@ -96,6 +157,17 @@ func PortsToMap(ports interface{}) map[string]int {
 	return res
 }
 
+// ShiftPorts returns copy of the structure containing port numbers, with all of them shifted by delta.
+func ShiftPorts[T any](ports T, delta int) T {
+	v := reflect.ValueOf(&ports).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if field := v.Field(i); field.Kind() == reflect.Int {
+			field.SetInt(field.Int() + int64(delta))
+		}
+	}
+	return ports
+}
+
 // CheckCosmosNodeHealth check the health of the running cosmos based node.
 func CheckCosmosNodeHealth(ctx context.Context, clientCtx client.Context, appInfo DeploymentInfo) error {
 	if appInfo.Status != AppStatusRunning {
@@ -0,0 +1,68 @@
+package infra
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/parallel"
+	"github.com/CoreumFoundation/crust/exec"
+)
+
+// PullPolicy defines when images of the apps are pulled.
+type PullPolicy string
+
+const (
+	// PullPolicyIfNotPresent means image is pulled only if it doesn't exist locally.
+	PullPolicyIfNotPresent PullPolicy = "if-not-present"
+
+	// PullPolicyAlways means image is pulled every time the app is deployed, so moving tags are updated.
+	PullPolicyAlways PullPolicy = "always"
+
+	// PullPolicyNever means image is never pulled, deployment fails if it doesn't exist locally.
+	PullPolicyNever PullPolicy = "never"
+)
+
+// PullPolicies returns the list of supported image pull policies.
+func PullPolicies() []PullPolicy {
+	return []PullPolicy{PullPolicyIfNotPresent, PullPolicyAlways, PullPolicyNever}
+}
+
+// ValidatePullPolicy verifies that image pull policy is supported.
+func ValidatePullPolicy(policy PullPolicy) error {
+	if !lo.Contains(PullPolicies(), policy) {
+		return errors.Errorf("unknown image pull policy %q, supported ones: %v", policy, PullPolicies())
+	}
+	return nil
+}
+
+// Images returns the sorted list of images used by the apps, as they are pulled by the container runtime.
+func (m AppSet) Images() []string {
+	images := lo.Uniq(lo.Map(m, func(app App, _ int) string {
+		return exec.QualifiedImage(app.Deployment().Image)
+	}))
+	sort.Strings(images)
+	return images
+}
+
+// PullImages pulls images in parallel, regardless of whether they exist locally. Images built locally are not pulled,
+// but their existence is verified.
+func PullImages(ctx context.Context, images []string) error {
+	return parallel.Run(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
+		slots := make(chan struct{}, 3)
+		for i := 0; i < cap(slots); i++ {
+			slots <- struct{}{}
+		}
+		for _, image := range images {
+			image := image
+			readyCh := make(chan struct{}, 1)
+			readyCh <- struct{}{}
+			spawn("pull."+image, parallel.Continue, func(ctx context.Context) error {
+				return ensureDockerImage(ctx, image, PullPolicyAlways, slots, readyCh)
+			})
+		}
+		return nil
+	})
+}
@@ -0,0 +1,78 @@
+package infra
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// ConfigOption describes the option of the config factory set by the flag.
+type ConfigOption struct {
+	// Flag is the name of the flag setting the option
+	Flag string
+
+	// Env is the name of the environment variable setting the option
+	Env string
+
+	// Changed is true if the flag is passed explicitly, its value is stored in the config factory then
+	Changed bool
+
+	// Set parses the value taken from the environment variable and stores it in the config factory
+	Set func(value string) error
+}
+
+// Resolve sets options of the config factory, defined by the executed command. Values are resolved in this order,
+// the later one wins: default value of the flag, environment variable, environment file, flag passed explicitly.
+// Options not passed to the function are not used by the command, so they are not taken from the environment file.
+func (f *ConfigFactory) Resolve(options []ConfigOption) error {
+	defined := map[string]bool{}
+	changed := map[string]bool{}
+	for _, option := range options {
+		defined[option.Flag] = true
+		if option.Changed {
+			changed[option.Flag] = true
+			continue
+		}
+		value := os.Getenv(option.Env)
+		if value == "" {
+			continue
+		}
+		if err := option.Set(value); err != nil {
+			return errors.Wrapf(err, "invalid value of %s: %q", option.Env, value)
+		}
+	}
+
+	// Path of the file may be set by environment variable, so the file is loaded once variables are applied.
+	file := f.EnvFile
+	if file == "" {
+		// Default file is optional.
+		if _, err := os.Stat(DefaultEnvFile); err == nil {
+			file = DefaultEnvFile
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return errors.WithStack(err)
+		}
+	}
+	if file != "" {
+		envFile, err := LoadEnvFile(file)
+		if err != nil {
+			return err
+		}
+		envFile.apply(f, func(flag string) bool {
+			return defined[flag] && !changed[flag]
+		})
+
+		// Path is stored, so commands executed in the environment shell load the same file.
+		f.EnvFile, err = filepath.Abs(file)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	// Profiles passed explicitly select the cored profile as well, so number of validators taken from
+	// the environment variable or the file doesn't replace it.
+	if changed["profiles"] && !changed["validators"] {
+		f.Validators = 0
+	}
+	return nil
+}
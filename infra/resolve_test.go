@@ -0,0 +1,221 @@
+package infra
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resolvedOptions are the options of config factory verified by the tests.
+type resolvedOptions struct {
+	EnvName    string
+	Profiles   []string
+	Validators int
+	Subnet     string
+}
+
+// testOptions returns options of config factory set by the flags. Flags are defined in the same way as by the commands
+// of znet, but values are stored directly in the config factory.
+func testOptions(configF *ConfigFactory) []ConfigOption {
+	return []ConfigOption{
+		{
+			Flag: "env",
+			Env:  "TEST_ZNET_ENV",
+			Set: func(value string) error {
+				configF.EnvName = value
+				return nil
+			},
+		},
+		{
+			Flag: "env-file",
+			Env:  "TEST_ZNET_ENV_FILE",
+			Set: func(value string) error {
+				configF.EnvFile = value
+				return nil
+			},
+		},
+		{
+			Flag: "profiles",
+			Env:  "TEST_ZNET_PROFILES",
+			Set: func(value string) error {
+				configF.Profiles = strings.Split(value, ",")
+				return nil
+			},
+		},
+		{
+			Flag: "validators",
+			Env:  "TEST_ZNET_VALIDATORS",
+			Set: func(value string) error {
+				validators, err := strconv.Atoi(value)
+				if err != nil {
+					return err
+				}
+				configF.Validators = validators
+				return nil
+			},
+		},
+	}
+}
+
+func TestResolvePrecedence(t *testing.T) {
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		file     string
+		flags    map[string]string
+		expected resolvedOptions
+	}{
+		{
+			name: "defaults",
+			expected: resolvedOptions{
+				EnvName:  "znet",
+				Profiles: []string{"1cored"},
+			},
+		},
+		{
+			name: "environment variables",
+			env: map[string]string{
+				"TEST_ZNET_ENV":        "from-env",
+				"TEST_ZNET_PROFILES":   "1cored,faucet",
+				"TEST_ZNET_VALIDATORS": "3",
+			},
+			expected: resolvedOptions{
+				EnvName:    "from-env",
+				Profiles:   []string{"1cored", "faucet"},
+				Validators: 3,
+			},
+		},
+		{
+			name: "file overrides environment variables",
+			env: map[string]string{
+				"TEST_ZNET_ENV":        "from-env",
+				"TEST_ZNET_VALIDATORS": "3",
+			},
+			file: "env: from-file\nvalidators: 5\n",
+			expected: resolvedOptions{
+				EnvName:    "from-file",
+				Profiles:   []string{"1cored"},
+				Validators: 5,
+			},
+		},
+		{
+			name: "flags override file and environment variables",
+			env: map[string]string{
+				"TEST_ZNET_ENV": "from-env",
+			},
+			file: "env: from-file\nprofiles: [faucet]\n",
+			flags: map[string]string{
+				"env": "from-flag",
+			},
+			expected: resolvedOptions{
+				EnvName:  "from-flag",
+				Profiles: []string{"faucet"},
+			},
+		},
+		{
+			name: "file doesn't override options not used by the command",
+			file: "env: from-file\nsubnet: 172.30.0.0/16\n",
+			expected: resolvedOptions{
+				EnvName:  "from-file",
+				Profiles: []string{"1cored"},
+			},
+		},
+		{
+			name: "profiles passed explicitly ignore validators from environment variable",
+			env: map[string]string{
+				"TEST_ZNET_VALIDATORS": "3",
+			},
+			flags: map[string]string{
+				"profiles": "5cored",
+			},
+			expected: resolvedOptions{
+				EnvName:  "znet",
+				Profiles: []string{"5cored"},
+			},
+		},
+		{
+			name: "profiles passed explicitly ignore validators from file",
+			file: "validators: 3\n",
+			flags: map[string]string{
+				"profiles": "5cored",
+			},
+			expected: resolvedOptions{
+				EnvName:  "znet",
+				Profiles: []string{"5cored"},
+			},
+		},
+		{
+			name: "validators passed explicitly together with profiles",
+			file: "validators: 3\n",
+			flags: map[string]string{
+				"profiles":   "5cored,faucet",
+				"validators": "1",
+			},
+			expected: resolvedOptions{
+				EnvName:    "znet",
+				Profiles:   []string{"5cored", "faucet"},
+				Validators: 1,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			for env, value := range tc.env {
+				t.Setenv(env, value)
+			}
+
+			configF := &ConfigFactory{
+				EnvName:  "znet",
+				Profiles: []string{"1cored"},
+			}
+			options := testOptions(configF)
+			// Flags passed explicitly are parsed before config is resolved.
+			for i, option := range options {
+				if value, exists := tc.flags[option.Flag]; exists {
+					require.NoError(t, option.Set(value))
+					options[i].Changed = true
+				}
+			}
+			if tc.file != "" {
+				file := filepath.Join(t.TempDir(), DefaultEnvFile)
+				require.NoError(t, os.WriteFile(file, []byte(tc.file), 0o600))
+				configF.EnvFile = file
+			}
+
+			require.NoError(t, configF.Resolve(options))
+			assert.Equal(t, tc.expected, resolvedOptions{
+				EnvName:    configF.EnvName,
+				Profiles:   configF.Profiles,
+				Validators: configF.Validators,
+				Subnet:     configF.Subnet,
+			})
+		})
+	}
+}
+
+func TestResolveEnvFileFromEnvironmentVariable(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "team.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("env: team\n"), 0o600))
+	t.Setenv("TEST_ZNET_ENV_FILE", file)
+
+	configF := &ConfigFactory{EnvName: "znet"}
+	require.NoError(t, configF.Resolve(testOptions(configF)))
+	assert.Equal(t, "team", configF.EnvName)
+	assert.Equal(t, file, configF.EnvFile)
+}
+
+func TestResolveRejectsInvalidEnvironmentVariable(t *testing.T) {
+	t.Setenv("TEST_ZNET_VALIDATORS", "three")
+
+	configF := &ConfigFactory{}
+	err := configF.Resolve(testOptions(configF))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TEST_ZNET_VALIDATORS")
+}
@@ -0,0 +1,105 @@
+package targets
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/crust/exec"
+)
+
+// chaosImage is the image containing tc and iptables, it is started in the network namespace of the container
+// to modify its network conditions, so application images don't need to contain those tools.
+const chaosImage = "nicolaka/netshoot:v0.11"
+
+// KillContainer kills the container without graceful shutdown.
+func KillContainer(ctx context.Context, name string) error {
+	if err := libexec.Exec(ctx, noStdout(exec.Docker("kill", name))); err != nil {
+		return errors.Wrapf(err, "killing container `%s` failed", name)
+	}
+	return nil
+}
+
+// PauseContainer freezes all the processes running in the container.
+func PauseContainer(ctx context.Context, name string) error {
+	if err := libexec.Exec(ctx, noStdout(exec.Docker("pause", name))); err != nil {
+		return errors.Wrapf(err, "pausing container `%s` failed", name)
+	}
+	return nil
+}
+
+// UnpauseContainer resumes processes running in the paused container. Nothing is done if container is not paused.
+func UnpauseContainer(ctx context.Context, name string) error {
+	buf := &bytes.Buffer{}
+	cmd := exec.Docker("inspect", "--format", "{{.State.Paused}}", name)
+	cmd.Stdout = buf
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return errors.Wrapf(err, "inspecting container `%s` failed", name)
+	}
+	if strings.TrimSpace(buf.String()) != "true" {
+		return nil
+	}
+	if err := libexec.Exec(ctx, noStdout(exec.Docker("unpause", name))); err != nil {
+		return errors.Wrapf(err, "unpausing container `%s` failed", name)
+	}
+	return nil
+}
+
+// ContainerIP returns the IP address of the container in the environment's network.
+func ContainerIP(ctx context.Context, name string) (string, error) {
+	buf := &bytes.Buffer{}
+	cmd := exec.Docker("inspect", "--format", "{{range .NetworkSettings.Networks}}{{.IPAddress}} {{end}}", name)
+	cmd.Stdout = buf
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return "", errors.Wrapf(err, "inspecting container `%s` failed", name)
+	}
+	ips := strings.Fields(buf.String())
+	if len(ips) == 0 {
+		return "", errors.Errorf("container `%s` is not connected to any network", name)
+	}
+	return ips[0], nil
+}
+
+// DegradeNetwork adds latency and packet loss to the traffic sent by the container using netem. Previous settings
+// are replaced.
+func DegradeNetwork(ctx context.Context, name, delay, loss string) error {
+	args := []string{"tc", "qdisc", "replace", "dev", "eth0", "root", "netem"}
+	if delay != "" {
+		args = append(args, "delay", delay)
+	}
+	if loss != "" {
+		args = append(args, "loss", loss)
+	}
+	return execInNetNS(ctx, name, strings.Join(args, " "))
+}
+
+// IsolateFrom drops all the traffic between the container and IPs.
+func IsolateFrom(ctx context.Context, name string, ips []string) error {
+	cmds := make([]string, 0, 2*len(ips))
+	for _, ip := range ips {
+		cmds = append(cmds,
+			"iptables -A INPUT -s "+ip+" -j DROP",
+			"iptables -A OUTPUT -d "+ip+" -j DROP",
+		)
+	}
+	return execInNetNS(ctx, name, strings.Join(cmds, " && "))
+}
+
+// HealNetwork removes all the network conditions and partitions applied to the container.
+func HealNetwork(ctx context.Context, name string) error {
+	// Deleting qdisc fails if there is none, so its result is ignored.
+	return execInNetNS(ctx, name, "(tc qdisc del dev eth0 root 2>/dev/null || true) && iptables -F INPUT && iptables -F OUTPUT")
+}
+
+// execInNetNS executes shell command in the network namespace of the container.
+func execInNetNS(ctx context.Context, name, command string) error {
+	cmd := exec.Docker("run", "--rm", "--network", "container:"+name, "--cap-add", "NET_ADMIN",
+		exec.QualifiedImage(chaosImage), "sh", "-c", command)
+	if err := libexec.Exec(ctx, noStdout(cmd)); err != nil {
+		return errors.Wrapf(err, "modifying network of container `%s` failed", name)
+	}
+	return nil
+}
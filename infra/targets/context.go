@@ -0,0 +1,257 @@
+package targets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/crust/exec"
+)
+
+// Docker runtimes requiring special handling.
+const (
+	RuntimeNative  = "native"
+	RuntimeColima  = "colima"
+	RuntimeLima    = "lima"
+	RuntimeDesktop = "docker-desktop"
+	RuntimePodman  = "podman"
+)
+
+// DockerContext describes docker context used to run containers.
+type DockerContext struct {
+	Name     string
+	Endpoint string
+	Runtime  string
+
+	// Rootless is true if containers are run by rootless podman, mapping root of the container to the current user
+	Rootless bool
+}
+
+// vmMount is the host directory shared with the VM running docker daemon.
+type vmMount struct {
+	Location string `yaml:"location"`
+	Writable bool   `yaml:"writable"`
+}
+
+// CurrentDockerContext returns docker context used by docker CLI, respecting DOCKER_CONTEXT and DOCKER_HOST variables.
+func CurrentDockerContext(ctx context.Context) (DockerContext, error) {
+	if exec.IsPodman() {
+		return currentPodmanContext(ctx)
+	}
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		return DockerContext{Name: "DOCKER_HOST", Endpoint: host, Runtime: detectRuntime("", host)}, nil
+	}
+
+	buf := &bytes.Buffer{}
+	cmd := exec.Docker("context", "inspect")
+	cmd.Stdout = buf
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return DockerContext{}, errors.Wrap(err, "inspecting docker context failed")
+	}
+
+	var contexts []struct {
+		Name      string
+		Endpoints map[string]struct {
+			Host string
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &contexts); err != nil || len(contexts) == 0 {
+		return DockerContext{}, errors.Errorf("unexpected output of `docker context inspect`: %s", buf.String())
+	}
+
+	dockerCtx := DockerContext{
+		Name:     contexts[0].Name,
+		Endpoint: contexts[0].Endpoints["docker"].Host,
+	}
+	dockerCtx.Runtime = detectRuntime(dockerCtx.Name, dockerCtx.Endpoint)
+	return dockerCtx, nil
+}
+
+// currentPodmanContext returns the connection used by podman CLI. Podman has no docker contexts, it is configured
+// by `podman system connection` and CONTAINER_HOST variable instead.
+func currentPodmanContext(ctx context.Context) (DockerContext, error) {
+	buf := &bytes.Buffer{}
+	cmd := exec.Docker("info", "--format", "{{.Host.Security.Rootless}} {{.Host.RemoteSocket.Path}}")
+	cmd.Stdout = buf
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return DockerContext{}, errors.Wrap(err, "inspecting podman failed")
+	}
+
+	rootless, endpoint, _ := strings.Cut(strings.TrimSpace(buf.String()), " ")
+	if host := os.Getenv("CONTAINER_HOST"); host != "" {
+		endpoint = host
+	}
+	return DockerContext{
+		Name:     exec.ContainerRuntimePodman,
+		Endpoint: endpoint,
+		Runtime:  RuntimePodman,
+		Rootless: rootless == "true",
+	}, nil
+}
+
+func detectRuntime(name, endpoint string) string {
+	switch {
+	case name == "colima" || strings.HasPrefix(name, "colima-") || strings.Contains(endpoint, "/.colima/"):
+		return RuntimeColima
+	case strings.HasPrefix(name, "lima-") || strings.Contains(endpoint, "/.lima/"):
+		return RuntimeLima
+	case name == "desktop-linux" || strings.Contains(endpoint, "/.docker/run/"):
+		return RuntimeDesktop
+	default:
+		return RuntimeNative
+	}
+}
+
+// VerifyBindMounts checks that the directory may be mounted in containers in writable mode. On colima and lima,
+// docker daemon runs inside VM, and only directories shared with that VM may be mounted.
+func (c DockerContext) VerifyBindMounts(dir string) error {
+	mounts, err := c.vmMounts()
+	if err != nil {
+		return err
+	}
+	if mounts == nil {
+		return nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, m := range mounts {
+		location := m.Location
+		if location == "~" || strings.HasPrefix(location, "~/") {
+			location = homeDir + strings.TrimPrefix(location, "~")
+		}
+		if resolved, err := filepath.EvalSymlinks(location); err == nil {
+			location = resolved
+		}
+		if dir != location && !strings.HasPrefix(dir, location+string(filepath.Separator)) {
+			continue
+		}
+		if !m.Writable {
+			return errors.Errorf("directory %s is shared with %s VM in read-only mode, make the mount writable in the VM config",
+				dir, c.Runtime)
+		}
+		return nil
+	}
+
+	locations := make([]string, 0, len(mounts))
+	for _, m := range mounts {
+		locations = append(locations, m.Location)
+	}
+	return errors.Errorf("directory %s is not shared with %s VM (docker context %q), shared directories: %s; "+
+		"add it to mounts of the VM or use --home flag to store the environment in a shared directory", dir, c.Runtime,
+		c.Name, strings.Join(locations, ", "))
+}
+
+// vmMounts returns directories shared with the VM running docker daemon. Nil is returned if all directories
+// are available.
+func (c DockerContext) vmMounts() ([]vmMount, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var (
+		configFile string
+		defaults   []vmMount
+	)
+	switch c.Runtime {
+	case RuntimeColima:
+		profile := c.vmInstance("/.colima/", strings.TrimPrefix(strings.TrimPrefix(c.Name, "colima"), "-"))
+		if profile == "" {
+			profile = "default"
+		}
+		colimaHome := os.Getenv("COLIMA_HOME")
+		if colimaHome == "" {
+			colimaHome = filepath.Join(homeDir, ".colima")
+		}
+		configFile = filepath.Join(colimaHome, profile, "colima.yaml")
+		defaults = []vmMount{{Location: "~", Writable: true}, {Location: "/tmp/colima", Writable: true}}
+	case RuntimeLima:
+		limaHome := os.Getenv("LIMA_HOME")
+		if limaHome == "" {
+			limaHome = filepath.Join(homeDir, ".lima")
+		}
+		configFile = filepath.Join(limaHome, c.vmInstance("/.lima/", strings.TrimPrefix(c.Name, "lima-")), "lima.yaml")
+		defaults = []vmMount{{Location: "~", Writable: false}, {Location: "/tmp/lima", Writable: true}}
+	default:
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(configFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return defaults, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var config struct {
+		Mounts []vmMount `yaml:"mounts"`
+	}
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, errors.Wrapf(err, "parsing config file %s failed", configFile)
+	}
+	if len(config.Mounts) == 0 {
+		return defaults, nil
+	}
+	return config.Mounts, nil
+}
+
+// vmInstance returns the name of VM instance taken from the path of docker socket, following the marker, e.g.
+// unix:///Users/user/.colima/<instance>/docker.sock. If it can't be found there, def is returned.
+func (c DockerContext) vmInstance(marker, def string) string {
+	_, path, found := strings.Cut(c.Endpoint, marker)
+	if !found {
+		return def
+	}
+	instance, _, _ := strings.Cut(path, "/")
+	if instance == "" {
+		return def
+	}
+	return instance
+}
+
+// verifyDockerContext logs the docker context in use and verifies that the directory may be mounted in containers.
+func verifyDockerContext(ctx context.Context, dir string) (DockerContext, error) {
+	dockerCtx, err := CurrentDockerContext(ctx)
+	if err != nil {
+		return DockerContext{}, err
+	}
+	logger.Get(ctx).Info("Using docker context", zap.String("name", dockerCtx.Name),
+		zap.String("endpoint", dockerCtx.Endpoint), zap.String("runtime", dockerCtx.Runtime),
+		zap.Bool("rootless", dockerCtx.Rootless))
+	return dockerCtx, dockerCtx.VerifyBindMounts(dir)
+}
+
+// runAsUserArgs returns arguments of `docker run` running the container as the current user, so files created
+// in mounted directories are owned by that user. Rootless podman maps users of the container to subordinate IDs
+// of the current user, so the user namespace keeping its ID is required.
+func runAsUserArgs(dockerCtx DockerContext) []string {
+	args := []string{"--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())}
+	if dockerCtx.Rootless {
+		args = append(args, "--userns", "keep-id")
+	}
+	return args
+}
+
+// securityArgs returns arguments of `docker run` required by the container runtime to access mounted directories.
+// Podman is used mostly on hosts enforcing SELinux, where containers can't access directories not labeled for them,
+// so separation is disabled instead of relabeling directories of the host.
+func securityArgs() []string {
+	if !exec.IsPodman() {
+		return nil
+	}
+	return []string{"--security-opt", "label=disable"}
+}
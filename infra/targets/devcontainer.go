@@ -0,0 +1,187 @@
+package targets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/crust/exec"
+)
+
+// Modes of accessing docker daemon from the devcontainer.
+const (
+	// DevContainerNone means crust doesn't run inside the container.
+	DevContainerNone = ""
+
+	// DevContainerDinD means docker daemon runs inside the devcontainer, so it sees the same filesystem and network.
+	DevContainerDinD = "docker-in-docker"
+
+	// DevContainerDooD means docker socket of the host is passed to the devcontainer, so paths of bind mounts
+	// are resolved on the host and published ports are bound to the host's network.
+	DevContainerDooD = "docker-outside-of-docker"
+)
+
+// DevContainer describes the devcontainer crust runs in.
+type DevContainer struct {
+	Mode string
+
+	// ID is the ID of the devcontainer known to the docker daemon, set only in docker-outside-of-docker mode
+	ID string
+
+	// Mounts are the directories of the host bind-mounted into the devcontainer
+	Mounts []BindMount
+}
+
+// BindMount is the directory of the host bind-mounted into the container.
+type BindMount struct {
+	Source      string `json:"Source"`
+	Destination string `json:"Destination"`
+	Type        string `json:"Type"`
+	RW          bool   `json:"RW"`
+}
+
+// InDevContainer returns true if crust runs inside the container, e.g. devcontainer or GitHub Codespace.
+func InDevContainer() bool {
+	if os.Getenv("REMOTE_CONTAINERS") == "true" || os.Getenv("CODESPACES") == "true" {
+		return true
+	}
+	for _, file := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(file); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectDevContainer detects how docker daemon is accessed from the devcontainer. If the daemon knows the container
+// crust runs in, the socket of the host is passed to it, otherwise the daemon runs inside the devcontainer.
+func DetectDevContainer(ctx context.Context) (DevContainer, error) {
+	if !InDevContainer() {
+		return DevContainer{Mode: DevContainerNone}, nil
+	}
+	if _, err := osexec.LookPath(exec.ContainerRuntime()); err != nil {
+		return DevContainer{}, errors.Errorf("%s CLI not found", exec.ContainerRuntime())
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return DevContainer{}, errors.WithStack(err)
+	}
+	buf := &bytes.Buffer{}
+	cmd := exec.Docker("inspect", "--type", "container", "--format", "{{.Id}} {{json .Mounts}}", hostname)
+	cmd.Stdout = buf
+	cmd.Stderr = io.Discard
+	if libexec.Exec(ctx, cmd) != nil {
+		// Container is not known to the daemon, so the daemon runs inside it.
+		return DevContainer{Mode: DevContainerDinD}, nil
+	}
+
+	id, mountsRaw, _ := strings.Cut(strings.TrimSpace(buf.String()), " ")
+	var mounts []BindMount
+	if err := json.Unmarshal([]byte(mountsRaw), &mounts); err != nil {
+		return DevContainer{}, errors.Wrapf(err, "decoding mounts of the devcontainer failed")
+	}
+	devContainer := DevContainer{Mode: DevContainerDooD, ID: id}
+	for _, m := range mounts {
+		if m.Type == "bind" {
+			devContainer.Mounts = append(devContainer.Mounts, m)
+		}
+	}
+	// The most nested mount wins, so mounts are sorted by destination in reverse order.
+	sort.Slice(devContainer.Mounts, func(i, j int) bool {
+		return devContainer.Mounts[i].Destination > devContainer.Mounts[j].Destination
+	})
+	return devContainer, nil
+}
+
+// HostPath translates path inside the devcontainer to the path on the host, where docker daemon resolves
+// sources of bind mounts.
+func (d DevContainer) HostPath(path string) (string, error) {
+	if d.Mode != DevContainerDooD {
+		return path, nil
+	}
+	if m, ok := d.mountOf(path); ok {
+		return filepath.Join(m.Source, strings.TrimPrefix(path, m.Destination)), nil
+	}
+
+	destinations := make([]string, 0, len(d.Mounts))
+	for _, m := range d.Mounts {
+		destinations = append(destinations, m.Destination)
+	}
+	return "", errors.Errorf("directory %s is not mounted from the host into the devcontainer, so docker daemon "+
+		"can't mount it in containers, mounted directories: %s; use --home flag to store the environment "+
+		"in the mounted directory", path, strings.Join(destinations, ", "))
+}
+
+// WorkspaceDir returns the writable directory mounted from the host which contains the path, e.g. the workspace
+// containing the current directory. Empty string is returned if there is no such directory.
+func (d DevContainer) WorkspaceDir(path string) string {
+	if m, ok := d.mountOf(path); ok && m.RW {
+		return m.Destination
+	}
+	return ""
+}
+
+func (d DevContainer) mountOf(path string) (BindMount, bool) {
+	for _, m := range d.Mounts {
+		if path == m.Destination || strings.HasPrefix(path, m.Destination+string(filepath.Separator)) {
+			return m, true
+		}
+	}
+	return BindMount{}, false
+}
+
+// connectNetwork connects the devcontainer to the docker network, so apps are reachable using the names
+// of their containers.
+func (d DevContainer) connectNetwork(ctx context.Context, network string) error {
+	if d.Mode != DevContainerDooD {
+		return nil
+	}
+	connected, err := d.connectedTo(ctx, network)
+	if err != nil || connected {
+		return err
+	}
+	if err := libexec.Exec(ctx, noStdout(exec.Docker("network", "connect", network, d.ID))); err != nil {
+		return errors.Wrapf(err, "connecting devcontainer to network '%s' failed", network)
+	}
+	return nil
+}
+
+// disconnectNetwork disconnects the devcontainer from the docker network, otherwise network can't be deleted.
+func (d DevContainer) disconnectNetwork(ctx context.Context, network string) error {
+	if d.Mode != DevContainerDooD {
+		return nil
+	}
+	connected, err := d.connectedTo(ctx, network)
+	if err != nil || !connected {
+		return err
+	}
+	if err := libexec.Exec(ctx, noStdout(exec.Docker("network", "disconnect", "-f", network, d.ID))); err != nil {
+		return errors.Wrapf(err, "disconnecting devcontainer from network '%s' failed", network)
+	}
+	return nil
+}
+
+func (d DevContainer) connectedTo(ctx context.Context, network string) (bool, error) {
+	buf := &bytes.Buffer{}
+	cmd := exec.Docker("inspect", "--type", "container", "--format", "{{json .NetworkSettings.Networks}}", d.ID)
+	cmd.Stdout = buf
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return false, errors.Wrap(err, "inspecting networks of devcontainer failed")
+	}
+	var networks map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &networks); err != nil {
+		return false, errors.WithStack(err)
+	}
+	_, connected := networks[network]
+	return connected, nil
+}
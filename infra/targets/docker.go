@@ -4,13 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"os"
 	osexec "os/exec"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -26,8 +26,13 @@ const (
 	// AppHomeDir is the path inide container where application's home directory is mounted.
 	AppHomeDir = "/app"
 
-	labelEnv = "com.coreum.crust.znet.env"
-	labelApp = "com.coreum.crust.znet.app"
+	labelEnv  = "com.coreum.crust.znet.env"
+	labelApp  = "com.coreum.crust.znet.app"
+	labelHome = "com.coreum.crust.znet.home"
+	labelHost = "com.coreum.crust.znet.host"
+
+	// maxRestarts is the number of times container with on-failure restart policy is restarted before docker gives up.
+	maxRestarts = 5
 )
 
 // FIXME (wojciech): Entire logic here could be easily implemented by using docker API instead of binary execution
@@ -47,6 +52,8 @@ type Docker struct {
 
 	mu            sync.Mutex
 	networkExists bool
+	devContainer  DevContainer
+	dockerCtx     DockerContext
 }
 
 // Stop stops running applications.
@@ -117,17 +124,70 @@ func (d *Docker) Remove(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	return d.deleteNetwork(ctx, d.config.EnvName)
+	return d.deleteNetwork(ctx, d.config.NetworkName)
 }
 
 // Deploy deploys environment to docker target.
 func (d *Docker) Deploy(ctx context.Context, appSet infra.AppSet) error {
+	dockerCtx, err := verifyDockerContext(ctx, d.config.HomeDir)
+	if err != nil {
+		return err
+	}
+	devContainer, err := DetectDevContainer(ctx)
+	if err != nil {
+		return err
+	}
+	if devContainer.Mode != DevContainerNone {
+		homeDir, err := devContainer.HostPath(d.config.HomeDir)
+		if err != nil {
+			return err
+		}
+		logger.Get(ctx).Info("Running inside devcontainer", zap.String("mode", devContainer.Mode),
+			zap.String("hostHomeDir", homeDir))
+	}
+	if remoteHost := dockerCtx.RemoteHost(); remoteHost != "" {
+		if err := VerifyRemoteHome(ctx, d.config.HomeDir); err != nil {
+			return err
+		}
+		logger.Get(ctx).Info("Running on remote docker host, ports are published on all its interfaces",
+			zap.String("host", remoteHost))
+	}
+	d.devContainer = devContainer
+	d.dockerCtx = dockerCtx
+	if err := d.reconcile(ctx); err != nil {
+		return err
+	}
 	return appSet.Deploy(ctx, d, d.config, d.spec)
 }
 
+// reconcile brings containers left by the previous, interrupted or crashed, run in line with the spec. Containers
+// of the apps which have never been deployed successfully are removed, so they are created from scratch instead
+// of starting half-configured ones, and apps which containers exited are marked as stopped, so they are started
+// again.
+func (d *Docker) reconcile(ctx context.Context) error {
+	return forContainer(ctx, d.config.EnvName, func(ctx context.Context, info container) error {
+		app, exists := d.spec.Apps[info.AppName]
+		if !exists {
+			return nil
+		}
+		log := logger.Get(ctx).With(zap.String("id", info.ID), zap.String("name", info.Name),
+			zap.String("appName", info.AppName))
+
+		switch {
+		case app.Info().Status == infra.AppStatusNotDeployed:
+			log.Warn("Removing container left by the interrupted start")
+			return removeContainer(ctx, info)
+		case app.Info().Status == infra.AppStatusRunning && !info.Running:
+			log.Warn("Container exited, it is started again")
+			app.SetInfo(infra.DeploymentInfo{Status: infra.AppStatusStopped})
+		}
+		return nil
+	})
+}
+
 // DeployContainer starts container in docker.
 func (d *Docker) DeployContainer(ctx context.Context, app infra.Deployment) (infra.DeploymentInfo, error) {
-	if err := d.ensureNetwork(ctx, d.config.EnvName); err != nil {
+	if err := d.ensureNetwork(ctx, d.config.NetworkName); err != nil {
 		return infra.DeploymentInfo{}, err
 	}
 
@@ -145,42 +205,103 @@ func (d *Docker) DeployContainer(ctx context.Context, app infra.Deployment) (inf
 	if id != "" {
 		startCmd = exec.Docker("start", id)
 	} else {
-		runArgs := d.prepareRunArgs(name, app)
+		platform, err := imagePlatform(ctx, app.Image)
+		if err != nil {
+			return infra.DeploymentInfo{}, err
+		}
+		runArgs, err := d.prepareRunArgs(name, platform, app)
+		if err != nil {
+			return infra.DeploymentInfo{}, err
+		}
 		startCmd = exec.Docker(runArgs...)
 	}
 	idBuf := &bytes.Buffer{}
 	startCmd.Stdout = idBuf
 
 	if err := libexec.Exec(ctx, startCmd); err != nil {
+		// `docker run` creates container before starting it, so container is left behind if starting fails
+		// or is interrupted. It is removed, otherwise it would be started with outdated configuration next time.
+		if id == "" {
+			cleanupCtx, cancel := infra.CleanupContext(ctx)
+			defer cancel()
+			if rmErr := forceRemoveContainer(cleanupCtx, name); rmErr != nil {
+				log.Error("Removing partially created container failed", zap.Error(rmErr))
+			}
+		}
 		return infra.DeploymentInfo{}, err
 	}
 
 	log.Info("Container started", zap.String("id", strings.TrimSuffix(idBuf.String(), "\n")))
 
 	// FromHostIP = ipLocalhost here means that application is available on host's localhost, not container's localhost
+	hostFromHost := "localhost"
+	switch {
+	case d.dockerCtx.RemoteHost() != "":
+		// Ports are published on the remote host running the daemon.
+		hostFromHost = d.dockerCtx.RemoteHost()
+	case d.devContainer.Mode == DevContainerDooD:
+		// Ports are published on the localhost of the host running the daemon, not the devcontainer, but it is
+		// connected to the network of the environment, so apps are reachable the same way as from other containers.
+		hostFromHost = name
+	}
+	ip, ipv6, err := containerIPs(ctx, name, d.config.NetworkName)
+	if err != nil {
+		return infra.DeploymentInfo{}, err
+	}
 	return infra.DeploymentInfo{
 		Container:         name,
 		Status:            infra.AppStatusRunning,
-		HostFromHost:      "localhost",
+		HostFromHost:      hostFromHost,
 		HostFromContainer: name,
 		Ports:             app.Ports,
+		IP:                ip,
+		IPv6:              ipv6,
 	}, nil
 }
 
-func (d *Docker) prepareRunArgs(name string, app infra.Deployment) []string {
+// RemoveContainer removes container deployed by DeployContainer.
+func (d *Docker) RemoveContainer(ctx context.Context, info infra.DeploymentInfo) error {
+	logger.Get(ctx).Info("Removing container", zap.String("name", info.Container))
+	return forceRemoveContainer(ctx, info.Container)
+}
+
+// imagePlatform returns the platform of the local image. Image may be pulled for other platform than the one
+// of docker, if there is no native one, so it is passed explicitly when container is created.
+func imagePlatform(ctx context.Context, image string) (string, error) {
+	buf := &bytes.Buffer{}
+	cmd := exec.Docker("image", "inspect", "--format", "{{ .Os }}/{{ .Architecture }}", image)
+	cmd.Stdout = buf
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return "", errors.Wrapf(err, "inspecting platform of image '%s' failed", image)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func (d *Docker) prepareRunArgs(name, platform string, app infra.Deployment) ([]string, error) {
 	runArgs := []string{
-		"run", "--name", name, "-d", "--label", labelEnv + "=" + d.config.EnvName,
-		"--label", labelApp + "=" + app.Name, "--network", d.config.EnvName,
+		"run", "--name", name, "-d", "--platform", platform, "--label", labelEnv + "=" + d.config.EnvName,
+		"--label", labelApp + "=" + app.Name, "--label", labelHome + "=" + d.config.HomeDir,
+		"--label", labelHost + "=" + hostID(), "--network", d.config.NetworkName,
 	}
+	for _, alias := range d.config.NetworkAliases[app.Name] {
+		runArgs = append(runArgs, "--network-alias", alias)
+	}
+	runArgs = append(runArgs, securityArgs()...)
+	runArgs = append(runArgs, resourceArgs(app.Resources)...)
+	runArgs = append(runArgs, restartArgs(app.RestartPolicy)...)
 	if app.RunAsUser {
-		runArgs = append(runArgs, "--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()))
+		runArgs = append(runArgs, runAsUserArgs(d.dockerCtx)...)
 	}
-	for _, port := range app.Ports {
-		portStr := strconv.Itoa(port)
-		runArgs = append(runArgs, "-p", "127.0.0.1:"+portStr+":"+portStr+"/tcp")
+	for name, port := range app.Ports {
+		runArgs = append(runArgs, "-p", publishAddress(d.dockerCtx, app)+":"+strconv.Itoa(port)+":"+
+			strconv.Itoa(app.ContainerPort(name))+"/tcp")
 	}
 	for _, v := range app.Volumes {
-		runArgs = append(runArgs, "-v", v.Source+":"+v.Destination)
+		source, err := daemonPath(d.dockerCtx, d.devContainer, v.Source)
+		if err != nil {
+			return nil, err
+		}
+		runArgs = append(runArgs, "-v", source+":"+v.Destination)
 	}
 	if app.EnvVarsFunc != nil {
 		for _, env := range app.EnvVarsFunc() {
@@ -196,7 +317,37 @@ func (d *Docker) prepareRunArgs(name string, app infra.Deployment) []string {
 		runArgs = append(runArgs, app.ArgsFunc()...)
 	}
 
-	return runArgs
+	return runArgs, nil
+}
+
+// restartArgs returns arguments of `docker run` setting the restart policy of the container.
+func restartArgs(policy infra.RestartPolicy) []string {
+	switch policy {
+	case infra.RestartPolicyNever:
+		return nil
+	case infra.RestartPolicyOnFailure:
+		return []string{"--restart", string(policy) + ":" + strconv.Itoa(maxRestarts)}
+	default:
+		return []string{"--restart", string(policy)}
+	}
+}
+
+// resourceArgs returns arguments of `docker run` limiting resources available to the container.
+func resourceArgs(resources infra.Resources) []string {
+	var args []string
+	if resources.CPUShares > 0 {
+		args = append(args, "--cpu-shares", strconv.Itoa(resources.CPUShares))
+	}
+	if resources.CPUs > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(resources.CPUs, 'f', -1, 64))
+	}
+	if resources.Memory > 0 {
+		args = append(args, "--memory", strconv.FormatInt(resources.Memory, 10))
+	}
+	if resources.Pids > 0 {
+		args = append(args, "--pids-limit", strconv.Itoa(resources.Pids))
+	}
+	return args
 }
 
 func (d *Docker) ensureNetwork(ctx context.Context, network string) error {
@@ -216,18 +367,37 @@ func (d *Docker) ensureNetwork(ctx context.Context, network string) error {
 	}
 	if d.networkExists {
 		log.Info("Docker network exists")
-		return nil
+		return d.devContainer.connectNetwork(ctx, network)
+	}
+
+	subnet := d.config.Subnet
+	if subnet == "" {
+		freeSubnet, err := freeSubnet(ctx)
+		if err != nil {
+			return err
+		}
+		subnet = freeSubnet.String()
+	}
+
+	createArgs := []string{
+		"network", "create", "--subnet", subnet, "--label", labelEnv + "=" + d.config.EnvName,
+		"--label", labelHome + "=" + d.config.HomeDir, "--label", labelHost + "=" + hostID(),
+	}
+	if d.config.IPv6 {
+		subnetIPv6 := ipv6Subnet(network)
+		createArgs = append(createArgs, "--ipv6", "--subnet", subnetIPv6)
+		log = log.With(zap.String("subnetIPv6", subnetIPv6))
 	}
 
-	log.Info("Creating docker network")
+	log.Info("Creating docker network", zap.String("subnet", subnet))
 
-	if err := libexec.Exec(ctx, noStdout(exec.Docker("network", "create", network))); err != nil {
+	if err := libexec.Exec(ctx, noStdout(exec.Docker(append(createArgs, network)...))); err != nil {
 		return errors.Wrapf(err, "creating network '%s' failed", network)
 	}
 
 	d.networkExists = true
 	log.Info("Docker network created")
-	return nil
+	return d.devContainer.connectNetwork(ctx, network)
 }
 
 func (d *Docker) deleteNetwork(ctx context.Context, network string) error {
@@ -242,6 +412,14 @@ func (d *Docker) deleteNetwork(ctx context.Context, network string) error {
 	log := logger.Get(ctx).With(zap.String("network", network))
 	log.Info("Deleting docker network")
 
+	devContainer, err := DetectDevContainer(ctx)
+	if err != nil {
+		return err
+	}
+	if err := devContainer.disconnectNetwork(ctx, network); err != nil {
+		return err
+	}
+
 	if err := libexec.Exec(ctx, noStdout(exec.Docker("network", "rm", network))); err != nil {
 		return errors.Wrapf(err, "deleting network '%s' failed", network)
 	}
@@ -250,9 +428,131 @@ func (d *Docker) deleteNetwork(ctx context.Context, network string) error {
 	return nil
 }
 
+// RestartContainer restarts docker container.
+func RestartContainer(ctx context.Context, name string) error {
+	if err := libexec.Exec(ctx, noStdout(exec.Docker("restart", name))); err != nil {
+		return errors.Wrapf(err, "restarting container `%s` failed", name)
+	}
+	return nil
+}
+
+// RunOnce runs the command in the new container created using image, volumes and environment of the app deployment.
+// Container is not connected to any network and it is removed once command completes. Both stdout and stderr
+// of the command are written to output.
+func RunOnce(ctx context.Context, app infra.Deployment, args []string, output io.Writer) error {
+	devContainer, err := DetectDevContainer(ctx)
+	if err != nil {
+		return err
+	}
+	dockerCtx, err := CurrentDockerContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	runArgs := append([]string{"run", "--rm", "--network", "none"}, securityArgs()...)
+	if app.RunAsUser {
+		runArgs = append(runArgs, runAsUserArgs(dockerCtx)...)
+	}
+	for _, v := range app.Volumes {
+		source, err := daemonPath(dockerCtx, devContainer, v.Source)
+		if err != nil {
+			return err
+		}
+		runArgs = append(runArgs, "-v", source+":"+v.Destination)
+	}
+	if app.EnvVarsFunc != nil {
+		for _, env := range app.EnvVarsFunc() {
+			runArgs = append(runArgs, "-e", env.Name+"="+env.Value)
+		}
+	}
+	if app.Entrypoint != "" {
+		runArgs = append(runArgs, "--entrypoint", app.Entrypoint)
+	}
+	runArgs = append(runArgs, exec.QualifiedImage(app.Image))
+	runArgs = append(runArgs, args...)
+
+	cmd := exec.Docker(runArgs...)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return errors.Wrapf(err, "running command in the container of %s failed", app.Name)
+	}
+	return nil
+}
+
+// SaveContainerLogs stores logs of docker container in the file.
+func SaveContainerLogs(ctx context.Context, name, file string) error {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	cmd := exec.Docker("logs", "--timestamps", name)
+	cmd.Stdout = f
+	cmd.Stderr = f
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return errors.Wrapf(err, "fetching logs of container `%s` failed", name)
+	}
+	return nil
+}
+
+// StreamContainerLogs writes logs of docker container to the output, starting from the tail number of recent lines
+// and following new ones until context is canceled.
+func StreamContainerLogs(ctx context.Context, name string, tail int, output io.Writer) error {
+	cmd := exec.Docker("logs", "--follow", "--tail", strconv.Itoa(tail), name)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := libexec.Exec(ctx, cmd); err != nil && !errors.Is(err, ctx.Err()) {
+		return errors.Wrapf(err, "streaming logs of container `%s` failed", name)
+	}
+	return nil
+}
+
+// SaveDockerEvents stores docker events of the containers belonging to the environment, emitted since the provided
+// time, in the file.
+func SaveDockerEvents(ctx context.Context, envName string, since time.Time, file string) error {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	// --until is required, otherwise docker streams new events forever.
+	cmd := exec.Docker("events",
+		"--since", strconv.FormatInt(since.Unix(), 10),
+		"--until", strconv.FormatInt(time.Now().Unix(), 10),
+		"--filter", "label="+labelEnv+"="+envName,
+		"--format", "{{json .}}",
+	)
+	cmd.Stdout = f
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return errors.Wrap(err, "fetching docker events failed")
+	}
+	return nil
+}
+
+// ContainerIDs returns IDs of the containers belonging to the environment, keyed by container names.
+func ContainerIDs(ctx context.Context, envName string) (map[string]string, error) {
+	var mu sync.Mutex
+	ids := map[string]string{}
+	err := forContainer(ctx, envName, func(ctx context.Context, info container) error {
+		mu.Lock()
+		defer mu.Unlock()
+		ids[info.Name] = info.ID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
 func containerExists(ctx context.Context, name string) (string, error) {
 	idBuf := &bytes.Buffer{}
-	existsCmd := exec.Docker("ps", "-aq", "--no-trunc", "--filter", "name="+name)
+	// Name filter matches substrings, so it is anchored, otherwise containers of apps having names prefixed
+	// with this one would match too.
+	existsCmd := exec.Docker("ps", "-aq", "--no-trunc", "--filter", "name=^/?"+name+"$")
 	existsCmd.Stdout = idBuf
 	if err := libexec.Exec(ctx, existsCmd); err != nil {
 		return "", err
@@ -336,9 +636,23 @@ func removeContainer(ctx context.Context, info container) error {
 	return nil
 }
 
+// forceRemoveContainer removes container, killing it first if it is running. It succeeds if container doesn't exist.
+func forceRemoveContainer(ctx context.Context, name string) error {
+	cmd := exec.Docker("rm", "--force", name)
+	cmd.Stderr = io.Discard
+	if err := libexec.Exec(ctx, noStdout(cmd)); err != nil {
+		exists, existsErr := containerExists(ctx, name)
+		if existsErr == nil && exists == "" {
+			return nil
+		}
+		return errors.Wrapf(err, "removing container `%s` failed", name)
+	}
+	return nil
+}
+
 func networkExists(ctx context.Context, network string) (bool, error) {
 	buf := &bytes.Buffer{}
-	cmd := exec.Docker("network", "ls", "-q", "--no-trunc", "--filter", "name="+network)
+	cmd := exec.Docker("network", "ls", "-q", "--no-trunc", "--filter", "name=^"+network+"$")
 	cmd.Stdout = buf
 	if err := libexec.Exec(ctx, cmd); err != nil {
 		return false, err
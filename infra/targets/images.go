@@ -0,0 +1,88 @@
+package targets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/crust/exec"
+)
+
+// Labels set by the crust build system on images built from source repositories.
+const (
+	labelImageRevision = "org.opencontainers.image.revision"
+	labelImageRepoPath = "com.coreum.crust.repo-path"
+)
+
+// ImageInfo describes locally available image.
+type ImageInfo struct {
+	// ID is the ID of the image
+	ID string
+
+	// Created is the time image was built at
+	Created time.Time
+
+	// Revision is the commit of the source repository image was built from, with `-dirty` suffix if repository
+	// contained uncommitted changes, empty if image was not built by crust from source repository
+	Revision string
+
+	// RepoPath is the path of the source repository image was built from
+	RepoPath string
+}
+
+// InspectImage returns information about the local image.
+func InspectImage(ctx context.Context, image string) (ImageInfo, error) {
+	buf := &bytes.Buffer{}
+	cmd := exec.Docker("image", "inspect", image)
+	cmd.Stdout = buf
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return ImageInfo{}, errors.Wrapf(err, "inspecting image `%s` failed", image)
+	}
+
+	var info []struct {
+		ID      string `json:"Id"` //nolint:tagliatelle // `Id` is defined by docker
+		Created time.Time
+		Config  struct {
+			Labels map[string]string
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &info); err != nil {
+		return ImageInfo{}, errors.Wrap(err, "unmarshalling image properties failed")
+	}
+	if len(info) == 0 {
+		return ImageInfo{}, errors.Errorf("image `%s` doesn't exist", image)
+	}
+	return ImageInfo{
+		ID:       info[0].ID,
+		Created:  info[0].Created,
+		Revision: info[0].Config.Labels[labelImageRevision],
+		RepoPath: info[0].Config.Labels[labelImageRepoPath],
+	}, nil
+}
+
+// ContainerImageID returns ID of the image container has been created from. Empty string is returned if container
+// doesn't exist.
+func ContainerImageID(ctx context.Context, name string) (string, error) {
+	id, err := containerExists(ctx, name)
+	if err != nil || id == "" {
+		return "", err
+	}
+
+	buf := &bytes.Buffer{}
+	cmd := exec.Docker("inspect", "--format", "{{.Image}}", id)
+	cmd.Stdout = buf
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return "", errors.Wrapf(err, "inspecting container `%s` failed", name)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// RemoveContainer removes container, killing it first if it is running. It succeeds if container doesn't exist.
+func RemoveContainer(ctx context.Context, name string) error {
+	return forceRemoveContainer(ctx, name)
+}
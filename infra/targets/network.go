@@ -0,0 +1,74 @@
+package targets
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/crust/exec"
+)
+
+// networkNameRegexp matches names of docker networks accepted by docker.
+var networkNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// aliasRegexp matches DNS names which may be used as network aliases.
+var aliasRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*$`)
+
+// ValidateNetworkName verifies that the name of docker network is valid, empty name means the default one.
+func ValidateNetworkName(name string) error {
+	if name != "" && !networkNameRegexp.MatchString(name) {
+		return errors.Errorf("invalid network name %q, only letters, digits and `_.-` are allowed", name)
+	}
+	return nil
+}
+
+// ParseNetworkAliases parses aliases, in the form of <app>=<alias>, under which apps are reachable in the docker
+// network, in addition to the names of their containers.
+func ParseNetworkAliases(aliases []string) (map[string][]string, error) {
+	result := map[string][]string{}
+	owners := map[string]string{}
+	for _, def := range aliases {
+		app, alias, ok := strings.Cut(def, "=")
+		if !ok || app == "" {
+			return nil, errors.Errorf("invalid network alias %q, expected <app>=<alias>, e.g. cored-00=node.local", def)
+		}
+		if !aliasRegexp.MatchString(alias) {
+			return nil, errors.Errorf("invalid network alias %q, lowercase DNS name is expected", alias)
+		}
+		if owner, exists := owners[alias]; exists && owner != app {
+			return nil, errors.Errorf("network alias %q is assigned to both %s and %s", alias, owner, app)
+		}
+		owners[alias] = app
+		result[app] = append(result[app], alias)
+	}
+	return result, nil
+}
+
+// ipv6Subnet returns the unique local /64 IPv6 subnet for the network, derived from its name, so it is stable
+// between runs and unlikely to collide with networks of other environments.
+func ipv6Subnet(network string) string {
+	hash := sha256.Sum256([]byte(network))
+	ip := make(net.IP, net.IPv6len)
+	ip[0] = 0xfd
+	copy(ip[1:8], hash[:7])
+	return (&net.IPNet{IP: ip, Mask: net.CIDRMask(64, 128)}).String()
+}
+
+// containerIPs returns IPv4 and IPv6 addresses assigned to the container in the network.
+func containerIPs(ctx context.Context, name, network string) (string, string, error) {
+	buf := &bytes.Buffer{}
+	cmd := exec.Docker("inspect", "--type", "container", "--format",
+		`{{with index .NetworkSettings.Networks "`+network+`"}}{{.IPAddress}},{{.GlobalIPv6Address}}{{end}}`, name)
+	cmd.Stdout = buf
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return "", "", errors.Wrapf(err, "inspecting addresses of container `%s` failed", name)
+	}
+	ipv4, ipv6, _ := strings.Cut(strings.TrimSpace(buf.String()), ",")
+	return ipv4, ipv6, nil
+}
@@ -0,0 +1,154 @@
+package targets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/crust/exec"
+)
+
+// Kinds of docker resources created for the environments.
+const (
+	ResourceContainer = "container"
+	ResourceNetwork   = "network"
+	ResourceVolume    = "volume"
+)
+
+// EnvResource is docker resource created for the environment.
+type EnvResource struct {
+	// Kind is the kind of the resource: container, network or volume
+	Kind string
+
+	// ID is the ID of the resource, volumes are identified by their names
+	ID string
+
+	// Name is the name of the resource
+	Name string
+
+	// Env is the name of the environment resource belongs to
+	Env string
+
+	// HomeDir is the home directory of the environment, empty if resource was created by older version of znet
+	HomeDir string
+
+	// Host is the host which created the resource, empty if resource was created by older version of znet
+	Host string
+}
+
+// IsLocal returns true if the resource was created by this host. Docker daemon may be shared by many hosts,
+// so home directories of other hosts' environments don't exist locally.
+func (r EnvResource) IsLocal() bool {
+	return r.Host == hostID()
+}
+
+// hostID returns the identity of the host running znet, stored in labels of created resources.
+func hostID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return "unknown"
+	}
+	return host
+}
+
+// ListEnvResources returns containers, networks and volumes labeled with the names of the environments, ordered
+// by kind, so they may be removed in that order.
+func ListEnvResources(ctx context.Context) ([]EnvResource, error) {
+	var resources []EnvResource
+	for _, kind := range []string{ResourceContainer, ResourceNetwork, ResourceVolume} {
+		kindResources, err := listEnvResources(ctx, kind)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, kindResources...)
+	}
+	return resources, nil
+}
+
+func listEnvResources(ctx context.Context, kind string) ([]EnvResource, error) {
+	listArgs := []string{"ps", "-aq", "--no-trunc"}
+	switch kind {
+	case ResourceNetwork:
+		listArgs = []string{"network", "ls", "-q", "--no-trunc"}
+	case ResourceVolume:
+		listArgs = []string{"volume", "ls", "-q"}
+	}
+	listBuf := &bytes.Buffer{}
+	listCmd := exec.Docker(append(listArgs, "--filter", "label="+labelEnv)...)
+	listCmd.Stdout = listBuf
+	if err := libexec.Exec(ctx, listCmd); err != nil {
+		return nil, errors.Wrapf(err, "listing %ss failed", kind)
+	}
+	ids := strings.Fields(listBuf.String())
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	inspectBuf := &bytes.Buffer{}
+	inspectCmd := exec.Docker(append([]string{kind, "inspect"}, ids...)...)
+	inspectCmd.Stdout = inspectBuf
+	if err := libexec.Exec(ctx, inspectCmd); err != nil {
+		return nil, errors.Wrapf(err, "inspecting %ss failed", kind)
+	}
+
+	// Labels of containers are nested in the config, while networks and volumes have them at the top level.
+	var info []struct {
+		ID     string `json:"Id"` //nolint:tagliatelle // `Id` is defined by docker
+		Name   string
+		Labels map[string]string
+		Config struct {
+			Labels map[string]string
+		}
+	}
+	if err := json.Unmarshal(inspectBuf.Bytes(), &info); err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling %s properties failed", kind)
+	}
+
+	resources := make([]EnvResource, 0, len(info))
+	for _, i := range info {
+		labels := i.Labels
+		if kind == ResourceContainer {
+			labels = i.Config.Labels
+		}
+		id := i.ID
+		if kind == ResourceVolume {
+			id = i.Name
+		}
+		resources = append(resources, EnvResource{
+			Kind:    kind,
+			ID:      id,
+			Name:    strings.TrimPrefix(i.Name, "/"),
+			Env:     labels[labelEnv],
+			HomeDir: labels[labelHome],
+			Host:    labels[labelHost],
+		})
+	}
+	return resources, nil
+}
+
+// RemoveEnvResource removes the resource. Containers are killed and their anonymous volumes are removed too.
+func RemoveEnvResource(ctx context.Context, resource EnvResource) error {
+	cmd := exec.Docker("volume", "rm", resource.ID)
+	switch resource.Kind {
+	case ResourceContainer:
+		cmd = exec.Docker("rm", "--force", "--volumes", resource.ID)
+	case ResourceNetwork:
+		devContainer, err := DetectDevContainer(ctx)
+		if err != nil {
+			return err
+		}
+		if err := devContainer.disconnectNetwork(ctx, resource.Name); err != nil {
+			return err
+		}
+		cmd = exec.Docker("network", "rm", resource.ID)
+	}
+	if err := libexec.Exec(ctx, noStdout(cmd)); err != nil {
+		return errors.Wrapf(err, "removing %s `%s` failed", resource.Kind, resource.Name)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package targets
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/crust/exec"
+	"github.com/CoreumFoundation/crust/infra"
+)
+
+// remoteCheckImage is the image used to verify that the home directory is shared with the remote docker host.
+const remoteCheckImage = "alpine:3.17.0"
+
+var remoteHome struct {
+	local  string
+	remote string
+}
+
+// SetRemoteHome sets the directory on the remote docker host where the local home directory of znet is available,
+// e.g. because it is mounted locally using sshfs or NFS. Paths of bind mounts are translated to that directory
+// if docker daemon runs on the remote host.
+func SetRemoteHome(localDir, remoteDir string) {
+	if resolved, err := filepath.EvalSymlinks(localDir); err == nil {
+		localDir = resolved
+	}
+	if abs, err := filepath.Abs(localDir); err == nil {
+		localDir = abs
+	}
+	remoteHome.local = localDir
+	remoteHome.remote = remoteDir
+}
+
+// RemoteHost returns the host running docker daemon if it is accessed over the network, using ssh:// or tcp://
+// endpoint. Empty string is returned if daemon runs locally.
+func (c DockerContext) RemoteHost() string {
+	u, err := url.Parse(c.Endpoint)
+	if err != nil || (u.Scheme != "ssh" && u.Scheme != "tcp") {
+		return ""
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return ""
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return ""
+	}
+	return host
+}
+
+// remotePath translates the local path to the one on the remote docker host.
+func remotePath(path string) (string, error) {
+	if remoteHome.remote == "" {
+		return "", errors.New("docker daemon runs on the remote host, so the home directory must be shared with it, " +
+			"pass its path on the remote host using --remote-home")
+	}
+	rel, err := filepath.Rel(remoteHome.local, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("directory %s is outside of the home directory %s shared with the remote docker host",
+			path, remoteHome.local)
+	}
+	return filepath.ToSlash(filepath.Join(remoteHome.remote, rel)), nil
+}
+
+// daemonPath returns the path of the local directory as seen by docker daemon, which differs if daemon runs
+// on the remote host or outside of the devcontainer.
+func daemonPath(dockerCtx DockerContext, devContainer DevContainer, path string) (string, error) {
+	if dockerCtx.RemoteHost() != "" {
+		return remotePath(path)
+	}
+	return devContainer.HostPath(path)
+}
+
+// publishAddress returns the address ports of the containers are published on. Ports published by remote docker
+// host must be reachable from the local one, so they are published on all the interfaces, the same as ports of apps
+// requiring it.
+func publishAddress(dockerCtx DockerContext, app infra.Deployment) string {
+	if dockerCtx.RemoteHost() != "" || app.PublishOnAllInterfaces {
+		return "0.0.0.0"
+	}
+	return "127.0.0.1"
+}
+
+// VerifyRemoteHome verifies that the directory is shared with the remote docker host, by writing random token
+// to the file and reading it from the container.
+func VerifyRemoteHome(ctx context.Context, dir string) error {
+	remoteDir, err := remotePath(dir)
+	if err != nil {
+		return err
+	}
+
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return errors.WithStack(err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+	tokenFile := filepath.Join(dir, ".remote-check")
+	if err := os.WriteFile(tokenFile, []byte(token), 0o600); err != nil {
+		return errors.WithStack(err)
+	}
+	defer os.Remove(tokenFile)
+
+	buf := &bytes.Buffer{}
+	runArgs := append([]string{"run", "--rm", "--network", "none"}, securityArgs()...)
+	cmd := exec.Docker(append(runArgs, "-v", remoteDir+":/check:ro", exec.QualifiedImage(remoteCheckImage),
+		"cat", "/check/.remote-check")...)
+	cmd.Stdout = buf
+	if err := libexec.Exec(ctx, cmd); err != nil || strings.TrimSpace(buf.String()) != token {
+		return errors.Errorf("directory %s is not shared with the remote docker host as %s, mount it e.g. "+
+			"using sshfs or NFS, or fix --remote-home", dir, remoteDir)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package targets
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/crust/exec"
+)
+
+// candidateSubnets returns subnets tried, in order, when looking for the free one. Ranges match the default address
+// pools of docker.
+func candidateSubnets() []*net.IPNet {
+	var subnets []*net.IPNet
+	for i := 18; i <= 31; i++ {
+		subnets = append(subnets, &net.IPNet{IP: net.IPv4(172, byte(i), 0, 0).To4(), Mask: net.CIDRMask(16, 32)})
+	}
+	for i := 0; i < 256; i += 16 {
+		subnets = append(subnets, &net.IPNet{IP: net.IPv4(192, 168, byte(i), 0).To4(), Mask: net.CIDRMask(20, 32)})
+	}
+	return subnets
+}
+
+// freeSubnet returns the subnet which doesn't collide with any network reachable from the host, including the ones
+// routed through VPN, nor with any docker network.
+func freeSubnet(ctx context.Context) (*net.IPNet, error) {
+	used, err := hostSubnets()
+	if err != nil {
+		return nil, err
+	}
+	dockerSubnets, err := dockerNetworkSubnets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return selectSubnet(candidateSubnets(), append(used, dockerSubnets...))
+}
+
+// selectSubnet returns the first candidate subnet which doesn't overlap with any of the used ones.
+func selectSubnet(candidates, used []*net.IPNet) (*net.IPNet, error) {
+	for _, candidate := range candidates {
+		if !overlapsAny(candidate, used) {
+			return candidate, nil
+		}
+	}
+	return nil, errors.New("no free subnet found, set the subnet explicitly")
+}
+
+func overlapsAny(subnet *net.IPNet, used []*net.IPNet) bool {
+	for _, u := range used {
+		if subnet.Contains(u.IP) || u.Contains(subnet.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostSubnets returns subnets of host network interfaces and, on linux, subnets of host routes.
+func hostSubnets() ([]*net.IPNet, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var subnets []*net.IPNet
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.To4() == nil {
+			continue
+		}
+		subnets = append(subnets, &net.IPNet{IP: ipNet.IP.Mask(ipNet.Mask), Mask: ipNet.Mask})
+	}
+
+	routes, err := routeSubnets("/proc/net/route")
+	if err != nil {
+		return nil, err
+	}
+	return append(subnets, routes...), nil
+}
+
+// routeSubnets parses routing table in the format of /proc/net/route. Default route is skipped.
+func routeSubnets(file string) ([]*net.IPNet, error) {
+	content, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var subnets []*net.IPNet
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	// First line is the header.
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 {
+			continue
+		}
+		dst, err := parseRouteIP(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		mask, err := parseRouteIP(fields[7])
+		if err != nil {
+			return nil, err
+		}
+		if ones, _ := net.IPMask(mask).Size(); ones == 0 {
+			continue
+		}
+		subnets = append(subnets, &net.IPNet{IP: dst, Mask: net.IPMask(mask)})
+	}
+	return subnets, errors.WithStack(scanner.Err())
+}
+
+// parseRouteIP parses IPv4 address stored in /proc/net/route as little-endian hex number.
+func parseRouteIP(value string) (net.IP, error) {
+	raw, err := hex.DecodeString(value)
+	if err != nil || len(raw) != net.IPv4len {
+		return nil, errors.Errorf("invalid address %q in routing table", value)
+	}
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, binary.LittleEndian.Uint32(raw))
+	return ip, nil
+}
+
+// dockerNetworkSubnets returns subnets used by existing docker networks.
+func dockerNetworkSubnets(ctx context.Context) ([]*net.IPNet, error) {
+	idsBuf := &bytes.Buffer{}
+	idsCmd := exec.Docker("network", "ls", "-q", "--no-trunc")
+	idsCmd.Stdout = idsBuf
+	if err := libexec.Exec(ctx, idsCmd); err != nil {
+		return nil, err
+	}
+	ids := strings.Fields(idsBuf.String())
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	format := "{{range .IPAM.Config}}{{.Subnet}} {{end}}"
+	if exec.IsPodman() {
+		// Podman reports subnets of the network in its own format.
+		format = "{{range .Subnets}}{{.Subnet}} {{end}}"
+	}
+	buf := &bytes.Buffer{}
+	cmd := exec.Docker(append([]string{"network", "inspect", "--format", format}, ids...)...)
+	cmd.Stdout = buf
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return nil, err
+	}
+	return parseDockerSubnets(buf.String())
+}
+
+// parseDockerSubnets parses space-separated list of subnets reported by docker. IPv6 subnets are skipped.
+func parseDockerSubnets(output string) ([]*net.IPNet, error) {
+	var subnets []*net.IPNet
+	for _, cidr := range strings.Fields(output) {
+		_, subnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid subnet %q of docker network", cidr)
+		}
+		if subnet.IP.To4() != nil {
+			subnets = append(subnets, subnet)
+		}
+	}
+	return subnets, nil
+}
@@ -0,0 +1,105 @@
+package targets
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectSubnet(t *testing.T) {
+	testCases := []struct {
+		name string
+		// dockerOutput is the list of subnets reported by docker for existing networks.
+		dockerOutput string
+		hostSubnets  []string
+		expected     string
+	}{
+		{
+			name:     "no networks",
+			expected: "172.18.0.0/16",
+		},
+		{
+			name:         "docker networks",
+			dockerOutput: "172.17.0.0/16 172.18.0.0/16 fd00:1::/64 172.19.0.0/16",
+			expected:     "172.20.0.0/16",
+		},
+		{
+			name:         "docker network inside candidate",
+			dockerOutput: "172.18.5.0/24",
+			expected:     "172.19.0.0/16",
+		},
+		{
+			name:         "docker network containing candidates",
+			dockerOutput: "172.16.0.0/12",
+			expected:     "192.168.0.0/20",
+		},
+		{
+			name:         "host and docker networks",
+			dockerOutput: "172.18.0.0/16",
+			hostSubnets:  []string{"172.19.0.0/16", "172.20.1.0/24"},
+			expected:     "172.21.0.0/16",
+		},
+		{
+			name:         "partially used 192.168",
+			dockerOutput: "172.16.0.0/12 192.168.0.0/20",
+			hostSubnets:  []string{"192.168.17.0/24"},
+			expected:     "192.168.32.0/20",
+		},
+		{
+			name:         "exhausted",
+			dockerOutput: "172.16.0.0/12",
+			hostSubnets:  []string{"192.168.0.0/16"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			used, err := parseDockerSubnets(tc.dockerOutput)
+			require.NoError(t, err)
+			used = append(used, lo.Map(tc.hostSubnets, func(cidr string, _ int) *net.IPNet {
+				_, subnet, err := net.ParseCIDR(cidr)
+				require.NoError(t, err)
+				return subnet
+			})...)
+
+			subnet, err := selectSubnet(candidateSubnets(), used)
+			if tc.expected == "" {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, subnet.String())
+		})
+	}
+}
+
+func TestParseDockerSubnetsRejectsInvalidSubnet(t *testing.T) {
+	_, err := parseDockerSubnets("172.18.0.0/16 invalid")
+	require.Error(t, err)
+}
+
+func TestRouteSubnets(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "route")
+	require.NoError(t, os.WriteFile(file, []byte(
+		"Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n"+
+			"eth0\t00000000\t0100A8C0\t0003\t0\t0\t100\t00000000\t0\t0\t0\n"+
+			"eth0\t0000A8C0\t00000000\t0001\t0\t0\t100\t00FFFFFF\t0\t0\t0\n"+
+			"tun0\t0000140A\t00000000\t0001\t0\t0\t0\t0000FFFF\t0\t0\t0\n",
+	), 0o600))
+
+	subnets, err := routeSubnets(file)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"192.168.0.0/24", "10.20.0.0/16"}, lo.Map(subnets, func(subnet *net.IPNet, _ int) string {
+		return subnet.String()
+	}))
+
+	subnets, err = routeSubnets(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, subnets)
+}
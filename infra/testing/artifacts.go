@@ -0,0 +1,253 @@
+package testing
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/types/query"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum/pkg/client"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+	"github.com/CoreumFoundation/crust/infra/apps/prometheus"
+	"github.com/CoreumFoundation/crust/infra/apps/relayercosmos"
+	"github.com/CoreumFoundation/crust/infra/targets"
+)
+
+// coredDumps are the RPC endpoints of cored which responses are stored as artifacts.
+var coredDumps = []string{"status", "net_info", "dump_consensus_state"}
+
+// summaryLogLines is the number of the last log lines of each app included in the summary.
+const summaryLogLines = 50
+
+// chainApp is the app of the blockchain, which state is included in the summary.
+type chainApp interface {
+	Name() string
+	Info() infra.DeploymentInfo
+	ClientContext() client.Context
+}
+
+// collectArtifacts stores the data useful to investigate failure of the test group in the timestamped directory
+// under the home directory of the environment. Collection is done on best-effort basis, failures are only logged.
+// Docker events emitted since the start of the group are included.
+func collectArtifacts(
+	ctx context.Context,
+	appSet infra.AppSet,
+	config infra.Config,
+	group string,
+	since time.Time,
+) (string, error) {
+	dir := filepath.Join(config.HomeDir, "artifacts", time.Now().UTC().Format("20060102-150405")+"-"+group)
+	for _, subDir := range []string{"logs", "cored", "relayer", "monitoring"} {
+		if err := os.MkdirAll(filepath.Join(dir, subDir), 0o700); err != nil {
+			return "", errors.WithStack(err)
+		}
+	}
+
+	log := logger.Get(ctx).With(zap.String("dir", dir))
+	logFailure := func(artifact string, err error) {
+		if err != nil {
+			log.Warn("Collecting artifact failed", zap.String("artifact", artifact), zap.Error(err))
+		}
+	}
+
+	logFailure("spec.json", copyArtifact(filepath.Join(config.HomeDir, "spec.json"), filepath.Join(dir, "spec.json")))
+	if config.RecordTraffic {
+		logFailure("traffic", copyArtifact(trafficFile(config, group), filepath.Join(dir, "traffic.jsonl")))
+	}
+	logFailure("docker events", targets.SaveDockerEvents(ctx, config.EnvName, since,
+		filepath.Join(dir, "docker-events.jsonl")))
+	for _, app := range appSet {
+		if container := app.Info().Container; container != "" {
+			logFailure(app.Name()+" logs", targets.SaveContainerLogs(ctx, container,
+				filepath.Join(dir, "logs", app.Name()+".log")))
+		}
+		if app.Info().Status != infra.AppStatusRunning {
+			continue
+		}
+
+		switch a := app.(type) {
+		case cored.Cored:
+			for _, dump := range coredDumps {
+				url := fmt.Sprintf("http://%s/%s", infra.JoinNetAddr("", a.Info().HostFromHost, a.Config().Ports.RPC), dump)
+				logFailure(a.Name()+" "+dump, downloadArtifact(ctx, url,
+					filepath.Join(dir, "cored", a.Name()+"-"+dump+".json")))
+			}
+		case relayercosmos.Relayer:
+			url := fmt.Sprintf("http://%s/relayer/metrics", infra.JoinNetAddr("", a.Info().HostFromHost, a.DebugPort()))
+			logFailure(a.Name()+" metrics", downloadArtifact(ctx, url, filepath.Join(dir, "relayer", a.Name()+".metrics")))
+
+			status := "healthy\n"
+			if err := a.HealthCheck(ctx); err != nil {
+				status = fmt.Sprintf("unhealthy: %s\n", err)
+			}
+			logFailure(a.Name()+" status", errors.WithStack(os.WriteFile(
+				filepath.Join(dir, "relayer", a.Name()+".status"), []byte(status), 0o600)))
+		case prometheus.Prometheus:
+			url := fmt.Sprintf("http://%s/api/v1/alerts", infra.JoinNetAddr("", a.Info().HostFromHost, a.DataSourcePort()))
+			logFailure(a.Name()+" alerts", downloadArtifact(ctx, url, filepath.Join(dir, "monitoring", "alerts.json")))
+		}
+	}
+	logFailure("summary", saveSummary(ctx, appSet, dir))
+	return dir, nil
+}
+
+// saveSummary stores the overview of the environment: status of the apps, block heights and pending IBC packets
+// of the chains, and the last log lines of each app, so the failure may be investigated without going through
+// all the artifacts.
+func saveSummary(ctx context.Context, appSet infra.AppSet, dir string) error {
+	apps := append(infra.AppSet{}, appSet...)
+	sort.Slice(apps, func(i, j int) bool {
+		return apps[i].Name() < apps[j].Name()
+	})
+
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APPLICATION\tTYPE\tSTATUS\tCONTAINER")
+	for _, app := range apps {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", app.Name(), app.Type(), app.Info().Status, app.Info().Container)
+	}
+
+	fmt.Fprintln(w, "\nCHAIN\tHEIGHT\tCATCHING UP")
+	var chains []chainApp
+	for _, app := range apps {
+		chain, ok := app.(chainApp)
+		if !ok || app.Info().Status != infra.AppStatusRunning {
+			continue
+		}
+		chains = append(chains, chain)
+
+		requestCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		status, err := chain.ClientContext().RPCClient().Status(requestCtx)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(w, "%s\terror: %s\t\n", chain.Name(), err)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%d\t%t\n", chain.Name(), status.SyncInfo.LatestBlockHeight, status.SyncInfo.CatchingUp)
+	}
+
+	fmt.Fprintln(w, "\nCHAIN\tCHANNEL\tPENDING PACKETS")
+	for _, chain := range chains {
+		if err := writePendingIBCPackets(ctx, w, chain); err != nil {
+			fmt.Fprintf(w, "%s\terror: %s\t\n", chain.Name(), err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, app := range apps {
+		lines, err := tailFile(filepath.Join(dir, "logs", app.Name()+".log"), summaryLogLines)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		fmt.Fprintf(buf, "\n===== Last %d log lines of %s =====\n", summaryLogLines, app.Name())
+		for _, line := range lines {
+			fmt.Fprintln(buf, line)
+		}
+	}
+
+	return errors.WithStack(os.WriteFile(filepath.Join(dir, "summary.txt"), buf.Bytes(), 0o600))
+}
+
+// writePendingIBCPackets writes the number of packets sent through each IBC channel of the chain which haven't been
+// acknowledged yet.
+func writePendingIBCPackets(ctx context.Context, w io.Writer, chain chainApp) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	queryClient := channeltypes.NewQueryClient(chain.ClientContext())
+	channels, err := queryClient.Channels(ctx, &channeltypes.QueryChannelsRequest{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, channel := range channels.Channels {
+		commitments, err := queryClient.PacketCommitments(ctx, &channeltypes.QueryPacketCommitmentsRequest{
+			PortId:     channel.PortId,
+			ChannelId:  channel.ChannelId,
+			Pagination: &query.PageRequest{Limit: 1, CountTotal: true},
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		var pending uint64
+		if commitments.Pagination != nil {
+			pending = commitments.Pagination.Total
+		}
+		fmt.Fprintf(w, "%s\t%s/%s\t%d\n", chain.Name(), channel.PortId, channel.ChannelId, pending)
+	}
+	return nil
+}
+
+// tailFile returns the last n lines of the file.
+func tailFile(file string, n int) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close()
+
+	lines := make([]string, 0, n)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(lines) == n {
+			lines = lines[1:]
+		}
+		lines = append(lines, scanner.Text())
+	}
+	return lines, errors.WithStack(scanner.Err())
+}
+
+func copyArtifact(src, dst string) error {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(dst, content, 0o600))
+}
+
+func downloadArtifact(ctx context.Context, url, dst string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status code %d received from %s", resp.StatusCode, url)
+	}
+
+	f, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, resp.Body)
+	return errors.WithStack(err)
+}
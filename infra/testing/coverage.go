@@ -0,0 +1,177 @@
+package testing
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/crust/infra"
+)
+
+// coverageGroupDir returns the directory where test binary of the group stores raw coverage data.
+func coverageGroupDir(config infra.Config, group string) string {
+	return filepath.Join(config.CoverageDir, "raw", group)
+}
+
+// coverageArgs returns arguments instructing the test binary to store coverage data in the directory of the group.
+// It is an equivalent of setting GOCOVERDIR for binaries built with -cover.
+func coverageArgs(config infra.Config, group string) ([]string, error) {
+	if config.CoverageDir == "" {
+		return nil, nil
+	}
+	dir, err := filepath.Abs(coverageGroupDir(config, group))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return []string{"-test.gocoverdir", dir}, nil
+}
+
+// mergeCoverage merges coverage data produced by test groups into single profile and generates HTML
+// and lcov reports from it.
+func mergeCoverage(ctx context.Context, config infra.Config, groups []string) error {
+	inputs := make([]string, 0, len(groups))
+	for _, group := range groups {
+		dir := coverageGroupDir(config, group)
+		files, err := os.ReadDir(dir)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return errors.WithStack(err)
+		}
+		if len(files) == 0 {
+			logger.Get(ctx).Warn("No coverage data produced by test group, was the binary built with -cover?",
+				zap.String("group", group))
+			continue
+		}
+		inputs = append(inputs, dir)
+	}
+	if len(inputs) == 0 {
+		return nil
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		// Tests may be executed on the machine without Go toolchain, e.g. by the integration-tests image,
+		// raw data is kept, so it may be merged elsewhere using `go tool covdata`.
+		logger.Get(ctx).Warn("Go toolchain not found, raw coverage data is not merged",
+			zap.String("dir", filepath.Join(config.CoverageDir, "raw")))
+		return nil
+	}
+
+	profile := filepath.Join(config.CoverageDir, "coverage.out")
+	if err := libexec.Exec(ctx, exec.Command("go", "tool", "covdata", "textfmt",
+		"-i", strings.Join(inputs, ","), "-o", profile)); err != nil {
+		return errors.Wrap(err, "merging coverage data failed")
+	}
+	if err := saveLCOV(profile, filepath.Join(config.CoverageDir, "lcov.info")); err != nil {
+		return err
+	}
+
+	// HTML report requires source code of the covered packages to be available, so it is done on best-effort basis.
+	if err := libexec.Exec(ctx, exec.Command("go", "tool", "cover", "-html", profile, "-o",
+		filepath.Join(config.CoverageDir, "coverage.html"))); err != nil {
+		logger.Get(ctx).Warn("Generating HTML coverage report failed", zap.Error(err))
+	}
+	return nil
+}
+
+// lcovFile collects execution counts of lines in a single source file.
+type lcovFile struct {
+	lines map[int]int
+}
+
+// saveLCOV converts Go coverage profile to lcov format.
+func saveLCOV(profile, dst string) error {
+	f, err := os.Open(profile)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	files := map[string]*lcovFile{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "mode:") || line == "" {
+			continue
+		}
+
+		// Line format is: <file>:<startLine>.<startCol>,<endLine>.<endCol> <statements> <count>
+		file, block, found := strings.Cut(line, ":")
+		if !found {
+			return errors.Errorf("invalid coverage profile line: %s", line)
+		}
+		fields := strings.Fields(block)
+		if len(fields) != 3 {
+			return errors.Errorf("invalid coverage profile line: %s", line)
+		}
+		start, end, found := strings.Cut(fields[0], ",")
+		if !found {
+			return errors.Errorf("invalid coverage profile line: %s", line)
+		}
+		startLine, err := strconv.Atoi(strings.Split(start, ".")[0])
+		if err != nil {
+			return errors.Wrapf(err, "invalid coverage profile line: %s", line)
+		}
+		endLine, err := strconv.Atoi(strings.Split(end, ".")[0])
+		if err != nil {
+			return errors.Wrapf(err, "invalid coverage profile line: %s", line)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return errors.Wrapf(err, "invalid coverage profile line: %s", line)
+		}
+
+		lf := files[file]
+		if lf == nil {
+			lf = &lcovFile{lines: map[int]int{}}
+			files[file] = lf
+		}
+		for l := startLine; l <= endLine; l++ {
+			lf.lines[l] += count
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := &strings.Builder{}
+	for _, name := range names {
+		lf := files[name]
+		lines := make([]int, 0, len(lf.lines))
+		for l := range lf.lines {
+			lines = append(lines, l)
+		}
+		sort.Ints(lines)
+
+		var hit int
+		fmt.Fprintf(buf, "SF:%s\n", name)
+		for _, l := range lines {
+			fmt.Fprintf(buf, "DA:%d,%d\n", l, lf.lines[l])
+			if lf.lines[l] > 0 {
+				hit++
+			}
+		}
+		fmt.Fprintf(buf, "LF:%d\nLH:%d\nend_of_record\n", len(lines), hit)
+	}
+	return errors.WithStack(os.WriteFile(dst, []byte(buf.String()), 0o600))
+}
@@ -0,0 +1,108 @@
+package testing
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/faucet"
+)
+
+// ExternalNetwork describes existing network, like devnet or testnet, tests are executed against.
+type ExternalNetwork struct {
+	// ChainID is the chain ID of the network
+	ChainID string `yaml:"chainId"`
+
+	// GRPCAddress is the address of gRPC endpoint of cored node, in form of host:port
+	GRPCAddress string `yaml:"grpcAddress"`
+
+	// FundingMnemonic is the mnemonic of the account, holding enough funds, used by tests to fund accounts
+	FundingMnemonic string `yaml:"fundingMnemonic"`
+
+	// StakerMnemonics are the mnemonics of accounts used by staking tests
+	StakerMnemonics []string `yaml:"stakerMnemonics"`
+
+	// FaucetURL is the URL of the faucet, faucet tests are skipped if it is not set
+	FaucetURL string `yaml:"faucetURL"`
+
+	// FaucetTransferAmount is the amount of tokens transferred by the faucet on each request
+	FaucetTransferAmount int64 `yaml:"faucetTransferAmount"`
+}
+
+// LoadExternalNetwork loads description of the external network from the YAML file.
+func LoadExternalNetwork(file string) (ExternalNetwork, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return ExternalNetwork{}, errors.WithStack(err)
+	}
+
+	var network ExternalNetwork
+	if err := yaml.Unmarshal(content, &network); err != nil {
+		return ExternalNetwork{}, errors.Wrapf(err, "parsing network file %s failed", file)
+	}
+
+	switch {
+	case network.ChainID == "":
+		return ExternalNetwork{}, errors.Errorf("chainId is not set in network file %s", file)
+	case network.GRPCAddress == "":
+		return ExternalNetwork{}, errors.Errorf("grpcAddress is not set in network file %s", file)
+	case network.FundingMnemonic == "":
+		return ExternalNetwork{}, errors.Errorf("fundingMnemonic is not set in network file %s", file)
+	}
+	if network.FaucetTransferAmount == 0 {
+		network.FaucetTransferAmount = faucet.DefaultTransferAmount
+	}
+	return network, nil
+}
+
+// RunExternal runs tests against the existing network, without deploying anything locally. Groups requiring
+// dedicated environment, like upgrade tests, are never executed because they would break the network. Groups are executed sequentially because they share
+// the funding account.
+func RunExternal(ctx context.Context, network ExternalNetwork, config infra.Config, onlyTestGroups ...string) error {
+	var unsupported []string
+	for group, tg := range testGroups {
+		if tg.Dedicated {
+			unsupported = append(unsupported, group)
+		}
+	}
+	if network.FaucetURL == "" {
+		unsupported = append(unsupported, groupFaucet)
+	}
+
+	testDir, batches, err := selectTestGroups(config, onlyTestGroups, unsupported)
+	if err != nil {
+		return err
+	}
+
+	logger.Get(ctx).Info("Running tests against external network", zap.String("chainID", network.ChainID),
+		zap.String("grpcAddress", network.GRPCAddress))
+
+	args := commonTestArgs(ctx, config, network.GRPCAddress)
+	return runTestGroups(ctx, config, testDir, batches, 1,
+		func(ctx context.Context, group string) (groupEnv, error) {
+			fullArgs := append([]string{}, args...)
+			switch group {
+			case groupCoreumModules:
+				fullArgs = append(fullArgs,
+					"-chain-id", network.ChainID,
+					"-log-format", config.LogFormat,
+					"-funding-mnemonic", network.FundingMnemonic,
+				)
+				for _, mnemonic := range network.StakerMnemonics {
+					fullArgs = append(fullArgs, "-staker-mnemonic", mnemonic)
+				}
+			case groupFaucet:
+				fullArgs = append(fullArgs,
+					"-transfer-amount", strconv.FormatInt(network.FaucetTransferAmount, 10),
+					"-faucet-address", network.FaucetURL,
+				)
+			}
+			return groupEnv{Args: fullArgs}, nil
+		})
+}
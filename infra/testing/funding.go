@@ -0,0 +1,111 @@
+package testing
+
+import (
+	"context"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/cosmos/go-bip39"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+	"github.com/CoreumFoundation/crust/infra/apps/faucet"
+)
+
+// testAccountLeaseTTL is the time after which account leased by the test group returns to the pool if it hasn't been
+// released, e.g. because znet has been killed.
+const testAccountLeaseTTL = 2 * time.Hour
+
+// testGroupFaucetRequests is the number of faucet requests funding the account of the test group, so it receives enough
+// tokens to fund accounts created by all the tests in the group.
+const testGroupFaucetRequests = 10
+
+// accountLeaser leases accounts from the pool for test groups and releases them once tests finish.
+type accountLeaser struct {
+	spec      *infra.Spec
+	addresses []string
+}
+
+func (l *accountLeaser) lease() (infra.PoolAccount, error) {
+	account, err := l.spec.LeaseAccount(testAccountLeaseTTL)
+	if err != nil {
+		return infra.PoolAccount{}, err
+	}
+	l.addresses = append(l.addresses, account.Address)
+	return account, nil
+}
+
+func (l *accountLeaser) releaseAll(ctx context.Context) {
+	for _, address := range l.addresses {
+		if err := l.spec.ReleaseAccount(address); err != nil {
+			logger.Get(ctx).Warn("Releasing pool account failed", zap.String("address", address), zap.Error(err))
+		}
+	}
+	l.addresses = nil
+}
+
+// fundingMnemonic returns mnemonic of the account used by test group to fund accounts created by tests.
+// If account pool is configured, account is leased from the pool for each group, so groups running in parallel,
+// even from different processes, don't compete for the sequence of the same account.
+// If faucet is running, new account is created for each group and funded using several faucet requests, so groups
+// don't compete for the sequence of the same account and the faucet is exercised on each run. Otherwise, the funding
+// account stored in genesis is used directly, so groups can't be executed in parallel then.
+func fundingMnemonic(
+	ctx context.Context,
+	appSet infra.AppSet,
+	leaser *accountLeaser,
+	coredNode cored.Cored,
+	parallel bool,
+) (string, error) {
+	if len(leaser.spec.AccountPool) > 0 {
+		account, err := leaser.lease()
+		if err != nil {
+			return "", err
+		}
+		logger.Get(ctx).Info("Using account leased from the pool", zap.String("address", account.Address))
+		return account.Mnemonic, nil
+	}
+
+	faucetApp := appSet.FindRunningApp(faucet.AppType, "faucet")
+	if faucetApp == nil {
+		if parallel {
+			return "", errors.New("test groups executed in parallel are funded using the faucet, " +
+				"but it is not running, start the environment with faucet profile")
+		}
+		return coredNode.Config().FundingMnemonic, nil
+	}
+	faucetNode := faucetApp.(faucet.Faucet)
+	if denom := coredNode.Config().Network.Denom(); faucetNode.Denom() != denom {
+		return "", errors.Errorf("faucet transfers %s, while test groups are funded with %s, don't set faucet denom "+
+			"when running integration tests", faucetNode.Denom(), denom)
+	}
+
+	entropy, err := bip39.NewEntropy(256)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	privKey, err := cored.PrivateKeyFromMnemonic(mnemonic)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	address, err := bech32.ConvertAndEncode(coredNode.Config().Network.AddressPrefix(), privKey.PubKey().Address())
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	logger.Get(ctx).Info("Funding test account using faucet", zap.String("address", address),
+		zap.Int("requests", testGroupFaucetRequests), zap.Int64("amount", faucetNode.TransferAmount()))
+	for i := 0; i < testGroupFaucetRequests; i++ {
+		if err := faucetNode.Fund(ctx, address); err != nil {
+			return "", err
+		}
+	}
+	return mnemonic, nil
+}
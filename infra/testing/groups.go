@@ -0,0 +1,136 @@
+package testing
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const (
+	groupCoreumModules = "coreum-modules"
+	groupCoreumUpgrade = "coreum-upgrade"
+	groupFaucet        = "faucet"
+
+	// upgradeFromVersion is the version of cored the chain is started with by upgrade tests.
+	upgradeFromVersion = "v0.1.1"
+)
+
+// testGroup describes requirements of a test group regarding its execution order and environment.
+type testGroup struct {
+	// DependsOn lists the groups which must complete before this one is started. Dependencies which are not selected
+	// to run are ignored.
+	DependsOn []string
+
+	// Isolated means that the group affects the chain in a way which might break other groups (e.g. upgrades it),
+	// so no other group is executed together with it.
+	Isolated bool
+
+	// CoredVersion is the version of cored the chain must be started with, empty means the current one.
+	CoredVersion string
+
+	// Dedicated means that the group is executed in the fresh environment created for it and removed once it
+	// completes, so destructive tests (e.g. upgrading or halting the chain) don't affect the shared environment.
+	// Dedicated groups are always isolated.
+	Dedicated bool
+
+	// Profiles lists the profiles deployed in the dedicated environment in addition to the ones of the shared
+	// environment.
+	Profiles []string
+}
+
+// testGroups declares the known test groups. Groups which are not listed here have no requirements.
+var testGroups = map[string]testGroup{
+	// Faucet tests verify the transfers done by the faucet, while groups executed in parallel are funded by it,
+	// so no other group is executed together with them.
+	groupFaucet: {
+		Isolated: true,
+	},
+	// When executed in parallel, accounts of the group are funded by the faucet, so it is verified first.
+	groupCoreumModules: {
+		DependsOn: []string{groupFaucet},
+	},
+	// Upgrade tests start the chain using the old version of cored and upgrade it, so they are executed
+	// in the dedicated environment.
+	groupCoreumUpgrade: {
+		Dedicated:    true,
+		CoredVersion: upgradeFromVersion,
+	},
+}
+
+// TestGroupInfo describes the test group and the environment it requires.
+type TestGroupInfo struct {
+	Name         string
+	CoredVersion string
+}
+
+// ListTestGroups returns known test groups together with the ones having built test binaries, sorted by name.
+func ListTestGroups(binDir string) ([]TestGroupInfo, error) {
+	names := lo.Keys(testGroups)
+	files, err := os.ReadDir(filepath.Join(binDir, ".cache", "integration-tests"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, errors.WithStack(err)
+	}
+	for _, f := range files {
+		if !f.IsDir() && !lo.Contains(names, f.Name()) {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Strings(names)
+
+	return lo.Map(names, func(name string, _ int) TestGroupInfo {
+		return TestGroupInfo{
+			Name:         name,
+			CoredVersion: testGroups[name].CoredVersion,
+		}
+	}), nil
+}
+
+// orderTestGroups sorts test groups topologically using their dependencies. Groups are returned in batches.
+// All the groups in a batch may be executed once all the previous batches are completed. Isolated groups always
+// form batches on their own.
+func orderTestGroups(groups []string) ([][]string, error) {
+	pending := map[string][]string{}
+	for _, g := range groups {
+		pending[g] = lo.Filter(testGroups[g].DependsOn, func(dep string, _ int) bool {
+			return lo.Contains(groups, dep)
+		})
+	}
+
+	batches := [][]string{}
+	for len(pending) > 0 {
+		ready := []string{}
+		for g, deps := range pending {
+			if len(deps) == 0 {
+				ready = append(ready, g)
+			}
+		}
+		if len(ready) == 0 {
+			cycle := lo.Keys(pending)
+			sort.Strings(cycle)
+			return nil, errors.Errorf("test groups have circular dependencies: %s", strings.Join(cycle, ", "))
+		}
+		sort.Strings(ready)
+
+		// Isolated groups are taken one by one, before the regular ones.
+		batch := ready
+		for _, g := range ready {
+			if testGroups[g].Isolated || testGroups[g].Dedicated {
+				batch = []string{g}
+				break
+			}
+		}
+
+		for _, g := range batch {
+			delete(pending, g)
+		}
+		for g, deps := range pending {
+			pending[g] = lo.Without(deps, batch...)
+		}
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
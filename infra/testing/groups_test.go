@@ -0,0 +1,102 @@
+package testing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderTestGroups(t *testing.T) {
+	testCases := []struct {
+		name     string
+		groups   map[string]testGroup
+		selected []string
+		expected [][]string
+		err      string
+	}{
+		{
+			name:     "independent",
+			selected: []string{"b", "a", "c"},
+			expected: [][]string{{"a", "b", "c"}},
+		},
+		{
+			name: "dependencies",
+			groups: map[string]testGroup{
+				"a": {DependsOn: []string{"b"}},
+				"b": {DependsOn: []string{"c"}},
+			},
+			selected: []string{"a", "b", "c", "d"},
+			expected: [][]string{{"c", "d"}, {"b"}, {"a"}},
+		},
+		{
+			name: "unselected dependency",
+			groups: map[string]testGroup{
+				"a": {DependsOn: []string{"b"}},
+			},
+			selected: []string{"a", "c"},
+			expected: [][]string{{"a", "c"}},
+		},
+		{
+			name: "isolated first",
+			groups: map[string]testGroup{
+				"b": {Isolated: true},
+				"c": {Dedicated: true},
+			},
+			selected: []string{"a", "b", "c", "d"},
+			expected: [][]string{{"b"}, {"c"}, {"a", "d"}},
+		},
+		{
+			name: "isolated after dependency",
+			groups: map[string]testGroup{
+				"a": {DependsOn: []string{"b"}},
+				"b": {Isolated: true},
+			},
+			selected: []string{"a", "b", "c"},
+			expected: [][]string{{"b"}, {"a", "c"}},
+		},
+		{
+			name: "cycle",
+			groups: map[string]testGroup{
+				"a": {DependsOn: []string{"b"}},
+				"b": {DependsOn: []string{"c"}},
+				"c": {DependsOn: []string{"a"}},
+			},
+			selected: []string{"a", "b", "c", "d"},
+			err:      "test groups have circular dependencies: a, b, c",
+		},
+		{
+			name: "self dependency",
+			groups: map[string]testGroup{
+				"a": {DependsOn: []string{"a"}},
+			},
+			selected: []string{"a"},
+			err:      "test groups have circular dependencies: a",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			knownGroups := testGroups
+			testGroups = tc.groups
+			t.Cleanup(func() {
+				testGroups = knownGroups
+			})
+
+			batches, err := orderTestGroups(tc.selected)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, batches)
+		})
+	}
+}
+
+func TestOrderKnownTestGroups(t *testing.T) {
+	batches, err := orderTestGroups([]string{groupCoreumModules, groupCoreumUpgrade, groupFaucet})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{groupCoreumUpgrade}, {groupFaucet}, {groupCoreumModules}}, batches)
+}
@@ -0,0 +1,80 @@
+package testing
+
+import (
+	"context"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	icacontrollertypes "github.com/cosmos/ibc-go/v4/modules/apps/27-interchain-accounts/controller/types"
+	icatypes "github.com/cosmos/ibc-go/v4/modules/apps/27-interchain-accounts/types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/CoreumFoundation/coreum/pkg/client"
+)
+
+// ICAControllerPortID returns the port of the controller chain bound to the interchain account of the owner.
+func ICAControllerPortID(owner string) (string, error) {
+	portID, err := icatypes.NewControllerPortID(owner)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return portID, nil
+}
+
+// ICAChannelVersion returns the version of the channel opened to register interchain account, containing the default
+// metadata expected by the host.
+func ICAChannelVersion(controllerConnectionID, hostConnectionID string) string {
+	return icatypes.NewDefaultMetadataString(controllerConnectionID, hostConnectionID)
+}
+
+// ICAHostAddress returns the address of the interchain account created by the host chain for the controller port
+// on the connection, so tests may fund it before the account is registered.
+func ICAHostAddress(hostAddressPrefix, hostConnectionID, controllerPortID string) (string, error) {
+	address := icatypes.GenerateAddress(authtypes.NewModuleAddress(icatypes.ModuleName), hostConnectionID,
+		controllerPortID)
+	result, err := bech32.ConvertAndEncode(hostAddressPrefix, address)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return result, nil
+}
+
+// ICAPacketData returns the packet data sent by the controller chain to execute messages by the interchain account
+// on the host chain. Signers of the messages must be set to the address of the interchain account.
+func ICAPacketData(msgs []sdk.Msg, memo string) (icatypes.InterchainAccountPacketData, error) {
+	data, err := icatypes.SerializeCosmosTx(codec.NewProtoCodec(codectypes.NewInterfaceRegistry()), msgs)
+	if err != nil {
+		return icatypes.InterchainAccountPacketData{}, errors.WithStack(err)
+	}
+	packetData := icatypes.InterchainAccountPacketData{
+		Type: icatypes.EXECUTE_TX,
+		Data: data,
+		Memo: memo,
+	}
+	if err := packetData.ValidateBasic(); err != nil {
+		return icatypes.InterchainAccountPacketData{}, errors.WithStack(err)
+	}
+	return packetData, nil
+}
+
+// QueryInterchainAccount returns the address of the interchain account registered by the owner on the controller
+// chain, it fails if the chain doesn't include interchain accounts controller module.
+func QueryInterchainAccount(ctx context.Context, clientCtx client.Context, owner, connectionID string) (string, error) {
+	res, err := icacontrollertypes.NewQueryClient(clientCtx).InterchainAccount(ctx,
+		&icacontrollertypes.QueryInterchainAccountRequest{
+			Owner:        owner,
+			ConnectionId: connectionID,
+		})
+	if status.Code(err) == codes.Unimplemented {
+		return "", errors.Errorf("chain %s doesn't support interchain accounts controller", clientCtx.ChainID())
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "querying interchain account of %s failed", owner)
+	}
+	return res.Address, nil
+}
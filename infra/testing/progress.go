@@ -0,0 +1,166 @@
+package testing
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum-tools/pkg/parallel"
+	"github.com/CoreumFoundation/crust/pkg/test2json"
+)
+
+// testEvent is the event produced by test2json, see `go doc test2json`.
+type testEvent struct {
+	Time    time.Time
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// testProgress tracks the state of tests executed by a test binary.
+type testProgress struct {
+	group   string
+	verbose bool
+	results []testResult
+	running map[string]struct{}
+	output  map[string][]string
+	passed  int
+	failed  int
+	skipped int
+}
+
+// runTestBinary executes test binary, converts its output to test2json events and streams progress of the tests to
+// the log. Results of the executed tests are returned. Conversion is done in-process, so Go toolchain is not required.
+func runTestBinary(ctx context.Context, group, binPath string, args []string, verbose bool) ([]testResult, error) {
+	cmd := exec.Command(binPath, append([]string{"-test.v=test2json"}, args...)...)
+
+	pr, pw := io.Pipe()
+
+	progress := &testProgress{
+		group:   group,
+		verbose: verbose,
+		running: map[string]struct{}{},
+		output:  map[string][]string{},
+	}
+	err := parallel.Run(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
+		spawn("tests", parallel.Continue, func(ctx context.Context) error {
+			defer pw.Close()
+
+			// Converter writes the start event immediately, so it is created once the pipe is consumed.
+			converter := test2json.NewConverter(pw, group, test2json.Timestamp)
+			// Like in test2json, stderr is converted too, exec ensures they are not written concurrently.
+			cmd.Stdout = converter
+			cmd.Stderr = converter
+
+			err := libexec.Exec(ctx, cmd)
+			converter.Exited(err)
+			if closeErr := converter.Close(); closeErr != nil && err == nil {
+				err = errors.WithStack(closeErr)
+			}
+			return err
+		})
+		spawn("progress", parallel.Continue, func(ctx context.Context) error {
+			defer pr.Close()
+			return progress.consume(ctx, pr)
+		})
+		return nil
+	})
+	return progress.results, err
+}
+
+func (p *testProgress) consume(ctx context.Context, r io.Reader) error {
+	log := logger.Get(ctx)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var event testEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			// Binary printed something not being a test2json event, pass it through.
+			log.Info(scanner.Text())
+			continue
+		}
+		p.handle(log, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	log.Info("Tests finished", zap.Int("passed", p.passed), zap.Int("failed", p.failed),
+		zap.Int("skipped", p.skipped))
+	return nil
+}
+
+func (p *testProgress) handle(log *zap.Logger, event testEvent) {
+	if event.Test == "" {
+		// Package-level events are reported once the binary exits.
+		if event.Action == "output" && p.verbose {
+			log.Info(strings.TrimRight(event.Output, "\n"))
+		}
+		return
+	}
+
+	progressLog := log
+	log = log.With(zap.String("test", event.Test))
+	switch event.Action {
+	case "run":
+		p.running[event.Test] = struct{}{}
+		log.Info("Test started")
+	case "output":
+		if p.verbose {
+			log.Info(strings.TrimRight(event.Output, "\n"))
+		}
+		p.output[event.Test] = append(p.output[event.Test], event.Output)
+	case "pass", "fail", "skip":
+		delete(p.running, event.Test)
+		duration := time.Duration(event.Elapsed * float64(time.Second))
+		elapsed := zap.Duration("elapsed", duration)
+		result := testResult{
+			Group:    p.group,
+			Name:     event.Test,
+			Duration: duration,
+			LogTail:  logTail(p.output[event.Test]),
+		}
+		switch event.Action {
+		case "pass":
+			p.passed++
+			result.Status = statusPassed
+			log.Info("Test passed", elapsed)
+		case "skip":
+			p.skipped++
+			result.Status = statusSkipped
+			log.Info("Test skipped", elapsed)
+		case "fail":
+			p.failed++
+			result.Status = statusFailed
+			if p.verbose {
+				// Output has been already printed.
+				log.Error("Test failed", elapsed)
+			} else {
+				log.Error("Test failed", elapsed, zap.String("output", strings.Join(p.output[event.Test], "")))
+			}
+		}
+		p.results = append(p.results, result)
+		delete(p.output, event.Test)
+		progressLog.Info("Progress", zap.Int("running", len(p.running)), zap.Int("passed", p.passed),
+			zap.Int("failed", p.failed), zap.Int("skipped", p.skipped))
+	}
+}
+
+// logTail returns last logTailLines lines of the output.
+func logTail(output []string) string {
+	if len(output) > logTailLines {
+		output = output[len(output)-logTailLines:]
+	}
+	return strings.Join(output, "")
+}
@@ -0,0 +1,154 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/crust/pkg/test2json"
+)
+
+func TestProgressConsume(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		expected []testResult
+	}{
+		{
+			name:   "empty",
+			output: "",
+		},
+		{
+			name: "results",
+			output: `=== RUN   TestPass
+    pass_test.go:10: passing
+--- PASS: TestPass (1.50s)
+=== RUN   TestFail
+    fail_test.go:20: first
+    fail_test.go:21: second
+--- FAIL: TestFail (0.25s)
+=== RUN   TestSkip
+    skip_test.go:30: not supported
+--- SKIP: TestSkip (0.00s)
+FAIL
+`,
+			expected: []testResult{
+				{
+					Group:    "group",
+					Name:     "TestPass",
+					Status:   statusPassed,
+					Duration: 1500 * time.Millisecond,
+					LogTail:  "=== RUN   TestPass\n    pass_test.go:10: passing\n--- PASS: TestPass (1.50s)\n",
+				},
+				{
+					Group:    "group",
+					Name:     "TestFail",
+					Status:   statusFailed,
+					Duration: 250 * time.Millisecond,
+					LogTail: "=== RUN   TestFail\n    fail_test.go:20: first\n    fail_test.go:21: second\n" +
+						"--- FAIL: TestFail (0.25s)\n",
+				},
+				{
+					Group:   "group",
+					Name:    "TestSkip",
+					Status:  statusSkipped,
+					LogTail: "=== RUN   TestSkip\n    skip_test.go:30: not supported\n--- SKIP: TestSkip (0.00s)\n",
+				},
+			},
+		},
+		{
+			name: "subtests",
+			output: `=== RUN   TestParent
+=== RUN   TestParent/child
+--- PASS: TestParent (2.00s)
+    --- PASS: TestParent/child (1.00s)
+PASS
+`,
+			expected: []testResult{
+				{
+					Group:    "group",
+					Name:     "TestParent/child",
+					Status:   statusPassed,
+					Duration: time.Second,
+					LogTail:  "=== RUN   TestParent/child\n    --- PASS: TestParent/child (1.00s)\n",
+				},
+				{
+					Group:    "group",
+					Name:     "TestParent",
+					Status:   statusPassed,
+					Duration: 2 * time.Second,
+					LogTail:  "=== RUN   TestParent\n--- PASS: TestParent (2.00s)\n",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			results := consumeOutput(t, tc.output)
+			assert.Equal(t, tc.expected, results)
+		})
+	}
+}
+
+func TestProgressConsumeIgnoresNonEvents(t *testing.T) {
+	ctx := logger.WithLogger(context.Background(), zap.NewNop())
+	progress := &testProgress{
+		group:   "group",
+		running: map[string]struct{}{},
+		output:  map[string][]string{},
+	}
+	input := "not an event\n" +
+		`{"Action":"run","Test":"TestA"}` + "\n" +
+		`{"Action":"pass","Test":"TestA","Elapsed":0.5}` + "\n"
+
+	require.NoError(t, progress.consume(ctx, bytes.NewBufferString(input)))
+	assert.Equal(t, []testResult{
+		{
+			Group:    "group",
+			Name:     "TestA",
+			Status:   statusPassed,
+			Duration: 500 * time.Millisecond,
+		},
+	}, progress.results)
+	assert.Empty(t, progress.running)
+}
+
+func TestLogTail(t *testing.T) {
+	output := make([]string, 0, logTailLines+10)
+	for i := 0; i < logTailLines+10; i++ {
+		output = append(output, "line\n")
+	}
+	output[10] = "first\n"
+
+	tail := logTail(output)
+	assert.Equal(t, logTailLines, bytes.Count([]byte(tail), []byte("\n")))
+	assert.True(t, bytes.HasPrefix([]byte(tail), []byte("first\n")))
+}
+
+// consumeOutput converts the output of the test binary to test2json events, the same way as runTestBinary does,
+// and returns results collected from them.
+func consumeOutput(t *testing.T, output string) []testResult {
+	buf := &bytes.Buffer{}
+	converter := test2json.NewConverter(buf, "group", test2json.Timestamp)
+	_, err := converter.Write([]byte(output))
+	require.NoError(t, err)
+	require.NoError(t, converter.Close())
+
+	ctx := logger.WithLogger(context.Background(), zap.NewNop())
+	progress := &testProgress{
+		group:   "group",
+		running: map[string]struct{}{},
+		output:  map[string][]string{},
+	}
+	require.NoError(t, progress.consume(ctx, buf))
+	assert.Empty(t, progress.running)
+	return progress.results
+}
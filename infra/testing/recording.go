@@ -0,0 +1,284 @@
+package testing
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum-tools/pkg/parallel"
+	"github.com/CoreumFoundation/crust/infra"
+)
+
+const (
+	// coredAddressArg is the argument of test binaries defining gRPC address of cored.
+	coredAddressArg = "-cored-address"
+
+	// maxTrafficFileSize is the size of the traffic file after which recording stops.
+	maxTrafficFileSize = 100 * 1024 * 1024
+
+	redacted = "[redacted]"
+)
+
+// redactedHeaders are the headers which values are never recorded.
+var redactedHeaders = []string{"authorization", "cookie", "set-cookie", "x-api-key"}
+
+// trafficRecord is the single request sent by tests, together with the response.
+type trafficRecord struct {
+	// mu protects the record, because request body may be closed after response is received.
+	mu sync.Mutex
+
+	Time             time.Time           `json:"time"`
+	Duration         time.Duration       `json:"duration"`
+	Method           string              `json:"method"`
+	RequestHeaders   map[string][]string `json:"requestHeaders"`
+	RequestBody      []byte              `json:"requestBody"`
+	RequestSize      int64               `json:"requestSize"`
+	Status           int                 `json:"status,omitempty"`
+	ResponseHeaders  map[string][]string `json:"responseHeaders,omitempty"`
+	ResponseTrailers map[string][]string `json:"responseTrailers,omitempty"`
+	ResponseBody     []byte              `json:"responseBody,omitempty"`
+	ResponseSize     int64               `json:"responseSize"`
+	Error            string              `json:"error,omitempty"`
+}
+
+// trafficFile returns path of the file where traffic of the test group is recorded.
+func trafficFile(config infra.Config, group string) string {
+	return filepath.Join(config.HomeDir, "traffic", group+".jsonl")
+}
+
+// recordTraffic executes fn with gRPC address of cored replaced by the proxy recording all the requests sent by tests
+// and responses returned by the node. If recording is disabled, fn is executed with unmodified arguments.
+func recordTraffic(
+	ctx context.Context,
+	config infra.Config,
+	group string,
+	args []string,
+	fn func(ctx context.Context, args []string) error,
+) error {
+	if !config.RecordTraffic {
+		return fn(ctx, args)
+	}
+
+	argIndex := -1
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == coredAddressArg {
+			argIndex = i + 1
+			break
+		}
+	}
+	if argIndex < 0 {
+		return fn(ctx, args)
+	}
+
+	file := trafficFile(config, group)
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return errors.WithStack(err)
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	recorder := &trafficRecorder{
+		log:       logger.Get(ctx).With(zap.String("group", group)),
+		file:      f,
+		bodyLimit: config.RecordBodyLimit,
+	}
+	server := &http.Server{
+		Handler:           h2c.NewHandler(recorder.proxy(args[argIndex]), &http2.Server{}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	proxiedArgs := append([]string{}, args...)
+	proxiedArgs[argIndex] = listener.Addr().String()
+	logger.Get(ctx).Info("Recording traffic between tests and cored", zap.String("file", file))
+
+	return parallel.Run(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
+		spawn("proxy", parallel.Continue, func(ctx context.Context) error {
+			if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return errors.WithStack(err)
+			}
+			return nil
+		})
+		spawn("tests", parallel.Exit, func(ctx context.Context) error {
+			defer server.Close()
+			return fn(ctx, proxiedArgs)
+		})
+		return nil
+	})
+}
+
+type trafficRecorder struct {
+	log       *zap.Logger
+	bodyLimit int
+
+	mu      sync.Mutex
+	file    io.Writer
+	written int64
+	full    bool
+}
+
+// proxy returns the reverse proxy forwarding gRPC requests to the address, which is either host:port or unix://path.
+func (r *trafficRecorder) proxy(address string) http.Handler {
+	dial := func(ctx context.Context, _, _ string, _ *tls.Config) (net.Conn, error) {
+		dialer := net.Dialer{}
+		if socket, ok := strings.CutPrefix(address, "unix://"); ok {
+			return dialer.DialContext(ctx, "unix", socket)
+		}
+		return dialer.DialContext(ctx, "tcp", address)
+	}
+
+	return &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = "http"
+			req.URL.Host = "cored"
+			// Request body is captured when the proxy forwards it.
+			record := &trafficRecord{
+				Time:           time.Now().UTC(),
+				Method:         req.URL.Path,
+				RequestHeaders: redactHeaders(req.Header),
+			}
+			if req.Body != nil {
+				req.Body = &capturingBody{ReadCloser: req.Body, limit: r.bodyLimit, onClose: func(body []byte, size int64) {
+					record.mu.Lock()
+					defer record.mu.Unlock()
+					record.RequestBody = body
+					record.RequestSize = size
+				}}
+			}
+			*req = *req.WithContext(context.WithValue(req.Context(), trafficRecordKey{}, record))
+		},
+		Transport: &http2.Transport{
+			AllowHTTP:      true,
+			DialTLSContext: dial,
+		},
+		// gRPC streams must not be buffered.
+		FlushInterval: -1,
+		ModifyResponse: func(resp *http.Response) error {
+			record, ok := resp.Request.Context().Value(trafficRecordKey{}).(*trafficRecord)
+			if !ok {
+				return nil
+			}
+			record.mu.Lock()
+			record.Status = resp.StatusCode
+			record.ResponseHeaders = redactHeaders(resp.Header)
+			record.mu.Unlock()
+			resp.Body = &capturingBody{ReadCloser: resp.Body, limit: r.bodyLimit, onClose: func(body []byte, size int64) {
+				record.mu.Lock()
+				record.ResponseBody = body
+				record.ResponseSize = size
+				// Trailers, containing gRPC status, are available once the body is read.
+				record.ResponseTrailers = redactHeaders(resp.Trailer)
+				record.Duration = time.Since(record.Time)
+				record.mu.Unlock()
+				r.write(record)
+			}}
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			if record, ok := req.Context().Value(trafficRecordKey{}).(*trafficRecord); ok {
+				record.mu.Lock()
+				record.Error = err.Error()
+				record.Duration = time.Since(record.Time)
+				record.mu.Unlock()
+				r.write(record)
+			}
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+}
+
+func (r *trafficRecorder) write(record *trafficRecord) {
+	record.mu.Lock()
+	line, err := json.Marshal(record)
+	record.mu.Unlock()
+	if err != nil {
+		r.log.Error("Encoding traffic record failed", zap.Error(err))
+		return
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.full {
+		return
+	}
+	if r.written+int64(len(line)) > maxTrafficFileSize {
+		r.full = true
+		r.log.Warn("Traffic file reached maximum size, recording stopped", zap.Int64("size", r.written))
+		return
+	}
+	n, err := r.file.Write(line)
+	r.written += int64(n)
+	if err != nil {
+		r.full = true
+		r.log.Error("Writing traffic record failed, recording stopped", zap.Error(err))
+	}
+}
+
+type trafficRecordKey struct{}
+
+// capturingBody stores the beginning of the body, up to the limit, and passes the captured part to onClose, together
+// with the total size of the body.
+type capturingBody struct {
+	io.ReadCloser
+	limit   int
+	onClose func(body []byte, size int64)
+
+	captured []byte
+	size     int64
+	once     sync.Once
+}
+
+func (b *capturingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if missing := b.limit - len(b.captured); missing > 0 {
+		b.captured = append(b.captured, p[:lo.Min([]int{n, missing})]...)
+	}
+	b.size += int64(n)
+	return n, err
+}
+
+func (b *capturingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.onClose(b.captured, b.size)
+	})
+	return err
+}
+
+func redactHeaders(headers http.Header) map[string][]string {
+	if len(headers) == 0 {
+		return nil
+	}
+	result := make(map[string][]string, len(headers))
+	for name, values := range headers {
+		if lo.Contains(redactedHeaders, strings.ToLower(name)) {
+			values = []string{redacted}
+		}
+		result[strings.ToLower(name)] = values
+	}
+	return result
+}
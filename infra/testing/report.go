@@ -0,0 +1,155 @@
+package testing
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// logTailLines is the number of last lines of test output stored in the report.
+	logTailLines = 50
+
+	statusPassed  = "passed"
+	statusFailed  = "failed"
+	statusSkipped = "skipped"
+)
+
+// testResult is the result of a single test stored in the report.
+type testResult struct {
+	Group    string        `json:"group"`
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Duration time.Duration `json:"-"`
+	LogTail  string        `json:"logTail,omitempty"`
+}
+
+// MarshalJSON stores duration in seconds, so it is easy to consume by other tools.
+func (r testResult) MarshalJSON() ([]byte, error) {
+	type result testResult
+	return json.Marshal(struct {
+		result
+		DurationSeconds float64 `json:"durationSeconds"`
+	}{
+		result:          result(r),
+		DurationSeconds: r.Duration.Seconds(),
+	})
+}
+
+type jsonReport struct {
+	Passed  int          `json:"passed"`
+	Failed  int          `json:"failed"`
+	Skipped int          `json:"skipped"`
+	Tests   []testResult `json:"tests"`
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// saveReports stores results of the tests in dir, as JUnit XML (report.xml) and JSON (report.json) files.
+func saveReports(dir string, results []testResult) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return errors.WithStack(err)
+	}
+
+	content, err := json.MarshalIndent(newJSONReport(results), "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "report.json"), content, 0o600); err != nil {
+		return errors.WithStack(err)
+	}
+
+	content, err = xml.MarshalIndent(newJUnitReport(results), "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	content = append([]byte(xml.Header), content...)
+	return errors.WithStack(os.WriteFile(filepath.Join(dir, "report.xml"), content, 0o600))
+}
+
+func newJSONReport(results []testResult) jsonReport {
+	report := jsonReport{Tests: results}
+	for _, r := range results {
+		switch r.Status {
+		case statusPassed:
+			report.Passed++
+		case statusFailed:
+			report.Failed++
+		case statusSkipped:
+			report.Skipped++
+		}
+	}
+	return report
+}
+
+func newJUnitReport(results []testResult) junitTestSuites {
+	var report junitTestSuites
+	suites := map[string]int{}
+	durations := map[string]time.Duration{}
+	for _, r := range results {
+		i, exists := suites[r.Group]
+		if !exists {
+			i = len(report.Suites)
+			suites[r.Group] = i
+			report.Suites = append(report.Suites, junitTestSuite{Name: r.Group})
+		}
+		suite := &report.Suites[i]
+
+		testCase := junitTestCase{
+			ClassName: r.Group,
+			Name:      r.Name,
+			Time:      junitTime(r.Duration),
+		}
+		switch r.Status {
+		case statusFailed:
+			suite.Failures++
+			testCase.Failure = &junitMessage{Message: "Test failed"}
+			testCase.SystemOut = r.LogTail
+		case statusSkipped:
+			suite.Skipped++
+			testCase.Skipped = &junitMessage{Message: "Test skipped"}
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, testCase)
+		durations[r.Group] += r.Duration
+	}
+	for i := range report.Suites {
+		report.Suites[i].Time = junitTime(durations[report.Suites[i].Name])
+	}
+	return report
+}
+
+// junitTime formats duration as number of seconds, as expected by JUnit.
+func junitTime(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
@@ -3,30 +3,184 @@ package testing
 import (
 	"context"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
 	"go.uber.org/zap"
 
-	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
 	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum-tools/pkg/parallel"
 	"github.com/CoreumFoundation/crust/infra"
 	"github.com/CoreumFoundation/crust/infra/apps/cored"
 	"github.com/CoreumFoundation/crust/infra/apps/faucet"
+	"github.com/CoreumFoundation/crust/infra/apps/sockets"
 )
 
-// Run deploys testing environment and runs tests there.
-func Run(ctx context.Context, target infra.Target, appSet infra.AppSet, config infra.Config, onlyTestGroups ...string) error { //nolint:funlen
+// Environment is the environment tests are executed in.
+type Environment struct {
+	AppSet infra.AppSet
+	Config infra.Config
+	Spec   *infra.Spec
+}
+
+// EnvRequest describes the dedicated environment required by the test group.
+type EnvRequest struct {
+	// Group is the name of the test group
+	Group string
+
+	// CoredVersion is the version of cored the chain must be started with, empty means the current one
+	CoredVersion string
+
+	// Profiles lists the profiles deployed in addition to the ones of the shared environment
+	Profiles []string
+}
+
+// ProvisionFunc creates and starts the dedicated environment for the test group. Returned function removes it.
+type ProvisionFunc func(ctx context.Context, req EnvRequest) (Environment, func(ctx context.Context) error, error)
+
+// Run deploys testing environment and runs tests there. Groups requiring dedicated environment are executed
+// in the environments created by provision.
+func Run(
+	ctx context.Context,
+	target infra.Target,
+	appSet infra.AppSet,
+	config infra.Config,
+	spec *infra.Spec,
+	provision ProvisionFunc,
+	onlyTestGroups ...string,
+) error {
+	testDir, batches, err := selectTestGroups(config, onlyTestGroups, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := target.Deploy(ctx, appSet); err != nil {
+		return err
+	}
+
+	log := logger.Get(ctx)
+	log.Info("Waiting until all applications start...")
+	waitCtx, waitCancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer waitCancel()
+	if err := infra.WaitUntilHealthy(waitCtx, buildWaitForApps(appSet)...); err != nil {
+		return err
+	}
+
+	log.Info("All the applications are ready")
+	coredApp := appSet.FindRunningApp(cored.AppType, "cored-00")
+	if coredApp == nil {
+		return errors.New("no running cored app found")
+	}
+
+	coredNode := coredApp.(cored.Cored)
+	args := commonTestArgs(ctx, config, coredGRPCAddress(appSet, coredNode))
+	parallelism := testParallelism(ctx, config)
+
+	leaser := &accountLeaser{spec: spec}
+	defer leaser.releaseAll(ctx)
+
+	return runTestGroups(ctx, config, testDir, batches, parallelism,
+		func(ctx context.Context, group string) (groupEnv, error) {
+			if testGroups[group].Dedicated {
+				return dedicatedGroupEnv(ctx, appSet, provision, group)
+			}
+			fullArgs, err := testGroupArgs(ctx, appSet, leaser, coredNode, config, group, args, parallelism > 1)
+			if err != nil {
+				return groupEnv{}, err
+			}
+			return groupEnv{AppSet: appSet, Args: fullArgs}, nil
+		})
+}
+
+// groupEnv is the environment the test group is executed in.
+type groupEnv struct {
+	// AppSet is used to collect artifacts if tests fail, nil means they are not collected
+	AppSet infra.AppSet
+
+	// Args are the arguments passed to the binary of the test group
+	Args []string
+
+	// Cleanup is called once tests of the group complete, nil means there is nothing to clean up
+	Cleanup func(ctx context.Context) error
+}
+
+// dedicatedGroupEnv provisions the dedicated environment for the test group. Once the group completes,
+// the environment is removed and it is waited until the shared one is healthy again.
+func dedicatedGroupEnv(
+	ctx context.Context,
+	sharedAppSet infra.AppSet,
+	provision ProvisionFunc,
+	group string,
+) (retEnv groupEnv, retErr error) {
+	if provision == nil {
+		return groupEnv{}, errors.Errorf("test group %q requires dedicated environment which is not supported here",
+			group)
+	}
+
+	log := logger.Get(ctx).With(zap.String("group", group))
+	log.Info("Provisioning dedicated environment for test group")
+	env, remove, err := provision(ctx, EnvRequest{
+		Group:        group,
+		CoredVersion: testGroups[group].CoredVersion,
+		Profiles:     testGroups[group].Profiles,
+	})
+	if err != nil {
+		return groupEnv{}, err
+	}
+
+	leaser := &accountLeaser{spec: env.Spec}
+	cleanup := func(ctx context.Context) error {
+		leaser.releaseAll(ctx)
+		log.Info("Removing dedicated environment of test group")
+		if err := remove(ctx); err != nil {
+			return err
+		}
+		waitCtx, waitCancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer waitCancel()
+		return infra.WaitUntilHealthy(waitCtx, buildWaitForApps(sharedAppSet)...)
+	}
+	defer func() {
+		if retErr != nil {
+			cleanupCtx, cancel := infra.CleanupContext(ctx)
+			defer cancel()
+			if err := cleanup(cleanupCtx); err != nil {
+				log.Error("Removing dedicated environment failed", zap.Error(err))
+			}
+		}
+	}()
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer waitCancel()
+	if err := infra.WaitUntilHealthy(waitCtx, buildWaitForApps(env.AppSet)...); err != nil {
+		return groupEnv{}, err
+	}
+
+	coredApp := env.AppSet.FindRunningApp(cored.AppType, "cored-00")
+	if coredApp == nil {
+		return groupEnv{}, errors.New("no running cored app found in dedicated environment")
+	}
+	coredNode := coredApp.(cored.Cored)
+	args := commonTestArgs(ctx, env.Config, coredGRPCAddress(env.AppSet, coredNode))
+	fullArgs, err := testGroupArgs(ctx, env.AppSet, leaser, coredNode, env.Config, group, args, false)
+	if err != nil {
+		return groupEnv{}, err
+	}
+	return groupEnv{AppSet: env.AppSet, Args: fullArgs, Cleanup: cleanup}, nil
+}
+
+// selectTestGroups returns the directory containing test binaries and batches of test groups to run. Groups
+// which are not supported are skipped if no groups are selected explicitly.
+func selectTestGroups(config infra.Config, onlyTestGroups, unsupported []string) (string, [][]string, error) {
 	testDir := filepath.Join(config.BinDir, ".cache", "integration-tests")
 	files, err := os.ReadDir(testDir)
 	if err != nil {
-		return errors.WithStack(err)
+		return "", nil, errors.WithStack(err)
 	}
 	binaries := make([]string, 0, len(files))
 	for _, f := range files {
@@ -38,87 +192,147 @@ func Run(ctx context.Context, target infra.Target, appSet infra.AppSet, config i
 
 	for _, tg := range onlyTestGroups {
 		if !lo.Contains(binaries, tg) {
-			return errors.Errorf("binary does not exist for test group %q", tg)
+			return "", nil, errors.Errorf("binary does not exist for test group %q", tg)
+		}
+		if lo.Contains(unsupported, tg) {
+			return "", nil, errors.Errorf("test group %q is not supported in this mode", tg)
 		}
 	}
 	// if not limitations were provided we test all binaries
 	if len(onlyTestGroups) == 0 {
-		onlyTestGroups = binaries
+		onlyTestGroups = lo.Without(binaries, unsupported...)
 	}
 
-	if err := target.Deploy(ctx, appSet); err != nil {
-		return err
-	}
-
-	log := logger.Get(ctx)
-	log.Info("Waiting until all applications start...")
-	waitCtx, waitCancel := context.WithTimeout(ctx, 5*time.Minute)
-	defer waitCancel()
-	if err := infra.WaitUntilHealthy(waitCtx, buildWaitForApps(appSet)...); err != nil {
-		return err
+	batches, err := orderTestGroups(onlyTestGroups)
+	if err != nil {
+		return "", nil, err
 	}
+	return testDir, batches, nil
+}
 
-	log.Info("All the applications are ready")
-	coredApp := appSet.FindRunningApp(cored.AppType, "cored-00")
-	if coredApp == nil {
-		return errors.New("no running cored app found")
+// coredGRPCAddress returns the address tests connect to gRPC of the node at. If unix socket proxy is deployed,
+// its socket is used, so tests opening many connections don't exhaust TCP ports of the host.
+func coredGRPCAddress(appSet infra.AppSet, coredNode cored.Cored) string {
+	if socketsApp := appSet.FindRunningApp(sockets.AppType, "sockets"); socketsApp != nil {
+		return "unix://" + socketsApp.(sockets.Sockets).GRPCSocket(coredNode)
 	}
+	return infra.JoinNetAddr("", coredNode.Info().HostFromHost, coredNode.Config().Ports.GRPC)
+}
 
-	coredNode := coredApp.(cored.Cored)
+// commonTestArgs returns arguments passed to the binaries of all the test groups.
+func commonTestArgs(ctx context.Context, config infra.Config, coredAddress string) []string {
 	args := []string{
 		// The tests themselves are not computationally expensive, most of the time they spend waiting for transactions
 		// to be included in blocks, so it should be safe to run more tests in parallel than we have CPus available.
 		"-test.parallel", strconv.Itoa(2 * runtime.NumCPU()),
-		"-cored-address", infra.JoinNetAddr("", coredNode.Info().HostFromHost, coredNode.Config().Ports.GRPC),
+		"-cored-address", coredAddress,
 	}
 	if config.TestFilter != "" {
-		log.Info("Running only tests matching filter", zap.String("filter", config.TestFilter))
+		logger.Get(ctx).Info("Running only tests matching filter", zap.String("filter", config.TestFilter))
 		args = append(args, "-test.run", config.TestFilter)
 	}
-	if config.VerboseLogging {
-		args = append(args, "-test.v")
-	}
+	return args
+}
 
-	var failed bool
-	// the execution order might be important
-	for _, onlyTestGroup := range onlyTestGroups {
-		// copy is not used here, since the linter complains in the next line that using append with pre-allocated
-		// length leads to extra space getting allocated.
-		fullArgs := append([]string{}, args...)
-		switch onlyTestGroup {
-		case "coreum-modules", "coreum-upgrade":
+func testParallelism(ctx context.Context, config infra.Config) int {
+	parallelism := config.TestParallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	if parallelism > 1 {
+		logger.Get(ctx).Info("Running independent test groups in parallel", zap.Int("parallelism", parallelism))
+	}
+	return parallelism
+}
 
-			fullArgs = append(fullArgs,
-				"-log-format", config.LogFormat,
-				"-funding-mnemonic", coredNode.Config().FaucetMnemonic,
-			)
+// runTestGroups runs batches of test groups. Groups in a batch are executed in parallel, up to the parallelism limit.
+func runTestGroups(
+	ctx context.Context,
+	config infra.Config,
+	testDir string,
+	batches [][]string,
+	parallelism int,
+	envFunc func(ctx context.Context, group string) (groupEnv, error),
+) error {
+	log := logger.Get(ctx)
 
-			for _, m := range appSet {
-				coredApp, ok := m.(cored.Cored)
-				if ok && coredApp.Config().IsValidator && strings.HasPrefix(coredApp.Name(), "cored-") {
-					fullArgs = append(fullArgs, "-staker-mnemonic", coredApp.Config().StakerMnemonic)
+	var (
+		mu      sync.Mutex
+		failed  bool
+		results []testResult
+	)
+	runBatch := func(batch []string) (retErr error) {
+		groupEnvs := make(map[string]groupEnv, len(batch))
+		defer func() {
+			cleanupCtx, cancel := infra.CleanupContext(ctx)
+			defer cancel()
+			for _, env := range groupEnvs {
+				if env.Cleanup == nil {
+					continue
 				}
+				if err := env.Cleanup(cleanupCtx); retErr == nil {
+					retErr = err
+				}
+			}
+		}()
+
+		// Environments are prepared sequentially, because funding of test accounts can't be done in parallel.
+		for _, group := range batch {
+			env, err := envFunc(ctx, group)
+			if err != nil {
+				return err
 			}
-		case "faucet":
-			faucetApp := appSet.FindRunningApp(faucet.AppType, "faucet")
-			if faucetApp == nil {
-				return errors.New("no running faucet app found")
+			coverArgs, err := coverageArgs(config, group)
+			if err != nil {
+				return err
 			}
-			faucetNode := faucetApp.(faucet.Faucet)
-			fullArgs = append(fullArgs,
-				"-transfer-amount", "1000000",
-				"-faucet-address", infra.JoinNetAddr("http", faucetNode.Info().HostFromHost, faucetNode.Port()),
-			)
+			env.Args = append(env.Args, coverArgs...)
+			groupEnvs[group] = env
 		}
 
-		binPath := filepath.Join(testDir, onlyTestGroup)
-		log := log.With(zap.String("binary", binPath), zap.Strings("args", fullArgs))
-		log.Info("Running tests")
+		return parallel.Run(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
+			slots := make(chan struct{}, parallelism)
+			for _, group := range batch {
+				group := group
+				spawn(group, parallel.Continue, func(ctx context.Context) error {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case slots <- struct{}{}:
+					}
+					defer func() {
+						<-slots
+					}()
+
+					env := groupEnvs[group]
+					groupResults, groupFailed, err := runTestGroup(ctx, env.AppSet, config, testDir, group, env.Args)
 
-		if err := libexec.Exec(ctx, exec.Command(binPath, fullArgs...)); err != nil {
-			log.Error("Tests failed", zap.Error(err))
-			failed = true
+					mu.Lock()
+					defer mu.Unlock()
+					results = append(results, groupResults...)
+					failed = failed || groupFailed
+					return err
+				})
+			}
+			return nil
+		})
+	}
+	for _, batch := range batches {
+		if err := runBatch(batch); err != nil {
+			return err
+		}
+	}
+	if config.CoverageDir != "" {
+		if err := mergeCoverage(ctx, config, lo.Flatten(batches)); err != nil {
+			return err
+		}
+		log.Info("Coverage reports saved", zap.String("dir", config.CoverageDir))
+	}
+	if config.ReportDir != "" {
+		if err := saveReports(config.ReportDir, results); err != nil {
+			return err
 		}
+		log.Info("Test reports saved", zap.String("dir", config.ReportDir))
 	}
 	if failed {
 		return errors.New("tests failed")
@@ -127,6 +341,88 @@ func Run(ctx context.Context, target infra.Target, appSet infra.AppSet, config i
 	return nil
 }
 
+// testGroupArgs returns arguments passed to the binary of the test group.
+func testGroupArgs(
+	ctx context.Context,
+	appSet infra.AppSet,
+	leaser *accountLeaser,
+	coredNode cored.Cored,
+	config infra.Config,
+	group string,
+	args []string,
+	parallel bool,
+) ([]string, error) {
+	// copy is not used here, since the linter complains in the next line that using append with pre-allocated
+	// length leads to extra space getting allocated.
+	fullArgs := append([]string{}, args...)
+	switch group {
+	case groupCoreumModules, groupCoreumUpgrade:
+		mnemonic, err := fundingMnemonic(ctx, appSet, leaser, coredNode, parallel)
+		if err != nil {
+			return nil, err
+		}
+
+		fullArgs = append(fullArgs,
+			"-log-format", config.LogFormat,
+			"-funding-mnemonic", mnemonic,
+		)
+
+		for _, m := range appSet {
+			coredApp, ok := m.(cored.Cored)
+			if ok && coredApp.Config().IsValidator && strings.HasPrefix(coredApp.Name(), "cored-") {
+				fullArgs = append(fullArgs, "-staker-mnemonic", coredApp.Config().StakerMnemonic)
+			}
+		}
+	case groupFaucet:
+		faucetApp := appSet.FindRunningApp(faucet.AppType, "faucet")
+		if faucetApp == nil {
+			return nil, errors.New("no running faucet app found")
+		}
+		faucetNode := faucetApp.(faucet.Faucet)
+		fullArgs = append(fullArgs,
+			"-transfer-amount", strconv.FormatInt(faucetNode.TransferAmount(), 10),
+			"-faucet-address", infra.JoinNetAddr("http", faucetNode.Info().HostFromHost, faucetNode.Port()),
+		)
+	}
+	return fullArgs, nil
+}
+
+// runTestGroup runs the binary of the test group. Failure of the tests is reported by returned flag, error is returned
+// only if tests couldn't be handled correctly.
+func runTestGroup(
+	ctx context.Context,
+	appSet infra.AppSet,
+	config infra.Config,
+	testDir, group string,
+	args []string,
+) ([]testResult, bool, error) {
+	binPath := filepath.Join(testDir, group)
+	log := logger.Get(ctx).With(zap.String("binary", binPath), zap.Strings("args", args))
+	log.Info("Running tests")
+
+	start := time.Now()
+	var results []testResult
+	err := recordTraffic(ctx, config, group, args, func(ctx context.Context, args []string) error {
+		var err error
+		results, err = runTestBinary(ctx, group, binPath, args, config.VerboseLogging)
+		return err
+	})
+	if err == nil {
+		return results, false, nil
+	}
+
+	log.Error("Tests failed", zap.Error(err))
+	if appSet == nil {
+		return results, true, nil
+	}
+	artifactsDir, err := collectArtifacts(ctx, appSet, config, group, start)
+	if err != nil {
+		return results, true, err
+	}
+	log.Info("Artifacts of failed tests collected", zap.String("dir", artifactsDir))
+	return results, true, nil
+}
+
 func buildWaitForApps(appSet infra.AppSet) []infra.HealthCheckCapable {
 	waitForApps := make([]infra.HealthCheckCapable, 0, len(appSet))
 	for _, app := range appSet {
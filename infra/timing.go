@@ -0,0 +1,124 @@
+package infra
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// startupTimingFile is the file in the directory of the environment where startup timings are recorded.
+const startupTimingFile = "startup.jsonl"
+
+// Phases of app startup which are measured.
+const (
+	PhaseImage      = "image"
+	PhaseHealthWait = "health-wait"
+	PhasePrepare    = "prepare"
+	PhaseContainer  = "container"
+	PhaseConfigure  = "configure"
+)
+
+// PhaseTiming is the time spent by app in the phase of startup. Start is relative to the beginning of deployment.
+type PhaseTiming struct {
+	Phase           string  `json:"phase"`
+	StartSeconds    float64 `json:"startSeconds"`
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// AppTiming collects timings of startup phases of the app.
+type AppTiming struct {
+	App    string        `json:"app"`
+	Phases []PhaseTiming `json:"phases"`
+
+	start time.Time
+}
+
+// Measure executes the function and records the time spent on it as the phase.
+func (t *AppTiming) Measure(phase string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	t.Phases = append(t.Phases, PhaseTiming{
+		Phase:           phase,
+		StartSeconds:    start.Sub(t.start).Seconds(),
+		DurationSeconds: time.Since(start).Seconds(),
+	})
+	return err
+}
+
+// EndSeconds returns the time, relative to the beginning of deployment, when the last phase ended.
+func (t *AppTiming) EndSeconds() float64 {
+	if len(t.Phases) == 0 {
+		return 0
+	}
+	last := t.Phases[len(t.Phases)-1]
+	return last.StartSeconds + last.DurationSeconds
+}
+
+// StartupTiming is the report of time spent on starting the apps.
+type StartupTiming struct {
+	Time time.Time    `json:"time"`
+	Apps []*AppTiming `json:"apps"`
+
+	mu sync.Mutex
+}
+
+// NewStartupTiming creates new startup timing report.
+func NewStartupTiming() *StartupTiming {
+	return &StartupTiming{Time: time.Now()}
+}
+
+// App starts measuring the startup of the app.
+func (s *StartupTiming) App(name string) *AppTiming {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	timing := &AppTiming{App: name, start: s.Time}
+	s.Apps = append(s.Apps, timing)
+	return timing
+}
+
+// SaveStartupTiming appends startup timing report to the history of the environment.
+func SaveStartupTiming(homeDir string, timing *StartupTiming) error {
+	content, err := json.Marshal(timing)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(homeDir, startupTimingFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(content, '\n'))
+	return errors.WithStack(err)
+}
+
+// LoadStartupTimings loads startup timing reports stored in the history of the environment, the oldest first.
+func LoadStartupTimings(homeDir string) ([]*StartupTiming, error) {
+	f, err := os.Open(filepath.Join(homeDir, startupTimingFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close()
+
+	var timings []*StartupTiming
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		timing := &StartupTiming{}
+		if err := json.Unmarshal(scanner.Bytes(), timing); err != nil {
+			return nil, errors.Wrapf(err, "invalid entry in startup timing file %s", f.Name())
+		}
+		timings = append(timings, timing)
+	}
+	return timings, errors.WithStack(scanner.Err())
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
 	"strings"
@@ -49,6 +50,7 @@ func (m AppSet) Deploy(ctx context.Context, t AppTarget, config Config, spec *Sp
 	log.Info(fmt.Sprintf("Staring AppSet deployment, apps: %s", strings.Join(lo.Map(m, func(app App, _ int) string {
 		return app.Name()
 	}), ",")))
+	timing := NewStartupTiming()
 	err := parallel.Run(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
 		deploymentSlots := make(chan struct{}, runtime.NumCPU())
 		for i := 0; i < cap(deploymentSlots); i++ {
@@ -67,6 +69,8 @@ func (m AppSet) Deploy(ctx context.Context, t AppTarget, config Config, spec *Sp
 		images := map[string]chan struct{}{}
 		for _, app := range m {
 			deployment := app.Deployment()
+			deployment.Resources = config.Resources.For(app.Type(), app.Name())
+			deployment.Image = exec.QualifiedImage(deployment.Image)
 			if _, exists := images[deployment.Image]; !exists {
 				ch := make(chan struct{}, 1)
 				ch <- struct{}{}
@@ -90,12 +94,17 @@ func (m AppSet) Deploy(ctx context.Context, t AppTarget, config Config, spec *Sp
 
 			appInfo := spec.Apps[name]
 			toDeploy := toDeploy
+			appTiming := timing.App(name)
 			spawn("deploy."+name, parallel.Continue, func(ctx context.Context) error {
 				deployment := toDeploy.Deployment
 
 				log.Info("Deployment initialized")
 
-				if err := ensureDockerImage(ctx, deployment.Image, imagePullSlots, toDeploy.ImageReadyCh); err != nil {
+				err := appTiming.Measure(PhaseImage, func() error {
+					return ensureDockerImage(ctx, deployment.Image, config.ImagePullPolicy, imagePullSlots,
+						toDeploy.ImageReadyCh)
+				})
+				if err != nil {
 					return err
 				}
 
@@ -125,7 +134,7 @@ func (m AppSet) Deploy(ctx context.Context, t AppTarget, config Config, spec *Sp
 
 				log.Info("Deployment started")
 
-				info, err := deployment.Deploy(ctx, t, config)
+				info, err := deployment.Deploy(ctx, t, config, appTiming)
 				if err != nil {
 					return err
 				}
@@ -142,8 +151,18 @@ func (m AppSet) Deploy(ctx context.Context, t AppTarget, config Config, spec *Sp
 		return nil
 	})
 	if err != nil {
+		// Apps deployed before the failure are recorded, so they are not deployed again, and they are stopped
+		// and removed by the next command.
+		if saveErr := spec.Save(); saveErr != nil {
+			log.Error("Saving spec failed", zap.Error(saveErr))
+		}
 		return err
 	}
+	if len(timing.Apps) > 0 {
+		if err := SaveStartupTiming(config.HomeDir, timing); err != nil {
+			return err
+		}
+	}
 	return spec.Save()
 }
 
@@ -157,7 +176,7 @@ func (m AppSet) FindRunningApp(appType AppType, appName string) App {
 	return nil
 }
 
-func ensureDockerImage(ctx context.Context, image string, slots, readyCh chan struct{}) error {
+func ensureDockerImage(ctx context.Context, image string, policy PullPolicy, slots, readyCh chan struct{}) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -177,10 +196,16 @@ func ensureDockerImage(ctx context.Context, image string, slots, readyCh chan st
 	if err := libexec.Exec(ctx, imageCmd); err != nil {
 		return errors.Wrapf(err, "failed to list image '%s'", image)
 	}
-	if imageBuf.Len() > 0 {
+	exists := imageBuf.Len() > 0
+	switch {
+	case exists && (policy != PullPolicyAlways || exec.IsLocalImage(image)):
 		log.Info("Docker image exists")
 		close(readyCh)
 		return nil
+	case exec.IsLocalImage(image):
+		return errors.Errorf("image '%s' doesn't exist, build it using `crust build images`", image)
+	case policy == PullPolicyNever:
+		return errors.Errorf("image '%s' doesn't exist and pulling is disabled by the image pull policy", image)
 	}
 
 	log.Info("Waiting for free slot for pulling the docker image")
@@ -193,8 +218,8 @@ func ensureDockerImage(ctx context.Context, image string, slots, readyCh chan st
 
 	log.Info("Pulling docker image")
 
-	if err := libexec.Exec(ctx, exec.Docker("pull", image)); err != nil {
-		return errors.Wrapf(err, "failed to pull docker image '%s'", image)
+	if err := pullDockerImage(ctx, image); err != nil {
+		return err
 	}
 
 	log.Info("Image pulled")
@@ -203,6 +228,33 @@ func ensureDockerImage(ctx context.Context, image string, slots, readyCh chan st
 	return nil
 }
 
+// fallbackPlatform is the platform of the image pulled if the image has no variant for the platform of docker.
+// All the external images are published for it, so it is used on arm64 machines, under emulation, if there is no
+// native image.
+const fallbackPlatform = "linux/amd64"
+
+// pullDockerImage pulls the image for the platform of docker. If the image is not published for that platform,
+// the one for fallbackPlatform is pulled.
+func pullDockerImage(ctx context.Context, image string) error {
+	errBuf := &bytes.Buffer{}
+	pullCmd := exec.Docker("pull", image)
+	pullCmd.Stderr = io.MultiWriter(os.Stderr, errBuf)
+	err := libexec.Exec(ctx, pullCmd)
+	if err == nil {
+		return nil
+	}
+	if !strings.Contains(errBuf.String(), "no matching manifest") {
+		return errors.Wrapf(err, "failed to pull docker image '%s'", image)
+	}
+
+	logger.Get(ctx).Warn("Docker image is not published for the platform of docker, it runs under emulation",
+		zap.String("image", image), zap.String("platform", fallbackPlatform))
+	if err := libexec.Exec(ctx, exec.Docker("pull", "--platform", fallbackPlatform, image)); err != nil {
+		return errors.Wrapf(err, "failed to pull docker image '%s'", image)
+	}
+	return nil
+}
+
 // DeploymentInfo contains info about deployed application.
 type DeploymentInfo struct {
 	// Container stores the name of the docker container where app is running - present only for apps running in docker
@@ -222,6 +274,39 @@ type DeploymentInfo struct {
 
 	// Ports describe network ports provided by the application
 	Ports map[string]int `json:"ports,omitempty"`
+
+	// IP is the IPv4 address assigned to the container in docker network
+	IP string `json:"ip,omitempty"`
+
+	// IPv6 is the IPv6 address assigned to the container in docker network, if IPv6 is enabled
+	IPv6 string `json:"ipv6,omitempty"`
+}
+
+// IBCPath describes IBC client, connection and channel established by the relayer between two chains.
+type IBCPath struct {
+	// Source is the end of the path on the chain the relayer connects to the peer chain, e.g. cored
+	Source IBCPathEnd `json:"source" yaml:"source"`
+
+	// Destination is the end of the path on the peer chain
+	Destination IBCPathEnd `json:"destination" yaml:"destination"`
+}
+
+// IBCPathEnd describes IBC identifiers on one of the chains connected by the relayer.
+type IBCPathEnd struct {
+	// ChainID is the ID of the chain
+	ChainID string `json:"chainID" yaml:"chainID"`
+
+	// ClientID is the ID of the client tracking the other chain
+	ClientID string `json:"clientID" yaml:"clientID"`
+
+	// ConnectionID is the ID of the connection to the other chain
+	ConnectionID string `json:"connectionID" yaml:"connectionID"`
+
+	// PortID is the ID of the port the channel is bound to
+	PortID string `json:"portID" yaml:"portID"`
+
+	// ChannelID is the ID of the channel to the other chain
+	ChannelID string `json:"channelID" yaml:"channelID"`
 }
 
 // Target represents target of deployment from the perspective of znet.
@@ -240,6 +325,9 @@ type Target interface {
 type AppTarget interface {
 	// DeployContainer deploys container to the target
 	DeployContainer(ctx context.Context, app Deployment) (DeploymentInfo, error)
+
+	// RemoveContainer removes container deployed by DeployContainer
+	RemoveContainer(ctx context.Context, info DeploymentInfo) error
 }
 
 // Prerequisites specifies list of other apps which have to be healthy before app may be started.
@@ -277,12 +365,20 @@ type Deployment struct {
 	// Ports are the network ports exposed by the application
 	Ports map[string]int
 
+	// ContainerPorts are the ports application listens on inside the container, mapped by the names used in Ports,
+	// if they differ from the ports published on the host
+	ContainerPorts map[string]int
+
+	// PublishOnAllInterfaces causes ports to be published on all the interfaces of the host, so the application is
+	// reachable from other devices in the network, by default they are published on the loopback interface only.
+	PublishOnAllInterfaces bool
+
 	// Requires is the list of health checks to be required before app can be deployed
 	Requires Prerequisites
 
 	// PrepareFunc is the function called before application is deployed for the first time.
 	// It is a good place to prepare configuration files and other things which must or might be done before application runs.
-	PrepareFunc func() error
+	PrepareFunc func(ctx context.Context) error
 
 	// ConfigureFunc is the function called after application is deployed for the first time.
 	// It is a good place to connect to the application to configure it because at this stage the app's IP address is known.
@@ -304,31 +400,118 @@ type Deployment struct {
 
 	// Entrypoint is the custom entrypoint for the container.
 	Entrypoint string
+
+	// Resources limits resources available to the container.
+	Resources Resources
+
+	// RestartPolicy defines if the container is restarted automatically after it exits.
+	RestartPolicy RestartPolicy
 }
 
-// Deploy deploys container to the target.
-func (app Deployment) Deploy(ctx context.Context, target AppTarget, config Config) (DeploymentInfo, error) {
-	if err := app.preprocess(ctx, config); err != nil {
+// RestartPolicy defines if the container is restarted automatically after it exits.
+type RestartPolicy string
+
+const (
+	// RestartPolicyNever means the container is never restarted automatically.
+	RestartPolicyNever RestartPolicy = ""
+
+	// RestartPolicyOnFailure means the container is restarted if it exits with non-zero code, limited number of times,
+	// so app crashing permanently doesn't consume resources forever.
+	RestartPolicyOnFailure RestartPolicy = "on-failure"
+
+	// RestartPolicyUnlessStopped means the container is restarted until it is stopped explicitly.
+	RestartPolicyUnlessStopped RestartPolicy = "unless-stopped"
+)
+
+// Resources defines limits of resources available to the container, zero values mean no limit.
+type Resources struct {
+	// CPUShares is the weight of the container used when CPU time is contended, docker uses 1024 by default
+	CPUShares int
+
+	// CPUs is the maximum number of CPUs used by the container, fractions are allowed
+	CPUs float64
+
+	// Memory is the maximum amount of memory, in bytes, used by the container
+	Memory int64
+
+	// Pids is the maximum number of processes running in the container
+	Pids int
+}
+
+// Merge returns resources with limits overridden by non-zero limits of the other one.
+func (r Resources) Merge(other Resources) Resources {
+	if other.CPUShares != 0 {
+		r.CPUShares = other.CPUShares
+	}
+	if other.CPUs != 0 {
+		r.CPUs = other.CPUs
+	}
+	if other.Memory != 0 {
+		r.Memory = other.Memory
+	}
+	if other.Pids != 0 {
+		r.Pids = other.Pids
+	}
+	return r
+}
+
+// AppResources maps names and types of the apps to limits of their resources.
+type AppResources map[string]Resources
+
+// For returns limits of the app's resources. Limits defined for the name of the app take precedence over the ones
+// defined for its type.
+func (r AppResources) For(appType AppType, name string) Resources {
+	return r[string(appType)].Merge(r[name])
+}
+
+// ContainerPort returns the port application listens on inside the container for the port published on the host.
+func (app Deployment) ContainerPort(name string) int {
+	if port, exists := app.ContainerPorts[name]; exists {
+		return port
+	}
+	return app.Ports[name]
+}
+
+// Deploy deploys container to the target. Time spent in each phase is recorded in timing.
+func (app Deployment) Deploy(ctx context.Context, target AppTarget, config Config, timing *AppTiming) (DeploymentInfo, error) {
+	if err := app.preprocess(ctx, config, timing); err != nil {
 		return DeploymentInfo{}, err
 	}
 
-	info, err := target.DeployContainer(ctx, app)
+	var info DeploymentInfo
+	err := timing.Measure(PhaseContainer, func() error {
+		var err error
+		info, err = target.DeployContainer(ctx, app)
+		return err
+	})
 	if err != nil {
 		return DeploymentInfo{}, err
 	}
-	if err := app.postprocess(ctx, info); err != nil {
+	if err := app.postprocess(ctx, info, timing); err != nil {
+		// If deployment is cancelled before new container is configured, it is removed, so it is created from scratch
+		// next time instead of starting half-configured one.
+		if ctx.Err() != nil && app.Info.Info().Status == AppStatusNotDeployed {
+			cleanupCtx, cancel := CleanupContext(ctx)
+			defer cancel()
+			if rmErr := target.RemoveContainer(cleanupCtx, info); rmErr != nil {
+				logger.Get(ctx).Error("Removing partially deployed container failed", zap.Error(rmErr))
+			}
+		}
 		return DeploymentInfo{}, err
 	}
 	return info, nil
 }
 
-func (app Deployment) preprocess(ctx context.Context, config Config) error {
+func (app Deployment) preprocess(ctx context.Context, config Config, timing *AppTiming) error {
 	must.OK(os.MkdirAll(config.AppDir+"/"+app.Name, 0o700))
 
 	if len(app.Requires.Dependencies) > 0 {
-		waitCtx, waitCancel := context.WithTimeout(ctx, app.Requires.Timeout)
-		defer waitCancel()
-		if err := WaitUntilHealthy(waitCtx, app.Requires.Dependencies...); err != nil {
+		err := timing.Measure(PhaseHealthWait, func() error {
+			waitCtx, waitCancel := context.WithTimeout(ctx, app.Requires.Timeout)
+			defer waitCancel()
+			return WaitUntilHealthy(waitCtx, app.Requires.Dependencies...)
+		})
+		if err != nil {
 			return err
 		}
 	}
@@ -338,17 +521,21 @@ func (app Deployment) preprocess(ctx context.Context, config Config) error {
 	}
 
 	if app.PrepareFunc != nil {
-		return app.PrepareFunc()
+		return timing.Measure(PhasePrepare, func() error {
+			return app.PrepareFunc(ctx)
+		})
 	}
 	return nil
 }
 
-func (app Deployment) postprocess(ctx context.Context, info DeploymentInfo) error {
+func (app Deployment) postprocess(ctx context.Context, info DeploymentInfo, timing *AppTiming) error {
 	if app.Info.Info().Status == AppStatusStopped {
 		return nil
 	}
 	if app.ConfigureFunc != nil {
-		return app.ConfigureFunc(ctx, info)
+		return timing.Measure(PhaseConfigure, func() error {
+			return app.ConfigureFunc(ctx, info)
+		})
 	}
 	return nil
 }
@@ -360,15 +547,46 @@ func NewConfigFactory() *ConfigFactory {
 
 // ConfigFactory collects config from CLI and produces real config.
 type ConfigFactory struct {
+	// EnvFile is the path to the environment file, empty means znet.yaml is loaded from the current directory if it
+	// exists
+	EnvFile string
+
 	// EnvName is the name of created environment
 	EnvName string
 
 	// Profiles defines the list of application profiles to run
 	Profiles []string
 
+	// Validators is the number of cored validators, it replaces the cored profile, 0 means profile is not replaced
+	Validators int
+
 	// CoredVersion defines the version of the cored to be used on start
 	CoredVersion string
 
+	// GaiaVersion defines the version of gaia to be used on start, empty means the default one
+	GaiaVersion string
+
+	// GenesisDenoms defines additional denoms, in the form of <base>:<display>:<exponent>, created at genesis
+	GenesisDenoms []string
+
+	// GenesisParams defines overrides of governance and staking parameters, in the form of <param>=<value>, applied
+	// to genesis
+	GenesisParams []string
+
+	// GenesisPatch is the path to the file containing JSON merge patch applied to the generated genesis
+	GenesisPatch string
+
+	// CoredSentries is the number of sentry nodes fronting each cored validator, 0 means validators are connected
+	// directly
+	CoredSentries int
+
+	// AccountPoolSize is the number of accounts funded in genesis which may be leased by tests and tools
+	AccountPoolSize int
+
+	// Contracts are the WASM contracts, in the form of <wasm-file>[:<instantiate-msg-file>], deployed when environment
+	// is started for the first time
+	Contracts []string
+
 	// HomeDir is the path where all the files are kept
 	HomeDir string
 
@@ -381,11 +599,95 @@ type ConfigFactory struct {
 	// TestGroups limits running integration tests on selected repository test group, empty means no filter
 	TestGroups []string
 
+	// TestParallelism is the maximum number of independent test groups executed in parallel
+	TestParallelism int
+
+	// NetworkFile is the path to the file describing external network tests are executed against, empty means tests
+	// are executed against local environment
+	NetworkFile string
+
+	// ReportDir is the directory where JUnit XML and JSON reports of integration tests are stored, empty means
+	// reports are not generated
+	ReportDir string
+
+	// CoverageDir is the directory where coverage of integration tests is stored, empty means coverage is not
+	// collected
+	CoverageDir string
+
+	// RecordTraffic enables recording of gRPC traffic between integration tests and cored
+	RecordTraffic bool
+
+	// RecordBodyLimit is the maximum number of bytes of each request and response body recorded
+	RecordBodyLimit int
+
 	// VerboseLogging turns on verbose logging
 	VerboseLogging bool
 
 	// LogFormat is the format used to encode logs
 	LogFormat string
+
+	// ContainerRuntime is the CLI used to manage containers, docker or podman, empty means it is detected
+	// automatically
+	ContainerRuntime string
+
+	// RemoteHomeDir is the path of the home directory on the remote docker host, where it is shared e.g. using sshfs
+	RemoteHomeDir string
+
+	// ImagePullPolicy defines when images of the apps are pulled: always, if-not-present or never
+	ImagePullPolicy string
+
+	// RegistryMirror is the registry, optionally followed by the path, prefixing all the pulled images
+	RegistryMirror string
+
+	// Resources defines overrides of resource limits of the apps, in the form of <app>.<resource>=<value>, where
+	// <app> is the name or the type of the app
+	Resources []string
+
+	// Subnet is the subnet of docker network created for the environment, empty means it is selected automatically
+	Subnet string
+
+	// NetworkName is the name of docker network created for the environment, empty means the name of the environment
+	NetworkName string
+
+	// IPv6 enables IPv6 in docker network created for the environment
+	IPv6 bool
+
+	// NetworkAliases are additional names, in the form of <app>=<alias>, apps are reachable under in docker network
+	NetworkAliases []string
+
+	// MockServerMappings is the path to the directory containing stub mappings served by mock server
+	MockServerMappings string
+
+	// FaucetTransferAmount is the amount of tokens transferred by faucet on each request
+	FaucetTransferAmount int
+
+	// FaucetDenom is the denom of tokens transferred by faucet, empty means the default denom of the chain
+	FaucetDenom string
+
+	// FaucetRateLimit is the maximum number of requests served by faucet for each IP address per minute, 0 means
+	// there is no limit
+	FaucetRateLimit int
+
+	// RelayerGasPriceMultiplier multiplies gas prices paid by relayers on all the chains
+	RelayerGasPriceMultiplier float64
+
+	// RelayerMemo is the memo attached to transactions broadcast by relayers
+	RelayerMemo string
+
+	// RelayerChannels are the channels, in the form of <relayer>=<channel>, of the source chain relayers relay
+	// packets from
+	RelayerChannels []string
+
+	// AutoPorts enables shifting ports of fresh environment if default ones are in use
+	AutoPorts bool
+
+	// KeyBackup is the file where encrypted keys of cored nodes are stored when environment is created, empty means
+	// keys are not backed up
+	KeyBackup string
+
+	// KeyRestore is the file containing encrypted keys of cored nodes used when environment is created, empty means
+	// random keys are generated
+	KeyRestore string
 }
 
 // NewSpec returns new spec.
@@ -427,10 +729,19 @@ type Spec struct {
 	// Env is the name of env
 	Env string `json:"env"`
 
+	// FrozenAt is the time when environment was frozen, nil if it is not frozen
+	FrozenAt *time.Time `json:"frozenAt,omitempty"`
+
+	// PortOffset is added to all the ports used by apps, it is set by `--auto-ports` if default ones are taken
+	PortOffset int `json:"portOffset,omitempty"`
+
 	mu sync.Mutex
 
 	// Apps is the description of running apps
 	Apps map[string]*AppInfo `json:"apps"`
+
+	// AccountPool is the list of accounts funded in genesis which may be leased
+	AccountPool []PoolAccount `json:"accountPool,omitempty"`
 }
 
 // Verify verifies that env and profiles in config matches the ones in spec.
@@ -441,6 +752,9 @@ func (s *Spec) Verify() error {
 	if !profilesCompare(s.Profiles, s.configF.Profiles) {
 		return errors.Errorf("profile mismatch, spec: %s, config: %s", strings.Join(s.Profiles, ","), strings.Join(s.configF.Profiles, ","))
 	}
+	if s.FrozenAt != nil {
+		return errors.Errorf("environment has been frozen at %s, thaw it first", s.FrozenAt.Local().Format(time.DateTime))
+	}
 	return nil
 }
 
@@ -495,6 +809,9 @@ type appInfoData struct {
 
 	// Info stores app deployment information
 	Info DeploymentInfo `json:"info"`
+
+	// IBCPath stores IBC identifiers of the path established by the relayer app
+	IBCPath *IBCPath `json:"ibcPath,omitempty"`
 }
 
 // AppInfo describes app running in environment.
@@ -504,6 +821,14 @@ type AppInfo struct {
 	data appInfoData
 }
 
+// Type returns type of the app.
+func (ai *AppInfo) Type() AppType {
+	ai.mu.RLock()
+	defer ai.mu.RUnlock()
+
+	return ai.data.Type
+}
+
 // SetInfo sets deployment info.
 func (ai *AppInfo) SetInfo(info DeploymentInfo) {
 	ai.mu.Lock()
@@ -520,6 +845,22 @@ func (ai *AppInfo) Info() DeploymentInfo {
 	return ai.data.Info
 }
 
+// SetIBCPath sets IBC identifiers of the path established by the relayer app.
+func (ai *AppInfo) SetIBCPath(path IBCPath) {
+	ai.mu.Lock()
+	defer ai.mu.Unlock()
+
+	ai.data.IBCPath = &path
+}
+
+// IBCPath returns IBC identifiers of the path established by the relayer app, nil if they haven't been recorded.
+func (ai *AppInfo) IBCPath() *IBCPath {
+	ai.mu.RLock()
+	defer ai.mu.RUnlock()
+
+	return ai.data.IBCPath
+}
+
 // MarshalJSON marshals data to JSON.
 func (ai *AppInfo) MarshalJSON() ([]byte, error) {
 	ai.mu.RLock()
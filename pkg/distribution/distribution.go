@@ -0,0 +1,144 @@
+// Package distribution provides helpers to test distribution of staking rewards and validator commission on Coreum.
+package distribution
+
+import (
+	"context"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	distributiontypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	"github.com/pkg/errors"
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/retry"
+	"github.com/CoreumFoundation/coreum/pkg/client"
+	"github.com/CoreumFoundation/coreum/testutil/event"
+)
+
+// blockTimeout is the time after which block is considered as not produced. It is more than enough for cored
+// running in znet.
+const blockTimeout = 6 * time.Second
+
+// AwaitBlocks waits until the chain produces the number of blocks. Rewards are distributed at the beginning
+// of each block, so waiting for blocks is the way to accrue them without guessing the real time it takes.
+func AwaitBlocks(ctx context.Context, clientCtx client.Context, blocks int64) error {
+	height, err := latestHeight(ctx, clientCtx)
+	if err != nil {
+		return err
+	}
+	targetHeight := height + blocks
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Duration(blocks+1)*blockTimeout)
+	defer cancel()
+
+	return retry.Do(waitCtx, time.Second, func() error {
+		height, err := latestHeight(waitCtx, clientCtx)
+		if err != nil {
+			return retry.Retryable(err)
+		}
+		if height < targetHeight {
+			return retry.Retryable(errors.Errorf("waiting for block %d, current block: %d", targetHeight, height))
+		}
+		return nil
+	})
+}
+
+// AwaitRewards waits, block by block, until rewards of the delegation reach the minimum amount and returns them.
+// Error is returned if they are not reached within the maximum number of blocks.
+func AwaitRewards(
+	ctx context.Context,
+	clientCtx client.Context,
+	delegator sdk.AccAddress,
+	validator sdk.ValAddress,
+	minAmount sdk.Coin,
+	maxBlocks int64,
+) (sdk.DecCoins, error) {
+	queryClient := distributiontypes.NewQueryClient(clientCtx)
+	for i := int64(0); ; i++ {
+		res, err := queryClient.DelegationRewards(ctx, &distributiontypes.QueryDelegationRewardsRequest{
+			DelegatorAddress: delegator.String(),
+			ValidatorAddress: validator.String(),
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "querying rewards of %s delegated to %s failed", delegator, validator)
+		}
+		if res.Rewards.AmountOf(minAmount.Denom).GTE(minAmount.Amount.ToDec()) {
+			return res.Rewards, nil
+		}
+		if i == maxBlocks {
+			return nil, errors.Errorf("rewards of %s delegated to %s haven't reached %s in %d blocks, current: %s",
+				delegator, validator, minAmount, maxBlocks, res.Rewards)
+		}
+		if err := AwaitBlocks(ctx, clientCtx, 1); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// WithdrawRewards withdraws rewards of the delegator, taken from the client context, accrued by the delegation
+// to the validator and returns the withdrawn amount.
+func WithdrawRewards(
+	ctx context.Context,
+	clientCtx client.Context,
+	txf client.Factory,
+	validator sdk.ValAddress,
+) (sdk.Coins, error) {
+	res, err := client.BroadcastTx(ctx, clientCtx, txf, &distributiontypes.MsgWithdrawDelegatorReward{
+		DelegatorAddress: clientCtx.FromAddress().String(),
+		ValidatorAddress: validator.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withdrawnAmount(res.Events, distributiontypes.EventTypeWithdrawRewards)
+}
+
+// WithdrawCommission withdraws commission of the validator operated by the account taken from the client context
+// and returns the withdrawn amount.
+func WithdrawCommission(ctx context.Context, clientCtx client.Context, txf client.Factory) (sdk.Coins, error) {
+	res, err := client.BroadcastTx(ctx, clientCtx, txf, &distributiontypes.MsgWithdrawValidatorCommission{
+		ValidatorAddress: sdk.ValAddress(clientCtx.FromAddress()).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withdrawnAmount(res.Events, distributiontypes.EventTypeWithdrawCommission)
+}
+
+// SplitRewards splits rewards allocated to the validator into its commission and the part shared by the delegators,
+// the same way the distribution module does it.
+func SplitRewards(rewards sdk.DecCoins, commissionRate sdk.Dec) (commission, shared sdk.DecCoins) {
+	commission = rewards.MulDec(commissionRate)
+	return commission, rewards.Sub(commission)
+}
+
+// DelegationRewards returns the part of rewards shared by the delegators which belongs to the delegation.
+// Distribution module truncates the intermediate results, so the real rewards may be lower by a few base units.
+func DelegationRewards(shared sdk.DecCoins, delegationTokens, validatorTokens sdk.Int) sdk.DecCoins {
+	return shared.MulDecTruncate(delegationTokens.ToDec()).QuoDecTruncate(validatorTokens.ToDec())
+}
+
+func withdrawnAmount(events []abci.Event, eventType string) (sdk.Coins, error) {
+	amount, err := event.FindStringEventAttribute(events, eventType, sdk.AttributeKeyAmount)
+	if err != nil {
+		return nil, err
+	}
+	coins, err := sdk.ParseCoinsNormalized(amount)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing withdrawn amount %q failed", amount)
+	}
+	return coins, nil
+}
+
+func latestHeight(ctx context.Context, clientCtx client.Context) (int64, error) {
+	requestCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	latestBlock, err := tmservice.NewServiceClient(clientCtx).GetLatestBlock(requestCtx,
+		&tmservice.GetLatestBlockRequest{})
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return latestBlock.Block.Header.Height, nil
+}
@@ -0,0 +1,68 @@
+package indexer
+
+import (
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/std"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	vestingtypes "github.com/cosmos/cosmos-sdk/x/auth/vesting/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	crisistypes "github.com/cosmos/cosmos-sdk/x/crisis/types"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	evidencetypes "github.com/cosmos/cosmos-sdk/x/evidence/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	paramsproposal "github.com/cosmos/cosmos-sdk/x/params/types/proposal"
+	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	upgradetypes "github.com/cosmos/cosmos-sdk/x/upgrade/types"
+	transfertypes "github.com/cosmos/ibc-go/v4/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
+	connectiontypes "github.com/cosmos/ibc-go/v4/modules/core/03-connection/types"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+	solomachinetypes "github.com/cosmos/ibc-go/v4/modules/light-clients/06-solomachine/types"
+	ibctmtypes "github.com/cosmos/ibc-go/v4/modules/light-clients/07-tendermint/types"
+
+	assetfttypes "github.com/CoreumFoundation/coreum/x/asset/ft/types"
+	assetnfttypes "github.com/CoreumFoundation/coreum/x/asset/nft/types"
+	"github.com/CoreumFoundation/coreum/x/nft"
+)
+
+// newEncoding returns the interface registry and the transaction config able to decode messages of modules
+// included in cored.
+func newEncoding() (codectypes.InterfaceRegistry, client.TxConfig) {
+	registry := codectypes.NewInterfaceRegistry()
+	for _, register := range []func(codectypes.InterfaceRegistry){
+		std.RegisterInterfaces,
+		authtypes.RegisterInterfaces,
+		vestingtypes.RegisterInterfaces,
+		authz.RegisterInterfaces,
+		banktypes.RegisterInterfaces,
+		crisistypes.RegisterInterfaces,
+		distrtypes.RegisterInterfaces,
+		evidencetypes.RegisterInterfaces,
+		feegrant.RegisterInterfaces,
+		govtypes.RegisterInterfaces,
+		paramsproposal.RegisterInterfaces,
+		slashingtypes.RegisterInterfaces,
+		stakingtypes.RegisterInterfaces,
+		upgradetypes.RegisterInterfaces,
+		transfertypes.RegisterInterfaces,
+		clienttypes.RegisterInterfaces,
+		connectiontypes.RegisterInterfaces,
+		channeltypes.RegisterInterfaces,
+		solomachinetypes.RegisterInterfaces,
+		ibctmtypes.RegisterInterfaces,
+		wasmtypes.RegisterInterfaces,
+		assetfttypes.RegisterInterfaces,
+		assetnfttypes.RegisterInterfaces,
+		nft.RegisterInterfaces,
+	} {
+		register(registry)
+	}
+	return registry, authtx.NewTxConfig(codec.NewProtoCodec(registry), authtx.DefaultSignModes)
+}
@@ -0,0 +1,361 @@
+// Package indexer provides the indexer storing blocks, transactions and events produced by cored node in postgres
+// database, so APIs may be built on top of the indexed store.
+package indexer
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	abci "github.com/tendermint/tendermint/abci/types"
+	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum-tools/pkg/parallel"
+)
+
+//go:embed schema.sql
+var schema string
+
+// Sources of the events.
+const (
+	SourceBeginBlock = "begin_block"
+	SourceTx         = "tx"
+	SourceEndBlock   = "end_block"
+)
+
+const (
+	// subscriber is the name of the subscriber of new block events.
+	subscriber = "indexer"
+
+	// requestTimeout is the timeout of the single request sent to the node.
+	requestTimeout = 10 * time.Second
+)
+
+// Config is the configuration of the indexer.
+type Config struct {
+	// Node is the RPC address of the cored node
+	Node string
+
+	// Database is the connection string of postgres database
+	Database string
+
+	// Address is the address the status server listens on
+	Address string
+
+	// PollInterval is the interval between queries for new blocks, used if new block event is missed
+	PollInterval time.Duration
+}
+
+// Status is the status of the indexer reported by the server.
+type Status struct {
+	// Height is the last height indexed
+	Height int64 `json:"height"`
+}
+
+// Run loads the schema into the database and indexes blocks until context is canceled. Indexing is resumed from
+// the last block stored in the database. Status is reported by the server at `/status`.
+func Run(ctx context.Context, config Config) error {
+	db, err := pgx.Connect(ctx, config.Database)
+	if err != nil {
+		return errors.Wrap(err, "connecting to the database failed")
+	}
+	defer db.Close(context.Background())
+
+	if _, err := db.Exec(ctx, schema); err != nil {
+		return errors.Wrap(err, "loading schema failed")
+	}
+
+	rpcClient, err := rpchttp.New(config.Node, "/websocket")
+	if err != nil {
+		return errors.Wrapf(err, "creating client of node %s failed", config.Node)
+	}
+
+	registry, txConfig := newEncoding()
+	ix := &indexer{
+		db:       db,
+		client:   rpcClient,
+		registry: registry,
+		txConfig: txConfig,
+	}
+	if err := db.QueryRow(ctx, "SELECT COALESCE(MAX(height), 0) FROM blocks").Scan(&ix.status.Height); err != nil {
+		return errors.Wrap(err, "querying last indexed height failed")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", ix.serveStatus)
+
+	listener, err := net.Listen("tcp", config.Address)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	httpServer := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	logger.Get(ctx).Info("Indexer started", zap.String("node", config.Node),
+		zap.String("address", listener.Addr().String()), zap.Int64("height", ix.status.Height))
+	return parallel.Run(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
+		spawn("indexer", parallel.Fail, func(ctx context.Context) error {
+			return ix.run(ctx, config.PollInterval)
+		})
+		spawn("server", parallel.Fail, func(ctx context.Context) error {
+			if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return errors.WithStack(err)
+			}
+			return nil
+		})
+		spawn("shutdown", parallel.Exit, func(ctx context.Context) error {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			return errors.WithStack(httpServer.Shutdown(shutdownCtx))
+		})
+		return nil
+	})
+}
+
+type indexer struct {
+	db       *pgx.Conn
+	client   *rpchttp.HTTP
+	registry codectypes.InterfaceRegistry
+	txConfig client.TxConfig
+
+	mu     sync.Mutex
+	status Status
+}
+
+func (ix *indexer) serveStatus(w http.ResponseWriter, r *http.Request) {
+	ix.mu.Lock()
+	status := ix.status
+	ix.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+// run indexes new blocks each time new block event is received. Events are only the trigger, blocks are fetched
+// from the node, so none of them is skipped if events are lost, e.g. when websocket connection is reestablished.
+func (ix *indexer) run(ctx context.Context, pollInterval time.Duration) error {
+	if err := ix.client.Start(); err != nil {
+		return errors.Wrap(err, "starting websocket client failed")
+	}
+	defer func() {
+		_ = ix.client.Stop()
+	}()
+
+	events, err := ix.client.Subscribe(ctx, subscriber, tmtypes.EventQueryNewBlock.String())
+	if err != nil {
+		return errors.Wrap(err, "subscribing to new blocks failed")
+	}
+
+	log := logger.Get(ctx)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		if err := ix.sync(ctx); err != nil {
+			if ctx.Err() != nil {
+				return errors.WithStack(ctx.Err())
+			}
+			// Indexing is retried after next block is produced.
+			log.Warn("Indexing blocks failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.WithStack(ctx.Err())
+		case <-events:
+		case <-ticker.C:
+		}
+	}
+}
+
+// sync indexes all the blocks produced since the last indexed one.
+func (ix *indexer) sync(ctx context.Context) error {
+	requestCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	status, err := ix.client.Status(requestCtx)
+	cancel()
+	if err != nil {
+		return errors.Wrap(err, "querying status of the node failed")
+	}
+
+	ix.mu.Lock()
+	height := ix.status.Height
+	ix.mu.Unlock()
+
+	if height < status.SyncInfo.EarliestBlockHeight-1 {
+		// Blocks pruned by the node can't be indexed.
+		height = status.SyncInfo.EarliestBlockHeight - 1
+	}
+	for height < status.SyncInfo.LatestBlockHeight {
+		height++
+		if err := ix.indexBlock(ctx, height); err != nil {
+			return errors.Wrapf(err, "indexing block %d failed", height)
+		}
+
+		ix.mu.Lock()
+		ix.status.Height = height
+		ix.mu.Unlock()
+	}
+	return nil
+}
+
+// indexBlock stores the block, its transactions and events in the database, all of them are stored in one database
+// transaction, so block is never indexed partially.
+func (ix *indexer) indexBlock(ctx context.Context, height int64) error {
+	requestCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	block, err := ix.client.Block(requestCtx, &height)
+	if err != nil {
+		return errors.Wrap(err, "fetching block failed")
+	}
+	results, err := ix.client.BlockResults(requestCtx, &height)
+	if err != nil {
+		return errors.Wrap(err, "fetching block results failed")
+	}
+	if len(results.TxsResults) != len(block.Block.Txs) {
+		return errors.Errorf("number of transaction results %d doesn't match number of transactions %d",
+			len(results.TxsResults), len(block.Block.Txs))
+	}
+
+	dbTx, err := ix.db.Begin(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() {
+		_ = dbTx.Rollback(ctx)
+	}()
+
+	if _, err := dbTx.Exec(ctx,
+		"INSERT INTO blocks (height, hash, time, proposer_address, num_txs) VALUES ($1, $2, $3, $4, $5)",
+		height, block.BlockID.Hash.String(), block.Block.Time, block.Block.ProposerAddress.String(),
+		len(block.Block.Txs)); err != nil {
+		return errors.Wrap(err, "storing block failed")
+	}
+	if err := insertEvents(ctx, dbTx, height, SourceBeginBlock, nil, results.BeginBlockEvents); err != nil {
+		return err
+	}
+	for i, txBytes := range block.Block.Txs {
+		if err := ix.indexTx(ctx, dbTx, height, i, txBytes, results.TxsResults[i]); err != nil {
+			return err
+		}
+	}
+	if err := insertEvents(ctx, dbTx, height, SourceEndBlock, nil, results.EndBlockEvents); err != nil {
+		return err
+	}
+
+	return errors.WithStack(dbTx.Commit(ctx))
+}
+
+func (ix *indexer) indexTx(
+	ctx context.Context,
+	dbTx pgx.Tx,
+	height int64,
+	index int,
+	txBytes tmtypes.Tx,
+	result *abci.ResponseDeliverTx,
+) error {
+	hash := fmt.Sprintf("%X", txBytes.Hash())
+
+	var memo *string
+	var messages []json.RawMessage
+	var msgTypes []string
+	// Transactions containing messages unknown to the indexer are stored without decoded messages.
+	if decodedTx, err := ix.txConfig.TxDecoder()(txBytes); err == nil {
+		if txWithMemo, ok := decodedTx.(sdk.TxWithMemo); ok {
+			m := txWithMemo.GetMemo()
+			memo = &m
+		}
+		for _, msg := range decodedTx.GetMsgs() {
+			msgJSON, err := ix.msgJSON(msg)
+			if err != nil {
+				return errors.Wrapf(err, "encoding message of transaction %s failed", hash)
+			}
+			messages = append(messages, msgJSON)
+			msgTypes = append(msgTypes, sdk.MsgTypeURL(msg))
+		}
+	}
+
+	var messagesJSON []byte
+	if messages != nil {
+		var err error
+		messagesJSON, err = json.Marshal(messages)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if _, err := dbTx.Exec(ctx,
+		`INSERT INTO transactions (hash, height, tx_index, code, codespace, log, gas_wanted, gas_used, memo, messages, raw)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		hash, height, index, result.Code, result.Codespace, result.Log, result.GasWanted, result.GasUsed, memo,
+		messagesJSON, []byte(txBytes)); err != nil {
+		return errors.Wrapf(err, "storing transaction %s failed", hash)
+	}
+	for i, msgType := range msgTypes {
+		if _, err := dbTx.Exec(ctx,
+			"INSERT INTO messages (tx_hash, msg_index, type, height) VALUES ($1, $2, $3, $4)",
+			hash, i, msgType, height); err != nil {
+			return errors.Wrapf(err, "storing message of transaction %s failed", hash)
+		}
+	}
+	return insertEvents(ctx, dbTx, height, SourceTx, &hash, result.Events)
+}
+
+// msgJSON encodes message to JSON containing its type in the `@type` field.
+func (ix *indexer) msgJSON(msg sdk.Msg) (json.RawMessage, error) {
+	msgAny, err := codectypes.NewAnyWithValue(msg)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	msgJSON, err := codec.ProtoMarshalJSON(msgAny, ix.registry)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return msgJSON, nil
+}
+
+type eventAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func insertEvents(
+	ctx context.Context,
+	dbTx pgx.Tx,
+	height int64,
+	source string,
+	txHash *string,
+	events []abci.Event,
+) error {
+	for i, event := range events {
+		attributes := make([]eventAttribute, 0, len(event.Attributes))
+		for _, attr := range event.Attributes {
+			attributes = append(attributes, eventAttribute{Key: string(attr.Key), Value: string(attr.Value)})
+		}
+		attributesJSON, err := json.Marshal(attributes)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if _, err := dbTx.Exec(ctx,
+			"INSERT INTO events (height, source, tx_hash, event_index, type, attributes) VALUES ($1, $2, $3, $4, $5, $6)",
+			height, source, txHash, i, event.Type, attributesJSON); err != nil {
+			return errors.Wrapf(err, "storing %s event failed", source)
+		}
+	}
+	return nil
+}
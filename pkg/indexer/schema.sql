@@ -0,0 +1,50 @@
+CREATE TABLE IF NOT EXISTS blocks
+(
+    height           BIGINT PRIMARY KEY,
+    hash             TEXT                     NOT NULL UNIQUE,
+    time             TIMESTAMP WITH TIME ZONE NOT NULL,
+    proposer_address TEXT                     NOT NULL,
+    num_txs          INTEGER                  NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS transactions
+(
+    hash       TEXT PRIMARY KEY,
+    height     BIGINT  NOT NULL REFERENCES blocks (height),
+    tx_index   INTEGER NOT NULL,
+    code       INTEGER NOT NULL,
+    codespace  TEXT    NOT NULL,
+    log        TEXT    NOT NULL,
+    gas_wanted BIGINT  NOT NULL,
+    gas_used   BIGINT  NOT NULL,
+    -- Memo and messages are NULL if transaction contains messages unknown to the indexer.
+    memo       TEXT,
+    messages   JSONB,
+    raw        BYTEA   NOT NULL
+);
+CREATE INDEX IF NOT EXISTS transactions_height_index ON transactions (height);
+
+CREATE TABLE IF NOT EXISTS messages
+(
+    tx_hash   TEXT    NOT NULL REFERENCES transactions (hash),
+    msg_index INTEGER NOT NULL,
+    type      TEXT    NOT NULL,
+    height    BIGINT  NOT NULL,
+    PRIMARY KEY (tx_hash, msg_index)
+);
+CREATE INDEX IF NOT EXISTS messages_type_index ON messages (type);
+
+CREATE TABLE IF NOT EXISTS events
+(
+    height      BIGINT  NOT NULL REFERENCES blocks (height),
+    -- Source is one of: begin_block, tx, end_block.
+    source      TEXT    NOT NULL,
+    -- Hash of the transaction is NULL for events emitted by begin and end blockers.
+    tx_hash     TEXT REFERENCES transactions (hash),
+    event_index INTEGER NOT NULL,
+    type        TEXT    NOT NULL,
+    attributes  JSONB   NOT NULL
+);
+CREATE INDEX IF NOT EXISTS events_height_index ON events (height);
+CREATE INDEX IF NOT EXISTS events_tx_hash_index ON events (tx_hash);
+CREATE INDEX IF NOT EXISTS events_type_index ON events (type);
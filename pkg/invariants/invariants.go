@@ -0,0 +1,460 @@
+// Package invariants provides the verifier watching new blocks produced by the cored nodes and checking invariants
+// which must hold after each block.
+package invariants
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	abci "github.com/tendermint/tendermint/abci/types"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum-tools/pkg/parallel"
+)
+
+// Names of the verified invariants.
+const (
+	InvariantSupply   = "supply"
+	InvariantAppHash  = "app-hash"
+	InvariantLiveness = "liveness"
+)
+
+const (
+	// maxNodeLag is the number of blocks node may stay behind the others before it is reported as not advancing and
+	// excluded from the verification of supply, so stopped or stuck nodes don't block it.
+	maxNodeLag = 10
+
+	// requestTimeout is the timeout of the single request sent to the node.
+	requestTimeout = 5 * time.Second
+)
+
+// Config is the configuration of the verifier.
+type Config struct {
+	// Nodes is the list of RPC addresses of the nodes, supply is verified using the first one reachable
+	Nodes []string
+
+	// Address is the address the status server listens on
+	Address string
+
+	// PollInterval is the interval between queries for new blocks
+	PollInterval time.Duration
+}
+
+// Discrepancy describes broken invariant.
+type Discrepancy struct {
+	Height    int64     `json:"height"`
+	Invariant string    `json:"invariant"`
+	Details   string    `json:"details"`
+	Time      time.Time `json:"time"`
+}
+
+// Status is the status of the verifier reported by the server.
+type Status struct {
+	// Height is the last height verified
+	Height        int64         `json:"height"`
+	Discrepancies []Discrepancy `json:"discrepancies"`
+}
+
+type node struct {
+	Address string
+	Client  *rpchttp.HTTP
+}
+
+// nodeState is the state of verification of the single node.
+type nodeState struct {
+	// appHashHeight is the last height the app hash computed by the node was verified at
+	appHashHeight int64
+
+	// pendingHeight and pendingAppHash are the app hash computed by the node waiting for the next block to be
+	// produced, pendingHeight is 0 if nothing is pending
+	pendingHeight  int64
+	pendingAppHash []byte
+
+	// lagging is set once the node is reported as not advancing, so it is reported once until it catches up
+	lagging bool
+}
+
+// Run verifies invariants of each new block until context is canceled. Discrepancies are logged as soon as they are
+// found and reported by the status server at `/status`.
+func Run(ctx context.Context, config Config) error {
+	if len(config.Nodes) == 0 {
+		return errors.New("no nodes provided")
+	}
+	nodes := make([]node, 0, len(config.Nodes))
+	for _, address := range config.Nodes {
+		client, err := rpchttp.New(address, "/websocket")
+		if err != nil {
+			return errors.Wrapf(err, "creating client of node %s failed", address)
+		}
+		nodes = append(nodes, node{Address: address, Client: client})
+	}
+
+	v := &verifier{
+		nodes:  nodes,
+		states: map[string]*nodeState{},
+	}
+	for _, n := range nodes {
+		v.states[n.Address] = &nodeState{}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", v.serveStatus)
+
+	listener, err := net.Listen("tcp", config.Address)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	httpServer := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	logger.Get(ctx).Info("Invariant verifier started", zap.Strings("nodes", config.Nodes),
+		zap.String("address", listener.Addr().String()))
+	return parallel.Run(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
+		spawn("verifier", parallel.Fail, func(ctx context.Context) error {
+			return v.run(ctx, config.PollInterval)
+		})
+		spawn("server", parallel.Fail, func(ctx context.Context) error {
+			if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return errors.WithStack(err)
+			}
+			return nil
+		})
+		spawn("shutdown", parallel.Exit, func(ctx context.Context) error {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			return errors.WithStack(httpServer.Shutdown(shutdownCtx))
+		})
+		return nil
+	})
+}
+
+type verifier struct {
+	nodes  []node
+	states map[string]*nodeState
+
+	mu     sync.Mutex
+	status Status
+}
+
+func (v *verifier) serveStatus(w http.ResponseWriter, r *http.Request) {
+	v.mu.Lock()
+	status := v.status
+	status.Discrepancies = append([]Discrepancy{}, v.status.Discrepancies...)
+	v.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+func (v *verifier) run(ctx context.Context, pollInterval time.Duration) error {
+	log := logger.Get(ctx)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.WithStack(ctx.Err())
+		case <-ticker.C:
+		}
+
+		heights := v.latestHeights(ctx)
+		if len(heights) == 0 {
+			log.Debug("No node is reachable")
+			continue
+		}
+		v.verifyLiveness(ctx, heights)
+		if err := v.verifyAppHashes(ctx, heights); err != nil {
+			if ctx.Err() != nil {
+				return errors.WithStack(ctx.Err())
+			}
+			// App hashes are verified again in the next round.
+			log.Warn("Verifying app hashes failed", zap.Error(err))
+		}
+
+		v.mu.Lock()
+		verifiedHeight := v.status.Height
+		v.mu.Unlock()
+
+		target := verifiableHeight(heights)
+		if verifiedHeight == 0 {
+			// Verification starts from the current block, history is not verified.
+			verifiedHeight = target - 1
+		}
+		for height := verifiedHeight + 1; height <= target; height++ {
+			nodes := lo.Filter(v.nodes, func(n node, _ int) bool { return heights[n.Address] >= height })
+			if err := v.verifySupply(ctx, nodes[0], height); err != nil {
+				if ctx.Err() != nil {
+					return errors.WithStack(ctx.Err())
+				}
+				// Block is verified again in the next round.
+				log.Warn("Verifying block failed", zap.Int64("height", height), zap.Error(err))
+				break
+			}
+			v.mu.Lock()
+			v.status.Height = height
+			v.mu.Unlock()
+		}
+	}
+}
+
+// latestHeights returns the latest heights of reachable nodes.
+func (v *verifier) latestHeights(ctx context.Context) map[string]int64 {
+	heights := map[string]int64{}
+	for _, n := range v.nodes {
+		requestCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+		status, err := n.Client.Status(requestCtx)
+		cancel()
+		if err != nil {
+			continue
+		}
+		heights[n.Address] = status.SyncInfo.LatestBlockHeight
+	}
+	return heights
+}
+
+// verifiableHeight returns the highest block produced by all the nodes which are not far behind the leading one.
+func verifiableHeight(heights map[string]int64) int64 {
+	var highest int64
+	for _, height := range heights {
+		if height > highest {
+			highest = height
+		}
+	}
+	target := highest
+	for _, height := range heights {
+		if height < target && highest-height <= maxNodeLag {
+			target = height
+		}
+	}
+	return target
+}
+
+// highestNode returns the address and the height of the node which produced the highest block.
+func highestNode(heights map[string]int64) (string, int64) {
+	var address string
+	var highest int64
+	for nodeAddress, height := range heights {
+		if height > highest || (height == highest && nodeAddress < address) {
+			address = nodeAddress
+			highest = height
+		}
+	}
+	return address, highest
+}
+
+// verifyLiveness reports nodes which are unreachable or stay behind the leading one by more than maxNodeLag blocks.
+// Node which computed different app hash rejects next blocks and stops advancing, so it is reported here too.
+func (v *verifier) verifyLiveness(ctx context.Context, heights map[string]int64) {
+	_, highest := highestNode(heights)
+	for _, n := range v.nodes {
+		state := v.states[n.Address]
+		height, reachable := heights[n.Address]
+		if reachable && highest-height <= maxNodeLag {
+			if state.lagging {
+				logger.Get(ctx).Info("Node caught up", zap.String("node", n.Address), zap.Int64("height", height))
+			}
+			state.lagging = false
+			continue
+		}
+		if state.lagging {
+			continue
+		}
+		state.lagging = true
+
+		details := fmt.Sprintf("node %s is unreachable", n.Address)
+		if reachable {
+			details = fmt.Sprintf("node %s stopped advancing at height %d, %d blocks behind", n.Address, height,
+				highest-height)
+		}
+		v.report(ctx, Discrepancy{
+			Height:    highest,
+			Invariant: InvariantLiveness,
+			Details:   details,
+		})
+	}
+}
+
+// verifyAppHashes verifies that app hash computed by each node itself, taken from its ABCI info, is equal to the one
+// committed by the network. App hash of the state after executing the block is stored in the header of the next one,
+// so app hash taken from the node is verified once the leading node produced the next block. Headers returned by all
+// the nodes are the same, so they can't be compared directly. Node computing different app hash rejects next blocks,
+// so its last app hash is always verified, while healthy nodes are verified at the heights sampled in each round.
+func (v *verifier) verifyAppHashes(ctx context.Context, heights map[string]int64) error {
+	leader, highest := highestNode(heights)
+	leaderNode, _ := lo.Find(v.nodes, func(n node) bool { return n.Address == leader })
+	for _, n := range v.nodes {
+		state := v.states[n.Address]
+		if _, reachable := heights[n.Address]; !reachable {
+			continue
+		}
+
+		if state.pendingHeight == 0 {
+			requestCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+			info, err := n.Client.ABCIInfo(requestCtx)
+			cancel()
+			if err != nil {
+				return errors.Wrapf(err, "fetching ABCI info from node %s failed", n.Address)
+			}
+			if info.Response.LastBlockHeight <= state.appHashHeight {
+				continue
+			}
+			state.pendingHeight = info.Response.LastBlockHeight
+			state.pendingAppHash = info.Response.LastBlockAppHash
+		}
+		if state.pendingHeight >= highest {
+			continue
+		}
+
+		nextHeight := state.pendingHeight + 1
+		requestCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+		block, err := leaderNode.Client.Block(requestCtx, &nextHeight)
+		cancel()
+		if err != nil {
+			return errors.Wrapf(err, "fetching block from node %s failed", leader)
+		}
+
+		height, appHash := state.pendingHeight, state.pendingAppHash
+		state.appHashHeight = height
+		state.pendingHeight = 0
+		state.pendingAppHash = nil
+		if bytes.Equal(appHash, block.Block.AppHash) {
+			continue
+		}
+		v.report(ctx, Discrepancy{
+			Height:    height,
+			Invariant: InvariantAppHash,
+			Details: fmt.Sprintf("node %s computed app hash %X, app hash committed by the network is %s", n.Address,
+				appHash, block.Block.AppHash),
+		})
+	}
+	return nil
+}
+
+// verifySupply verifies that the change of the total supply caused by the block is equal to the amount minted minus
+// the amount burned, according to the events emitted by the bank module.
+func (v *verifier) verifySupply(ctx context.Context, n node, height int64) error {
+	if height < 2 {
+		return nil
+	}
+	supplyBefore, err := totalSupply(ctx, n.Client, height-1)
+	if err != nil {
+		return err
+	}
+	supplyAfter, err := totalSupply(ctx, n.Client, height)
+	if err != nil {
+		return err
+	}
+
+	requestCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	results, err := n.Client.BlockResults(requestCtx, &height)
+	cancel()
+	if err != nil {
+		return errors.Wrapf(err, "fetching results of block from node %s failed", n.Address)
+	}
+	events := append([]abci.Event{}, results.BeginBlockEvents...)
+	for _, txResult := range results.TxsResults {
+		events = append(events, txResult.Events...)
+	}
+	events = append(events, results.EndBlockEvents...)
+
+	minted, err := sumAmounts(events, banktypes.EventTypeCoinMint)
+	if err != nil {
+		return err
+	}
+	burned, err := sumAmounts(events, banktypes.EventTypeCoinBurn)
+	if err != nil {
+		return err
+	}
+
+	expected, negative := supplyBefore.Add(minted...).SafeSub(burned)
+	if !negative && expected.String() == supplyAfter.String() {
+		return nil
+	}
+	v.report(ctx, Discrepancy{
+		Height:    height,
+		Invariant: InvariantSupply,
+		Details: fmt.Sprintf("supply before: %s, minted: %s, burned: %s, supply after: %s", supplyBefore, minted,
+			burned, supplyAfter),
+	})
+	return nil
+}
+
+func (v *verifier) report(ctx context.Context, discrepancy Discrepancy) {
+	discrepancy.Time = time.Now().UTC()
+	logger.Get(ctx).Error("Invariant broken", zap.String("invariant", discrepancy.Invariant),
+		zap.Int64("height", discrepancy.Height), zap.String("details", discrepancy.Details))
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.status.Discrepancies = append(v.status.Discrepancies, discrepancy)
+}
+
+// totalSupply queries the total supply of all the denoms at the height.
+func totalSupply(ctx context.Context, client *rpchttp.HTTP, height int64) (sdk.Coins, error) {
+	var supply sdk.Coins
+	req := &banktypes.QueryTotalSupplyRequest{Pagination: &query.PageRequest{}}
+	for {
+		reqBytes, err := req.Marshal()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		requestCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+		res, err := client.ABCIQueryWithOptions(requestCtx, "/cosmos.bank.v1beta1.Query/TotalSupply", reqBytes,
+			rpcclient.ABCIQueryOptions{Height: height})
+		cancel()
+		if err != nil {
+			return nil, errors.Wrapf(err, "querying total supply at height %d failed", height)
+		}
+		if !res.Response.IsOK() {
+			return nil, errors.Errorf("querying total supply at height %d failed: %s", height, res.Response.Log)
+		}
+
+		var resp banktypes.QueryTotalSupplyResponse
+		if err := resp.Unmarshal(res.Response.Value); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		supply = supply.Add(resp.Supply...)
+		if resp.Pagination == nil || len(resp.Pagination.NextKey) == 0 {
+			return supply, nil
+		}
+		req.Pagination.Key = resp.Pagination.NextKey
+	}
+}
+
+// sumAmounts sums the `amount` attributes of the events of the type.
+func sumAmounts(events []abci.Event, eventType string) (sdk.Coins, error) {
+	var sum sdk.Coins
+	for _, event := range events {
+		if event.Type != eventType {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if string(attr.Key) != sdk.AttributeKeyAmount {
+				continue
+			}
+			coins, err := sdk.ParseCoinsNormalized(string(attr.Value))
+			if err != nil {
+				return nil, errors.Wrapf(err, "parsing amount %q of %s event failed", attr.Value, eventType)
+			}
+			sum = sum.Add(coins...)
+		}
+	}
+	return sum, nil
+}
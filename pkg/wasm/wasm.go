@@ -0,0 +1,159 @@
+// Package wasm provides helpers to deploy WASM contracts and to test their migrations on Coreum.
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum/pkg/client"
+	"github.com/CoreumFoundation/coreum/testutil/event"
+)
+
+// InstantiateConfig contains parameters of contract instantiation.
+type InstantiateConfig struct {
+	CodeID  uint64
+	Admin   sdk.AccAddress
+	Label   string
+	Payload json.RawMessage
+	Funds   sdk.Coins
+}
+
+// Migration describes migration of the contract to the new code.
+type Migration struct {
+	// Contract is the address of the migrated contract
+	Contract string
+
+	// NewCode is the WASM byte code the contract is migrated to
+	NewCode []byte
+
+	// MigrateMsg is the message passed to the migrate entrypoint of the contract
+	MigrateMsg json.RawMessage
+
+	// StateQuery is the query executed before and after the migration to compare the state of the contract
+	StateQuery json.RawMessage
+}
+
+// MigrationResult is the result of the executed migration.
+type MigrationResult struct {
+	OldCodeID   uint64
+	NewCodeID   uint64
+	StateBefore json.RawMessage
+	StateAfter  json.RawMessage
+}
+
+// StateUnchanged returns true if state returned by the query is the same before and after the migration.
+func (r MigrationResult) StateUnchanged() bool {
+	return bytes.Equal(r.StateBefore, r.StateAfter)
+}
+
+// StoreCode stores the WASM byte code on chain and returns its code ID.
+func StoreCode(ctx context.Context, clientCtx client.Context, txf client.Factory, wasmData []byte) (uint64, error) {
+	res, err := client.BroadcastTx(ctx, clientCtx, txf, &wasmtypes.MsgStoreCode{
+		Sender:       clientCtx.FromAddress().String(),
+		WASMByteCode: wasmData,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return event.FindUint64EventAttribute(res.Events, wasmtypes.EventTypeStoreCode, wasmtypes.AttributeKeyCodeID)
+}
+
+// Instantiate instantiates the contract and returns its address.
+func Instantiate(ctx context.Context, clientCtx client.Context, txf client.Factory, cfg InstantiateConfig) (string, error) {
+	var admin string
+	if cfg.Admin != nil {
+		admin = cfg.Admin.String()
+	}
+	res, err := client.BroadcastTx(ctx, clientCtx, txf, &wasmtypes.MsgInstantiateContract{
+		Sender: clientCtx.FromAddress().String(),
+		Admin:  admin,
+		CodeID: cfg.CodeID,
+		Label:  cfg.Label,
+		Msg:    wasmtypes.RawContractMessage(cfg.Payload),
+		Funds:  cfg.Funds,
+	})
+	if err != nil {
+		return "", err
+	}
+	return event.FindStringEventAttribute(res.Events, wasmtypes.EventTypeInstantiate, wasmtypes.AttributeKeyContractAddr)
+}
+
+// Migrate migrates the contract to the code. Sender must be the admin of the contract.
+func Migrate(
+	ctx context.Context,
+	clientCtx client.Context,
+	txf client.Factory,
+	contract string,
+	codeID uint64,
+	migrateMsg json.RawMessage,
+) error {
+	_, err := client.BroadcastTx(ctx, clientCtx, txf, &wasmtypes.MsgMigrateContract{
+		Sender:   clientCtx.FromAddress().String(),
+		Contract: contract,
+		CodeID:   codeID,
+		Msg:      wasmtypes.RawContractMessage(migrateMsg),
+	})
+	return err
+}
+
+// Query executes smart query on the contract.
+func Query(ctx context.Context, clientCtx client.Context, contract string, payload json.RawMessage) (json.RawMessage, error) {
+	resp, err := wasmtypes.NewQueryClient(clientCtx).SmartContractState(ctx, &wasmtypes.QuerySmartContractStateRequest{
+		Address:   contract,
+		QueryData: wasmtypes.RawContractMessage(payload),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "querying contract %s failed", contract)
+	}
+	return json.RawMessage(resp.Data), nil
+}
+
+// CodeID returns the ID of the code used by the contract.
+func CodeID(ctx context.Context, clientCtx client.Context, contract string) (uint64, error) {
+	resp, err := wasmtypes.NewQueryClient(clientCtx).ContractInfo(ctx, &wasmtypes.QueryContractInfoRequest{
+		Address: contract,
+	})
+	if err != nil {
+		return 0, errors.Wrapf(err, "querying info of contract %s failed", contract)
+	}
+	return resp.CodeID, nil
+}
+
+// RunMigration stores the new code, migrates the contract to it and returns the state of the contract queried before
+// and after the migration, so assertions may be done on it.
+func RunMigration(ctx context.Context, clientCtx client.Context, txf client.Factory, m Migration) (MigrationResult, error) {
+	var (
+		result MigrationResult
+		err    error
+	)
+	if result.OldCodeID, err = CodeID(ctx, clientCtx, m.Contract); err != nil {
+		return MigrationResult{}, err
+	}
+	if result.StateBefore, err = Query(ctx, clientCtx, m.Contract, m.StateQuery); err != nil {
+		return MigrationResult{}, err
+	}
+	if result.NewCodeID, err = StoreCode(ctx, clientCtx, txf, m.NewCode); err != nil {
+		return MigrationResult{}, err
+	}
+	if err := Migrate(ctx, clientCtx, txf, m.Contract, result.NewCodeID, m.MigrateMsg); err != nil {
+		return MigrationResult{}, err
+	}
+
+	codeID, err := CodeID(ctx, clientCtx, m.Contract)
+	if err != nil {
+		return MigrationResult{}, err
+	}
+	if codeID != result.NewCodeID {
+		return MigrationResult{}, errors.Errorf("contract %s uses code %d after migration, expected: %d", m.Contract,
+			codeID, result.NewCodeID)
+	}
+	if result.StateAfter, err = Query(ctx, clientCtx, m.Contract, m.StateQuery); err != nil {
+		return MigrationResult{}, err
+	}
+	return result, nil
+}
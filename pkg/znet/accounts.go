@@ -0,0 +1,71 @@
+package znet
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/crust/infra"
+)
+
+// DefaultAccountLeaseTTL is the default time after which leased account returns to the pool if it hasn't been released.
+const DefaultAccountLeaseTTL = time.Hour
+
+type poolAccountStatus struct {
+	Address        string     `json:"address"`
+	Mnemonic       string     `json:"mnemonic"`
+	LeaseExpiresAt *time.Time `json:"leaseExpiresAt,omitempty"`
+}
+
+// AccountsList prints accounts of the pool together with their leases.
+func AccountsList(spec *infra.Spec) error {
+	leases, err := spec.AccountLeases()
+	if err != nil {
+		return err
+	}
+	expirations := make(map[string]time.Time, len(leases))
+	for _, lease := range leases {
+		expirations[lease.Address] = lease.ExpiresAt
+	}
+
+	accounts := make([]poolAccountStatus, 0, len(spec.AccountPool))
+	for _, account := range spec.AccountPool {
+		status := poolAccountStatus{
+			Address:  account.Address,
+			Mnemonic: account.Mnemonic,
+		}
+		if expiresAt, exists := expirations[account.Address]; exists {
+			status.LeaseExpiresAt = &expiresAt
+		}
+		accounts = append(accounts, status)
+	}
+	return printJSON(accounts)
+}
+
+// AccountsLease leases free account from the pool and prints it.
+func AccountsLease(spec *infra.Spec, ttl time.Duration) error {
+	if ttl <= 0 {
+		return errors.Errorf("invalid lease ttl %s, positive duration is expected", ttl)
+	}
+	account, err := spec.LeaseAccount(ttl)
+	if err != nil {
+		return err
+	}
+	return printJSON(account)
+}
+
+// AccountsRelease returns leased account to the pool.
+func AccountsRelease(spec *infra.Spec, address string) error {
+	return spec.ReleaseAccount(address)
+}
+
+func printJSON(v interface{}) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	fmt.Println(string(content))
+	return nil
+}
@@ -0,0 +1,248 @@
+package znet
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/crust/exec"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+	"github.com/CoreumFoundation/crust/infra/apps/gaiad"
+	"github.com/CoreumFoundation/crust/infra/apps/osmosisd"
+	"github.com/CoreumFoundation/crust/infra/apps/postgres"
+	"github.com/CoreumFoundation/crust/infra/targets"
+)
+
+const (
+	postgresBackupFile = "postgres.dump"
+	homeBackupFile     = "home.tar.gz"
+)
+
+// Backup stores state of the stateful non-chain app. Postgres databases are dumped using `pg_dump`, home directories
+// of other apps are archived while their containers are paused. Backups are stored outside the directory
+// of the environment, so they survive `remove` and may be restored into fresh environment. If name is empty,
+// current time is used.
+func Backup(ctx context.Context, configF *infra.ConfigFactory, config infra.Config, spec *infra.Spec,
+	appName, name string,
+) error {
+	app, err := backupApp(spec, appName)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = time.Now().UTC().Format("20060102-150405")
+	}
+	backupDir := filepath.Join(backupsDir(configF, appName), name)
+	if _, err := os.Stat(backupDir); err == nil {
+		return errors.Errorf("backup %q of app %s already exists", name, appName)
+	}
+	if err := os.MkdirAll(backupDir, 0o700); err != nil {
+		return errors.WithStack(err)
+	}
+
+	container := app.Info().Container
+	if app.Type() == postgres.AppType {
+		err = dumpPostgres(ctx, container, filepath.Join(backupDir, postgresBackupFile))
+	} else {
+		err = archiveAppHome(ctx, container, filepath.Join(config.AppDir, appName),
+			filepath.Join(backupDir, homeBackupFile))
+	}
+	if err != nil {
+		_ = os.RemoveAll(backupDir)
+		return err
+	}
+
+	logger.Get(ctx).Info("Backup created", zap.String("app", appName), zap.String("name", name),
+		zap.String("dir", backupDir))
+	return nil
+}
+
+// Restore restores state of the app from the backup. If name is empty, the latest backup is used. Apps other than
+// postgres are restarted, so they load the restored state.
+func Restore(ctx context.Context, configF *infra.ConfigFactory, config infra.Config, spec *infra.Spec,
+	appName, name string,
+) error {
+	app, err := backupApp(spec, appName)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		names, err := Backups(configF, appName)
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return errors.Errorf("no backup of app %s exists", appName)
+		}
+		name = names[len(names)-1]
+	}
+	backupDir := filepath.Join(backupsDir(configF, appName), name)
+	if _, err := os.Stat(backupDir); err != nil {
+		return errors.Wrapf(err, "backup %q of app %s doesn't exist", name, appName)
+	}
+
+	container := app.Info().Container
+	if app.Type() == postgres.AppType {
+		err = restorePostgres(ctx, container, filepath.Join(backupDir, postgresBackupFile))
+	} else {
+		err = restoreAppHome(ctx, container, filepath.Join(config.AppDir, appName),
+			filepath.Join(backupDir, homeBackupFile))
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Get(ctx).Info("Backup restored", zap.String("app", appName), zap.String("name", name))
+	return nil
+}
+
+// Backups returns names of the backups of the app, from the oldest to the latest one.
+func Backups(configF *infra.ConfigFactory, appName string) ([]string, error) {
+	entries, err := os.ReadDir(backupsDir(configF, appName))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, errors.WithStack(err)
+	}
+
+	type backup struct {
+		Name    string
+		ModTime time.Time
+	}
+	backups := make([]backup, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		backups = append(backups, backup{Name: entry.Name(), ModTime: info.ModTime()})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].ModTime.Before(backups[j].ModTime) })
+
+	names := make([]string, 0, len(backups))
+	for _, b := range backups {
+		names = append(names, b.Name)
+	}
+	return names, nil
+}
+
+func backupsDir(configF *infra.ConfigFactory, appName string) string {
+	return filepath.Join(configF.HomeDir, "backups", configF.EnvName, appName)
+}
+
+// backupApp returns the app to back up or restore. Chain apps are not supported because restoring state of a single
+// node would diverge it from the rest of the network.
+func backupApp(spec *infra.Spec, appName string) (*infra.AppInfo, error) {
+	app, exists := spec.Apps[appName]
+	if !exists {
+		return nil, errors.Errorf("app %s doesn't exist", appName)
+	}
+	switch app.Type() {
+	case cored.AppType, gaiad.AppType, osmosisd.AppType:
+		return nil, errors.Errorf("app %s is a chain app, only state of non-chain apps may be backed up", appName)
+	}
+	if app.Info().Status != infra.AppStatusRunning {
+		return nil, errors.Errorf("app %s is not running", appName)
+	}
+	return app, nil
+}
+
+func dumpPostgres(ctx context.Context, container, file string) error {
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	cmd := exec.Docker("exec", container, "pg_dump", "-U", postgres.User, "--format=custom", postgres.DB)
+	cmd.Stdout = f
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return errors.Wrapf(err, "dumping database of `%s` failed", container)
+	}
+	return nil
+}
+
+func restorePostgres(ctx context.Context, container, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	// Objects existing in the database are dropped first, so schema changes made since backup are reverted.
+	cmd := exec.Docker("exec", "-i", container, "pg_restore", "-U", postgres.User, "-d", postgres.DB,
+		"--clean", "--if-exists", "--no-owner")
+	cmd.Stdin = f
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return errors.Wrapf(err, "restoring database of `%s` failed", container)
+	}
+	return nil
+}
+
+// archiveAppHome archives home directory of the app. Container is paused meanwhile, so files are consistent.
+func archiveAppHome(ctx context.Context, container, homeDir, file string) error {
+	if _, err := os.Stat(homeDir); err != nil {
+		return errors.Wrapf(err, "app has no home directory to back up")
+	}
+	if err := targets.PauseContainer(ctx, container); err != nil {
+		return err
+	}
+	defer func() {
+		unpauseCtx, cancel := infra.CleanupContext(ctx)
+		defer cancel()
+		if err := targets.UnpauseContainer(unpauseCtx, container); err != nil {
+			logger.Get(ctx).Error("Unpausing container failed", zap.String("container", container), zap.Error(err))
+		}
+	}()
+
+	if err := libexec.Exec(ctx, osexec.Command("tar", "-czf", file, "-C", homeDir, ".")); err != nil {
+		return errors.Wrapf(err, "archiving home directory of `%s` failed", container)
+	}
+	return nil
+}
+
+// restoreAppHome replaces content of the home directory of the app with the archived one and restarts the app.
+func restoreAppHome(ctx context.Context, container, homeDir, file string) error {
+	if err := targets.PauseContainer(ctx, container); err != nil {
+		return err
+	}
+	err := func() error {
+		entries, err := os.ReadDir(homeDir)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		for _, entry := range entries {
+			if err := os.RemoveAll(filepath.Join(homeDir, entry.Name())); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		if err := libexec.Exec(ctx, osexec.Command("tar", "-xzf", file, "-C", homeDir)); err != nil {
+			return errors.Wrapf(err, "extracting home directory of `%s` failed", container)
+		}
+		return nil
+	}()
+
+	// Restarting paused container is not possible, so it is unpaused first.
+	cleanupCtx, cancel := infra.CleanupContext(ctx)
+	defer cancel()
+	if unpauseErr := targets.UnpauseContainer(cleanupCtx, container); unpauseErr != nil {
+		return unpauseErr
+	}
+	if err != nil {
+		return err
+	}
+	return targets.RestartContainer(ctx, container)
+}
@@ -0,0 +1,812 @@
+package znet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	mathrand "math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cosmosclient "github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/pkg/errors"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum-tools/pkg/parallel"
+	"github.com/CoreumFoundation/coreum/pkg/client"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+)
+
+// Distributions of intervals between transactions generated by the benchmark.
+const (
+	BenchArrivalUniform = "uniform"
+	BenchArrivalPoisson = "poisson"
+)
+
+const (
+	// benchBlockPollInterval is the interval between queries for new blocks, it limits the precision
+	// of measured inclusion latency.
+	benchBlockPollInterval = 100 * time.Millisecond
+
+	// benchInclusionTimeout is the time after which accepted transactions which are still not included in any block
+	// are considered lost.
+	benchInclusionTimeout = time.Minute
+
+	// benchMsgMixBlocks is the number of recent blocks sampled to compute the message mix.
+	benchMsgMixBlocks = 100
+)
+
+// BenchArrivals returns supported distributions of intervals between transactions.
+func BenchArrivals() []string {
+	return []string{BenchArrivalUniform, BenchArrivalPoisson}
+}
+
+// BenchConfig is the configuration of the benchmark.
+type BenchConfig struct {
+	// Accounts is the number of accounts generated to broadcast transactions concurrently
+	Accounts int
+
+	// Txs is the number of transactions signed and broadcast
+	Txs int
+
+	// TPS is the mean rate of transactions arriving to the broadcaster, zero means all of them arrive at once
+	TPS float64
+
+	// Arrival is the distribution of intervals between arriving transactions
+	Arrival string
+
+	// RateLimit is the rate of refilling the token bucket limiting broadcasting, zero means no limit
+	RateLimit float64
+
+	// Burst is the capacity of the token bucket, so the number of transactions which may be broadcast at once
+	Burst int
+
+	// AccountReuse is the probability that transaction is sent by the account which has already sent one, instead of
+	// the fresh one
+	AccountReuse float64
+
+	// MsgMix maps message types to their weights, defining the share of transactions of each type
+	MsgMix map[string]int
+
+	// MsgMixFrom is the RPC address of the node, e.g. of mainnet, the message mix is computed from by sampling
+	// recent blocks, it overrides MsgMix
+	MsgMixFrom string
+
+	// OutputDir is the directory where the report is stored
+	OutputDir string
+}
+
+// DefaultBenchConfig is the default configuration of the benchmark.
+var DefaultBenchConfig = BenchConfig{
+	Accounts:     50,
+	Txs:          1000,
+	Arrival:      BenchArrivalUniform,
+	Burst:        10,
+	AccountReuse: 0.9,
+	MsgMix:       map[string]int{PingPongMsgSend: 1},
+	OutputDir:    "bench",
+}
+
+// Validate validates the config.
+func (c BenchConfig) Validate() error {
+	if c.Accounts < 2 {
+		return errors.Errorf("at least 2 accounts are required, got %d", c.Accounts)
+	}
+	if c.Txs <= 0 {
+		return errors.Errorf("number of transactions must be positive, got %d", c.Txs)
+	}
+	if c.TPS < 0 {
+		return errors.Errorf("transactions per second must not be negative, got %v", c.TPS)
+	}
+	if c.Arrival != BenchArrivalUniform && c.Arrival != BenchArrivalPoisson {
+		return errors.Errorf("unknown arrival distribution %q, supported ones: %v", c.Arrival, BenchArrivals())
+	}
+	if c.RateLimit < 0 {
+		return errors.Errorf("rate limit must not be negative, got %v", c.RateLimit)
+	}
+	if c.RateLimit > 0 && c.Burst < 1 {
+		return errors.Errorf("burst must be positive, got %d", c.Burst)
+	}
+	if c.AccountReuse < 0 || c.AccountReuse > 1 {
+		return errors.Errorf("account reuse must be between 0 and 1, got %v", c.AccountReuse)
+	}
+	if c.MsgMixFrom != "" {
+		return nil
+	}
+	return validateMsgMix(c.MsgMix)
+}
+
+// BenchReport is the result of the benchmark.
+type BenchReport struct {
+	Nodes    int `json:"nodes"`
+	Accounts int `json:"accounts"`
+	// UsedAccounts is the number of accounts which sent at least one transaction
+	UsedAccounts int `json:"usedAccounts"`
+
+	TargetTPS    float64        `json:"targetTPS"`
+	Arrival      string         `json:"arrival"`
+	RateLimit    float64        `json:"rateLimit"`
+	Burst        int            `json:"burst"`
+	AccountReuse float64        `json:"accountReuse"`
+	MsgMix       map[string]int `json:"msgMix"`
+
+	// Submitted is the number of transactions broadcast to the nodes
+	Submitted int `json:"submitted"`
+	// Accepted is the number of transactions accepted to the mempool
+	Accepted int `json:"accepted"`
+	// Rejected is the number of transactions rejected by the nodes
+	Rejected int `json:"rejected"`
+	// Skipped is the number of transactions not broadcast because previous transaction of the same account
+	// was rejected, so their sequences are invalid
+	Skipped int `json:"skipped"`
+	// Included is the number of transactions included in blocks
+	Included int `json:"included"`
+	// MsgTypes is the number of transactions of each message type
+	MsgTypes map[string]int `json:"msgTypes"`
+
+	// Rejections maps reasons of rejections to the number of transactions rejected for them
+	Rejections map[string]int `json:"rejections"`
+
+	DurationSeconds float64 `json:"durationSeconds"`
+	// RateLimitedSeconds is the time transactions waited in total for the tokens of the rate limiter
+	RateLimitedSeconds float64 `json:"rateLimitedSeconds"`
+	AverageTPS         float64 `json:"averageTPS"`
+	// TPSOverTime is the number of transactions included in each second since the benchmark started
+	TPSOverTime []int `json:"tpsOverTime"`
+
+	LatencyP50 time.Duration `json:"latencyP50"`
+	LatencyP95 time.Duration `json:"latencyP95"`
+	LatencyP99 time.Duration `json:"latencyP99"`
+}
+
+type benchTx struct {
+	Account int
+	MsgType string
+	Bytes   []byte
+	Hash    string
+
+	Submitted time.Time
+	Accepted  bool
+	Included  time.Time
+}
+
+// Bench signs transactions offline, broadcasts them across all the cored nodes at the configured rate
+// and measures throughput and inclusion latency. Report is printed and stored in the output directory in JSON
+// and text formats.
+func Bench(ctx context.Context, appSet infra.AppSet, config BenchConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	var nodes []cored.Cored
+	for _, app := range appSet {
+		coredNode, ok := app.(cored.Cored)
+		if !ok || coredNode.Info().Status != infra.AppStatusRunning {
+			continue
+		}
+		nodes = append(nodes, coredNode)
+	}
+	if len(nodes) == 0 {
+		return errors.New("no running cored app found")
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name() < nodes[j].Name() })
+
+	clientCtx := nodes[0].ClientContext()
+	txf := nodes[0].TxFactory(clientCtx).WithSimulateAndExecute(true)
+	denom := nodes[0].Config().Network.Denom()
+	log := logger.Get(ctx)
+
+	if config.MsgMixFrom != "" {
+		mix, err := sampleMsgMix(ctx, clientCtx, config.MsgMixFrom, benchMsgMixBlocks)
+		if err != nil {
+			return err
+		}
+		log.Info("Message mix sampled", zap.String("node", config.MsgMixFrom), zap.Any("msgMix", mix))
+		config.MsgMix = mix
+	}
+
+	accounts, err := generateAccounts(clientCtx, "bench", config.Accounts)
+	if err != nil {
+		return err
+	}
+	funding := importMnemonic(clientCtx, "funding", nodes[0].Config().FundingMnemonic)
+	if err := fundAccounts(ctx, clientCtx, txf, funding, accounts, denom); err != nil {
+		return err
+	}
+	var validators []string
+	if config.MsgMix[PingPongMsgDelegate] > 0 {
+		validators, err = bondedValidators(ctx, clientCtx)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Info("Signing transactions", zap.Int("txs", config.Txs))
+	txs := planBenchTxs(config)
+	if err := signBenchTxs(ctx, clientCtx, txf, accounts, validators, denom, txs); err != nil {
+		return err
+	}
+
+	report := BenchReport{
+		Nodes:        len(nodes),
+		Accounts:     config.Accounts,
+		TargetTPS:    config.TPS,
+		Arrival:      config.Arrival,
+		RateLimit:    config.RateLimit,
+		Burst:        config.Burst,
+		AccountReuse: config.AccountReuse,
+		MsgMix:       config.MsgMix,
+		MsgTypes:     map[string]int{},
+		Rejections:   map[string]int{},
+	}
+	queues := map[int]chan *benchTx{}
+	for _, tx := range txs {
+		report.MsgTypes[tx.MsgType]++
+		if queues[tx.Account] == nil {
+			queues[tx.Account] = make(chan *benchTx, config.Txs)
+		}
+	}
+	report.UsedAccounts = len(queues)
+
+	log.Info("Broadcasting transactions", zap.Int("nodes", len(nodes)), zap.Int("accounts", report.UsedAccounts),
+		zap.Float64("tps", config.TPS), zap.Float64("rateLimit", config.RateLimit))
+	start := time.Now()
+	var mu sync.Mutex
+	err = parallel.Run(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
+		broadcastDone := make(chan struct{})
+		spawn("broadcast", parallel.Continue, func(ctx context.Context) error {
+			defer close(broadcastDone)
+			return parallel.Run(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
+				spawn("dispatcher", parallel.Continue, func(ctx context.Context) error {
+					rateLimited, err := dispatchBenchTxs(ctx, config, txs, queues)
+					mu.Lock()
+					report.RateLimitedSeconds = rateLimited.Seconds()
+					mu.Unlock()
+					return err
+				})
+				for account, queue := range queues {
+					node := nodes[account%len(nodes)]
+					queue := queue
+					spawn(fmt.Sprintf("account-%d", account), parallel.Continue, func(ctx context.Context) error {
+						broadcastBenchTxs(ctx, node, queue, &report, &mu)
+						return nil
+					})
+				}
+				return nil
+			})
+		})
+		spawn("blocks", parallel.Exit, func(ctx context.Context) error {
+			return watchBenchBlocks(ctx, clientCtx, txs, broadcastDone, &mu)
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	finalizeBenchReport(&report, txs, start)
+	return saveBenchReport(ctx, report, config.OutputDir)
+}
+
+// planBenchTxs decides which account sends each transaction and what message it contains. Transaction is sent
+// by the account which has already sent one with the probability of account reuse, otherwise fresh account is taken,
+// as long as there are any left.
+func planBenchTxs(config BenchConfig) []*benchTx {
+	msgTypes := make([]string, 0, len(config.MsgMix))
+	for msgType := range config.MsgMix {
+		msgTypes = append(msgTypes, msgType)
+	}
+	sort.Strings(msgTypes)
+
+	txs := make([]*benchTx, 0, config.Txs)
+	usedAccounts := 0
+	for i := 0; i < config.Txs; i++ {
+		account := usedAccounts
+		if usedAccounts == config.Accounts || (usedAccounts > 0 && mathrand.Float64() < config.AccountReuse) {
+			account = mathrand.Intn(usedAccounts)
+		} else {
+			usedAccounts++
+		}
+		txs = append(txs, &benchTx{
+			Account: account,
+			MsgType: pickPingPongMsgType(msgTypes, config.MsgMix),
+		})
+	}
+	return txs
+}
+
+// signBenchTxs signs the planned transactions using consecutive sequences of each account, so they may be broadcast
+// without waiting for the previous ones to be included in a block.
+func signBenchTxs(
+	ctx context.Context,
+	clientCtx client.Context,
+	txf tx.Factory,
+	accounts []sdk.AccAddress,
+	validators []string,
+	denom string,
+	txs []*benchTx,
+) error {
+	amount := sdk.NewCoins(sdk.NewCoin(denom, sdk.OneInt()))
+
+	// Gas price is raised by the fee model if blocks are full, so adjustment is applied to keep transactions valid.
+	gasPrice, err := client.GetGasPrice(ctx, clientCtx)
+	if err != nil {
+		return err
+	}
+	gasPrice.Amount = gasPrice.Amount.Mul(clientCtx.GasPriceAdjustment())
+	gas := map[string]uint64{}
+	for _, benchTx := range txs {
+		if _, exists := gas[benchTx.MsgType]; exists {
+			continue
+		}
+		_, msgGas, err := client.CalculateGas(ctx, clientCtx.WithFromAddress(accounts[0]), txf,
+			newPingPongMsg(benchTx.MsgType, accounts, 0, validators, amount))
+		if err != nil {
+			return err
+		}
+		gas[benchTx.MsgType] = msgGas
+	}
+	txf = txf.WithSimulateAndExecute(false).WithGasPrices(gasPrice.String())
+
+	type accountState struct {
+		KeyName       string
+		AccountNumber uint64
+		Sequence      uint64
+	}
+	states := map[int]*accountState{}
+	for _, benchTx := range txs {
+		state := states[benchTx.Account]
+		if state == nil {
+			accountInfo, err := client.GetAccountInfo(ctx, clientCtx, accounts[benchTx.Account])
+			if err != nil {
+				return err
+			}
+			key, err := clientCtx.Keyring().KeyByAddress(accounts[benchTx.Account])
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			state = &accountState{
+				KeyName:       key.GetName(),
+				AccountNumber: accountInfo.GetAccountNumber(),
+				Sequence:      accountInfo.GetSequence(),
+			}
+			states[benchTx.Account] = state
+		}
+
+		accountTxf := txf.
+			WithGas(gas[benchTx.MsgType]).
+			WithAccountNumber(state.AccountNumber).
+			WithSequence(state.Sequence)
+		state.Sequence++
+
+		txBuilder, err := accountTxf.BuildUnsignedTx(newPingPongMsg(benchTx.MsgType, accounts, benchTx.Account,
+			validators, amount))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if err := tx.Sign(accountTxf, state.KeyName, txBuilder, true); err != nil {
+			return errors.WithStack(err)
+		}
+		txBytes, err := clientCtx.TxConfig().TxEncoder()(txBuilder.GetTx())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		benchTx.Bytes = txBytes
+		benchTx.Hash = fmt.Sprintf("%X", tmtypes.Tx(txBytes).Hash())
+	}
+	return nil
+}
+
+// dispatchBenchTxs passes transactions to the queues of their accounts as they arrive, once the rate limiter allows it.
+// It returns the total time spent waiting for the rate limiter.
+func dispatchBenchTxs(
+	ctx context.Context,
+	config BenchConfig,
+	txs []*benchTx,
+	queues map[int]chan *benchTx,
+) (time.Duration, error) {
+	defer func() {
+		for _, queue := range queues {
+			close(queue)
+		}
+	}()
+
+	var limiter *tokenBucket
+	if config.RateLimit > 0 {
+		limiter = newTokenBucket(config.RateLimit, config.Burst)
+	}
+
+	var rateLimited time.Duration
+	arrival := time.Now()
+	for _, benchTx := range txs {
+		if config.TPS > 0 {
+			interval := float64(time.Second) / config.TPS
+			if config.Arrival == BenchArrivalPoisson {
+				// Intervals between events of poisson process are distributed exponentially.
+				interval *= mathrand.ExpFloat64()
+			}
+			arrival = arrival.Add(time.Duration(interval))
+			select {
+			case <-ctx.Done():
+				return rateLimited, errors.WithStack(ctx.Err())
+			case <-time.After(time.Until(arrival)):
+			}
+		}
+		if limiter != nil {
+			waitStart := time.Now()
+			if err := limiter.Wait(ctx); err != nil {
+				return rateLimited, err
+			}
+			rateLimited += time.Since(waitStart)
+		}
+		queues[benchTx.Account] <- benchTx
+	}
+	return rateLimited, nil
+}
+
+// tokenBucket limits the rate of events. Bucket holds up to burst tokens and is refilled at the constant rate,
+// each event takes one token, waiting until it is available. It is not safe for concurrent use.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait waits until token is available and takes it.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	now := time.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		select {
+		case <-ctx.Done():
+			return errors.WithStack(ctx.Err())
+		case <-time.After(wait):
+		}
+		b.tokens = 1
+		b.last = now.Add(wait)
+	}
+	b.tokens--
+	return nil
+}
+
+// broadcastBenchTxs broadcasts transactions of the account one by one to the node. Once transaction is rejected,
+// the remaining ones are skipped because their sequences are no longer valid.
+func broadcastBenchTxs(
+	ctx context.Context,
+	node cored.Cored,
+	queue <-chan *benchTx,
+	report *BenchReport,
+	mu *sync.Mutex,
+) {
+	rpcClient := node.ClientContext().RPCClient()
+	rejected := false
+	for benchTx := range queue {
+		if rejected {
+			mu.Lock()
+			report.Skipped++
+			mu.Unlock()
+			continue
+		}
+
+		mu.Lock()
+		benchTx.Submitted = time.Now()
+		report.Submitted++
+		mu.Unlock()
+
+		res, err := rpcClient.BroadcastTxSync(ctx, benchTx.Bytes)
+		if ctx.Err() != nil {
+			return
+		}
+
+		var reason string
+		switch {
+		case err != nil && strings.Contains(err.Error(), "mempool is full"):
+			reason = "mempool is full"
+		case err != nil:
+			reason = "broadcast failed"
+			logger.Get(ctx).Warn("Broadcasting transaction failed", zap.Error(err))
+		case res.Code != 0:
+			reason = fmt.Sprintf("%s/%d: %s", res.Codespace, res.Code, benchRejectionReason(res.Log))
+		}
+
+		mu.Lock()
+		if reason == "" {
+			benchTx.Accepted = true
+			mu.Unlock()
+			continue
+		}
+		report.Rejected++
+		report.Rejections[reason]++
+		mu.Unlock()
+
+		logger.Get(ctx).Warn("Transaction rejected", zap.String("node", node.Name()), zap.String("txHash", benchTx.Hash),
+			zap.String("reason", reason))
+		rejected = true
+	}
+}
+
+// benchRejectionReason strips details, like account sequences, from the log of rejected transaction, so rejections
+// of the same kind are grouped together. Cosmos SDK appends the description of the root error at the end.
+func benchRejectionReason(log string) string {
+	if i := strings.LastIndex(log, ": "); i >= 0 {
+		log = log[i+2:]
+	}
+	return strings.TrimSpace(log)
+}
+
+// watchBenchBlocks records the time each transaction is observed in a block. It returns once all the accepted
+// transactions are included or none of them has been included for a while after broadcasting is done.
+func watchBenchBlocks(
+	ctx context.Context,
+	clientCtx client.Context,
+	txs []*benchTx,
+	broadcastDone <-chan struct{},
+	mu *sync.Mutex,
+) error {
+	txsByHash := make(map[string]*benchTx, len(txs))
+	for _, benchTx := range txs {
+		txsByHash[benchTx.Hash] = benchTx
+	}
+
+	rpcClient := clientCtx.RPCClient()
+	status, err := rpcClient.Status(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	nextHeight := status.SyncInfo.LatestBlockHeight + 1
+	lastProgress := time.Now()
+	ticker := time.NewTicker(benchBlockPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.WithStack(ctx.Err())
+		case <-ticker.C:
+		}
+
+		status, err := rpcClient.Status(ctx)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		for ; nextHeight <= status.SyncInfo.LatestBlockHeight; nextHeight++ {
+			height := nextHeight
+			block, err := rpcClient.Block(ctx, &height)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			observed := time.Now()
+
+			mu.Lock()
+			for _, tx := range block.Block.Txs {
+				if benchTx, exists := txsByHash[fmt.Sprintf("%X", tx.Hash())]; exists {
+					benchTx.Included = observed
+					lastProgress = observed
+				}
+			}
+			mu.Unlock()
+		}
+
+		select {
+		case <-broadcastDone:
+		default:
+			// Time spent on waiting for transactions to arrive doesn't count as lack of progress.
+			lastProgress = time.Now()
+			continue
+		}
+
+		mu.Lock()
+		pending := 0
+		for _, benchTx := range txs {
+			if benchTx.Accepted && benchTx.Included.IsZero() {
+				pending++
+			}
+		}
+		mu.Unlock()
+
+		if pending == 0 {
+			return nil
+		}
+		if time.Since(lastProgress) > benchInclusionTimeout {
+			logger.Get(ctx).Warn("Accepted transactions haven't been included in blocks", zap.Int("txs", pending))
+			return nil
+		}
+	}
+}
+
+// sampleMsgMix computes weights of the message types from the transactions included in recent blocks of the network
+// the node belongs to. Messages of unsupported types are ignored.
+func sampleMsgMix(
+	ctx context.Context,
+	clientCtx client.Context,
+	nodeAddress string,
+	blocks int64,
+) (map[string]int, error) {
+	msgTypes := map[string]string{
+		sdk.MsgTypeURL(&banktypes.MsgSend{}):        PingPongMsgSend,
+		sdk.MsgTypeURL(&banktypes.MsgMultiSend{}):   PingPongMsgMultiSend,
+		sdk.MsgTypeURL(&stakingtypes.MsgDelegate{}): PingPongMsgDelegate,
+	}
+
+	rpcClient, err := cosmosclient.NewClientFromNode(nodeAddress)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating client of node %s failed", nodeAddress)
+	}
+	status, err := rpcClient.Status(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "querying status of node %s failed", nodeAddress)
+	}
+
+	decodeTx := clientCtx.TxConfig().TxDecoder()
+	mix := map[string]int{}
+	latestHeight := status.SyncInfo.LatestBlockHeight
+	for height := latestHeight; height > latestHeight-blocks && height > 0; height-- {
+		height := height
+		block, err := rpcClient.Block(ctx, &height)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fetching block %d from node %s failed", height, nodeAddress)
+		}
+		for _, txBytes := range block.Block.Txs {
+			decodedTx, err := decodeTx(txBytes)
+			if err != nil {
+				// Transactions containing messages unknown to cored can't be decoded.
+				continue
+			}
+			for _, msg := range decodedTx.GetMsgs() {
+				if msgType, ok := msgTypes[sdk.MsgTypeURL(msg)]; ok {
+					mix[msgType]++
+				}
+			}
+		}
+	}
+	if len(mix) == 0 {
+		return nil, errors.Errorf("no messages of supported types found in the last %d blocks of node %s", blocks,
+			nodeAddress)
+	}
+	return mix, nil
+}
+
+func finalizeBenchReport(report *BenchReport, txs []*benchTx, start time.Time) {
+	var (
+		latencies []time.Duration
+		end       = start
+	)
+	for _, benchTx := range txs {
+		if benchTx.Accepted {
+			report.Accepted++
+		}
+		if benchTx.Included.IsZero() {
+			continue
+		}
+		report.Included++
+		latencies = append(latencies, benchTx.Included.Sub(benchTx.Submitted))
+		if benchTx.Included.After(end) {
+			end = benchTx.Included
+		}
+
+		second := int(benchTx.Included.Sub(start) / time.Second)
+		for len(report.TPSOverTime) <= second {
+			report.TPSOverTime = append(report.TPSOverTime, 0)
+		}
+		report.TPSOverTime[second]++
+	}
+
+	report.DurationSeconds = end.Sub(start).Seconds()
+	if report.DurationSeconds > 0 {
+		report.AverageTPS = float64(report.Included) / report.DurationSeconds
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	report.LatencyP50 = percentile(latencies, 0.5)
+	report.LatencyP95 = percentile(latencies, 0.95)
+	report.LatencyP99 = percentile(latencies, 0.99)
+}
+
+// percentile returns the percentile of the sorted values.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(math.Ceil(p*float64(len(sorted))))-1]
+}
+
+func saveBenchReport(ctx context.Context, report BenchReport, outputDir string) error {
+	if err := os.MkdirAll(outputDir, 0o700); err != nil {
+		return errors.WithStack(err)
+	}
+
+	reportJSON, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, "bench-report.json"), reportJSON, 0o600); err != nil {
+		return errors.WithStack(err)
+	}
+
+	reportText := &strings.Builder{}
+	writeBenchReport(reportText, report)
+	if err := os.WriteFile(filepath.Join(outputDir, "bench-report.txt"), []byte(reportText.String()),
+		0o600); err != nil {
+		return errors.WithStack(err)
+	}
+
+	fmt.Print(reportText.String())
+	logger.Get(ctx).Info("Benchmark report stored", zap.String("outputDir", outputDir))
+	return nil
+}
+
+func writeBenchReport(w io.Writer, report BenchReport) {
+	fmt.Fprintf(w, "Nodes:             %d\n", report.Nodes)
+	fmt.Fprintf(w, "Accounts:          %d used of %d, reuse %.2f\n", report.UsedAccounts, report.Accounts,
+		report.AccountReuse)
+	if report.TargetTPS > 0 {
+		fmt.Fprintf(w, "Target TPS:        %.1f, %s arrival\n", report.TargetTPS, report.Arrival)
+	}
+	if report.RateLimit > 0 {
+		fmt.Fprintf(w, "Rate limit:        %.1f tx/s, burst %d, waited %.1fs\n", report.RateLimit, report.Burst,
+			report.RateLimitedSeconds)
+	}
+	fmt.Fprintf(w, "Transactions:      %d submitted, %d accepted, %d rejected, %d skipped, %d included\n",
+		report.Submitted, report.Accepted, report.Rejected, report.Skipped, report.Included)
+	fmt.Fprintf(w, "Message types:     %s\n", formatCounts(report.MsgTypes))
+	fmt.Fprintf(w, "Duration:          %.1fs\n", report.DurationSeconds)
+	fmt.Fprintf(w, "Average TPS:       %.1f\n", report.AverageTPS)
+	fmt.Fprintf(w, "Inclusion latency: p50 %s, p95 %s, p99 %s\n", report.LatencyP50, report.LatencyP95,
+		report.LatencyP99)
+
+	if len(report.Rejections) > 0 {
+		fmt.Fprintln(w, "Rejections:")
+		reasons := make([]string, 0, len(report.Rejections))
+		for reason := range report.Rejections {
+			reasons = append(reasons, reason)
+		}
+		sort.Strings(reasons)
+		for _, reason := range reasons {
+			fmt.Fprintf(w, "  %s: %d\n", reason, report.Rejections[reason])
+		}
+	}
+
+	fmt.Fprintln(w, "TPS over time:")
+	for second, count := range report.TPSOverTime {
+		fmt.Fprintf(w, "  %4ds: %d\n", second, count)
+	}
+}
+
+// formatCounts formats counts as comma-separated list of `<key>=<count>`, sorted by key.
+func formatCounts(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	items := make([]string, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, fmt.Sprintf("%s=%d", key, counts[key]))
+	}
+	return strings.Join(items, ", ")
+}
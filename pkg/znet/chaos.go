@@ -0,0 +1,212 @@
+package znet
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum-tools/pkg/parallel"
+	integrationtests "github.com/CoreumFoundation/coreum/integration-tests"
+	"github.com/CoreumFoundation/coreum/pkg/config/constant"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+	"github.com/CoreumFoundation/crust/infra/targets"
+)
+
+// ChaosKill kills containers of the apps without graceful shutdown. If no apps are specified, random validator
+// is selected. Killed apps are started again by `start` command.
+func ChaosKill(ctx context.Context, config infra.Config, spec *infra.Spec, appNames []string) error {
+	err := forChaosTargets(ctx, config, spec, appNames, "Killing app",
+		func(ctx context.Context, appName, container string) error {
+			if err := targets.KillContainer(ctx, container); err != nil {
+				return err
+			}
+			// App is marked as stopped, so `start` starts it again.
+			spec.Apps[appName].SetInfo(infra.DeploymentInfo{Status: infra.AppStatusStopped})
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+	return spec.Save()
+}
+
+// ChaosPause freezes containers of the apps. If no apps are specified, random validator is selected.
+func ChaosPause(ctx context.Context, config infra.Config, spec *infra.Spec, appNames []string) error {
+	return forChaosTargets(ctx, config, spec, appNames, "Pausing app",
+		func(ctx context.Context, appName, container string) error {
+			return targets.PauseContainer(ctx, container)
+		})
+}
+
+// ChaosNetem adds latency and packet loss to the traffic sent by the apps. If no apps are specified, random validator
+// is selected.
+func ChaosNetem(ctx context.Context, config infra.Config, spec *infra.Spec, appNames []string, delay, loss string) error {
+	if delay == "" && loss == "" {
+		return errors.New("delay or loss must be specified")
+	}
+	return forChaosTargets(ctx, config, spec, appNames, "Degrading network of app",
+		func(ctx context.Context, appName, container string) error {
+			return targets.DegradeNetwork(ctx, container, delay, loss)
+		})
+}
+
+// ChaosPartition splits apps into groups which can't communicate with each other. If no groups are specified,
+// validators are randomly split into two groups.
+func ChaosPartition(ctx context.Context, config infra.Config, spec *infra.Spec, groups [][]string) error {
+	if len(groups) == 0 {
+		validators, err := chaosValidators(config, spec)
+		if err != nil {
+			return err
+		}
+		if len(validators) < 2 {
+			return errors.New("at least two running validators are required to partition the network")
+		}
+		rand.Shuffle(len(validators), func(i, j int) {
+			validators[i], validators[j] = validators[j], validators[i]
+		})
+		groups = [][]string{validators[:len(validators)/2], validators[len(validators)/2:]}
+	}
+	if len(groups) < 2 {
+		return errors.New("at least two groups are required to partition the network")
+	}
+
+	ips := make([][]string, 0, len(groups))
+	containers := make([][]string, 0, len(groups))
+	for _, group := range groups {
+		groupContainers, err := chaosContainers(spec, group)
+		if err != nil {
+			return err
+		}
+		groupIPs := make([]string, 0, len(groupContainers))
+		for _, container := range groupContainers {
+			ip, err := targets.ContainerIP(ctx, container)
+			if err != nil {
+				return err
+			}
+			groupIPs = append(groupIPs, ip)
+		}
+		containers = append(containers, groupContainers)
+		ips = append(ips, groupIPs)
+	}
+
+	log := logger.Get(ctx)
+	for i, group := range groups {
+		log.Info("Isolating group of apps", zap.Strings("apps", group))
+		var otherIPs []string
+		for j := range ips {
+			if j != i {
+				otherIPs = append(otherIPs, ips[j]...)
+			}
+		}
+		for _, container := range containers[i] {
+			if err := targets.IsolateFrom(ctx, container, otherIPs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ChaosHeal resumes paused apps and removes all the network conditions and partitions.
+func ChaosHeal(ctx context.Context, spec *infra.Spec) error {
+	log := logger.Get(ctx)
+	return parallel.Run(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
+		for appName, app := range spec.Apps {
+			if app.Info().Status != infra.AppStatusRunning {
+				continue
+			}
+			appName := appName
+			container := app.Info().Container
+			spawn(appName, parallel.Continue, func(ctx context.Context) error {
+				if err := targets.UnpauseContainer(ctx, container); err != nil {
+					return err
+				}
+				if err := targets.HealNetwork(ctx, container); err != nil {
+					return err
+				}
+				log.Info("App healed", zap.String("app", appName))
+				return nil
+			})
+		}
+		return nil
+	})
+}
+
+func forChaosTargets(
+	ctx context.Context,
+	config infra.Config,
+	spec *infra.Spec,
+	appNames []string,
+	message string,
+	fn func(ctx context.Context, appName, container string) error,
+) error {
+	if len(appNames) == 0 {
+		validators, err := chaosValidators(config, spec)
+		if err != nil {
+			return err
+		}
+		if len(validators) == 0 {
+			return errors.New("no running validator found")
+		}
+		appNames = []string{validators[rand.Intn(len(validators))]}
+	}
+
+	containers, err := chaosContainers(spec, appNames)
+	if err != nil {
+		return err
+	}
+
+	log := logger.Get(ctx)
+	for i, container := range containers {
+		log.Info(message, zap.String("app", appNames[i]))
+		if err := fn(ctx, appNames[i], container); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chaosContainers returns containers of the running apps.
+func chaosContainers(spec *infra.Spec, appNames []string) ([]string, error) {
+	containers := make([]string, 0, len(appNames))
+	for _, appName := range appNames {
+		app, exists := spec.Apps[appName]
+		if !exists {
+			return nil, errors.Errorf("app %q does not exist", appName)
+		}
+		if app.Info().Status != infra.AppStatusRunning {
+			return nil, errors.Errorf("app %q is not running", appName)
+		}
+		containers = append(containers, app.Info().Container)
+	}
+	return containers, nil
+}
+
+// chaosValidators returns names of running cored validators.
+func chaosValidators(config infra.Config, spec *infra.Spec) ([]string, error) {
+	networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+	if err != nil {
+		return nil, err
+	}
+	appSet, err := apps.BuildAppSet(apps.NewFactory(config, spec, networkConfig), config.Profiles,
+		config.CoredVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var validators []string
+	for _, app := range appSet {
+		coredNode, ok := app.(cored.Cored)
+		if ok && coredNode.Config().IsValidator && coredNode.Info().Status == infra.AppStatusRunning {
+			validators = append(validators, coredNode.Name())
+		}
+	}
+	sort.Strings(validators)
+	return validators, nil
+}
@@ -2,22 +2,21 @@ package znet
 
 import (
 	"context"
-	"fmt"
 	"os"
 	osexec "os/exec"
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
-	"github.com/cosmos/cosmos-sdk/client/tx"
 	"github.com/cosmos/cosmos-sdk/crypto/hd"
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	"github.com/fsnotify/fsnotify"
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
 	"go.uber.org/zap"
 
 	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
@@ -26,11 +25,11 @@ import (
 	"github.com/CoreumFoundation/coreum-tools/pkg/parallel"
 	integrationtests "github.com/CoreumFoundation/coreum/integration-tests"
 	"github.com/CoreumFoundation/coreum/pkg/client"
-	"github.com/CoreumFoundation/coreum/pkg/config"
 	"github.com/CoreumFoundation/coreum/pkg/config/constant"
 	"github.com/CoreumFoundation/crust/infra"
 	"github.com/CoreumFoundation/crust/infra/apps"
 	"github.com/CoreumFoundation/crust/infra/apps/cored"
+	"github.com/CoreumFoundation/crust/infra/apps/relayercosmos"
 	"github.com/CoreumFoundation/crust/infra/targets"
 	"github.com/CoreumFoundation/crust/infra/testing"
 	"github.com/CoreumFoundation/crust/pkg/znet/tmux"
@@ -51,33 +50,15 @@ func Activate(ctx context.Context, configF *infra.ConfigFactory, config infra.Co
 		return errors.WithStack(err)
 	}
 
-	saveWrapper(config.WrapperDir, "start", "start")
-	saveWrapper(config.WrapperDir, "stop", "stop")
-	saveWrapper(config.WrapperDir, "remove", "remove")
-	// `test` can't be used here because it is a reserved keyword in bash
-	saveWrapper(config.WrapperDir, "tests", "test")
-	saveWrapper(config.WrapperDir, "spec", "spec")
-	saveWrapper(config.WrapperDir, "console", "console")
-	saveWrapper(config.WrapperDir, "ping-pong", "ping-pong")
-	saveLogsWrapper(config.WrapperDir, config.EnvName, "logs")
+	saveWrappers(config)
 
-	shell, promptVar, err := shellConfig(config.EnvName)
+	shell, shellArgs, shellEnv, err := shellConfig(config.EnvName)
 	if err != nil {
 		return err
 	}
-	shellCmd := osexec.Command(shell)
-	shellCmd.Env = append(os.Environ(),
-		"PATH="+config.WrapperDir+":"+os.Getenv("PATH"),
-		"CRUST_ZNET_ENV="+configF.EnvName,
-		"CRUST_ZNET_PROFILES="+strings.Join(configF.Profiles, ","),
-		"CRUST_ZNET_CORED_VERSION="+configF.CoredVersion,
-		"CRUST_ZNET_HOME="+configF.HomeDir,
-		"CRUST_ZNET_BIN_DIR="+configF.BinDir,
-		"CRUST_ZNET_FILTER="+configF.TestFilter,
-	)
-	if promptVar != "" {
-		shellCmd.Env = append(shellCmd.Env, promptVar)
-	}
+	shellCmd := osexec.Command(shell, shellArgs...)
+	shellCmd.Env = append(os.Environ(), envVars(configF, config)...)
+	shellCmd.Env = append(shellCmd.Env, shellEnv...)
 	shellCmd.Dir = config.HomeDir
 	shellCmd.Stdin = os.Stdin
 
@@ -116,6 +97,80 @@ func Activate(ctx context.Context, configF *infra.ConfigFactory, config infra.Co
 	})
 }
 
+// saveWrappers saves scripts executing znet commands in the environment.
+func saveWrappers(config infra.Config) {
+	saveWrapper(config.WrapperDir, "start", "start")
+	saveWrapper(config.WrapperDir, "stop", "stop")
+	saveWrapper(config.WrapperDir, "remove", "remove")
+	// `test` can't be used here because it is a reserved keyword in bash
+	saveWrapper(config.WrapperDir, "tests", "test")
+	saveWrapper(config.WrapperDir, "spec", "spec")
+	saveWrapper(config.WrapperDir, "status", "status")
+	saveWrapper(config.WrapperDir, "rebuild", "rebuild")
+	saveWrapper(config.WrapperDir, "dev", "dev")
+	saveWrapper(config.WrapperDir, "console", "console")
+	saveWrapper(config.WrapperDir, "ping-pong", "ping-pong")
+	saveWrapper(config.WrapperDir, "bench", "bench")
+	saveWrapper(config.WrapperDir, "ibc", "ibc")
+	saveWrapper(config.WrapperDir, "query", "query")
+	saveWrapper(config.WrapperDir, "fund", "fund")
+	saveWrapper(config.WrapperDir, "contracts", "contracts")
+	saveWrapper(config.WrapperDir, "history", "history")
+	saveWrapper(config.WrapperDir, "doctor", "doctor")
+	saveWrapper(config.WrapperDir, "upgrade", "upgrade")
+	saveWrapper(config.WrapperDir, "chaos", "chaos")
+	saveWrapper(config.WrapperDir, "freeze", "freeze")
+	saveWrapper(config.WrapperDir, "thaw", "thaw")
+	saveWrapper(config.WrapperDir, "backup", "backup")
+	saveWrapper(config.WrapperDir, "restore", "restore")
+	saveWrapper(config.WrapperDir, "export", "export")
+	saveWrapper(config.WrapperDir, "serve", "serve")
+	saveWrapper(config.WrapperDir, "hosts", "hosts")
+	saveWrapper(config.WrapperDir, "qr", "qr")
+	saveWrapper(config.WrapperDir, "accounts", "accounts")
+	saveLogsWrapper(config.WrapperDir, config.EnvName, "logs")
+}
+
+// envVars returns environment variables configuring znet commands executed in the environment.
+func envVars(configF *infra.ConfigFactory, config infra.Config) []string {
+	return []string{
+		"PATH=" + config.WrapperDir + ":" + os.Getenv("PATH"),
+		"CRUST_ZNET_ENV=" + configF.EnvName,
+		"CRUST_ZNET_ENV_FILE=" + configF.EnvFile,
+		"CRUST_ZNET_PROFILES=" + strings.Join(configF.Profiles, ","),
+		"CRUST_ZNET_CORED_VERSION=" + configF.CoredVersion,
+		"CRUST_ZNET_GAIA_VERSION=" + configF.GaiaVersion,
+		"CRUST_ZNET_HOME=" + configF.HomeDir,
+		"CRUST_ZNET_BIN_DIR=" + configF.BinDir,
+		"CRUST_ZNET_FILTER=" + configF.TestFilter,
+		"CRUST_ZNET_SUBNET=" + configF.Subnet,
+		"CRUST_ZNET_NETWORK_NAME=" + configF.NetworkName,
+		"CRUST_ZNET_IPV6=" + strconv.FormatBool(configF.IPv6),
+		"CRUST_ZNET_NETWORK_ALIASES=" + strings.Join(configF.NetworkAliases, ","),
+		"CRUST_ZNET_CONTAINER_RUNTIME=" + configF.ContainerRuntime,
+		"CRUST_ZNET_REMOTE_HOME=" + configF.RemoteHomeDir,
+		"CRUST_ZNET_IMAGE_PULL_POLICY=" + configF.ImagePullPolicy,
+		"CRUST_ZNET_REGISTRY_MIRROR=" + configF.RegistryMirror,
+		"CRUST_ZNET_AUTO_PORTS=" + strconv.FormatBool(configF.AutoPorts),
+		"CRUST_ZNET_KEY_BACKUP=" + config.KeyBackup,
+		"CRUST_ZNET_KEY_RESTORE=" + config.KeyRestore,
+		"CRUST_ZNET_GENESIS_DENOMS=" + strings.Join(configF.GenesisDenoms, ","),
+		"CRUST_ZNET_GENESIS_PARAMS=" + strings.Join(configF.GenesisParams, ","),
+		"CRUST_ZNET_GENESIS_PATCH=" + config.GenesisPatch,
+		"CRUST_ZNET_SENTRIES=" + strconv.Itoa(configF.CoredSentries),
+		"CRUST_ZNET_ACCOUNT_POOL=" + strconv.Itoa(configF.AccountPoolSize),
+		"CRUST_ZNET_CONTRACTS=" + strings.Join(config.Contracts, ","),
+		"CRUST_ZNET_RESOURCES=" + strings.Join(configF.Resources, ","),
+		"CRUST_ZNET_FAUCET_TRANSFER_AMOUNT=" + strconv.Itoa(configF.FaucetTransferAmount),
+		"CRUST_ZNET_FAUCET_DENOM=" + configF.FaucetDenom,
+		"CRUST_ZNET_FAUCET_RATE_LIMIT=" + strconv.Itoa(configF.FaucetRateLimit),
+		"CRUST_ZNET_MOCKSERVER_MAPPINGS=" + config.MockServerMappings,
+		"CRUST_ZNET_RELAYER_GAS_PRICE_MULTIPLIER=" + strconv.FormatFloat(configF.RelayerGasPriceMultiplier, 'f', -1, 64),
+		"CRUST_ZNET_RELAYER_MEMO=" + configF.RelayerMemo,
+		"CRUST_ZNET_RELAYER_CHANNELS=" + strings.Join(configF.RelayerChannels, ","),
+	}
+}
+
 // Start starts environment.
 func Start(ctx context.Context, config infra.Config, spec *infra.Spec) (retErr error) {
 	if err := spec.Verify(); err != nil {
@@ -127,13 +182,81 @@ func Start(ctx context.Context, config infra.Config, spec *infra.Spec) (retErr e
 	if err != nil {
 		return err
 	}
+
+	// Contracts are deployed and keys are backed up and restored only once, when the chain is created.
+	firstStart := true
+	if coredApp, exists := spec.Apps["cored-00"]; exists && coredApp.Info().Status != infra.AppStatusNotDeployed {
+		firstStart = false
+	}
+
+	// Everything required to back up and restore keys is resolved before environment is deployed, otherwise failure
+	// would happen once the chain is already created, and it is never retried.
+	var backupPassphrase string
+	var nodeKeys map[string]cored.NodeKeys
+	if firstStart && config.KeyBackup != "" {
+		if err := ValidateKeyBackup(config.KeyBackup); err != nil {
+			return err
+		}
+		if backupPassphrase, err = KeyPassphrase(true); err != nil {
+			return err
+		}
+	}
+	if firstStart && config.KeyRestore != "" {
+		restorePassphrase, err := KeyPassphrase(false)
+		if err != nil {
+			return err
+		}
+		if nodeKeys, err = LoadNodeKeys(ctx, config.KeyRestore, restorePassphrase); err != nil {
+			return err
+		}
+	}
+
 	appF := apps.NewFactory(config, spec, networkConfig)
+	if nodeKeys != nil {
+		appF.WithNodeKeys(nodeKeys)
+	}
 	appSet, err := apps.BuildAppSet(appF, config.Profiles, config.CoredVersion)
 	if err != nil {
 		return err
 	}
+	if nodeKeys != nil {
+		if err := VerifyNodeKeys(ctx, nodeKeys, appSet); err != nil {
+			return err
+		}
+	}
+	appSet, err = verifyPorts(ctx, config, spec, appF, appSet)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := target.Deploy(ctx, appSet); err != nil {
+		return err
+	}
+	detectClockJump(ctx, appSet)
+	if err := recordIBCPaths(ctx, spec, appSet); err != nil {
+		return err
+	}
+	if firstStart {
+		if config.KeyBackup != "" {
+			if err := BackupNodeKeys(ctx, config, appSet, backupPassphrase); err != nil {
+				return err
+			}
+		}
+		if err := deployStartupContracts(ctx, config, appSet); err != nil {
+			return err
+		}
+	}
 
-	return target.Deploy(ctx, appSet)
+	timings, err := infra.LoadStartupTimings(config.HomeDir)
+	if err != nil {
+		return err
+	}
+	// Timing is not recorded if all the apps were already running.
+	if len(timings) == 0 || timings[len(timings)-1].Time.Before(start) {
+		return nil
+	}
+	return printStartupTiming(timings[len(timings)-1])
 }
 
 // Stop stops environment.
@@ -147,6 +270,11 @@ func Stop(ctx context.Context, config infra.Config, spec *infra.Spec) (retErr er
 		}
 	}()
 
+	// Paused containers must be resumed before they are stopped.
+	if err := unfreeze(ctx, spec); err != nil {
+		return err
+	}
+
 	target := targets.NewDocker(config, spec)
 	return target.Stop(ctx)
 }
@@ -174,8 +302,17 @@ func Remove(ctx context.Context, config infra.Config, spec *infra.Spec) (retErr
 	return errors.WithStack(err)
 }
 
-// Test runs integration tests.
-func Test(ctx context.Context, config infra.Config, spec *infra.Spec) error {
+// Test runs integration tests. If network file is configured, tests are executed against the external network
+// described there, otherwise local environment is used.
+func Test(ctx context.Context, configF *infra.ConfigFactory, config infra.Config, spec *infra.Spec) error {
+	if config.NetworkFile != "" {
+		network, err := testing.LoadExternalNetwork(config.NetworkFile)
+		if err != nil {
+			return err
+		}
+		return testing.RunExternal(ctx, network, config, config.TestGroups...)
+	}
+
 	if err := spec.Verify(); err != nil {
 		return err
 	}
@@ -196,12 +333,95 @@ func Test(ctx context.Context, config infra.Config, spec *infra.Spec) error {
 		return err
 	}
 
-	return testing.Run(ctx, target, appSet, config, config.TestGroups...)
+	detectClockJump(ctx, appSet)
+	return testing.Run(ctx, target, appSet, config, spec, provisionTestEnv(configF, config, spec),
+		config.TestGroups...)
+}
+
+// provisionTestEnv returns the function creating dedicated environments for test groups. Environment is named
+// `<env>-<group>`. Environments use the same ports, so the shared one is stopped while the dedicated one exists
+// and it is started again once the dedicated one is removed.
+func provisionTestEnv(configF *infra.ConfigFactory, config infra.Config, spec *infra.Spec) testing.ProvisionFunc {
+	return func(
+		ctx context.Context,
+		req testing.EnvRequest,
+	) (retEnv testing.Environment, retRemove func(ctx context.Context) error, retErr error) {
+		envF := *configF
+		envF.EnvName = configF.EnvName + "-" + req.Group
+		envF.Profiles = lo.Union(config.Profiles, req.Profiles)
+		// Subnet of the shared environment is still taken by its network, so free one is picked, and the dedicated
+		// network is named after the environment.
+		envF.Subnet = ""
+		envF.NetworkName = ""
+		if req.CoredVersion != "" {
+			envF.CoredVersion = req.CoredVersion
+		}
+
+		// Environment left by the previous run is removed, so chain starts from genesis.
+		staleSpec := infra.NewSpec(&envF)
+		if err := Remove(ctx, NewConfig(&envF, staleSpec), staleSpec); err != nil {
+			return testing.Environment{}, nil, err
+		}
+		if err := Stop(ctx, config, spec); err != nil {
+			return testing.Environment{}, nil, err
+		}
+
+		envSpec := infra.NewSpec(&envF)
+		envConfig := NewConfig(&envF, envSpec)
+		remove := func(ctx context.Context) error {
+			if err := Remove(ctx, envConfig, envSpec); err != nil {
+				return err
+			}
+			return Start(ctx, config, spec)
+		}
+		defer func() {
+			if retErr != nil {
+				cleanupCtx, cancel := infra.CleanupContext(ctx)
+				defer cancel()
+				if err := remove(cleanupCtx); err != nil {
+					logger.Get(ctx).Error("Removing dedicated environment failed", zap.Error(err))
+				}
+			}
+		}()
+
+		if err := Start(ctx, envConfig, envSpec); err != nil {
+			return testing.Environment{}, nil, err
+		}
+		networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+		if err != nil {
+			return testing.Environment{}, nil, err
+		}
+		appSet, err := apps.BuildAppSet(apps.NewFactory(envConfig, envSpec, networkConfig), envConfig.Profiles,
+			envConfig.CoredVersion)
+		if err != nil {
+			return testing.Environment{}, nil, err
+		}
+		return testing.Environment{AppSet: appSet, Config: envConfig, Spec: envSpec}, remove, nil
+	}
 }
 
-// Spec prints specification of running environment.
-func Spec(spec *infra.Spec) error {
-	fmt.Println(spec)
+// IBCReset forces relayers to regenerate IBC clients, connections and channels and restarts them.
+func IBCReset(ctx context.Context, config infra.Config, spec *infra.Spec) error {
+	log := logger.Get(ctx)
+	for appName, app := range spec.Apps {
+		if app.Type() != relayercosmos.AppType {
+			continue
+		}
+
+		log := log.With(zap.String("app", appName))
+		if err := relayercosmos.ResetPaths(filepath.Join(config.AppDir, appName)); err != nil {
+			return err
+		}
+		if app.Info().Status != infra.AppStatusRunning {
+			log.Info("Relayer is not running, paths will be regenerated on next start")
+			continue
+		}
+
+		log.Info("Restarting relayer to regenerate paths")
+		if err := targets.RestartContainer(ctx, app.Info().Container); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -239,40 +459,6 @@ func Console(ctx context.Context, config infra.Config, spec *infra.Spec) error {
 	return tmux.Kill(ctx, config.EnvName)
 }
 
-// PingPong connects to cored node and sends transactions back and forth from one account to another to generate
-// transactions on the blockchain.
-func PingPong(ctx context.Context, appSet infra.AppSet) error {
-	coredApp := appSet.FindRunningApp(cored.AppType, "cored-00")
-	if coredApp == nil {
-		return errors.New("no running cored app found")
-	}
-	coredNode := coredApp.(cored.Cored)
-	clientCtx := coredNode.ClientContext()
-	txf := coredNode.TxFactory(clientCtx)
-
-	aliceAddr := importMnemonic(clientCtx, "alice", cored.AliceMnemonic)
-	bobAddr := importMnemonic(clientCtx, "bob", cored.BobMnemonic)
-	charlieAddr := importMnemonic(clientCtx, "charlie", cored.CharlieMnemonic)
-
-	for {
-		if err := sendTokens(ctx, clientCtx, txf, aliceAddr, bobAddr, *coredNode.Config().Network); err != nil {
-			return err
-		}
-		if err := sendTokens(ctx, clientCtx, txf, bobAddr, charlieAddr, *coredNode.Config().Network); err != nil {
-			return err
-		}
-		if err := sendTokens(ctx, clientCtx, txf, charlieAddr, aliceAddr, *coredNode.Config().Network); err != nil {
-			return err
-		}
-
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(time.Second):
-		}
-	}
-}
-
 // importMnemonic imports the mnemonic into the ClientContext Keyring and returns its address.
 func importMnemonic(clientCtx client.Context, keyName, mnemonic string) sdk.AccAddress {
 	keyInfo, err := clientCtx.Keyring().NewAccount(
@@ -287,59 +473,15 @@ func importMnemonic(clientCtx client.Context, keyName, mnemonic string) sdk.AccA
 	return keyInfo.GetAddress()
 }
 
-func sendTokens(ctx context.Context, clientCtx client.Context, txf tx.Factory, from, to sdk.AccAddress, network config.Network) error {
-	log := logger.Get(ctx)
-
-	amount := sdk.NewCoin(network.Denom(), sdk.OneInt())
-	txf = txf.WithSimulateAndExecute(true)
-
-	msg := &banktypes.MsgSend{
-		FromAddress: from.String(),
-		ToAddress:   to.String(),
-		Amount:      sdk.NewCoins(amount),
-	}
-
-	res, err := client.BroadcastTx(ctx, clientCtx, txf, msg)
-	if err != nil {
-		return err
-	}
-
-	log.Info("Sent tokens", zap.Stringer("from", from), zap.Stringer("to", to),
-		zap.Stringer("amount", amount), zap.String("txHash", res.TxHash),
-		zap.Int64("gasUsed", res.GasUsed))
-
-	bankClient := banktypes.NewQueryClient(clientCtx)
-
-	fromBalance, err := bankClient.Balance(ctx, &banktypes.QueryBalanceRequest{
-		Address: from.String(),
-		Denom:   amount.Denom,
-	})
-	if err != nil {
-		return err
-	}
-	toBalance, err := bankClient.Balance(ctx, &banktypes.QueryBalanceRequest{
-		Address: to.String(),
-		Denom:   amount.Denom,
-	})
-	if err != nil {
-		return err
-	}
-
-	log.Info("Current balance", zap.Stringer("wallet", from), zap.Stringer("balance", fromBalance.Balance))
-	log.Info("Current balance", zap.Stringer("wallet", to), zap.Stringer("balance", toBalance.Balance))
-
-	return nil
-}
-
 func saveWrapper(dir, file, command string) {
-	must.OK(os.WriteFile(dir+"/"+file, []byte(`#!/bin/bash
+	must.OK(os.WriteFile(dir+"/"+file, []byte(`#!/bin/sh
 exec "`+exe+`" "`+command+`" "$@"
 `), 0o700))
 }
 
 func saveLogsWrapper(dir, envName, file string) {
-	must.OK(os.WriteFile(dir+"/"+file, []byte(`#!/bin/bash
-if [ "$1" == "" ]; then
+	must.OK(os.WriteFile(dir+"/"+file, []byte(`#!/bin/sh
+if [ "$1" = "" ]; then
   echo "Provide the name of application"
   exit 1
 fi
@@ -347,24 +489,48 @@ exec docker logs -f "`+envName+`-$1"
 `), 0o700))
 }
 
-var supportedShells = map[string]func(envName string) string{
-	"bash": func(envName string) string {
-		return "PS1=(" + envName + `) [\u@\h \W]\$ `
+// shellSession returns arguments and environment variables setting the prompt of the shell.
+type shellSession func(envName string) (args []string, env []string)
+
+var supportedShells = map[string]shellSession{
+	"bash": func(envName string) ([]string, []string) {
+		return nil, []string{"PS1=(" + envName + `) [\u@\h \W]\$ `}
 	},
-	"zsh": func(envName string) string {
-		return "PROMPT=(" + envName + `) [%n@%m %1~]%# `
+	"zsh": func(envName string) ([]string, []string) {
+		return nil, []string{"PROMPT=(" + envName + `) [%n@%m %1~]%# `}
+	},
+	"fish": func(envName string) ([]string, []string) {
+		// Fish doesn't read prompt from environment, so the configured prompt function is wrapped instead,
+		// after user's configuration is loaded.
+		return []string{"--init-command", `functions -q fish_prompt; and functions -c fish_prompt __znet_fish_prompt
+function fish_prompt
+	echo -n "(` + envName + `) "
+	functions -q __znet_fish_prompt; and __znet_fish_prompt; or echo -n '> '
+end`}, nil
 	},
 }
 
-func shellConfig(envName string) (string, string, error) {
+// posixShell sets the prompt of any other shell following POSIX.
+func posixShell(envName string) ([]string, []string) {
+	return nil, []string{"PS1=(" + envName + ") $ "}
+}
+
+// shellConfig returns the shell started in the environment, together with its arguments and environment variables.
+// Shell defined by $SHELL is preferred, if it is not set or doesn't exist, one of the known shells is used.
+func shellConfig(envName string) (string, []string, []string, error) {
 	shell := os.Getenv("SHELL")
-	if _, exists := supportedShells[filepath.Base(shell)]; !exists {
+	if shell != "" {
+		if _, err := osexec.LookPath(shell); err != nil {
+			shell = ""
+		}
+	}
+	if shell == "" {
 		var shells []string
 		switch runtime.GOOS {
 		case "darwin":
-			shells = []string{"zsh", "bash"}
+			shells = []string{"zsh", "bash", "sh"}
 		default:
-			shells = []string{"bash", "zsh"}
+			shells = []string{"bash", "zsh", "sh"}
 		}
 		for _, s := range shells {
 			if shell2, err := osexec.LookPath(s); err == nil {
@@ -374,12 +540,13 @@ func shellConfig(envName string) (string, string, error) {
 		}
 	}
 	if shell == "" {
-		return "", "", errors.New("custom shell not defined and supported shell not found")
+		return "", nil, nil, errors.New("custom shell not defined and supported shell not found")
 	}
 
-	var promptVar string
-	if promptVarFn, exists := supportedShells[filepath.Base(shell)]; exists {
-		promptVar = promptVarFn(envName)
+	session, exists := supportedShells[filepath.Base(shell)]
+	if !exists {
+		session = posixShell
 	}
-	return shell, promptVar, nil
+	args, env := session(envName)
+	return shell, args, env, nil
 }
@@ -0,0 +1,36 @@
+package znet
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/CoreumFoundation/crust/infra"
+)
+
+// AppNames returns sorted names of the apps stored in the spec of the environment.
+func AppNames(spec *infra.Spec) []string {
+	names := lo.Keys(spec.Apps)
+	sort.Strings(names)
+	return names
+}
+
+// CoredVersions returns versions of cored binaries available in the bin directory, which may be passed
+// to `--cored-version`.
+func CoredVersions(binDir string) []string {
+	entries, err := os.ReadDir(filepath.Join(binDir, ".cache", "docker", "cored"))
+	if err != nil {
+		return nil
+	}
+	var versions []string
+	for _, entry := range entries {
+		if version, ok := strings.CutPrefix(entry.Name(), "cored-"); ok && !entry.IsDir() {
+			versions = append(versions, version)
+		}
+	}
+	sort.Strings(versions)
+	return versions
+}
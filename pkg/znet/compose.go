@@ -0,0 +1,131 @@
+package znet
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/crust/exec"
+	"github.com/CoreumFoundation/crust/infra"
+)
+
+type composeFile struct {
+	Name     string                    `yaml:"name"`
+	Services map[string]composeService `yaml:"services"`
+	Networks map[string]composeNetwork `yaml:"networks"`
+}
+
+type composeService struct {
+	ContainerName string                           `yaml:"container_name"` //nolint:tagliatelle // defined by docker compose
+	Hostname      string                           `yaml:"hostname"`
+	Image         string                           `yaml:"image"`
+	User          string                           `yaml:"user,omitempty"`
+	Entrypoint    []string                         `yaml:"entrypoint,omitempty"`
+	Command       []string                         `yaml:"command,omitempty"`
+	Environment   map[string]string                `yaml:"environment,omitempty"`
+	Ports         []string                         `yaml:"ports,omitempty"`
+	Volumes       []string                         `yaml:"volumes,omitempty"`
+	DependsOn     []string                         `yaml:"depends_on,omitempty"` //nolint:tagliatelle // defined by docker compose
+	CPUShares     int                              `yaml:"cpu_shares,omitempty"` //nolint:tagliatelle // defined by docker compose
+	CPUs          float64                          `yaml:"cpus,omitempty"`
+	MemLimit      int64                            `yaml:"mem_limit,omitempty"`  //nolint:tagliatelle // defined by docker compose
+	PidsLimit     int                              `yaml:"pids_limit,omitempty"` //nolint:tagliatelle // defined by docker compose
+	Restart       string                           `yaml:"restart,omitempty"`
+	Networks      map[string]composeServiceNetwork `yaml:"networks"`
+}
+
+type composeServiceNetwork struct {
+	Aliases []string `yaml:"aliases"`
+}
+
+type composeNetwork struct {
+	Name string `yaml:"name"`
+}
+
+// ExportCompose renders apps of the environment into docker-compose file. Containers are configured the same way
+// as by `start` and they are reachable under the same hostnames, but names of containers and the network are
+// suffixed, so they don't collide with the stopped environment. Files prepared in the home directories of the apps
+// are mounted from their current location, that's why environment must have been started before. Steps executed
+// by znet after container is started, like loading database schemas, are not reproduced.
+func ExportCompose(ctx context.Context, config infra.Config, appSet infra.AppSet, output string) error {
+	compose := composeFile{
+		Name:     config.EnvName + "-compose",
+		Services: map[string]composeService{},
+		Networks: map[string]composeNetwork{
+			"default": {Name: config.EnvName + "-compose"},
+		},
+	}
+	for _, app := range appSet {
+		if app.Info().Status == infra.AppStatusNotDeployed {
+			return errors.Errorf("app %s has never been started, start the environment first", app.Name())
+		}
+
+		deployment := app.Deployment()
+		resources := config.Resources.For(app.Type(), app.Name())
+		// Apps connect to each other using names of the containers created by `start`.
+		hostname := config.EnvName + "-" + deployment.Name
+		service := composeService{
+			ContainerName: hostname + "-compose",
+			Hostname:      hostname,
+			Image:         exec.QualifiedImage(deployment.Image),
+			DependsOn:     app.Info().DependsOn,
+			CPUShares:     resources.CPUShares,
+			CPUs:          resources.CPUs,
+			MemLimit:      resources.Memory,
+			PidsLimit:     resources.Pids,
+			Restart:       string(deployment.RestartPolicy),
+			Networks: map[string]composeServiceNetwork{
+				"default": {Aliases: append([]string{hostname}, config.NetworkAliases[deployment.Name]...)},
+			},
+		}
+		if deployment.RunAsUser {
+			service.User = fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+		}
+		if deployment.Entrypoint != "" {
+			service.Entrypoint = []string{deployment.Entrypoint}
+		}
+		if deployment.ArgsFunc != nil {
+			service.Command = deployment.ArgsFunc()
+		}
+		if deployment.EnvVarsFunc != nil {
+			service.Environment = map[string]string{}
+			for _, env := range deployment.EnvVarsFunc() {
+				service.Environment[env.Name] = env.Value
+			}
+		}
+		publishAddress := "127.0.0.1"
+		if deployment.PublishOnAllInterfaces {
+			publishAddress = "0.0.0.0"
+		}
+		for name, port := range deployment.Ports {
+			service.Ports = append(service.Ports, publishAddress+":"+strconv.Itoa(port)+":"+
+				strconv.Itoa(deployment.ContainerPort(name))+"/tcp")
+		}
+		sort.Strings(service.Ports)
+		for _, v := range deployment.Volumes {
+			service.Volumes = append(service.Volumes, v.Source+":"+v.Destination)
+		}
+		compose.Services[deployment.Name] = service
+	}
+
+	encoded, err := yaml.Marshal(compose)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if output == "-" {
+		fmt.Print(string(encoded))
+		return nil
+	}
+	if err := os.WriteFile(output, encoded, 0o600); err != nil {
+		return errors.WithStack(err)
+	}
+	logger.Get(ctx).Info("Compose file exported", zap.String("file", output))
+	return nil
+}
@@ -0,0 +1,139 @@
+package znet
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+	"github.com/CoreumFoundation/crust/pkg/wasm"
+)
+
+// ContractDeployment describes contract to deploy.
+type ContractDeployment struct {
+	// WASMFile is the path to the file containing WASM byte code of the contract
+	WASMFile string
+
+	// InstantiateMsgFile is the path to the file containing instantiate message, empty object is used if not set
+	InstantiateMsgFile string
+
+	// Label is the label of the contract, name of the WASM file is used if not set
+	Label string
+
+	// Funds are the tokens sent to the contract on instantiation
+	Funds sdk.Coins
+}
+
+// DeployedContract is the result of contract deployment.
+type DeployedContract struct {
+	CodeID  uint64 `json:"codeId"`
+	Address string `json:"address"`
+}
+
+// ParseContractDeployment parses contract definition in the form of `<wasm-file>[:<instantiate-msg-file>]`.
+func ParseContractDeployment(def string) ContractDeployment {
+	wasmFile, msgFile, _ := strings.Cut(def, ":")
+	return ContractDeployment{
+		WASMFile:           wasmFile,
+		InstantiateMsgFile: msgFile,
+	}
+}
+
+// ContractsDeploy deploys the contract and prints its code ID and address.
+func ContractsDeploy(ctx context.Context, appSet infra.AppSet, deployment ContractDeployment) error {
+	contract, err := deployContract(ctx, appSet, deployment)
+	if err != nil {
+		return err
+	}
+	return printJSON(contract)
+}
+
+// deployContract stores the code of the contract and instantiates it on the running chain using the funding account
+// stored in genesis, which becomes the admin of the contract.
+func deployContract(ctx context.Context, appSet infra.AppSet, deployment ContractDeployment) (DeployedContract, error) {
+	coredApp := appSet.FindRunningApp(cored.AppType, "cored-00")
+	if coredApp == nil {
+		return DeployedContract{}, errors.New("no running cored app found")
+	}
+	coredNode := coredApp.(cored.Cored)
+
+	wasmData, err := os.ReadFile(deployment.WASMFile)
+	if err != nil {
+		return DeployedContract{}, errors.Wrap(err, "reading contract code failed")
+	}
+	instantiateMsg := json.RawMessage("{}")
+	if deployment.InstantiateMsgFile != "" {
+		instantiateMsg, err = os.ReadFile(deployment.InstantiateMsgFile)
+		if err != nil {
+			return DeployedContract{}, errors.Wrap(err, "reading instantiate message failed")
+		}
+		if !json.Valid(instantiateMsg) {
+			return DeployedContract{}, errors.Errorf("instantiate message in %s is not a valid JSON",
+				deployment.InstantiateMsgFile)
+		}
+	}
+	label := deployment.Label
+	if label == "" {
+		label = strings.TrimSuffix(filepath.Base(deployment.WASMFile), filepath.Ext(deployment.WASMFile))
+	}
+
+	clientCtx := coredNode.ClientContext()
+	from := importMnemonic(clientCtx, "funding", coredNode.Config().FundingMnemonic)
+	clientCtx = clientCtx.WithFromName("funding").WithFromAddress(from)
+	txf := coredNode.TxFactory(clientCtx).WithSimulateAndExecute(true)
+
+	log := logger.Get(ctx).With(zap.String("file", deployment.WASMFile))
+	log.Info("Storing contract code")
+	codeID, err := wasm.StoreCode(ctx, clientCtx, txf, wasmData)
+	if err != nil {
+		return DeployedContract{}, errors.Wrap(err, "storing contract code failed")
+	}
+
+	log.Info("Instantiating contract", zap.Uint64("codeID", codeID))
+	address, err := wasm.Instantiate(ctx, clientCtx, txf, wasm.InstantiateConfig{
+		CodeID:  codeID,
+		Admin:   from,
+		Label:   label,
+		Payload: instantiateMsg,
+		Funds:   deployment.Funds,
+	})
+	if err != nil {
+		return DeployedContract{}, errors.Wrap(err, "instantiating contract failed")
+	}
+
+	log.Info("Contract deployed", zap.Uint64("codeID", codeID), zap.String("address", address))
+	return DeployedContract{CodeID: codeID, Address: address}, nil
+}
+
+// deployStartupContracts deploys contracts configured to be deployed when environment is started for the first time.
+func deployStartupContracts(ctx context.Context, config infra.Config, appSet infra.AppSet) error {
+	if len(config.Contracts) == 0 {
+		return nil
+	}
+	coredApp := appSet.FindRunningApp(cored.AppType, "cored-00")
+	if coredApp == nil {
+		return errors.New("no running cored app found")
+	}
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, time.Minute)
+	defer waitCancel()
+	if err := infra.WaitUntilHealthy(waitCtx, coredApp.(cored.Cored)); err != nil {
+		return err
+	}
+
+	for _, def := range config.Contracts {
+		if _, err := deployContract(ctx, appSet, ParseContractDeployment(def)); err != nil {
+			return errors.Wrapf(err, "deploying contract %s failed", def)
+		}
+	}
+	return nil
+}
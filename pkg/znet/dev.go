@@ -0,0 +1,210 @@
+package znet
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/targets"
+)
+
+// devDebounce is the time without changes after which images are rebuilt, so saving many files at once, e.g. when
+// switching branches, triggers single rebuild.
+const devDebounce = 2 * time.Second
+
+// Dev watches source repositories of the images used by the apps, rebuilds images whenever sources change,
+// and recreates containers of the apps using them, preserving their state. If no app names are passed, all
+// the deployed apps using images built by crust are watched.
+func Dev(ctx context.Context, configF *infra.ConfigFactory, appNames []string) error {
+	log := logger.Get(ctx)
+
+	_, appSet, err := loadAppSet(configF)
+	if err != nil {
+		return err
+	}
+	repos, err := devRepos(ctx, appSet, appNames)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer watcher.Close()
+
+	repoPaths := lo.Keys(repos)
+	sort.Strings(repoPaths)
+	for _, repo := range repoPaths {
+		if err := watchDirs(watcher, repo); err != nil {
+			return err
+		}
+		log.Info("Watching sources", zap.String("repo", repo), zap.Strings("images", repos[repo]))
+	}
+
+	changed := map[string]bool{}
+	var rebuild <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-watcher.Errors:
+			return errors.WithStack(err)
+		case event := <-watcher.Events:
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := watchDirs(watcher, event.Name); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+			if event.Op == fsnotify.Chmod || !isSourceFile(event.Name) {
+				continue
+			}
+			repo, exists := lo.Find(repoPaths, func(repo string) bool {
+				return strings.HasPrefix(event.Name, repo+string(filepath.Separator))
+			})
+			if !exists {
+				continue
+			}
+			log.Debug("Source file changed", zap.String("file", event.Name))
+			changed[repo] = true
+			rebuild = time.After(devDebounce)
+		case <-rebuild:
+			var images []string
+			for repo := range changed {
+				images = append(images, repos[repo]...)
+			}
+			sort.Strings(images)
+			changed = map[string]bool{}
+			rebuild = nil
+
+			if err := devRebuild(ctx, configF, images); err != nil {
+				if errors.Is(err, ctx.Err()) {
+					return err
+				}
+				// Build fails e.g. if code doesn't compile, so next change is awaited instead of exiting.
+				log.Error("Rebuilding failed, waiting for the next change", zap.Error(err))
+				continue
+			}
+			log.Info("Apps are running the latest sources", zap.Strings("images", images))
+		}
+	}
+}
+
+// devRepos maps source repositories to the images built from them, used by the apps.
+func devRepos(ctx context.Context, appSet infra.AppSet, appNames []string) (map[string][]string, error) {
+	for _, name := range appNames {
+		if !lo.ContainsBy(appSet, func(app infra.App) bool { return app.Name() == name }) {
+			return nil, errors.Errorf("app %s doesn't exist", name)
+		}
+	}
+
+	repos := map[string][]string{}
+	images := map[string]bool{}
+	for _, app := range appSet {
+		if len(appNames) > 0 && !lo.Contains(appNames, app.Name()) {
+			continue
+		}
+		image := app.Deployment().Image
+		if app.Info().Status == infra.AppStatusNotDeployed || !strings.HasSuffix(image, localImageTag) ||
+			images[image] {
+			continue
+		}
+		images[image] = true
+
+		info, err := targets.InspectImage(ctx, image)
+		if err != nil {
+			return nil, err
+		}
+		if info.RepoPath == "" {
+			logger.Get(ctx).Warn("Image doesn't contain path of its source repository, rebuild it first",
+				zap.String("image", image))
+			continue
+		}
+		repos[info.RepoPath] = append(repos[info.RepoPath], image)
+	}
+	if len(repos) == 0 {
+		return nil, errors.New("none of the deployed apps uses image built by crust from the source repository, " +
+			"start the environment first")
+	}
+	return repos, nil
+}
+
+// devRebuild rebuilds images and recreates containers of the apps using them.
+func devRebuild(ctx context.Context, configF *infra.ConfigFactory, images []string) error {
+	// Lock is held only while rebuilding, so other commands may be used in the environment while sources are watched.
+	unlock, err := LockEnv(configF)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Spec is loaded again, because it might have been modified by other commands since the previous rebuild.
+	spec, appSet, err := loadAppSet(configF)
+	if err != nil {
+		return err
+	}
+	config := NewConfig(configF, spec)
+
+	// All the images are built before any container is removed, so apps keep running if build fails.
+	for _, image := range images {
+		if err := buildImage(ctx, config, image); err != nil {
+			return err
+		}
+	}
+	var restart bool
+	for _, image := range images {
+		removed, err := removeContainers(ctx, spec, appSet, image)
+		if err != nil {
+			return err
+		}
+		restart = restart || removed
+	}
+	if !restart {
+		return nil
+	}
+	return Start(ctx, config, spec)
+}
+
+// watchDirs adds the directory and all its subdirectories to the watcher. Hidden directories, like `.git`,
+// and directories containing build artifacts and dependencies are skipped.
+func watchDirs(watcher *fsnotify.Watcher, root string) error {
+	return errors.WithStack(filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				// Directory has been removed in the meantime.
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if path != root && (strings.HasPrefix(name, ".") || name == "bin" || name == "node_modules" ||
+			name == "vendor") {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	}))
+}
+
+// isSourceFile returns true if file affects the binary, tests don't.
+func isSourceFile(path string) bool {
+	name := filepath.Base(path)
+	return (strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go")) ||
+		name == "go.mod" || name == "go.sum"
+}
@@ -0,0 +1,258 @@
+package znet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	osexec "os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/crust/exec"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/targets"
+)
+
+const (
+	// minDockerVersion is the oldest version of docker engine known to support all the features used by znet.
+	minDockerVersion = "20.10"
+
+	// minPodmanVersion is the oldest version of podman known to support all the features used by znet.
+	minPodmanVersion = "4.0"
+
+	// minFreeDiskSpace is the free disk space in the home directory below which a warning is printed.
+	minFreeDiskSpace = 10 * 1024 * 1024 * 1024
+
+	// minOpenFiles is the limit of open files below which a warning is printed.
+	minOpenFiles = 4096
+)
+
+// doctorCheck is a single check executed by the doctor command.
+type doctorCheck struct {
+	Name  string
+	Check func(ctx context.Context) (string, error)
+
+	// Remedy explains how to fix the problem if check fails
+	Remedy string
+
+	// Optional checks only print warning if they fail
+	Optional bool
+
+	// Next checks are skipped if fatal check fails, because they depend on it
+	Fatal bool
+}
+
+// Doctor diagnoses problems with the setup of the host preventing environment from working correctly.
+func Doctor(ctx context.Context, config infra.Config, appSet infra.AppSet) error {
+	var dockerCtx targets.DockerContext
+	checks := []doctorCheck{
+		{
+			Name: "disk space",
+			Check: func(ctx context.Context) (string, error) {
+				return checkDiskSpace(config.HomeDir)
+			},
+			Remedy:   "free disk space, e.g. by running `remove` in unused environments and `docker system prune`",
+			Optional: true,
+		},
+		{
+			Name:     "open files limit",
+			Check:    checkOpenFilesLimit,
+			Remedy:   fmt.Sprintf("raise the limit, e.g. by running `ulimit -n %d` before entering the environment", minOpenFiles),
+			Optional: true,
+		},
+		{
+			Name: "ports",
+			Check: func(ctx context.Context) (string, error) {
+				return checkPorts(appSet)
+			},
+			Remedy: "stop processes or environments using the ports, use `lsof -i :<port>` to find them",
+		},
+		{
+			Name: "tmux",
+			Check: func(ctx context.Context) (string, error) {
+				path, err := osexec.LookPath("tmux")
+				if err != nil {
+					return "", errors.New("tmux not found")
+				}
+				return path, nil
+			},
+			Remedy:   "install tmux to use `console` or use `console --web` instead",
+			Optional: true,
+		},
+		{
+			Name: "docker context",
+			Check: func(ctx context.Context) (string, error) {
+				if _, err := osexec.LookPath(exec.ContainerRuntime()); err != nil {
+					return "", errors.Errorf("%s CLI not found", exec.ContainerRuntime())
+				}
+				var err error
+				dockerCtx, err = targets.CurrentDockerContext(ctx)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%s (%s, runtime: %s)", dockerCtx.Name, dockerCtx.Endpoint, dockerCtx.Runtime), nil
+			},
+			Remedy: "install docker or podman and make sure `docker info` or `podman info` works for the current user, " +
+				"select the runtime using --container-runtime if both are installed",
+			Fatal: true,
+		},
+		{
+			Name: "devcontainer",
+			Check: func(ctx context.Context) (string, error) {
+				return checkDevContainer(ctx, config.HomeDir)
+			},
+			Remedy: "store the environment in the directory mounted from the host, e.g. the workspace, using --home",
+		},
+		{
+			Name:  "docker version",
+			Check: checkDockerVersion,
+			Remedy: "upgrade docker engine to version " + minDockerVersion + " or podman to version " + minPodmanVersion +
+				" or newer",
+		},
+		{
+			Name: "bind mounts",
+			Check: func(ctx context.Context) (string, error) {
+				if remoteHost := dockerCtx.RemoteHost(); remoteHost != "" {
+					if err := targets.VerifyRemoteHome(ctx, config.HomeDir); err != nil {
+						return "", err
+					}
+					return config.HomeDir + " is shared with the remote docker host " + remoteHost, nil
+				}
+				if err := dockerCtx.VerifyBindMounts(config.HomeDir); err != nil {
+					return "", err
+				}
+				return config.HomeDir + " may be mounted in containers", nil
+			},
+			Remedy: "share the home directory with the docker VM or pass --home pointing to the shared directory",
+		},
+	}
+
+	var failed bool
+	for _, check := range checks {
+		result, err := check.Check(ctx)
+		if err == nil {
+			fmt.Printf("[ OK ] %s: %s\n", check.Name, result)
+			continue
+		}
+		if check.Optional {
+			fmt.Printf("[WARN] %s: %s\n", check.Name, err)
+			fmt.Printf("       fix: %s\n", check.Remedy)
+			continue
+		}
+		failed = true
+		fmt.Printf("[FAIL] %s: %s\n", check.Name, err)
+		fmt.Printf("       fix: %s\n", check.Remedy)
+		if check.Fatal {
+			break
+		}
+	}
+	if failed {
+		return errors.New("problems detected")
+	}
+	return nil
+}
+
+func checkDevContainer(ctx context.Context, homeDir string) (string, error) {
+	devContainer, err := targets.DetectDevContainer(ctx)
+	if err != nil {
+		return "", err
+	}
+	switch devContainer.Mode {
+	case targets.DevContainerNone:
+		return "not running inside container", nil
+	case targets.DevContainerDinD:
+		return "docker daemon runs inside the devcontainer", nil
+	}
+	hostHomeDir, err := devContainer.HostPath(homeDir)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("docker daemon of the host is used, %s is mounted from %s, apps are reachable by "+
+		"container names", homeDir, hostHomeDir), nil
+}
+
+func checkDockerVersion(ctx context.Context) (string, error) {
+	engine, format, minVersion := "docker engine", "{{.Server.Version}}", minDockerVersion
+	if exec.IsPodman() {
+		// Local podman has no server, client runs containers itself.
+		engine, format, minVersion = "podman", "{{.Client.Version}}", minPodmanVersion
+	}
+
+	buf := &bytes.Buffer{}
+	cmd := exec.Docker("version", "--format", format)
+	cmd.Stdout = buf
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return "", errors.Wrapf(err, "checking version of %s failed", engine)
+	}
+	version := strings.TrimSpace(buf.String())
+	if compareVersions(version, minVersion) < 0 {
+		return "", errors.Errorf("%s %s is older than %s", engine, version, minVersion)
+	}
+	return engine + " " + version, nil
+}
+
+// compareVersions compares numeric components of dot-separated versions, suffixes like `-ce` are ignored.
+func compareVersions(v1, v2 string) int {
+	parts1 := strings.Split(v1, ".")
+	parts2 := strings.Split(v2, ".")
+	for i := 0; i < len(parts1) && i < len(parts2); i++ {
+		n1 := leadingNumber(parts1[i])
+		n2 := leadingNumber(parts2[i])
+		if n1 != n2 {
+			if n1 < n2 {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(parts1) - len(parts2)
+}
+
+func leadingNumber(s string) int {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(s[:end])
+	return n
+}
+
+func checkDiskSpace(dir string) (string, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return "", errors.WithStack(err)
+	}
+	free := stat.Bavail * uint64(stat.Bsize)
+	result := fmt.Sprintf("%.1f GiB free in %s", float64(free)/(1024*1024*1024), dir)
+	if free < minFreeDiskSpace {
+		return "", errors.New("only " + result)
+	}
+	return result, nil
+}
+
+func checkOpenFilesLimit(ctx context.Context) (string, error) {
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		return "", errors.WithStack(err)
+	}
+	if limit.Cur < minOpenFiles {
+		return "", errors.Errorf("soft limit is %d, at least %d is recommended", limit.Cur, minOpenFiles)
+	}
+	return strconv.FormatUint(limit.Cur, 10), nil
+}
+
+// checkPorts verifies that ports published by the apps which are not running are free on the host.
+func checkPorts(appSet infra.AppSet) (string, error) {
+	if conflicts := findPortConflicts(appSet, 0, nil); len(conflicts) > 0 {
+		busy := make([]string, 0, len(conflicts))
+		for _, c := range conflicts {
+			busy = append(busy, c.String())
+		}
+		return "", errors.Errorf("ports in use: %s", strings.Join(busy, ", "))
+	}
+	return "ports required by the profiles are free", nil
+}
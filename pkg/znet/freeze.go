@@ -0,0 +1,134 @@
+package znet
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum-tools/pkg/parallel"
+	integrationtests "github.com/CoreumFoundation/coreum/integration-tests"
+	"github.com/CoreumFoundation/coreum/pkg/config/constant"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+	"github.com/CoreumFoundation/crust/infra/targets"
+)
+
+// clockJumpThreshold is the age of the latest block above which it is assumed that the host has been suspended.
+const clockJumpThreshold = time.Minute
+
+// Freeze pauses all the running apps, so environment survives suspending the host.
+func Freeze(ctx context.Context, spec *infra.Spec) error {
+	if spec.FrozenAt != nil {
+		logger.Get(ctx).Info("Environment is already frozen", zap.Time("frozenAt", *spec.FrozenAt))
+		return nil
+	}
+
+	if err := forRunningContainers(ctx, spec, targets.PauseContainer); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	spec.FrozenAt = &now
+	if err := spec.Save(); err != nil {
+		return errors.WithStack(err)
+	}
+	logger.Get(ctx).Info("Environment frozen, use thaw to resume it")
+	return nil
+}
+
+// Thaw resumes apps paused by Freeze and waits until chain produces new block.
+func Thaw(ctx context.Context, config infra.Config, spec *infra.Spec) error {
+	if err := unfreeze(ctx, spec); err != nil {
+		return err
+	}
+
+	networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+	if err != nil {
+		return err
+	}
+	appSet, err := apps.BuildAppSet(apps.NewFactory(config, spec, networkConfig), config.Profiles,
+		config.CoredVersion)
+	if err != nil {
+		return err
+	}
+
+	coredApp := appSet.FindRunningApp(cored.AppType, "cored-00")
+	if coredApp == nil {
+		return nil
+	}
+	coredNode := coredApp.(cored.Cored)
+
+	log := logger.Get(ctx).With(zap.String("node", coredNode.Name()))
+	log.Info("Waiting for new block")
+	status, err := coredNode.ClientContext().RPCClient().Status(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := waitForHeight(ctx, coredNode.ClientContext(), status.SyncInfo.LatestBlockHeight+1); err != nil {
+		return errors.Wrap(err, "chain hasn't produced new block after thawing, restart the environment using stop and start")
+	}
+	log.Info("Environment thawed and producing blocks")
+	return nil
+}
+
+// unfreeze resumes apps paused by Freeze.
+func unfreeze(ctx context.Context, spec *infra.Spec) error {
+	if spec.FrozenAt == nil {
+		return nil
+	}
+
+	if err := forRunningContainers(ctx, spec, targets.UnpauseContainer); err != nil {
+		return err
+	}
+
+	logger.Get(ctx).Info("Environment resumed", zap.Duration("frozenFor", time.Since(*spec.FrozenAt)))
+	spec.FrozenAt = nil
+	return errors.WithStack(spec.Save())
+}
+
+// detectClockJump warns if the latest block produced by the chain is much older than the current time, which happens
+// if the host has been suspended without freezing the environment.
+func detectClockJump(ctx context.Context, appSet infra.AppSet) {
+	for _, app := range appSet {
+		coredNode, ok := app.(cored.Cored)
+		if !ok || coredNode.Info().Status != infra.AppStatusRunning {
+			continue
+		}
+
+		requestCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		status, err := coredNode.ClientContext().RPCClient().Status(requestCtx)
+		cancel()
+		if err != nil {
+			continue
+		}
+		age := time.Since(status.SyncInfo.LatestBlockTime)
+		if age < clockJumpThreshold {
+			continue
+		}
+
+		logger.Get(ctx).Warn("Latest block is much older than current time, host has probably been suspended "+
+			"without freezing the environment. Chain should recover by itself within a few blocks, if it doesn't, "+
+			"restart the environment using stop and start. Use freeze before suspending the host and thaw "+
+			"after resuming it next time.", zap.String("node", coredNode.Name()), zap.Duration("latestBlockAge", age))
+		return
+	}
+}
+
+func forRunningContainers(ctx context.Context, spec *infra.Spec, fn func(ctx context.Context, name string) error) error {
+	return parallel.Run(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
+		for appName, app := range spec.Apps {
+			if app.Info().Status != infra.AppStatusRunning {
+				continue
+			}
+			container := app.Info().Container
+			spawn(appName, parallel.Continue, func(ctx context.Context) error {
+				return fn(ctx, container)
+			})
+		}
+		return nil
+	})
+}
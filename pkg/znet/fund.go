@@ -0,0 +1,67 @@
+package znet
+
+import (
+	"context"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum/pkg/client"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+	"github.com/CoreumFoundation/crust/infra/apps/faucet"
+)
+
+// Fund sends tokens to the address from the funding account stored in genesis. If amount is empty, the same amount
+// the faucet sends is used. Hash of the transaction is returned.
+func Fund(ctx context.Context, appSet infra.AppSet, address string, amount sdk.Coins) (string, error) {
+	coredApp := appSet.FindRunningApp(cored.AppType, "cored-00")
+	if coredApp == nil {
+		return "", errors.New("no running cored app found")
+	}
+	coredNode := coredApp.(cored.Cored)
+
+	if _, err := sdk.AccAddressFromBech32(address); err != nil {
+		return "", errors.Wrapf(err, "invalid address %q", address)
+	}
+	if amount.Empty() {
+		amount = sdk.NewCoins(sdk.NewInt64Coin(coredNode.Config().Network.Denom(), faucet.DefaultTransferAmount))
+	}
+
+	clientCtx := coredNode.ClientContext()
+	from := importMnemonic(clientCtx, "funding", coredNode.Config().FundingMnemonic)
+	res, err := client.BroadcastTx(ctx, clientCtx.WithFromName("funding").WithFromAddress(from),
+		coredNode.TxFactory(clientCtx).WithSimulateAndExecute(true),
+		&banktypes.MsgSend{
+			FromAddress: from.String(),
+			ToAddress:   address,
+			Amount:      amount,
+		})
+	if err != nil {
+		return "", err
+	}
+
+	logger.Get(ctx).Info("Account funded", zap.String("address", address), zap.Stringer("amount", amount),
+		zap.String("txHash", res.TxHash))
+	return res.TxHash, nil
+}
+
+// FundFromFaucet requests faucet to send tokens to the address. Faucet always sends the same amount.
+func FundFromFaucet(ctx context.Context, appSet infra.AppSet, address string) error {
+	faucetApp := appSet.FindRunningApp(faucet.AppType, "faucet")
+	if faucetApp == nil {
+		return errors.New("no running faucet app found, start the environment with faucet profile")
+	}
+	if _, err := sdk.AccAddressFromBech32(address); err != nil {
+		return errors.Wrapf(err, "invalid address %q", address)
+	}
+
+	if err := faucetApp.(faucet.Faucet).Fund(ctx, address); err != nil {
+		return err
+	}
+	logger.Get(ctx).Info("Account funded by faucet", zap.String("address", address))
+	return nil
+}
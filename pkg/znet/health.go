@@ -0,0 +1,208 @@
+package znet
+
+import (
+	"context"
+	"time"
+
+	"github.com/samber/lo"
+
+	integrationtests "github.com/CoreumFoundation/coreum/integration-tests"
+	"github.com/CoreumFoundation/coreum/pkg/config/constant"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+)
+
+// Overall states of the environment reported by EnvironmentStatus.
+const (
+	// EnvStatusHealthy means all the deployed apps are running, they pass health checks and chain produces blocks.
+	EnvStatusHealthy = "healthy"
+
+	// EnvStatusDegraded means some apps are not running or are unhealthy, or chain doesn't produce blocks.
+	EnvStatusDegraded = "degraded"
+
+	// EnvStatusFrozen means environment has been frozen.
+	EnvStatusFrozen = "frozen"
+
+	// EnvStatusStopped means none of the apps is running.
+	EnvStatusStopped = "stopped"
+
+	// EnvStatusNotDeployed means environment hasn't been started yet.
+	EnvStatusNotDeployed = "notDeployed"
+)
+
+// chainStallThreshold is the age of the latest block after which chain is considered stalled.
+const chainStallThreshold = 30 * time.Second
+
+// EnvStatus summarizes the state of the whole environment for external monitors.
+type EnvStatus struct {
+	// Env is the name of the environment
+	Env string `json:"env"`
+
+	// Status is the overall state of the environment: healthy, degraded, frozen, stopped or notDeployed
+	Status string `json:"status"`
+
+	// Reasons explains why environment is not healthy
+	Reasons []string `json:"reasons,omitempty"`
+
+	// Profiles is the list of deployed application profiles
+	Profiles []string `json:"profiles"`
+
+	// FrozenAt is the time when environment was frozen, absent if it is not frozen
+	FrozenAt *time.Time `json:"frozenAt,omitempty"`
+
+	// CheckedAt is the time when status was collected
+	CheckedAt time.Time `json:"checkedAt"`
+
+	// Chain is the state of cored chain, absent if cored is not running
+	Chain *ChainStatus `json:"chain,omitempty"`
+
+	// Apps is the list of applications sorted by name
+	Apps []AppStatus `json:"apps"`
+}
+
+// ChainStatus is the state of cored chain.
+type ChainStatus struct {
+	// ChainID is the ID of the chain
+	ChainID string `json:"chainID"`
+
+	// Height is the height of the latest block
+	Height int64 `json:"height"`
+
+	// BlockTime is the time of the latest block
+	BlockTime time.Time `json:"blockTime"`
+
+	// Error is the reason why state of the chain couldn't be queried
+	Error string `json:"error,omitempty"`
+}
+
+// AppStatus is the state of the application.
+type AppStatus struct {
+	// Name is the name of the application
+	Name string `json:"name"`
+
+	// Type is the type of the application, e.g. `cored`
+	Type string `json:"type"`
+
+	// Status is the status of the application: notDeployed, stopped or running
+	Status string `json:"status"`
+
+	// Health is the result of the health check of running application: healthy or unhealthy, absent if application
+	// is not running or it doesn't provide health check
+	Health string `json:"health,omitempty"`
+
+	// HealthError is the reason why application is unhealthy
+	HealthError string `json:"healthError,omitempty"`
+}
+
+// EnvironmentStatus collects state of the apps, results of their health checks and progress of the chain.
+func EnvironmentStatus(ctx context.Context, configF *infra.ConfigFactory) (EnvStatus, error) {
+	spec, appSet, err := loadAppSet(configF)
+	if err != nil {
+		return EnvStatus{}, err
+	}
+
+	doc, err := NewSpecDocument(ctx, spec)
+	if err != nil {
+		return EnvStatus{}, err
+	}
+	checkSpecHealth(ctx, appSet, doc.Apps)
+
+	status := EnvStatus{
+		Env:       doc.Env,
+		Profiles:  doc.Profiles,
+		FrozenAt:  doc.FrozenAt,
+		CheckedAt: time.Now().UTC(),
+		Apps: lo.Map(doc.Apps, func(app SpecApp, _ int) AppStatus {
+			return AppStatus{
+				Name:        app.Name,
+				Type:        app.Type,
+				Status:      app.Status,
+				Health:      app.Health,
+				HealthError: app.HealthError,
+			}
+		}),
+	}
+	if status.FrozenAt == nil {
+		status.Chain = chainStatus(ctx, appSet)
+	}
+	status.Status, status.Reasons = overallStatus(status)
+	return status, nil
+}
+
+// loadAppSet reads the current spec of the environment and builds its app set, so long-running servers report
+// changes made by other commands.
+func loadAppSet(configF *infra.ConfigFactory) (*infra.Spec, infra.AppSet, error) {
+	spec := infra.NewSpec(configF)
+	networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+	if err != nil {
+		return nil, nil, err
+	}
+	config := NewConfig(configF, spec)
+	appSet, err := apps.BuildAppSet(apps.NewFactory(config, spec, networkConfig), config.Profiles,
+		config.CoredVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+	return spec, appSet, nil
+}
+
+func chainStatus(ctx context.Context, appSet infra.AppSet) *ChainStatus {
+	app, exists := lo.Find(appSet, func(app infra.App) bool {
+		coredNode, ok := app.(cored.Cored)
+		return ok && coredNode.Info().Status == infra.AppStatusRunning
+	})
+	if !exists {
+		return nil
+	}
+
+	coredNode := app.(cored.Cored)
+	chain := &ChainStatus{ChainID: string(coredNode.Config().Network.ChainID())}
+	requestCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	status, err := coredNode.ClientContext().RPCClient().Status(requestCtx)
+	if err != nil {
+		chain.Error = err.Error()
+		return chain
+	}
+	chain.Height = status.SyncInfo.LatestBlockHeight
+	chain.BlockTime = status.SyncInfo.LatestBlockTime
+	return chain
+}
+
+func overallStatus(status EnvStatus) (string, []string) {
+	deployed := lo.Filter(status.Apps, func(app AppStatus, _ int) bool {
+		return app.Status != "notDeployed"
+	})
+	switch {
+	case len(deployed) == 0:
+		return EnvStatusNotDeployed, nil
+	case status.FrozenAt != nil:
+		return EnvStatusFrozen, nil
+	case !lo.ContainsBy(deployed, func(app AppStatus) bool { return app.Status == string(infra.AppStatusRunning) }):
+		return EnvStatusStopped, nil
+	}
+
+	var reasons []string
+	for _, app := range deployed {
+		switch {
+		case app.Status != string(infra.AppStatusRunning):
+			reasons = append(reasons, app.Name+" is "+app.Status)
+		case app.Health == SpecHealthUnhealthy:
+			reasons = append(reasons, app.Name+" is unhealthy: "+app.HealthError)
+		}
+	}
+	if chain := status.Chain; chain != nil {
+		switch {
+		case chain.Error != "":
+			reasons = append(reasons, "querying chain failed: "+chain.Error)
+		case status.CheckedAt.Sub(chain.BlockTime) > chainStallThreshold:
+			reasons = append(reasons, "chain doesn't produce blocks, latest one is "+
+				status.CheckedAt.Sub(chain.BlockTime).Round(time.Second).String()+" old")
+		}
+	}
+	if len(reasons) > 0 {
+		return EnvStatusDegraded, reasons
+	}
+	return EnvStatusHealthy, nil
+}
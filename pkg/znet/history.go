@@ -0,0 +1,172 @@
+package znet
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/CoreumFoundation/crust/infra"
+)
+
+// historyDir is the directory, next to the home directories of the environments, where commands executed in each
+// environment are recorded, so history is kept when environment is removed.
+const historyDir = ".history"
+
+// legacyHistoryFile is the file in the home directory of the environment where history was recorded previously.
+const legacyHistoryFile = "history.jsonl"
+
+// SecretAnnotation is the annotation of the flag which value is secret, e.g. token, so it is redacted in the history.
+const SecretAnnotation = "crust_secret"
+
+// redactedValue is stored in the history instead of the value of the secret flag.
+const redactedValue = "***"
+
+// historyEntry is the record of executed command.
+type historyEntry struct {
+	Time         time.Time         `json:"time"`
+	Command      string            `json:"command"`
+	Args         []string          `json:"args,omitempty"`
+	Flags        map[string]string `json:"flags,omitempty"`
+	Profiles     []string          `json:"profiles,omitempty"`
+	CoredVersion string            `json:"coredVersion,omitempty"`
+	Duration     string            `json:"duration"`
+	Error        string            `json:"error,omitempty"`
+}
+
+func historyPath(configF *infra.ConfigFactory) string {
+	return filepath.Join(configF.HomeDir, historyDir, configF.EnvName+".jsonl")
+}
+
+// migrateHistory moves history recorded in the home directory of the environment to the history directory.
+func migrateHistory(configF *infra.ConfigFactory) error {
+	legacyPath := filepath.Join(configF.HomeDir, configF.EnvName, legacyHistoryFile)
+	if _, err := os.Stat(legacyPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+	path := historyPath(configF)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(legacyPath, path))
+}
+
+// recordHistory appends the executed command to the history of the environment. History is stored outside the home
+// directory of the environment, so it is kept when environment is removed.
+func recordHistory(configF *infra.ConfigFactory, cmd *cobra.Command, args []string, start time.Time, cmdErr error) error {
+	entry := historyEntry{
+		Time:         start.UTC(),
+		Command:      cmd.CommandPath(),
+		Args:         args,
+		Flags:        map[string]string{},
+		Profiles:     configF.Profiles,
+		CoredVersion: configF.CoredVersion,
+		Duration:     time.Since(start).Round(time.Millisecond).String(),
+	}
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		if _, secret := flag.Annotations[SecretAnnotation]; secret {
+			entry.Flags[flag.Name] = redactedValue
+			return
+		}
+		if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+			entry.Flags[flag.Name] = strings.Join(sliceValue.GetSlice(), ",")
+			return
+		}
+		entry.Flags[flag.Name] = flag.Value.String()
+	})
+	if cmdErr != nil {
+		entry.Error = cmdErr.Error()
+	}
+
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := migrateHistory(configF); err != nil {
+		return err
+	}
+	path := historyPath(configF)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return errors.WithStack(err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(content, '\n'))
+	return errors.WithStack(err)
+}
+
+// History prints the last commands executed in the environment.
+func History(configF *infra.ConfigFactory, limit int) error {
+	if err := migrateHistory(configF); err != nil {
+		return err
+	}
+	f, err := os.Open(historyPath(configF))
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Println("No commands have been executed in the environment yet")
+		return nil
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	var entries []historyEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var entry historyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return errors.Wrapf(err, "invalid entry in history file %s", f.Name())
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.WithStack(err)
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tDURATION\tRESULT\tCOMMAND")
+	for _, e := range entries {
+		result := "success"
+		if e.Error != "" {
+			result = "failure: " + e.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Time.Local().Format(time.DateTime), e.Duration, result, e.commandLine())
+	}
+	return errors.WithStack(w.Flush())
+}
+
+func (e historyEntry) commandLine() string {
+	names := lo.Keys(e.Flags)
+	sort.Strings(names)
+
+	parts := []string{e.Command}
+	for _, name := range names {
+		parts = append(parts, "--"+name+"="+e.Flags[name])
+	}
+	return strings.Join(append(parts, e.Args...), " ")
+}
@@ -0,0 +1,126 @@
+package znet
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	integrationtests "github.com/CoreumFoundation/coreum/integration-tests"
+	"github.com/CoreumFoundation/coreum/pkg/config/constant"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps"
+)
+
+// DefaultHostsFile is the default file where host entries are stored.
+const DefaultHostsFile = "/etc/hosts"
+
+// hostsAddress is the address host entries point to. All the ports of the apps are published on host's localhost
+// and they are unique, so the same address is used for all of them.
+const hostsAddress = "127.0.0.1"
+
+// HostsAdd adds entries mapping `<app>.<env>.local` hostnames to the apps, so they may be used in configs
+// and browsers. Entries of the environment added previously are replaced.
+func HostsAdd(ctx context.Context, config infra.Config, spec *infra.Spec, hostsFile string) error {
+	networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+	if err != nil {
+		return err
+	}
+	appSet, err := apps.BuildAppSet(apps.NewFactory(config, spec, networkConfig), config.Profiles,
+		config.CoredVersion)
+	if err != nil {
+		return err
+	}
+
+	hostnames := make([]string, 0, len(appSet))
+	for _, app := range appSet {
+		hostnames = append(hostnames, appHostname(config.EnvName, app.Name()))
+	}
+	sort.Strings(hostnames)
+
+	lines := make([]string, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		lines = append(lines, hostsAddress+" "+hostname)
+	}
+	if err := updateHostsFile(ctx, hostsFile, config.EnvName, lines); err != nil {
+		return err
+	}
+	logger.Get(ctx).Info("Host entries added", zap.String("file", hostsFile), zap.Strings("hostnames", hostnames))
+	return nil
+}
+
+// HostsRemove removes entries added by HostsAdd.
+func HostsRemove(ctx context.Context, config infra.Config, hostsFile string) error {
+	if err := updateHostsFile(ctx, hostsFile, config.EnvName, nil); err != nil {
+		return err
+	}
+	logger.Get(ctx).Info("Host entries removed", zap.String("file", hostsFile))
+	return nil
+}
+
+func appHostname(envName, appName string) string {
+	return appName + "." + envName + ".local"
+}
+
+// updateHostsFile replaces the block of the environment in the hosts file with lines. Block is removed if there are
+// no lines. If file is not writable by the current user, sudo is used.
+func updateHostsFile(ctx context.Context, hostsFile, envName string, lines []string) error {
+	content, err := os.ReadFile(hostsFile)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	begin := "# BEGIN znet " + envName
+	end := "# END znet " + envName
+	var result []string
+	inBlock := false
+	for _, line := range strings.Split(strings.TrimRight(string(content), "\n"), "\n") {
+		switch {
+		case line == begin:
+			inBlock = true
+		case line == end:
+			inBlock = false
+		case !inBlock:
+			result = append(result, line)
+		}
+	}
+	if len(lines) > 0 {
+		result = append(result, begin)
+		result = append(result, lines...)
+		result = append(result, end)
+	}
+	newContent := []byte(strings.Join(result, "\n") + "\n")
+
+	err = os.WriteFile(hostsFile, newContent, 0o644)
+	if err == nil || !errors.Is(err, os.ErrPermission) {
+		return errors.WithStack(err)
+	}
+
+	logger.Get(ctx).Info("Hosts file is not writable, sudo is used to update it", zap.String("file", hostsFile))
+	tmpFile, err := os.CreateTemp("", "znet-hosts-*")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(newContent); err != nil {
+		tmpFile.Close()
+		return errors.WithStack(err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	// cp is used, so the existing file is overwritten in place, keeping its owner and permissions.
+	cmd := osexec.Command("sudo", "cp", tmpFile.Name(), hostsFile)
+	cmd.Stdin = os.Stdin
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return errors.Wrapf(err, "updating %s failed", hostsFile)
+	}
+	return nil
+}
@@ -0,0 +1,943 @@
+package znet
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	upgradetypes "github.com/cosmos/cosmos-sdk/x/upgrade/types"
+	transfertypes "github.com/cosmos/ibc-go/v4/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
+	connectiontypes "github.com/cosmos/ibc-go/v4/modules/core/03-connection/types"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v4/modules/core/23-commitment/types"
+	host "github.com/cosmos/ibc-go/v4/modules/core/24-host"
+	"github.com/cosmos/ibc-go/v4/modules/core/exported"
+	solomachinetypes "github.com/cosmos/ibc-go/v4/modules/light-clients/06-solomachine/types"
+	ibctmtypes "github.com/cosmos/ibc-go/v4/modules/light-clients/07-tendermint/types"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum-tools/pkg/must"
+	"github.com/CoreumFoundation/coreum/pkg/client"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+)
+
+// Scenarios executed by the mock IBC counterparty.
+const (
+	IBCMockClientForgedHeader     = "client-forged-header"
+	IBCMockConnectionForgedProof  = "connection-forged-proof"
+	IBCMockConnectionWrongChain   = "connection-wrong-chain"
+	IBCMockPacketValid            = "packet-valid"
+	IBCMockPacketForgedProof      = "packet-forged-proof"
+	IBCMockPacketTimedOut         = "packet-timed-out"
+	IBCMockPacketWrongSource      = "packet-wrong-source"
+	IBCMockPacketMalformedData    = "packet-malformed-data"
+	IBCMockPacketUnbackedUnescrow = "packet-unbacked-unescrow"
+	IBCMockPacketReplay           = "packet-replay"
+	IBCMockAckUnsentPacket        = "ack-unsent-packet"
+	IBCMockChannelWrongVersion    = "channel-wrong-version"
+	IBCMockClientMisbehaviour     = "client-misbehaviour"
+)
+
+const (
+	// Identifiers of the mock's own client, connection and channel, cored only sees them in messages and proofs.
+	ibcMockClientID     = "07-tendermint-0"
+	ibcMockConnectionID = "connection-0"
+	ibcMockChannelID    = "channel-0"
+	ibcMockDiversifier  = "crust-ibc-mock"
+
+	// ibcMockGas is the gas limit of transactions sent by the mock. Gas is not estimated, because simulation fails
+	// for messages cored rejects, so they would never be delivered in a block.
+	ibcMockGas = 1_000_000
+
+	ibcMockDenom          = "mocktoken"
+	ibcMockAmount         = 1000
+	ibcMockSender         = "ibc-mock"
+	ibcMockPacketTimeout  = 10 * time.Minute
+	ibcMockMaxClockDrift  = 10 * time.Second
+	ibcMockWrongChainID   = "mock-chain-1"
+	ibcMockWrongVersion   = "ics20-2"
+	ibcMockUnsentSequence = 1_000_000
+)
+
+// ibcMockPrefix is the commitment prefix of the mock, the same as the one used by cosmos chains.
+var ibcMockPrefix = commitmenttypes.NewMerklePrefix([]byte(host.StoreKey))
+
+// ibcMockScenario is a single message sequence sent to cored by the mock, with verification of the way cored
+// handles it.
+type ibcMockScenario struct {
+	Name string
+
+	// Expected describes the behaviour of cored verified by the scenario
+	Expected string
+
+	// Channel scenarios require connection and transfer channel to be opened between cored and the mock first
+	Channel bool
+
+	// Run sends messages to cored and returns the observed behaviour, error is returned if it is not the expected one
+	Run func(ctx context.Context, m *ibcMock) (string, error)
+}
+
+// ibcMockScenarios returns scenarios in the order they are executed. Misbehaviour freezes the client, so it must be
+// the last one.
+func ibcMockScenarios() []ibcMockScenario {
+	return []ibcMockScenario{
+		{
+			Name:     IBCMockClientForgedHeader,
+			Expected: "client update signed by the key not known to the client is rejected",
+			Run:      ibcMockClientForgedHeaderRun,
+		},
+		{
+			Name:     IBCMockConnectionForgedProof,
+			Expected: "connection handshake with proofs signed by the unknown key is rejected",
+			Run: func(ctx context.Context, m *ibcMock) (string, error) {
+				msg, err := m.connectionOpenTry(ctx, secp256k1.GenPrivKey(), m.chainID)
+				if err != nil {
+					return "", err
+				}
+				return m.expectRejected(ctx, msg)
+			},
+		},
+		{
+			Name:     IBCMockConnectionWrongChain,
+			Expected: "connection handshake claiming the counterparty tracks a different chain is rejected",
+			Run: func(ctx context.Context, m *ibcMock) (string, error) {
+				msg, err := m.connectionOpenTry(ctx, m.key, ibcMockWrongChainID)
+				if err != nil {
+					return "", err
+				}
+				return m.expectRejected(ctx, msg)
+			},
+		},
+		{
+			Name:     IBCMockPacketValid,
+			Expected: "valid transfer is accepted and vouchers are minted, it proves the mock works",
+			Channel:  true,
+			Run:      ibcMockPacketValidRun,
+		},
+		{
+			Name:     IBCMockPacketForgedProof,
+			Expected: "packet with commitment proof signed by the unknown key is rejected",
+			Channel:  true,
+			Run: func(ctx context.Context, m *ibcMock) (string, error) {
+				msg, err := m.recvPacket(secp256k1.GenPrivKey(), m.transferPacket(ibcMockDenom))
+				if err != nil {
+					return "", err
+				}
+				return m.expectRejected(ctx, msg)
+			},
+		},
+		{
+			Name:     IBCMockPacketTimedOut,
+			Expected: "packet delivered after its timeout is rejected",
+			Channel:  true,
+			Run: func(ctx context.Context, m *ibcMock) (string, error) {
+				data := transfertypes.NewFungibleTokenPacketData(ibcMockDenom, sdk.NewInt(ibcMockAmount).String(),
+					ibcMockSender, m.receiver.String())
+				msg, err := m.recvPacket(m.key, m.packet(data.GetBytes(), time.Now().Add(-time.Minute)))
+				if err != nil {
+					return "", err
+				}
+				return m.expectRejected(ctx, msg)
+			},
+		},
+		{
+			Name:     IBCMockPacketWrongSource,
+			Expected: "packet sent from the channel which is not the counterparty of the destination one is rejected",
+			Channel:  true,
+			Run: func(ctx context.Context, m *ibcMock) (string, error) {
+				packet := m.transferPacket(ibcMockDenom)
+				packet.SourceChannel = "channel-999"
+				msg, err := m.recvPacket(m.key, packet)
+				if err != nil {
+					return "", err
+				}
+				return m.expectRejected(ctx, msg)
+			},
+		},
+		{
+			Name:     IBCMockPacketMalformedData,
+			Expected: "packet not containing ICS-20 data is acknowledged with error",
+			Channel:  true,
+			Run: func(ctx context.Context, m *ibcMock) (string, error) {
+				ack, err := m.deliverPacket(ctx, m.packet([]byte("not an ICS-20 packet"),
+					time.Now().Add(ibcMockPacketTimeout)))
+				if err != nil {
+					return "", err
+				}
+				if ack.Error == "" {
+					return "", errors.New("cored acknowledged malformed packet with success")
+				}
+				return "error acknowledgement: " + ack.Error, nil
+			},
+		},
+		{
+			Name: IBCMockPacketUnbackedUnescrow,
+			Expected: "packet returning native tokens never sent over the channel is acknowledged with error and " +
+				"nothing is released from escrow",
+			Channel: true,
+			Run:     ibcMockPacketUnbackedUnescrowRun,
+		},
+		{
+			Name:     IBCMockPacketReplay,
+			Expected: "packet delivered for the second time is a no-op and vouchers are minted once",
+			Channel:  true,
+			Run:      ibcMockPacketReplayRun,
+		},
+		{
+			Name:     IBCMockAckUnsentPacket,
+			Expected: "acknowledgement of the packet cored never sent is a no-op",
+			Channel:  true,
+			Run:      ibcMockAckUnsentPacketRun,
+		},
+		{
+			Name:     IBCMockChannelWrongVersion,
+			Expected: "transfer channel handshake with unsupported ICS-20 version is rejected",
+			Channel:  true,
+			Run: func(ctx context.Context, m *ibcMock) (string, error) {
+				msg, err := m.channelOpenTry(ibcMockChannelID+"1", ibcMockWrongVersion)
+				if err != nil {
+					return "", err
+				}
+				return m.expectRejected(ctx, msg)
+			},
+		},
+		{
+			Name: IBCMockClientMisbehaviour,
+			Expected: "proof of the counterparty signing two different values at the same sequence freezes the client " +
+				"and packets are no longer accepted",
+			Run: ibcMockClientMisbehaviourRun,
+		},
+	}
+}
+
+// IBCMockScenarios returns names of the scenarios executed by the mock IBC counterparty.
+func IBCMockScenarios() []string {
+	return lo.Map(ibcMockScenarios(), func(s ibcMockScenario, _ int) string { return s.Name })
+}
+
+// IBCMock acts as IBC counterparty of cored, sending malformed and unexpected handshake messages and packets which
+// real chain and relayer never produce, and verifies that cored handles them safely. Counterparty is a solo machine,
+// so the mock may sign any state it claims to have. If no scenario names are passed, all the scenarios are executed.
+func IBCMock(ctx context.Context, appSet infra.AppSet, scenarioNames []string) error {
+	for _, name := range scenarioNames {
+		if !lo.Contains(IBCMockScenarios(), name) {
+			return errors.Errorf("unknown scenario %q, supported ones: %v", name, IBCMockScenarios())
+		}
+	}
+	scenarios := lo.Filter(ibcMockScenarios(), func(s ibcMockScenario, _ int) bool {
+		return len(scenarioNames) == 0 || lo.Contains(scenarioNames, s.Name)
+	})
+
+	coredApp := appSet.FindRunningApp(cored.AppType, "cored-00")
+	if coredApp == nil {
+		return errors.New("no running cored app found")
+	}
+	log := logger.Get(ctx)
+
+	m, err := newIBCMock(ctx, coredApp.(cored.Cored))
+	if err != nil {
+		return err
+	}
+	log.Info("Mock IBC counterparty created", zap.String("clientID", m.clientID))
+
+	var failed int
+	for _, scenario := range scenarios {
+		if scenario.Channel && m.channelID == "" {
+			if err := m.openChannel(ctx); err != nil {
+				return errors.WithMessage(err, "opening channel between cored and the mock failed")
+			}
+			log.Info("Transfer channel opened", zap.String("connectionID", m.connectionID),
+				zap.String("channelID", m.channelID))
+		}
+		if err := m.sync(ctx); err != nil {
+			return err
+		}
+
+		result, err := scenario.Run(ctx, m)
+		if ctx.Err() != nil {
+			return errors.WithStack(ctx.Err())
+		}
+		if err != nil {
+			failed++
+			fmt.Printf("[FAIL] %s: %s\n", scenario.Name, err)
+			fmt.Printf("       expected: %s\n", scenario.Expected)
+			continue
+		}
+		fmt.Printf("[PASS] %s: %s\n", scenario.Name, result)
+	}
+	if failed > 0 {
+		return errors.Errorf("%d of %d scenarios failed", failed, len(scenarios))
+	}
+	return nil
+}
+
+// ibcAck is the acknowledgement written by cored after receiving the packet.
+type ibcAck struct {
+	Result []byte `json:"result"`
+	Error  string `json:"error"`
+}
+
+// ibcMock is the solo machine counterparty of cored.
+type ibcMock struct {
+	clientCtx client.Context
+	txf       tx.Factory
+	cdc       codec.BinaryCodec
+	chainID   string
+	denom     string
+
+	// address signs transactions sent by the mock
+	address sdk.AccAddress
+
+	// receiver receives tokens transferred by the mock, it doesn't pay fees, so its balances are easy to verify
+	receiver sdk.AccAddress
+
+	// key signs the states of the solo machine
+	key       cryptotypes.PrivKey
+	sequence  uint64
+	timestamp uint64
+
+	clientID       string
+	connectionID   string
+	channelID      string
+	packetSequence uint64
+}
+
+// newIBCMock funds the account of the mock and creates solo machine client of the mock on cored.
+func newIBCMock(ctx context.Context, coredNode cored.Cored) (*ibcMock, error) {
+	clientCtx := coredNode.ClientContext()
+	txf := coredNode.TxFactory(clientCtx)
+	m := &ibcMock{
+		clientCtx: clientCtx,
+		cdc:       codec.NewProtoCodec(codectypes.NewInterfaceRegistry()),
+		chainID:   string(coredNode.Config().Network.ChainID()),
+		denom:     coredNode.Config().Network.Denom(),
+		key:       secp256k1.GenPrivKey(),
+		sequence:  1,
+		timestamp: uint64(time.Now().Unix()),
+	}
+
+	accounts, err := generateAccounts(clientCtx, "ibc-mock", 2)
+	if err != nil {
+		return nil, err
+	}
+	m.address, m.receiver = accounts[0], accounts[1]
+	funding := importMnemonic(clientCtx, "funding", coredNode.Config().FundingMnemonic)
+	if err := fundAccounts(ctx, clientCtx, txf.WithSimulateAndExecute(true), funding, accounts[:1],
+		m.denom); err != nil {
+		return nil, err
+	}
+
+	gasPrice, err := client.GetGasPrice(ctx, clientCtx)
+	if err != nil {
+		return nil, err
+	}
+	gasPrice.Amount = gasPrice.Amount.Mul(clientCtx.GasPriceAdjustment())
+	m.txf = txf.WithGas(ibcMockGas).WithGasPrices(gasPrice.String())
+
+	pubKey, err := codectypes.NewAnyWithValue(m.key.PubKey())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	consensusState := &solomachinetypes.ConsensusState{
+		PublicKey:   pubKey,
+		Diversifier: ibcMockDiversifier,
+		Timestamp:   m.timestamp,
+	}
+	msg, err := clienttypes.NewMsgCreateClient(solomachinetypes.NewClientState(m.sequence, consensusState, false),
+		consensusState, m.address.String())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res, err := m.broadcast(ctx, msg)
+	if err != nil {
+		return nil, errors.WithMessage(err, "creating solo machine client failed")
+	}
+	m.clientID, err = eventAttribute(res, clienttypes.EventTypeCreateClient, clienttypes.AttributeKeyClientID)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// sync reads the sequence and timestamp of the solo machine from its client stored on cored. Each verified proof
+// increments the sequence, while rejected transactions don't, so it is done before each scenario.
+func (m *ibcMock) sync(ctx context.Context) error {
+	res, err := clienttypes.NewQueryClient(m.clientCtx).ClientState(ctx,
+		&clienttypes.QueryClientStateRequest{ClientId: m.clientID})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var clientState solomachinetypes.ClientState
+	if err := clientState.Unmarshal(res.ClientState.Value); err != nil {
+		return errors.WithStack(err)
+	}
+	m.sequence = clientState.Sequence
+	// Timestamp of the proof can't be older than the one of the consensus state.
+	m.timestamp = lo.Max([]uint64{uint64(time.Now().Unix()), clientState.ConsensusState.Timestamp})
+	return nil
+}
+
+// openChannel opens connection and transfer channel between cored and the mock. Mock initializes both handshakes,
+// so cored executes try and confirm steps verifying the proofs of the mock.
+func (m *ibcMock) openChannel(ctx context.Context) error {
+	if err := m.sync(ctx); err != nil {
+		return err
+	}
+	msgConnTry, err := m.connectionOpenTry(ctx, m.key, m.chainID)
+	if err != nil {
+		return err
+	}
+	res, err := m.broadcast(ctx, msgConnTry)
+	if err != nil {
+		return errors.WithMessage(err, "connection open try failed")
+	}
+	m.connectionID, err = eventAttribute(res, connectiontypes.EventTypeConnectionOpenTry,
+		connectiontypes.AttributeKeyConnectionID)
+	if err != nil {
+		return err
+	}
+
+	if err := m.sync(ctx); err != nil {
+		return err
+	}
+	proofAck, err := m.connectionProof(m.key, m.sequence, connectiontypes.OPEN, m.connectionID)
+	if err != nil {
+		return err
+	}
+	if _, err := m.broadcast(ctx, connectiontypes.NewMsgConnectionOpenConfirm(m.connectionID, proofAck, m.height(),
+		m.address.String())); err != nil {
+		return errors.WithMessage(err, "connection open confirm failed")
+	}
+
+	if err := m.sync(ctx); err != nil {
+		return err
+	}
+	msgChanTry, err := m.channelOpenTry(ibcMockChannelID, transfertypes.Version)
+	if err != nil {
+		return err
+	}
+	res, err = m.broadcast(ctx, msgChanTry)
+	if err != nil {
+		return errors.WithMessage(err, "channel open try failed")
+	}
+	channelID, err := eventAttribute(res, channeltypes.EventTypeChannelOpenTry, channeltypes.AttributeKeyChannelID)
+	if err != nil {
+		return err
+	}
+
+	if err := m.sync(ctx); err != nil {
+		return err
+	}
+	proofAck, err = m.channelProof(m.key, channeltypes.OPEN, ibcMockChannelID, channelID, transfertypes.Version)
+	if err != nil {
+		return err
+	}
+	if _, err := m.broadcast(ctx, channeltypes.NewMsgChannelOpenConfirm(transfertypes.PortID, channelID, proofAck,
+		m.height(), m.address.String())); err != nil {
+		return errors.WithMessage(err, "channel open confirm failed")
+	}
+	m.channelID = channelID
+	return nil
+}
+
+// connectionOpenTry returns the message opening connection on cored, claiming that the mock initialized it and
+// stores the client of the chain with the provided ID. Proofs are signed with the provided key.
+func (m *ibcMock) connectionOpenTry(
+	ctx context.Context,
+	key cryptotypes.PrivKey,
+	chainID string,
+) (*connectiontypes.MsgConnectionOpenTry, error) {
+	status, err := m.clientCtx.RPCClient().Status(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	stakingClient := stakingtypes.NewQueryClient(m.clientCtx)
+	histInfo, err := stakingClient.HistoricalInfo(ctx,
+		&stakingtypes.QueryHistoricalInfoRequest{Height: status.SyncInfo.LatestBlockHeight})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	params, err := stakingClient.Params(ctx, &stakingtypes.QueryParamsRequest{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// Client and consensus states must be the ones cored expects its counterparty to store.
+	consensusHeight := clienttypes.NewHeight(clienttypes.ParseChainID(m.chainID),
+		uint64(status.SyncInfo.LatestBlockHeight))
+	unbondingPeriod := params.Params.UnbondingTime
+	clientState := ibctmtypes.NewClientState(chainID, ibctmtypes.DefaultTrustLevel, unbondingPeriod*2/3,
+		unbondingPeriod, ibcMockMaxClockDrift, consensusHeight, commitmenttypes.GetSDKSpecs(),
+		[]string{upgradetypes.StoreKey, upgradetypes.KeyUpgradedIBCState}, false, false)
+	header := histInfo.Hist.Header
+	consensusState := ibctmtypes.NewConsensusState(header.Time, commitmenttypes.NewMerkleRoot(header.AppHash),
+		tmbytes.HexBytes(header.NextValidatorsHash))
+
+	// Cored verifies the proofs one by one, each one at the next sequence.
+	proofInit, err := m.connectionProof(key, m.sequence, connectiontypes.INIT, "")
+	if err != nil {
+		return nil, err
+	}
+	signBytes, err := solomachinetypes.ClientStateSignBytes(m.cdc, m.sequence+1, m.timestamp, ibcMockDiversifier,
+		ibcMockPath(host.FullClientStatePath(ibcMockClientID)), clientState)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	proofClient, err := m.proof(key, signBytes)
+	if err != nil {
+		return nil, err
+	}
+	signBytes, err = solomachinetypes.ConsensusStateSignBytes(m.cdc, m.sequence+2, m.timestamp, ibcMockDiversifier,
+		ibcMockPath(host.FullConsensusStatePath(ibcMockClientID, consensusHeight)), consensusState)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	proofConsensus, err := m.proof(key, signBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return connectiontypes.NewMsgConnectionOpenTry(m.clientID, ibcMockConnectionID, ibcMockClientID, clientState,
+		ibcMockPrefix, []*connectiontypes.Version{connectiontypes.DefaultIBCVersion}, 0, proofInit, proofClient,
+		proofConsensus, m.height(), consensusHeight, m.address.String()), nil
+}
+
+// connectionProof returns the proof of the mock storing its end of the connection in the provided state.
+func (m *ibcMock) connectionProof(
+	key cryptotypes.PrivKey,
+	sequence uint64,
+	state connectiontypes.State,
+	counterpartyConnectionID string,
+) ([]byte, error) {
+	connection := connectiontypes.NewConnectionEnd(state, ibcMockClientID,
+		connectiontypes.NewCounterparty(m.clientID, counterpartyConnectionID, ibcMockPrefix),
+		[]*connectiontypes.Version{connectiontypes.DefaultIBCVersion}, 0)
+	signBytes, err := solomachinetypes.ConnectionStateSignBytes(m.cdc, sequence, m.timestamp, ibcMockDiversifier,
+		ibcMockPath(host.ConnectionPath(ibcMockConnectionID)), connection)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return m.proof(key, signBytes)
+}
+
+// header returns the header rotating the key of the solo machine to the provided one, signed by that key.
+func (m *ibcMock) header(key cryptotypes.PrivKey) (*solomachinetypes.Header, error) {
+	pubKey, err := codectypes.NewAnyWithValue(key.PubKey())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	header := &solomachinetypes.Header{
+		Sequence:       m.sequence,
+		Timestamp:      m.timestamp,
+		NewPublicKey:   pubKey,
+		NewDiversifier: ibcMockDiversifier,
+	}
+	signBytes, err := solomachinetypes.HeaderSignBytes(m.cdc, header)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if header.Signature, err = m.signature(key, signBytes); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// misbehaviour returns the proof of the solo machine committing to two different packets with the same sequence.
+func (m *ibcMock) misbehaviour() (*solomachinetypes.Misbehaviour, error) {
+	path := ibcMockPath(host.PacketCommitmentPath(transfertypes.PortID, ibcMockChannelID, ibcMockUnsentSequence))
+	misbehaviour := &solomachinetypes.Misbehaviour{
+		ClientId: m.clientID,
+		Sequence: m.sequence,
+	}
+	for i, sigAndData := range []**solomachinetypes.SignatureAndData{
+		&misbehaviour.SignatureOne, &misbehaviour.SignatureTwo,
+	} {
+		data, err := solomachinetypes.PacketCommitmentDataBytes(m.cdc, path, []byte(fmt.Sprintf("commitment-%d", i)))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		signBytes, err := solomachinetypes.MisbehaviourSignBytes(m.cdc, m.sequence, m.timestamp, ibcMockDiversifier,
+			solomachinetypes.PACKETCOMMITMENT, data)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		signature, err := m.signature(m.key, signBytes)
+		if err != nil {
+			return nil, err
+		}
+		*sigAndData = &solomachinetypes.SignatureAndData{
+			Signature: signature,
+			DataType:  solomachinetypes.PACKETCOMMITMENT,
+			Data:      data,
+			Timestamp: m.timestamp,
+		}
+	}
+	return misbehaviour, nil
+}
+
+// channelOpenTry returns the message opening transfer channel on cored, claiming that the mock initialized it.
+func (m *ibcMock) channelOpenTry(mockChannelID, version string) (*channeltypes.MsgChannelOpenTry, error) {
+	proofInit, err := m.channelProof(m.key, channeltypes.INIT, mockChannelID, "", version)
+	if err != nil {
+		return nil, err
+	}
+	return channeltypes.NewMsgChannelOpenTry(transfertypes.PortID, version, channeltypes.UNORDERED,
+		[]string{m.connectionID}, transfertypes.PortID, mockChannelID, version, proofInit, m.height(),
+		m.address.String()), nil
+}
+
+// channelProof returns the proof of the mock storing its end of the channel in the provided state.
+func (m *ibcMock) channelProof(
+	key cryptotypes.PrivKey,
+	state channeltypes.State,
+	mockChannelID, counterpartyChannelID, version string,
+) ([]byte, error) {
+	channel := channeltypes.NewChannel(state, channeltypes.UNORDERED,
+		channeltypes.NewCounterparty(transfertypes.PortID, counterpartyChannelID), []string{ibcMockConnectionID},
+		version)
+	signBytes, err := solomachinetypes.ChannelStateSignBytes(m.cdc, m.sequence, m.timestamp, ibcMockDiversifier,
+		ibcMockPath(host.ChannelPath(transfertypes.PortID, mockChannelID)), channel)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return m.proof(key, signBytes)
+}
+
+// packet returns the next packet sent by the mock to cored over the transfer channel.
+func (m *ibcMock) packet(data []byte, timeout time.Time) channeltypes.Packet {
+	m.packetSequence++
+	return channeltypes.NewPacket(data, m.packetSequence, transfertypes.PortID, ibcMockChannelID,
+		transfertypes.PortID, m.channelID, clienttypes.ZeroHeight(), uint64(timeout.UnixNano()))
+}
+
+// transferPacket returns the packet transferring tokens to the receiver.
+func (m *ibcMock) transferPacket(denom string) channeltypes.Packet {
+	data := transfertypes.NewFungibleTokenPacketData(denom, sdk.NewInt(ibcMockAmount).String(), ibcMockSender,
+		m.receiver.String())
+	return m.packet(data.GetBytes(), time.Now().Add(ibcMockPacketTimeout))
+}
+
+// recvPacket returns the message delivering the packet to cored, with the commitment signed by the provided key.
+func (m *ibcMock) recvPacket(key cryptotypes.PrivKey, packet channeltypes.Packet) (*channeltypes.MsgRecvPacket, error) {
+	signBytes, err := solomachinetypes.PacketCommitmentSignBytes(m.cdc, m.sequence, m.timestamp, ibcMockDiversifier,
+		ibcMockPath(host.PacketCommitmentPath(packet.SourcePort, packet.SourceChannel, packet.Sequence)),
+		channeltypes.CommitPacket(m.cdc, packet))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	proof, err := m.proof(key, signBytes)
+	if err != nil {
+		return nil, err
+	}
+	return channeltypes.NewMsgRecvPacket(packet, proof, m.height(), m.address.String()), nil
+}
+
+// deliverPacket delivers the packet to cored and returns the acknowledgement written by cored.
+func (m *ibcMock) deliverPacket(ctx context.Context, packet channeltypes.Packet) (ibcAck, error) {
+	msg, err := m.recvPacket(m.key, packet)
+	if err != nil {
+		return ibcAck{}, err
+	}
+	res, err := m.broadcast(ctx, msg)
+	if err != nil {
+		return ibcAck{}, errors.WithMessage(err, "packet has been rejected")
+	}
+	ackJSON, err := eventAttribute(res, channeltypes.EventTypeWriteAck, channeltypes.AttributeKeyAck)
+	if err != nil {
+		return ibcAck{}, err
+	}
+	var ack ibcAck
+	if err := json.Unmarshal([]byte(ackJSON), &ack); err != nil {
+		return ibcAck{}, errors.Wrapf(err, "decoding acknowledgement %q failed", ackJSON)
+	}
+	return ack, nil
+}
+
+// voucherDenom returns the denom of vouchers minted by cored for the tokens transferred by the mock.
+func (m *ibcMock) voucherDenom(denom string) string {
+	return transfertypes.ParseDenomTrace(
+		transfertypes.GetPrefixedDenom(transfertypes.PortID, m.channelID, denom)).IBCDenom()
+}
+
+// height returns the proof height, which is the current sequence of the solo machine.
+func (m *ibcMock) height() clienttypes.Height {
+	return clienttypes.NewHeight(0, m.sequence)
+}
+
+// proof signs the bytes and encodes the signature in the form of the proof verified by the solo machine client.
+func (m *ibcMock) proof(key cryptotypes.PrivKey, signBytes []byte) ([]byte, error) {
+	signature, err := m.signature(key, signBytes)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := m.cdc.Marshal(&solomachinetypes.TimestampedSignatureData{
+		SignatureData: signature,
+		Timestamp:     m.timestamp,
+	})
+	return proof, errors.WithStack(err)
+}
+
+func (m *ibcMock) signature(key cryptotypes.PrivKey, signBytes []byte) ([]byte, error) {
+	sig, err := key.Sign(signBytes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	signature, err := m.cdc.Marshal(signing.SignatureDataToProto(&signing.SingleSignatureData{Signature: sig}))
+	return signature, errors.WithStack(err)
+}
+
+func (m *ibcMock) broadcast(ctx context.Context, msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+	return client.BroadcastTx(ctx, m.clientCtx.WithFromAddress(m.address), m.txf, msgs...)
+}
+
+// expectRejected broadcasts the message and verifies that transaction is rejected by cored.
+func (m *ibcMock) expectRejected(ctx context.Context, msg sdk.Msg) (string, error) {
+	res, err := m.broadcast(ctx, msg)
+	if err == nil {
+		return "", errors.Errorf("cored accepted transaction %s", res.TxHash)
+	}
+	// Errors not returned by the chain, e.g. network ones, don't prove anything.
+	var abciErr *sdkerrors.Error
+	if !errors.As(err, &abciErr) {
+		return "", err
+	}
+	return "rejected: " + err.Error(), nil
+}
+
+func ibcMockClientForgedHeaderRun(ctx context.Context, m *ibcMock) (string, error) {
+	header, err := m.header(secp256k1.GenPrivKey())
+	if err != nil {
+		return "", err
+	}
+	msg, err := clienttypes.NewMsgUpdateClient(m.clientID, header, m.address.String())
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return m.expectRejected(ctx, msg)
+}
+
+func ibcMockPacketValidRun(ctx context.Context, m *ibcMock) (string, error) {
+	voucherDenom := m.voucherDenom(ibcMockDenom)
+	before, err := queryBalance(ctx, m.clientCtx, m.receiver.String(), voucherDenom)
+	if err != nil {
+		return "", err
+	}
+	ack, err := m.deliverPacket(ctx, m.transferPacket(ibcMockDenom))
+	if err != nil {
+		return "", err
+	}
+	if ack.Error != "" {
+		return "", errors.Errorf("cored acknowledged valid packet with error: %s", ack.Error)
+	}
+	after, err := queryBalance(ctx, m.clientCtx, m.receiver.String(), voucherDenom)
+	if err != nil {
+		return "", err
+	}
+	if !after.Equal(before.AddRaw(ibcMockAmount)) {
+		return "", errors.Errorf("balance of vouchers is %s, expected %s", after, before.AddRaw(ibcMockAmount))
+	}
+	return fmt.Sprintf("accepted, %d%s minted", ibcMockAmount, voucherDenom), nil
+}
+
+func ibcMockPacketUnbackedUnescrowRun(ctx context.Context, m *ibcMock) (string, error) {
+	before, err := queryBalance(ctx, m.clientCtx, m.receiver.String(), m.denom)
+	if err != nil {
+		return "", err
+	}
+	// Prefix tells cored that tokens originate from cored and return back, so they are released from escrow.
+	ack, err := m.deliverPacket(ctx, m.transferPacket(
+		transfertypes.GetPrefixedDenom(transfertypes.PortID, ibcMockChannelID, m.denom)))
+	if err != nil {
+		return "", err
+	}
+	if ack.Error == "" {
+		return "", errors.New("cored acknowledged packet releasing tokens never escrowed with success")
+	}
+	after, err := queryBalance(ctx, m.clientCtx, m.receiver.String(), m.denom)
+	if err != nil {
+		return "", err
+	}
+	if !after.Equal(before) {
+		return "", errors.Errorf("receiver got %s%s", after.Sub(before), m.denom)
+	}
+	return "error acknowledgement: " + ack.Error, nil
+}
+
+func ibcMockPacketReplayRun(ctx context.Context, m *ibcMock) (string, error) {
+	voucherDenom := m.voucherDenom(ibcMockDenom)
+	before, err := queryBalance(ctx, m.clientCtx, m.receiver.String(), voucherDenom)
+	if err != nil {
+		return "", err
+	}
+	packet := m.transferPacket(ibcMockDenom)
+	ack, err := m.deliverPacket(ctx, packet)
+	if err != nil {
+		return "", err
+	}
+	if ack.Error != "" {
+		return "", errors.Errorf("cored acknowledged packet with error: %s", ack.Error)
+	}
+
+	if err := m.sync(ctx); err != nil {
+		return "", err
+	}
+	msg, err := m.recvPacket(m.key, packet)
+	if err != nil {
+		return "", err
+	}
+	msgResponse := &channeltypes.MsgRecvPacketResponse{}
+	result, err := m.expectNoOp(ctx, msg, msgResponse, &msgResponse.Result)
+	if err != nil {
+		return "", err
+	}
+
+	after, err := queryBalance(ctx, m.clientCtx, m.receiver.String(), voucherDenom)
+	if err != nil {
+		return "", err
+	}
+	if !after.Equal(before.AddRaw(ibcMockAmount)) {
+		return "", errors.Errorf("balance of vouchers is %s, expected %s", after, before.AddRaw(ibcMockAmount))
+	}
+	return result, nil
+}
+
+func ibcMockAckUnsentPacketRun(ctx context.Context, m *ibcMock) (string, error) {
+	data := transfertypes.NewFungibleTokenPacketData(m.denom, sdk.NewInt(ibcMockAmount).String(), m.address.String(),
+		ibcMockSender)
+	packet := channeltypes.NewPacket(data.GetBytes(), ibcMockUnsentSequence, transfertypes.PortID, m.channelID,
+		transfertypes.PortID, ibcMockChannelID, clienttypes.ZeroHeight(),
+		uint64(time.Now().Add(ibcMockPacketTimeout).UnixNano()))
+	ack := channeltypes.NewResultAcknowledgement([]byte{1}).Acknowledgement()
+
+	signBytes, err := solomachinetypes.PacketAcknowledgementSignBytes(m.cdc, m.sequence, m.timestamp,
+		ibcMockDiversifier,
+		ibcMockPath(host.PacketAcknowledgementPath(packet.DestinationPort, packet.DestinationChannel, packet.Sequence)),
+		channeltypes.CommitAcknowledgement(ack))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	proof, err := m.proof(m.key, signBytes)
+	if err != nil {
+		return "", err
+	}
+	msgResponse := &channeltypes.MsgAcknowledgementResponse{}
+	return m.expectNoOp(ctx, channeltypes.NewMsgAcknowledgement(packet, ack, proof, m.height(), m.address.String()),
+		msgResponse, &msgResponse.Result)
+}
+
+func ibcMockClientMisbehaviourRun(ctx context.Context, m *ibcMock) (string, error) {
+	misbehaviour, err := m.misbehaviour()
+	if err != nil {
+		return "", err
+	}
+	msg, err := clienttypes.NewMsgSubmitMisbehaviour(m.clientID, misbehaviour, m.address.String())
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if _, err := m.broadcast(ctx, msg); err != nil {
+		return "", errors.WithMessage(err, "misbehaviour has been rejected")
+	}
+	res, err := clienttypes.NewQueryClient(m.clientCtx).ClientStatus(ctx,
+		&clienttypes.QueryClientStatusRequest{ClientId: m.clientID})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if res.Status != exported.Frozen.String() {
+		return "", errors.Errorf("client status is %s after misbehaviour", res.Status)
+	}
+	if m.channelID == "" {
+		return "client frozen", nil
+	}
+
+	msgRecv, err := m.recvPacket(m.key, m.transferPacket(ibcMockDenom))
+	if err != nil {
+		return "", err
+	}
+	if _, err := m.expectRejected(ctx, msgRecv); err != nil {
+		return "", errors.WithMessage(err, "packet delivered over frozen client")
+	}
+	return "client frozen, packets rejected", nil
+}
+
+// expectNoOp broadcasts the packet message and verifies that cored either rejects it or executes it as no-op.
+// Message response is decoded into msgResponse, result points to its field storing the result.
+func (m *ibcMock) expectNoOp(
+	ctx context.Context,
+	msg sdk.Msg,
+	msgResponse codec.ProtoMarshaler,
+	result *channeltypes.ResponseResultType,
+) (string, error) {
+	res, err := m.broadcast(ctx, msg)
+	if err != nil {
+		var abciErr *sdkerrors.Error
+		if !errors.As(err, &abciErr) {
+			return "", err
+		}
+		return "rejected: " + err.Error(), nil
+	}
+	if err := decodeMsgResponse(res, msgResponse); err != nil {
+		return "", err
+	}
+	if *result != channeltypes.NOOP {
+		return "", errors.Errorf("cored executed transaction %s with result %s", res.TxHash, *result)
+	}
+	return "accepted as no-op", nil
+}
+
+// decodeMsgResponse decodes the response of the first message executed by the transaction.
+func decodeMsgResponse(res *sdk.TxResponse, msgResponse codec.ProtoMarshaler) error {
+	data, err := hex.DecodeString(res.Data)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var txMsgData sdk.TxMsgData
+	if err := txMsgData.Unmarshal(data); err != nil {
+		return errors.WithStack(err)
+	}
+	if len(txMsgData.Data) == 0 {
+		return errors.Errorf("transaction %s contains no message responses", res.TxHash)
+	}
+	return errors.WithStack(msgResponse.Unmarshal(txMsgData.Data[0].Data))
+}
+
+// eventAttribute returns the value of the attribute of the event emitted by the transaction.
+func eventAttribute(res *sdk.TxResponse, eventType, key string) (string, error) {
+	for _, log := range res.Logs {
+		for _, event := range log.Events {
+			if event.Type != eventType {
+				continue
+			}
+			for _, attr := range event.Attributes {
+				if attr.Key == key {
+					return attr.Value, nil
+				}
+			}
+		}
+	}
+	return "", errors.Errorf("transaction %s hasn't emitted attribute %s of event %s", res.TxHash, key, eventType)
+}
+
+// ibcMockPath returns the path in the store of the mock, where the value is stored.
+func ibcMockPath(path string) commitmenttypes.MerklePath {
+	prefixedPath, err := commitmenttypes.ApplyPrefix(ibcMockPrefix, commitmenttypes.NewMerklePath(path))
+	// Prefix is never empty, so it doesn't fail.
+	must.OK(err)
+	return prefixedPath
+}
@@ -0,0 +1,94 @@
+package znet
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/relayercosmos"
+)
+
+// ibcPathTimeout is the time relayers have to establish IBC paths after environment is started.
+const ibcPathTimeout = 5 * time.Minute
+
+// recordIBCPaths waits until relayers establish IBC paths and stores their identifiers in the spec. Failure doesn't
+// break the start, because paths may still be established later and recorded by the `spec` command.
+func recordIBCPaths(ctx context.Context, spec *infra.Spec, appSet infra.AppSet) error {
+	relayers := runningRelayers(spec, appSet)
+	if len(relayers) == 0 {
+		return nil
+	}
+
+	log := logger.Get(ctx)
+	waitCtx, cancel := context.WithTimeout(ctx, ibcPathTimeout)
+	defer cancel()
+	healthChecks := make([]infra.HealthCheckCapable, 0, len(relayers))
+	for _, relayer := range relayers {
+		healthChecks = append(healthChecks, relayer)
+	}
+	log.Info("Waiting for relayers to establish IBC paths")
+	if err := infra.WaitUntilHealthy(waitCtx, healthChecks...); err != nil {
+		log.Warn("Relayers haven't established IBC paths, they are not recorded in the spec", zap.Error(err))
+		return nil
+	}
+
+	updateIBCPaths(ctx, spec, relayers)
+	return spec.Save()
+}
+
+// refreshIBCPaths updates IBC identifiers of paths established by running relayers, so paths established after
+// the start or regenerated by `ibc reset` are visible in the spec.
+func refreshIBCPaths(ctx context.Context, spec *infra.Spec, appSet infra.AppSet) error {
+	if spec.FrozenAt != nil {
+		return nil
+	}
+	relayers := runningRelayers(spec, appSet)
+	if len(relayers) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, specHealthCheckTimeout)
+	defer cancel()
+	if !updateIBCPaths(ctx, spec, relayers) {
+		return nil
+	}
+	return spec.Save()
+}
+
+// updateIBCPaths queries IBC identifiers of paths established by relayers and stores them in the spec. It returns true
+// if any of them has changed.
+func updateIBCPaths(ctx context.Context, spec *infra.Spec, relayers []relayercosmos.Relayer) bool {
+	var changed bool
+	for _, relayer := range relayers {
+		path, err := relayer.IBCPath(ctx)
+		if err != nil {
+			logger.Get(ctx).Debug("Querying IBC path failed", zap.String("app", relayer.Name()), zap.Error(err))
+			continue
+		}
+		appInfo := spec.Apps[relayer.Name()]
+		if current := appInfo.IBCPath(); current != nil && reflect.DeepEqual(*current, path) {
+			continue
+		}
+		appInfo.SetIBCPath(path)
+		changed = true
+	}
+	return changed
+}
+
+func runningRelayers(spec *infra.Spec, appSet infra.AppSet) []relayercosmos.Relayer {
+	var relayers []relayercosmos.Relayer
+	for _, app := range appSet {
+		relayer, ok := app.(relayercosmos.Relayer)
+		if !ok {
+			continue
+		}
+		if appInfo, exists := spec.Apps[relayer.Name()]; exists && appInfo.Info().Status == infra.AppStatusRunning {
+			relayers = append(relayers, relayer)
+		}
+	}
+	return relayers
+}
@@ -0,0 +1,318 @@
+package znet
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// IDE formats supported by GenerateIDEConfig.
+const (
+	IDEVSCode    = "vscode"
+	IDEJetBrains = "jetbrains"
+)
+
+// ideSkippedCommands are the commands not worth exposing in IDE.
+var ideSkippedCommands = map[string]bool{
+	"help":       true,
+	"completion": true,
+	"docs":       true,
+	"ide":        true,
+}
+
+// ideCommand is the znet command exposed in IDE.
+type ideCommand struct {
+	// Path is the list of subcommands, e.g. ["chaos", "kill"].
+	Path []string
+	// Short is the description of the command.
+	Short string
+	// TakesArgs is true if command accepts positional arguments.
+	TakesArgs bool
+	// BinDirFlag is true if command accepts --bin-dir flag.
+	BinDirFlag bool
+	// FilterFlag is true if command accepts --filter flag.
+	FilterFlag bool
+}
+
+func (c ideCommand) Name() string {
+	return "znet " + strings.Join(c.Path, " ")
+}
+
+// GenerateIDEConfig generates tasks and run configurations of the IDE for all the commands registered in rootCmd,
+// so they stay in sync with the CLI. binDir is the directory of crust repository.
+func GenerateIDEConfig(rootCmd *cobra.Command, binDir, ide, outputDir string, force bool) error {
+	binDir, err := filepath.EvalSymlinks(binDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	binDir, err = filepath.Abs(binDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	commands := ideCommands(rootCmd, nil)
+	var files map[string][]byte
+	switch ide {
+	case IDEVSCode:
+		files, err = vscodeFiles(commands, binDir)
+	case IDEJetBrains:
+		files, err = jetBrainsFiles(commands, binDir)
+	default:
+		return errors.Errorf("unknown IDE %q, supported ones: %s | %s", ide, IDEVSCode, IDEJetBrains)
+	}
+	if err != nil {
+		return err
+	}
+
+	for file := range files {
+		path := filepath.Join(outputDir, file)
+		if _, err := os.Stat(path); err == nil && !force {
+			return errors.Errorf("file %q already exists, use --force to overwrite it", path)
+		}
+	}
+	for file, content := range files {
+		path := filepath.Join(outputDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func ideCommands(cmd *cobra.Command, path []string) []ideCommand {
+	var commands []ideCommand
+	for _, subCmd := range cmd.Commands() {
+		if subCmd.Hidden || ideSkippedCommands[subCmd.Name()] {
+			continue
+		}
+		subPath := append(append([]string{}, path...), subCmd.Name())
+		if subCmd.Runnable() {
+			commands = append(commands, ideCommand{
+				Path:       subPath,
+				Short:      subCmd.Short,
+				TakesArgs:  strings.Contains(subCmd.Use, " "),
+				BinDirFlag: subCmd.Flags().Lookup("bin-dir") != nil,
+				FilterFlag: subCmd.Flags().Lookup("filter") != nil,
+			})
+		}
+		commands = append(commands, ideCommands(subCmd, subPath)...)
+	}
+	return commands
+}
+
+type vscodeTask struct {
+	Label          string   `json:"label"`
+	Detail         string   `json:"detail,omitempty"`
+	Type           string   `json:"type"`
+	Command        string   `json:"command"`
+	ProblemMatcher []string `json:"problemMatcher"`
+}
+
+type vscodeInput struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+type vscodeLaunchConfig struct {
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	Request   string   `json:"request"`
+	Mode      string   `json:"mode"`
+	Program   string   `json:"program,omitempty"`
+	Args      []string `json:"args,omitempty"`
+	ProcessID string   `json:"processId,omitempty"`
+}
+
+// vscodeFiles generates tasks running each command and launch configurations debugging them.
+// Faucet may be debugged by attaching to its process running in the container.
+func vscodeFiles(commands []ideCommand, binDir string) (map[string][]byte, error) {
+	crust := filepath.Join(binDir, "bin", "crust")
+
+	tasks := make([]vscodeTask, 0, len(commands))
+	launchConfigs := make([]vscodeLaunchConfig, 0, len(commands)+1)
+	for _, c := range commands {
+		command := crust + " " + c.Name()
+		if c.TakesArgs {
+			command += " ${input:znetArgs}"
+		}
+		tasks = append(tasks, vscodeTask{
+			Label:          c.Name(),
+			Detail:         c.Short,
+			Type:           "shell",
+			Command:        command,
+			ProblemMatcher: []string{},
+		})
+		if c.FilterFlag {
+			tasks = append(tasks, vscodeTask{
+				Label:          c.Name() + " (filtered)",
+				Detail:         c.Short + ", only tests matching the filter",
+				Type:           "shell",
+				Command:        crust + " " + c.Name() + " --filter=${input:testFilter}",
+				ProblemMatcher: []string{},
+			})
+		}
+
+		args := append([]string{}, c.Path...)
+		if c.BinDirFlag {
+			// Debugged binary is built outside the repository, so directory can't be derived from its path.
+			args = append(args, "--bin-dir", binDir)
+		}
+		launchConfigs = append(launchConfigs, vscodeLaunchConfig{
+			Name:    "Debug " + c.Name(),
+			Type:    "go",
+			Request: "launch",
+			Mode:    "debug",
+			Program: filepath.Join(binDir, "cmd", "znet"),
+			Args:    args,
+		})
+	}
+	launchConfigs = append(launchConfigs, vscodeLaunchConfig{
+		Name:      "Attach to faucet",
+		Type:      "go",
+		Request:   "attach",
+		Mode:      "local",
+		ProcessID: "faucet",
+	})
+
+	tasksJSON, err := ideJSON(struct {
+		Version string        `json:"version"`
+		Tasks   []vscodeTask  `json:"tasks"`
+		Inputs  []vscodeInput `json:"inputs"`
+	}{
+		Version: "2.0.0",
+		Tasks:   tasks,
+		Inputs: []vscodeInput{
+			{ID: "znetArgs", Type: "promptString", Description: "Arguments passed to the command"},
+			{ID: "testFilter", Type: "promptString", Description: "Regular expression selecting tests to run"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	launchJSON, err := ideJSON(struct {
+		Version        string               `json:"version"`
+		Configurations []vscodeLaunchConfig `json:"configurations"`
+	}{
+		Version:        "0.2.0",
+		Configurations: launchConfigs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{
+		filepath.Join(".vscode", "tasks.json"):  tasksJSON,
+		filepath.Join(".vscode", "launch.json"): launchJSON,
+	}, nil
+}
+
+type jetBrainsOption struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type jetBrainsValue struct {
+	Value string `xml:"value,attr"`
+}
+
+type jetBrainsConfiguration struct {
+	Name        string            `xml:"name,attr"`
+	Type        string            `xml:"type,attr"`
+	FactoryName string            `xml:"factoryName,attr,omitempty"`
+	Options     []jetBrainsOption `xml:"option"`
+	WorkingDir  *jetBrainsValue   `xml:"working_directory,omitempty"`
+	Parameters  *jetBrainsValue   `xml:"parameters,omitempty"`
+	Kind        *jetBrainsValue   `xml:"kind,omitempty"`
+	Package     *jetBrainsValue   `xml:"package,omitempty"`
+	Directory   *jetBrainsValue   `xml:"directory,omitempty"`
+}
+
+type jetBrainsRunConfig struct {
+	XMLName       xml.Name               `xml:"component"`
+	Name          string                 `xml:"name,attr"`
+	Configuration jetBrainsConfiguration `xml:"configuration"`
+}
+
+// jetBrainsFiles generates shell run configurations running each command and Go run configurations debugging them.
+func jetBrainsFiles(commands []ideCommand, binDir string) (map[string][]byte, error) {
+	crust := filepath.Join(binDir, "bin", "crust")
+
+	files := map[string][]byte{}
+	addConfig := func(cfg jetBrainsConfiguration) error {
+		content, err := xml.MarshalIndent(jetBrainsRunConfig{
+			Name:          "ProjectRunConfigurationManager",
+			Configuration: cfg,
+		}, "", "  ")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		fileName := strings.ReplaceAll(cfg.Name, " ", "_") + ".run.xml"
+		files[filepath.Join(".run", fileName)] = append(content, '\n')
+		return nil
+	}
+
+	for _, c := range commands {
+		err := addConfig(jetBrainsConfiguration{
+			Name: c.Name(),
+			Type: "ShConfigurationType",
+			Options: []jetBrainsOption{
+				{Name: "SCRIPT_TEXT", Value: crust + " " + c.Name()},
+				{Name: "INDEPENDENT_SCRIPT_PATH", Value: "true"},
+				{Name: "SCRIPT_PATH", Value: ""},
+				{Name: "SCRIPT_OPTIONS", Value: ""},
+				{Name: "INDEPENDENT_SCRIPT_WORKING_DIRECTORY", Value: "true"},
+				{Name: "SCRIPT_WORKING_DIRECTORY", Value: "$PROJECT_DIR$"},
+				{Name: "INDEPENDENT_INTERPRETER_PATH", Value: "true"},
+				{Name: "INTERPRETER_PATH", Value: "/bin/bash"},
+				{Name: "INTERPRETER_OPTIONS", Value: ""},
+				{Name: "EXECUTE_IN_TERMINAL", Value: "true"},
+				{Name: "EXECUTE_SCRIPT_FILE", Value: "false"},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		args := append([]string{}, c.Path...)
+		if c.BinDirFlag {
+			// Debugged binary is built outside the repository, so directory can't be derived from its path.
+			args = append(args, "--bin-dir", binDir)
+		}
+		err = addConfig(jetBrainsConfiguration{
+			Name:        "Debug " + c.Name(),
+			Type:        "GoApplicationRunConfiguration",
+			FactoryName: "Go Application",
+			WorkingDir:  &jetBrainsValue{Value: binDir},
+			Parameters:  &jetBrainsValue{Value: strings.Join(args, " ")},
+			Kind:        &jetBrainsValue{Value: "DIRECTORY"},
+			Package:     &jetBrainsValue{Value: "github.com/CoreumFoundation/crust/cmd/znet"},
+			Directory:   &jetBrainsValue{Value: filepath.Join(binDir, "cmd", "znet")},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func ideJSON(v interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	// Descriptions may contain characters like `<`, they are kept readable.
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,328 @@
+package znet
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	tmcrypto "github.com/tendermint/tendermint/crypto"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+)
+
+const (
+	// keyArchiveVersion is the version of the format of the key archive.
+	keyArchiveVersion = 1
+
+	// keyPassphraseEnv is the environment variable containing passphrase protecting the key archive.
+	keyPassphraseEnv = "CRUST_ZNET_KEY_PASSPHRASE"
+
+	// Parameters of scrypt recommended for interactive logins.
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// keyArchive is the encrypted archive of the keys of cored nodes.
+type keyArchive struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// nodeKeyFiles are the key files of cored node, in the format used by tendermint.
+type nodeKeyFiles struct {
+	NodeKey      json.RawMessage `json:"nodeKey"`
+	ValidatorKey json.RawMessage `json:"validatorKey,omitempty"`
+}
+
+// keyArchiveContent is the content of the key archive before encryption.
+type keyArchiveContent struct {
+	Env   string                  `json:"env"`
+	Nodes map[string]nodeKeyFiles `json:"nodes"`
+}
+
+// ValidateKeyBackup verifies that the key archive may be written to the file, so it is known before the environment
+// is deployed.
+func ValidateKeyBackup(file string) error {
+	info, err := os.Stat(file)
+	switch {
+	case err == nil:
+		if !info.Mode().IsRegular() {
+			return errors.Errorf("key backup file %s is not a regular file", file)
+		}
+		f, err := os.OpenFile(file, os.O_WRONLY, 0o600)
+		if err != nil {
+			return errors.Wrapf(err, "key backup file %s is not writable", file)
+		}
+		return errors.WithStack(f.Close())
+	case errors.Is(err, os.ErrNotExist):
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			return errors.Wrapf(err, "key backup file %s can't be created", file)
+		}
+		if err := f.Close(); err != nil {
+			return errors.WithStack(err)
+		}
+		return errors.WithStack(os.Remove(file))
+	default:
+		return errors.WithStack(err)
+	}
+}
+
+// BackupNodeKeys stores node and validator keys of all the cored nodes in the archive encrypted using passphrase.
+func BackupNodeKeys(ctx context.Context, config infra.Config, appSet infra.AppSet, passphrase string) error {
+	content := keyArchiveContent{
+		Env:   config.EnvName,
+		Nodes: map[string]nodeKeyFiles{},
+	}
+	for _, app := range appSet {
+		coredNode, ok := app.(cored.Cored)
+		if !ok {
+			continue
+		}
+		configDir := filepath.Join(coredNode.Config().HomeDir, "config")
+		nodeKey, err := os.ReadFile(filepath.Join(configDir, "node_key.json"))
+		if err != nil {
+			return errors.Wrapf(err, "reading node key of %s failed", coredNode.Name())
+		}
+		files := nodeKeyFiles{NodeKey: nodeKey}
+		if coredNode.Config().IsValidator {
+			files.ValidatorKey, err = os.ReadFile(filepath.Join(configDir, "priv_validator_key.json"))
+			if err != nil {
+				return errors.Wrapf(err, "reading validator key of %s failed", coredNode.Name())
+			}
+		}
+		content.Nodes[coredNode.Name()] = files
+	}
+	if len(content.Nodes) == 0 {
+		return errors.New("no cored nodes found")
+	}
+
+	plaintext, err := json.Marshal(content)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	archive, err := encryptKeyArchive(plaintext, passphrase)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.WriteFile(config.KeyBackup, encoded, 0o600); err != nil {
+		return errors.WithStack(err)
+	}
+
+	logger.Get(ctx).Info("Keys of cored nodes backed up", zap.String("file", config.KeyBackup),
+		zap.Strings("nodes", sortedNodeNames(content.Nodes)))
+	return nil
+}
+
+// LoadNodeKeys decrypts the archive created by BackupNodeKeys and returns keys of the nodes mapped by their names.
+func LoadNodeKeys(ctx context.Context, file, passphrase string) (map[string]cored.NodeKeys, error) {
+	encoded, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var archive keyArchive
+	if err := json.Unmarshal(encoded, &archive); err != nil {
+		return nil, errors.Wrapf(err, "decoding key archive %s failed", file)
+	}
+	if archive.Version != keyArchiveVersion {
+		return nil, errors.Errorf("unsupported version %d of key archive %s", archive.Version, file)
+	}
+
+	plaintext, err := decryptKeyArchive(archive, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var content keyArchiveContent
+	if err := json.Unmarshal(plaintext, &content); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	keys := map[string]cored.NodeKeys{}
+	for name, files := range content.Nodes {
+		var nodeKeys cored.NodeKeys
+		nodeKeys.NodeKey, err = unmarshalPrivKey(files.NodeKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding node key of %s failed", name)
+		}
+		if len(files.ValidatorKey) > 0 {
+			nodeKeys.ValidatorKey, err = unmarshalPrivKey(files.ValidatorKey)
+			if err != nil {
+				return nil, errors.Wrapf(err, "decoding validator key of %s failed", name)
+			}
+		}
+		keys[name] = nodeKeys
+	}
+
+	logger.Get(ctx).Info("Keys of cored nodes restored", zap.String("file", file),
+		zap.String("sourceEnv", content.Env), zap.Strings("nodes", sortedNodeNames(content.Nodes)))
+	return keys, nil
+}
+
+// unmarshalPrivKey decodes private key stored in `node_key.json` or `priv_validator_key.json` file.
+func unmarshalPrivKey(raw json.RawMessage) (ed25519.PrivateKey, error) {
+	var key struct {
+		PrivKey tmcrypto.PrivKey `json:"priv_key"`
+	}
+	if err := tmjson.Unmarshal(raw, &key); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if key.PrivKey == nil || len(key.PrivKey.Bytes()) != ed25519.PrivateKeySize {
+		return nil, errors.New("ed25519 private key expected")
+	}
+	return ed25519.PrivateKey(key.PrivKey.Bytes()), nil
+}
+
+func encryptKeyArchive(plaintext []byte, passphrase string) (keyArchive, error) {
+	archive := keyArchive{
+		Version: keyArchiveVersion,
+		KDF:     fmt.Sprintf("scrypt:%d:%d:%d", scryptN, scryptR, scryptP),
+		Salt:    make([]byte, 32),
+	}
+	if _, err := rand.Read(archive.Salt); err != nil {
+		return keyArchive{}, errors.WithStack(err)
+	}
+	aead, err := keyArchiveCipher(passphrase, archive.Salt)
+	if err != nil {
+		return keyArchive{}, err
+	}
+	archive.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(archive.Nonce); err != nil {
+		return keyArchive{}, errors.WithStack(err)
+	}
+	archive.Ciphertext = aead.Seal(nil, archive.Nonce, plaintext, nil)
+	return archive, nil
+}
+
+func decryptKeyArchive(archive keyArchive, passphrase string) ([]byte, error) {
+	if archive.KDF != fmt.Sprintf("scrypt:%d:%d:%d", scryptN, scryptR, scryptP) {
+		return nil, errors.Errorf("unsupported key derivation function %q", archive.KDF)
+	}
+	aead, err := keyArchiveCipher(passphrase, archive.Salt)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, archive.Nonce, archive.Ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("decrypting key archive failed, passphrase is wrong or archive is corrupted")
+	}
+	return plaintext, nil
+}
+
+func keyArchiveCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return aead, nil
+}
+
+// VerifyNodeKeys verifies that keys restored from the archive match the cored nodes of the app set by name. Nodes
+// missing in the topology fail the verification, because their identities would be lost silently, while nodes
+// missing in the archive get random keys.
+func VerifyNodeKeys(ctx context.Context, nodeKeys map[string]cored.NodeKeys, appSet infra.AppSet) error {
+	nodes := map[string]struct{}{}
+	for _, app := range appSet {
+		if coredNode, ok := app.(cored.Cored); ok {
+			nodes[coredNode.Name()] = struct{}{}
+		}
+	}
+
+	var unmatched, random []string
+	for name := range nodeKeys {
+		if _, exists := nodes[name]; !exists {
+			unmatched = append(unmatched, name)
+		}
+	}
+	for name := range nodes {
+		if _, exists := nodeKeys[name]; !exists {
+			random = append(random, name)
+		}
+	}
+	sort.Strings(unmatched)
+	sort.Strings(random)
+
+	if len(unmatched) > 0 {
+		return errors.Errorf("key archive contains keys of nodes %s which don't exist in the environment, "+
+			"use the same profiles as the backed up environment", strings.Join(unmatched, ", "))
+	}
+	if len(random) > 0 {
+		logger.Get(ctx).Warn("Key archive doesn't contain keys of some nodes, random keys are generated",
+			zap.Strings("nodes", random))
+	}
+	return nil
+}
+
+// KeyPassphrase returns passphrase protecting the key archive, taken from the environment variable or, if it is not
+// set, read from the terminal. If confirm is true, passphrase is asked twice.
+func KeyPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(keyPassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", errors.Errorf("passphrase of the key archive must be set using %s", keyPassphraseEnv)
+	}
+
+	fmt.Fprint(os.Stderr, "Passphrase of the key archive: ")
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if len(passphrase) == 0 {
+		return "", errors.New("passphrase must not be empty")
+	}
+	if confirm {
+		fmt.Fprint(os.Stderr, "Repeat passphrase: ")
+		repeated, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		if string(repeated) != string(passphrase) {
+			return "", errors.New("passphrases don't match")
+		}
+	}
+	return string(passphrase), nil
+}
+
+func sortedNodeNames(nodes map[string]nodeKeyFiles) []string {
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
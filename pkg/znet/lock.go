@@ -0,0 +1,53 @@
+package znet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/crust/infra"
+)
+
+// LockEnv acquires exclusive lock on the environment, so commands modifying its spec and containers are not executed
+// concurrently. It fails immediately if the lock is held by another process. Lock file is stored next to the home
+// directory of the environment, because it is deleted by `remove`. Returned function releases the lock.
+func LockEnv(configF *infra.ConfigFactory) (func(), error) {
+	if err := os.MkdirAll(configF.HomeDir, 0o700); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	lockFile := filepath.Join(configF.HomeDir, configF.EnvName+".lock")
+	f, err := os.OpenFile(lockFile, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = f.Close()
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, errors.WithStack(err)
+		}
+		holder, _ := os.ReadFile(lockFile)
+		return nil, errors.Errorf("another znet command is running in environment %q (%s), wait until it finishes",
+			configF.EnvName, strings.TrimSpace(string(holder)))
+	}
+
+	// Holder is recorded, so it is reported to other processes trying to acquire the lock.
+	holder := fmt.Sprintf("pid %d: %s", os.Getpid(), strings.Join(os.Args, " "))
+	if err := f.Truncate(0); err != nil {
+		_ = f.Close()
+		return nil, errors.WithStack(err)
+	}
+	if _, err := f.WriteAt([]byte(holder), 0); err != nil {
+		_ = f.Close()
+		return nil, errors.WithStack(err)
+	}
+
+	return func() {
+		// Closing the file releases the lock.
+		_ = f.Close()
+	}, nil
+}
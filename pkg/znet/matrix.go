@@ -0,0 +1,55 @@
+package znet
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/crust/infra/apps"
+	"github.com/CoreumFoundation/crust/infra/testing"
+)
+
+// ciMatrixEntry is the entry of CI matrix running single test group in its own environment.
+type ciMatrixEntry struct {
+	Group        string `json:"group"`
+	Profiles     string `json:"profiles"`
+	CoredVersion string `json:"coredVersion,omitempty"`
+	Command      string `json:"command"`
+}
+
+// CIMatrix prints the matrix, in the format of GitHub Actions, running each test group in a separate job.
+// It is printed in a single line, so it may be stored in the output of the step.
+func CIMatrix(binDir string) error {
+	groups, err := testing.ListTestGroups(binDir)
+	if err != nil {
+		return err
+	}
+
+	profiles := strings.Join(apps.IntegrationTestsProfiles(), ",")
+	matrix := struct {
+		Include []ciMatrixEntry `json:"include"`
+	}{
+		Include: make([]ciMatrixEntry, 0, len(groups)),
+	}
+	for _, group := range groups {
+		command := "crust znet test --test-groups=" + group.Name
+		if group.CoredVersion != "" {
+			command += " --cored-version=" + group.CoredVersion
+		}
+		matrix.Include = append(matrix.Include, ciMatrixEntry{
+			Group:        group.Name,
+			Profiles:     profiles,
+			CoredVersion: group.CoredVersion,
+			Command:      command,
+		})
+	}
+
+	out, err := json.Marshal(matrix)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
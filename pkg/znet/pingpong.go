@@ -0,0 +1,417 @@
+package znet
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	mathrand "math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/cosmos/go-bip39"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum-tools/pkg/must"
+	"github.com/CoreumFoundation/coreum-tools/pkg/parallel"
+	"github.com/CoreumFoundation/coreum/pkg/client"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+)
+
+// Message types sent by ping-pong.
+const (
+	PingPongMsgSend      = "send"
+	PingPongMsgMultiSend = "multi-send"
+	PingPongMsgDelegate  = "delegate"
+)
+
+const (
+	// generatedAccountBalance is the amount each generated account is funded with to pay fees.
+	generatedAccountBalance = 1_000_000_000
+
+	// fundingBatch is the maximum number of accounts funded by single transaction.
+	fundingBatch = 100
+)
+
+// PingPongMsgTypes returns message types supported by ping-pong.
+func PingPongMsgTypes() []string {
+	return []string{PingPongMsgSend, PingPongMsgMultiSend, PingPongMsgDelegate}
+}
+
+// PingPongConfig is the configuration of the traffic generated by ping-pong.
+type PingPongConfig struct {
+	// TPS is the number of transactions broadcast per second
+	TPS float64
+
+	// Accounts is the number of accounts generated and funded to send transactions from
+	Accounts int
+
+	// MsgMix maps message types to their weights, defining the share of transactions of each type
+	MsgMix map[string]int
+
+	// Duration is the time after which ping-pong stops, it runs until interrupted if zero
+	Duration time.Duration
+
+	// IBC enables the mode where tokens are transferred between cored and gaia over the relayer instead
+	IBC bool
+}
+
+// DefaultPingPongConfig is the default configuration of ping-pong.
+var DefaultPingPongConfig = PingPongConfig{
+	TPS:      1,
+	Accounts: 3,
+	MsgMix:   map[string]int{PingPongMsgSend: 1},
+}
+
+// Validate validates the config.
+func (c PingPongConfig) Validate() error {
+	if c.TPS <= 0 {
+		return errors.Errorf("transactions per second must be positive, got %v", c.TPS)
+	}
+	if c.Accounts < 2 {
+		return errors.Errorf("at least 2 accounts are required, got %d", c.Accounts)
+	}
+	if c.Duration < 0 {
+		return errors.Errorf("duration must not be negative, got %s", c.Duration)
+	}
+
+	return validateMsgMix(c.MsgMix)
+}
+
+func validateMsgMix(mix map[string]int) error {
+	var total int
+	for msgType, weight := range mix {
+		if !isPingPongMsgType(msgType) {
+			return errors.Errorf("unknown message type %q, supported ones: %v", msgType, PingPongMsgTypes())
+		}
+		if weight < 0 {
+			return errors.Errorf("weight of message type %q must not be negative", msgType)
+		}
+		total += weight
+	}
+	if total == 0 {
+		return errors.New("message mix must contain at least one message type with positive weight")
+	}
+	return nil
+}
+
+func isPingPongMsgType(msgType string) bool {
+	for _, t := range PingPongMsgTypes() {
+		if t == msgType {
+			return true
+		}
+	}
+	return false
+}
+
+// PingPong generates accounts, funds them and sends transactions of the configured types between them at the
+// configured rate. Summary of achieved TPS and failures is reported once it stops.
+func PingPong(ctx context.Context, appSet infra.AppSet, config PingPongConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if config.IBC {
+		return pingPongIBC(ctx, appSet, config)
+	}
+
+	coredApp := appSet.FindRunningApp(cored.AppType, "cored-00")
+	if coredApp == nil {
+		return errors.New("no running cored app found")
+	}
+	coredNode := coredApp.(cored.Cored)
+	clientCtx := coredNode.ClientContext()
+	txf := coredNode.TxFactory(clientCtx).WithSimulateAndExecute(true)
+	denom := coredNode.Config().Network.Denom()
+
+	accounts, err := generateAccounts(clientCtx, "ping-pong", config.Accounts)
+	if err != nil {
+		return err
+	}
+	funding := importMnemonic(clientCtx, "funding", coredNode.Config().FundingMnemonic)
+	if err := fundAccounts(ctx, clientCtx, txf, funding, accounts, denom); err != nil {
+		return err
+	}
+
+	var validators []string
+	if config.MsgMix[PingPongMsgDelegate] > 0 {
+		validators, err = bondedValidators(ctx, clientCtx)
+		if err != nil {
+			return err
+		}
+	}
+
+	gen := pingPongGenerator{
+		clientCtx:  clientCtx,
+		txf:        txf,
+		accounts:   accounts,
+		validators: validators,
+		amount:     sdk.NewCoins(sdk.NewCoin(denom, sdk.OneInt())),
+		stats:      map[string]*pingPongStats{},
+	}
+	for msgType := range config.MsgMix {
+		gen.stats[msgType] = &pingPongStats{}
+	}
+
+	runCtx := ctx
+	if config.Duration > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, config.Duration)
+		defer cancel()
+	}
+
+	logger.Get(ctx).Info("Ping-pong started", zap.Float64("tps", config.TPS), zap.Int("accounts", config.Accounts),
+		zap.Any("msgMix", config.MsgMix), zap.Duration("duration", config.Duration))
+	start := time.Now()
+	err = gen.run(runCtx, config)
+	gen.reportSummary(ctx, time.Since(start))
+
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return errors.WithStack(ctx.Err())
+}
+
+type pingPongStats struct {
+	Sent      int
+	Succeeded int
+	Failed    int
+}
+
+type pingPongGenerator struct {
+	clientCtx  client.Context
+	txf        tx.Factory
+	accounts   []sdk.AccAddress
+	validators []string
+	amount     sdk.Coins
+
+	mu     sync.Mutex
+	stats  map[string]*pingPongStats
+	missed int
+}
+
+// run dispatches transactions to the accounts at the configured rate. Each account broadcasts one transaction at a time
+// to keep its sequence consistent, so if all the accounts are busy when transaction is due, it is counted as missed.
+func (g *pingPongGenerator) run(ctx context.Context, config PingPongConfig) error {
+	msgTypes := make([]string, 0, len(config.MsgMix))
+	for msgType := range config.MsgMix {
+		msgTypes = append(msgTypes, msgType)
+	}
+	sort.Strings(msgTypes)
+
+	return parallel.Run(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
+		jobs := make(chan string)
+		spawn("ticker", parallel.Fail, func(ctx context.Context) error {
+			ticker := time.NewTicker(time.Duration(float64(time.Second) / config.TPS))
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return errors.WithStack(ctx.Err())
+				case <-ticker.C:
+				}
+
+				msgType := pickPingPongMsgType(msgTypes, config.MsgMix)
+				select {
+				case jobs <- msgType:
+				default:
+					g.mu.Lock()
+					g.missed++
+					g.mu.Unlock()
+				}
+			}
+		})
+		for i := range g.accounts {
+			i := i
+			spawn(fmt.Sprintf("account-%d", i), parallel.Fail, func(ctx context.Context) error {
+				for {
+					select {
+					case <-ctx.Done():
+						return errors.WithStack(ctx.Err())
+					case msgType := <-jobs:
+						g.broadcast(ctx, i, msgType)
+					}
+				}
+			})
+		}
+		return nil
+	})
+}
+
+func pickPingPongMsgType(msgTypes []string, mix map[string]int) string {
+	var total int
+	for _, msgType := range msgTypes {
+		total += mix[msgType]
+	}
+	n := mathrand.Intn(total)
+	for _, msgType := range msgTypes {
+		n -= mix[msgType]
+		if n < 0 {
+			return msgType
+		}
+	}
+	panic("message type not picked")
+}
+
+func (g *pingPongGenerator) broadcast(ctx context.Context, accountIndex int, msgType string) {
+	from := g.accounts[accountIndex]
+	msg := newPingPongMsg(msgType, g.accounts, accountIndex, g.validators, g.amount)
+
+	g.mu.Lock()
+	g.stats[msgType].Sent++
+	g.mu.Unlock()
+
+	log := logger.Get(ctx).With(zap.String("msgType", msgType), zap.Stringer("from", from))
+	res, err := client.BroadcastTx(ctx, g.clientCtx.WithFromAddress(from), g.txf, msg)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err != nil {
+		if ctx.Err() == nil {
+			g.stats[msgType].Failed++
+			log.Warn("Transaction failed", zap.Error(err))
+		}
+		return
+	}
+	g.stats[msgType].Succeeded++
+	log.Info("Transaction sent", zap.String("txHash", res.TxHash), zap.Int64("gasUsed", res.GasUsed))
+}
+
+// newPingPongMsg creates the message of the type sent by the account to the next accounts.
+func newPingPongMsg(
+	msgType string,
+	accounts []sdk.AccAddress,
+	accountIndex int,
+	validators []string,
+	amount sdk.Coins,
+) sdk.Msg {
+	from := accounts[accountIndex]
+	next := accounts[(accountIndex+1)%len(accounts)]
+
+	switch msgType {
+	case PingPongMsgSend:
+		return &banktypes.MsgSend{
+			FromAddress: from.String(),
+			ToAddress:   next.String(),
+			Amount:      amount,
+		}
+	case PingPongMsgMultiSend:
+		afterNext := accounts[(accountIndex+2)%len(accounts)]
+		return banktypes.NewMsgMultiSend(
+			[]banktypes.Input{banktypes.NewInput(from, amount.Add(amount...))},
+			[]banktypes.Output{banktypes.NewOutput(next, amount), banktypes.NewOutput(afterNext, amount)},
+		)
+	case PingPongMsgDelegate:
+		return &stakingtypes.MsgDelegate{
+			DelegatorAddress: from.String(),
+			ValidatorAddress: validators[mathrand.Intn(len(validators))],
+			Amount:           amount[0],
+		}
+	default:
+		panic(errors.Errorf("unknown message type %q", msgType))
+	}
+}
+
+// bondedValidators returns operator addresses of the bonded validators.
+func bondedValidators(ctx context.Context, clientCtx client.Context) ([]string, error) {
+	res, err := stakingtypes.NewQueryClient(clientCtx).Validators(ctx, &stakingtypes.QueryValidatorsRequest{
+		Status: stakingtypes.BondStatusBonded,
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	validators := make([]string, 0, len(res.Validators))
+	for _, v := range res.Validators {
+		validators = append(validators, v.OperatorAddress)
+	}
+	if len(validators) == 0 {
+		return nil, errors.New("no bonded validators to delegate to")
+	}
+	return validators, nil
+}
+
+func (g *pingPongGenerator) reportSummary(ctx context.Context, elapsed time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	log := logger.Get(ctx)
+	var total pingPongStats
+	msgTypes := make([]string, 0, len(g.stats))
+	for msgType := range g.stats {
+		msgTypes = append(msgTypes, msgType)
+	}
+	sort.Strings(msgTypes)
+	for _, msgType := range msgTypes {
+		stats := g.stats[msgType]
+		total.Sent += stats.Sent
+		total.Succeeded += stats.Succeeded
+		total.Failed += stats.Failed
+		log.Info("Ping-pong summary of message type", zap.String("msgType", msgType), zap.Int("sent", stats.Sent),
+			zap.Int("succeeded", stats.Succeeded), zap.Int("failed", stats.Failed))
+	}
+
+	var tps float64
+	if elapsed > 0 {
+		tps = float64(total.Succeeded) / elapsed.Seconds()
+	}
+	log.Info("Ping-pong summary", zap.Duration("elapsed", elapsed), zap.Float64("achievedTPS", tps),
+		zap.Int("sent", total.Sent), zap.Int("succeeded", total.Succeeded), zap.Int("failed", total.Failed),
+		zap.Int("missed", g.missed))
+	if g.missed > 0 {
+		log.Warn("Some transactions were not sent because all the accounts were busy, increase number of accounts")
+	}
+}
+
+// generateAccounts generates random accounts and imports them into the keyring under names prefixed by keyPrefix.
+func generateAccounts(clientCtx client.Context, keyPrefix string, n int) ([]sdk.AccAddress, error) {
+	accounts := make([]sdk.AccAddress, 0, n)
+	for i := 0; i < n; i++ {
+		entropy := make([]byte, 32)
+		must.Any(rand.Read(entropy))
+		mnemonic, err := bip39.NewMnemonic(entropy)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		accounts = append(accounts, importMnemonic(clientCtx, fmt.Sprintf("%s-%d", keyPrefix, i), mnemonic))
+	}
+	return accounts, nil
+}
+
+// fundAccounts funds generated accounts from the funding account using multi-send transactions.
+func fundAccounts(
+	ctx context.Context,
+	clientCtx client.Context,
+	txf tx.Factory,
+	funding sdk.AccAddress,
+	accounts []sdk.AccAddress,
+	denom string,
+) error {
+	balance := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(generatedAccountBalance)))
+	for start := 0; start < len(accounts); start += fundingBatch {
+		end := start + fundingBatch
+		if end > len(accounts) {
+			end = len(accounts)
+		}
+
+		outputs := make([]banktypes.Output, 0, end-start)
+		for _, account := range accounts[start:end] {
+			outputs = append(outputs, banktypes.NewOutput(account, balance))
+		}
+		total := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(generatedAccountBalance*int64(len(outputs)))))
+		if _, err := client.BroadcastTx(ctx, clientCtx.WithFromAddress(funding), txf,
+			banktypes.NewMsgMultiSend([]banktypes.Input{banktypes.NewInput(funding, total)}, outputs)); err != nil {
+			return errors.Wrap(err, "funding generated accounts failed")
+		}
+	}
+	logger.Get(ctx).Info("Generated accounts funded", zap.Int("accounts", len(accounts)),
+		zap.Stringer("balance", balance))
+	return nil
+}
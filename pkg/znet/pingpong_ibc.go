@@ -0,0 +1,251 @@
+package znet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	transfertypes "github.com/cosmos/ibc-go/v4/modules/apps/transfer/types"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+	ibctmtypes "github.com/cosmos/ibc-go/v4/modules/light-clients/07-tendermint/types"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum-tools/pkg/retry"
+	"github.com/CoreumFoundation/coreum/pkg/client"
+	"github.com/CoreumFoundation/crust/exec"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+	"github.com/CoreumFoundation/crust/infra/apps/gaiad"
+	"github.com/CoreumFoundation/crust/infra/cosmoschain"
+	"github.com/CoreumFoundation/crust/infra/targets"
+)
+
+const (
+	// pingPongIBCTimeout is the time transferred tokens must be delivered to the other chain in.
+	pingPongIBCTimeout = 2 * time.Minute
+
+	// gaiaSenderKey is the key in the keyring of gaia used to send tokens back. Key of the relayer is not used,
+	// so transactions don't compete with the relayer for the sequence number.
+	gaiaSenderKey = "validator"
+	gaiaFees      = "5000stake"
+)
+
+// pingPongIBC transfers tokens from cored to gaia and back over the relayer, verifying balances on both chains
+// after each transfer.
+func pingPongIBC(ctx context.Context, appSet infra.AppSet, config PingPongConfig) error {
+	coredApp := appSet.FindRunningApp(cored.AppType, "cored-00")
+	if coredApp == nil {
+		return errors.New("no running cored app found")
+	}
+	coredNode := coredApp.(cored.Cored)
+	gaiaApp := appSet.FindRunningApp(gaiad.AppType, "ibc-gaia")
+	if gaiaApp == nil {
+		return errors.New("no running gaia app found, start the environment with ibc profile")
+	}
+	gaiaNode := gaiaApp.(cosmoschain.BaseApp)
+
+	coredClientCtx := coredNode.ClientContext()
+	gaiaClientCtx := gaiaNode.ClientContext()
+	txf := coredNode.TxFactory(coredClientCtx).WithSimulateAndExecute(true)
+	denom := coredNode.Config().Network.Denom()
+
+	coredChannel, gaiaChannel, err := findIBCChannel(ctx, coredClientCtx, gaiad.DefaultChainID)
+	if err != nil {
+		return err
+	}
+	ibcDenom := transfertypes.ParseDenomTrace(
+		transfertypes.GetPrefixedDenom(transfertypes.PortID, gaiaChannel, denom)).IBCDenom()
+
+	accounts, err := generateAccounts(coredClientCtx, "ping-pong", 1)
+	if err != nil {
+		return err
+	}
+	coredAddress := accounts[0]
+	funding := importMnemonic(coredClientCtx, "funding", coredNode.Config().FundingMnemonic)
+	if err := fundAccounts(ctx, coredClientCtx, txf, funding, accounts, denom); err != nil {
+		return err
+	}
+	gaiaAddress, err := gaiaExec(ctx, gaiaNode, "keys", "show", gaiaSenderKey, "-a",
+		"--keyring-backend", "test", "--keyring-dir", targets.AppHomeDir)
+	if err != nil {
+		return err
+	}
+	gaiaAddress = strings.TrimSpace(gaiaAddress)
+
+	amount := sdk.NewInt(1)
+	log := logger.Get(ctx).With(zap.String("coredChannel", coredChannel), zap.String("gaiaChannel", gaiaChannel))
+	log.Info("IBC ping-pong started", zap.Stringer("coredAddress", coredAddress), zap.String("gaiaAddress", gaiaAddress),
+		zap.String("ibcDenom", ibcDenom))
+
+	runCtx := ctx
+	if config.Duration > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, config.Duration)
+		defer cancel()
+	}
+
+	var succeeded, failed int
+	var roundTrips time.Duration
+	start := time.Now()
+	interval := time.Duration(float64(time.Second) / config.TPS)
+	for runCtx.Err() == nil {
+		roundStart := time.Now()
+		err := func() error {
+			gaiaBalance, err := queryBalance(runCtx, gaiaClientCtx, gaiaAddress, ibcDenom)
+			if err != nil {
+				return err
+			}
+			res, err := client.BroadcastTx(runCtx, coredClientCtx.WithFromAddress(coredAddress), txf,
+				&transfertypes.MsgTransfer{
+					SourcePort:       transfertypes.PortID,
+					SourceChannel:    coredChannel,
+					Token:            sdk.NewCoin(denom, amount),
+					Sender:           coredAddress.String(),
+					Receiver:         gaiaAddress,
+					TimeoutTimestamp: uint64(time.Now().Add(pingPongIBCTimeout).UnixNano()),
+				})
+			if err != nil {
+				return errors.WithMessage(err, "transferring tokens to gaia failed")
+			}
+			log.Info("Tokens sent to gaia", zap.String("txHash", res.TxHash))
+			if err := awaitBalance(runCtx, gaiaClientCtx, gaiaAddress, ibcDenom, gaiaBalance.Add(amount)); err != nil {
+				return err
+			}
+
+			coredBalance, err := queryBalance(runCtx, coredClientCtx, coredAddress.String(), denom)
+			if err != nil {
+				return err
+			}
+			if _, err := gaiaExec(runCtx, gaiaNode, "tx", "ibc-transfer", "transfer", transfertypes.PortID, gaiaChannel,
+				coredAddress.String(), amount.String()+ibcDenom,
+				"--from", gaiaSenderKey,
+				"--chain-id", gaiad.DefaultChainID,
+				"--keyring-backend", "test",
+				"--keyring-dir", targets.AppHomeDir,
+				"--node", infra.JoinNetAddr("tcp", "localhost", gaiaNode.Ports().RPC),
+				"--broadcast-mode", "block",
+				"--fees", gaiaFees,
+				"--output", "json",
+				"-y",
+			); err != nil {
+				return errors.WithMessage(err, "transferring tokens back to cored failed")
+			}
+			log.Info("Tokens sent back to cored")
+			return awaitBalance(runCtx, coredClientCtx, coredAddress.String(), denom, coredBalance.Add(amount))
+		}()
+
+		switch {
+		case err == nil:
+			succeeded++
+			roundTrips += time.Since(roundStart)
+			log.Info("IBC round trip completed", zap.Duration("duration", time.Since(roundStart)))
+		case runCtx.Err() == nil:
+			failed++
+			log.Warn("IBC round trip failed", zap.Error(err))
+		}
+
+		select {
+		case <-runCtx.Done():
+		case <-time.After(interval - time.Since(roundStart)):
+		}
+	}
+
+	var avgRoundTrip time.Duration
+	if succeeded > 0 {
+		avgRoundTrip = roundTrips / time.Duration(succeeded)
+	}
+	log.Info("IBC ping-pong summary", zap.Duration("elapsed", time.Since(start)), zap.Int("succeeded", succeeded),
+		zap.Int("failed", failed), zap.Duration("avgRoundTrip", avgRoundTrip))
+	return errors.WithStack(ctx.Err())
+}
+
+// findIBCChannel returns the transfer channel of the chain connected to the chain with the provided ID, together with
+// the ID of the channel on the counterparty chain.
+func findIBCChannel(ctx context.Context, clientCtx client.Context, counterpartyChainID string) (string, string, error) {
+	queryClient := channeltypes.NewQueryClient(clientCtx)
+	channels, err := queryClient.Channels(ctx, &channeltypes.QueryChannelsRequest{})
+	if err != nil {
+		return "", "", errors.WithStack(err)
+	}
+	for _, channel := range channels.Channels {
+		if channel.PortId != transfertypes.PortID || channel.State != channeltypes.OPEN {
+			continue
+		}
+		clientState, err := queryClient.ChannelClientState(ctx, &channeltypes.QueryChannelClientStateRequest{
+			PortId:    channel.PortId,
+			ChannelId: channel.ChannelId,
+		})
+		if err != nil {
+			return "", "", errors.WithStack(err)
+		}
+		if clientState.IdentifiedClientState == nil || clientState.IdentifiedClientState.ClientState == nil {
+			continue
+		}
+		var tmClientState ibctmtypes.ClientState
+		if err := tmClientState.Unmarshal(clientState.IdentifiedClientState.ClientState.Value); err != nil {
+			continue
+		}
+		if tmClientState.ChainId == counterpartyChainID {
+			return channel.ChannelId, channel.Counterparty.ChannelId, nil
+		}
+	}
+	return "", "", errors.Errorf("no open transfer channel to %s found, check if the relayer is running",
+		counterpartyChainID)
+}
+
+func queryBalance(ctx context.Context, clientCtx client.Context, address, denom string) (sdk.Int, error) {
+	res, err := banktypes.NewQueryClient(clientCtx).Balance(ctx, &banktypes.QueryBalanceRequest{
+		Address: address,
+		Denom:   denom,
+	})
+	if err != nil {
+		return sdk.Int{}, errors.WithStack(err)
+	}
+	return res.Balance.Amount, nil
+}
+
+// awaitBalance waits until the balance of the address is equal to the expected one.
+func awaitBalance(ctx context.Context, clientCtx client.Context, address, denom string, expected sdk.Int) error {
+	ctx, cancel := context.WithTimeout(ctx, pingPongIBCTimeout)
+	defer cancel()
+
+	return retry.Do(ctx, time.Second, func() error {
+		balance, err := queryBalance(ctx, clientCtx, address, denom)
+		if err != nil {
+			return retry.Retryable(err)
+		}
+		if !balance.Equal(expected) {
+			return retry.Retryable(errors.Errorf("balance of %s is %s%s, expected %s%s", address, balance, denom,
+				expected, denom))
+		}
+		return nil
+	})
+}
+
+// gaiaExec executes gaiad command inside the container of gaia and returns its output.
+func gaiaExec(ctx context.Context, gaiaNode cosmoschain.BaseApp, args ...string) (string, error) {
+	output := &bytes.Buffer{}
+	cmd := exec.Docker(append([]string{"exec", "-e", "HOME=" + targets.AppHomeDir, gaiaNode.Info().Container,
+		gaiaNode.AppTypeConfig().ExecName}, args...)...)
+	cmd.Stdout = output
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return "", errors.Wrapf(err, "executing gaiad command failed")
+	}
+
+	// Result of transaction is verified, because it is returned even if transaction fails.
+	var res struct {
+		Code   *uint32 `json:"code"`
+		RawLog string  `json:"raw_log"`
+	}
+	if json.Unmarshal(output.Bytes(), &res) == nil && res.Code != nil && *res.Code != 0 {
+		return "", errors.Errorf("transaction failed with code %d: %s", *res.Code, res.RawLog)
+	}
+	return output.String(), nil
+}
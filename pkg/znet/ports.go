@@ -0,0 +1,239 @@
+package znet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/crust/exec"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps"
+	"github.com/CoreumFoundation/crust/infra/targets"
+)
+
+const (
+	// autoPortsStep is the difference between consecutive offsets tried by `--auto-ports`.
+	autoPortsStep = 1000
+
+	// autoPortsAttempts is the number of offsets tried by `--auto-ports`.
+	autoPortsAttempts = 20
+
+	maxPort = 65535
+)
+
+// portConflict describes port required by the app which is already taken on the host.
+type portConflict struct {
+	App   string
+	Name  string
+	Port  int
+	Owner string
+
+	// Env is the name of the environment reserving the port, empty if port is used by something else
+	Env string
+}
+
+func (c portConflict) String() string {
+	s := fmt.Sprintf("%d (%s %s)", c.Port, c.App, c.Name)
+	if c.Owner != "" {
+		s += " used by " + c.Owner
+	}
+	return s
+}
+
+// findPortConflicts returns ports published by the apps which are not running, shifted by delta, and are not free
+// on the host or are reserved by other environments.
+func findPortConflicts(appSet infra.AppSet, delta int, reserved map[int]string) []portConflict {
+	var conflicts []portConflict
+	for _, app := range appSet {
+		if app.Info().Status == infra.AppStatusRunning {
+			continue
+		}
+		for name, port := range app.Deployment().Ports {
+			port += delta
+			if port > maxPort {
+				conflicts = append(conflicts, portConflict{App: app.Name(), Name: name, Port: port, Owner: "out of range"})
+				continue
+			}
+			if env, exists := reserved[port]; exists {
+				conflicts = append(conflicts, portConflict{App: app.Name(), Name: name, Port: port, Owner: "environment " + env, Env: env})
+				continue
+			}
+			listener, err := net.Listen("tcp", infra.JoinNetAddr("", "127.0.0.1", port))
+			if err != nil {
+				conflicts = append(conflicts, portConflict{App: app.Name(), Name: name, Port: port})
+				continue
+			}
+			_ = listener.Close()
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Port < conflicts[j].Port })
+	return conflicts
+}
+
+// reservedPorts returns ports recorded in the specs of other environments stored in the home directory, mapped to
+// the names of those environments. Ports are reserved even if the environment is stopped, so it may be started again.
+func reservedPorts(config infra.Config) (map[int]string, error) {
+	homeDir := filepath.Dir(config.HomeDir)
+	entries, err := os.ReadDir(homeDir)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	reserved := map[int]string{}
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == config.EnvName {
+			continue
+		}
+		specRaw, err := os.ReadFile(filepath.Join(homeDir, entry.Name(), "spec.json"))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		var spec infra.Spec
+		if err := json.Unmarshal(specRaw, &spec); err != nil {
+			return nil, errors.Wrapf(err, "decoding spec of environment %s failed", entry.Name())
+		}
+		for _, app := range spec.Apps {
+			for _, port := range app.Info().Ports {
+				reserved[port] = entry.Name()
+			}
+		}
+	}
+	return reserved, nil
+}
+
+// describePortOwners finds processes or containers listening on the conflicting ports.
+func describePortOwners(ctx context.Context, conflicts []portConflict) {
+	for i := range conflicts {
+		if conflicts[i].Owner == "" {
+			conflicts[i].Owner = portOwner(ctx, conflicts[i].Port)
+		}
+	}
+}
+
+// portOwner returns the container publishing the port or the process listening on it, empty string is returned
+// if it can't be determined.
+func portOwner(ctx context.Context, port int) string {
+	buf := &bytes.Buffer{}
+	cmd := exec.Docker("ps", "--filter", "publish="+strconv.Itoa(port), "--format", "{{.Names}}")
+	cmd.Stdout = buf
+	if err := libexec.Exec(ctx, cmd); err == nil {
+		if containers := strings.Fields(buf.String()); len(containers) > 0 {
+			return "container " + strings.Join(containers, ", ")
+		}
+	}
+
+	if _, err := osexec.LookPath("lsof"); err != nil {
+		return ""
+	}
+	buf.Reset()
+	cmd = osexec.Command("lsof", "-nP", "-iTCP:"+strconv.Itoa(port), "-sTCP:LISTEN", "-Fpc")
+	cmd.Stdout = buf
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return ""
+	}
+	// Output contains lines starting with `p` followed by PID and `c` followed by command name.
+	var pid, command string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		switch {
+		case strings.HasPrefix(line, "p") && pid == "":
+			pid = line[1:]
+		case strings.HasPrefix(line, "c") && command == "":
+			command = line[1:]
+		}
+	}
+	if pid == "" {
+		return ""
+	}
+	return fmt.Sprintf("process %s (pid %s)", command, pid)
+}
+
+// verifyPorts verifies that ports required by the apps are free. Ports of the fresh environment are shifted
+// by an offset, stored in the spec, if they are reserved by other environments, or, if auto ports are enabled, used
+// by other processes. App set is rebuilt using the offset then.
+func verifyPorts(ctx context.Context, config infra.Config, spec *infra.Spec, appF *apps.Factory,
+	appSet infra.AppSet,
+) (infra.AppSet, error) {
+	dockerCtx, err := targets.CurrentDockerContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dockerCtx.RemoteHost() != "" {
+		// Ports are published on the remote docker host, so ports of the local one don't matter, and daemon reports
+		// busy ones itself.
+		return appSet, nil
+	}
+
+	fresh := true
+	for _, app := range spec.Apps {
+		if app.Info().Status != infra.AppStatusNotDeployed {
+			fresh = false
+			break
+		}
+	}
+
+	// Ports of the environment which has already been started are stored in the config files of the apps,
+	// so they can't be changed, and there is no point in checking other environments.
+	var reserved map[int]string
+	if fresh {
+		var err error
+		reserved, err = reservedPorts(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	conflicts := findPortConflicts(appSet, 0, reserved)
+	if len(conflicts) == 0 {
+		return appSet, nil
+	}
+	describePortOwners(ctx, conflicts)
+	conflictsStr := make([]string, 0, len(conflicts))
+	for _, c := range conflicts {
+		conflictsStr = append(conflictsStr, c.String())
+	}
+
+	if !fresh {
+		return nil, errors.Errorf("ports required by the environment are in use: %s, ports of the environment "+
+			"which has already been started can't be shifted, free them or remove the environment",
+			strings.Join(conflictsStr, "; "))
+	}
+	usedByEnvs := lo.EveryBy(conflicts, func(c portConflict) bool {
+		return c.Env != ""
+	})
+	if !usedByEnvs && !config.AutoPorts {
+		return nil, errors.Errorf("ports required by the environment are in use: %s, free them or use --auto-ports "+
+			"to shift ports of the environment", strings.Join(conflictsStr, "; "))
+	}
+
+	for i := 1; i <= autoPortsAttempts; i++ {
+		delta := i * autoPortsStep
+		if len(findPortConflicts(appSet, delta, reserved)) > 0 {
+			continue
+		}
+		spec.PortOffset += delta
+		if err := spec.Save(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		logger.Get(ctx).Info("Ports of the environment shifted because default ones are in use",
+			zap.Int("offset", spec.PortOffset), zap.Strings("conflicts", conflictsStr))
+		return apps.BuildAppSet(appF, config.Profiles, config.CoredVersion)
+	}
+	return nil, errors.Errorf("no free ports found for the environment, ports in use: %s",
+		strings.Join(conflictsStr, "; "))
+}
@@ -0,0 +1,112 @@
+package znet
+
+import (
+	"testing"
+
+	integrationtests "github.com/CoreumFoundation/coreum/integration-tests"
+	"github.com/CoreumFoundation/coreum/pkg/config/constant"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps"
+	"github.com/CoreumFoundation/crust/infra/apps/bigdipper"
+)
+
+const testPortOffset = 3 * autoPortsStep
+
+// testAppSet builds app set of the fresh environment, having ports shifted by the offset.
+func testAppSet(t *testing.T, homeDir string, profiles []string, portOffset int) infra.AppSet {
+	configF := &infra.ConfigFactory{
+		EnvName:  "znet",
+		HomeDir:  homeDir,
+		BinDir:   t.TempDir(),
+		Profiles: profiles,
+	}
+	spec := infra.NewSpec(configF)
+	spec.PortOffset = portOffset
+	config := NewConfig(configF, spec)
+
+	networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+	require.NoError(t, err)
+	appSet, err := apps.BuildAppSet(apps.NewFactory(config, spec, networkConfig), config.Profiles, config.CoredVersion)
+	require.NoError(t, err)
+	return appSet
+}
+
+func deployments(appSet infra.AppSet) map[string]infra.Deployment {
+	result := map[string]infra.Deployment{}
+	for _, app := range appSet {
+		result[app.Name()] = app.Deployment()
+	}
+	return result
+}
+
+func TestPortOffset(t *testing.T) {
+	for _, profile := range apps.Profiles() {
+		profile := profile
+		t.Run(profile, func(t *testing.T) {
+			profiles := []string{profile}
+			if err := apps.ValidateProfiles(profiles); err != nil {
+				profiles = append(apps.DefaultProfiles(), profile)
+			}
+
+			defaultApps := deployments(testAppSet(t, t.TempDir(), profiles, 0))
+			shiftedApps := deployments(testAppSet(t, t.TempDir(), profiles, testPortOffset))
+			require.Equal(t, len(defaultApps), len(shiftedApps))
+
+			for name, defaultApp := range defaultApps {
+				shiftedApp, exists := shiftedApps[name]
+				require.True(t, exists, "app %s doesn't exist", name)
+				require.Len(t, shiftedApp.Ports, len(defaultApp.Ports), "app %s", name)
+
+				for portName, port := range defaultApp.Ports {
+					assert.Equal(t, port+testPortOffset, shiftedApp.Ports[portName], "app %s, port %s", name, portName)
+
+					if _, fixed := defaultApp.ContainerPorts[portName]; fixed {
+						// Port is built into the image, so the shifted port published on the host is mapped to it.
+						assert.Equal(t, defaultApp.ContainerPort(portName), shiftedApp.ContainerPort(portName),
+							"app %s, port %s", name, portName)
+					} else {
+						assert.Equal(t, shiftedApp.Ports[portName], shiftedApp.ContainerPort(portName),
+							"app %s, port %s", name, portName)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBigDipperContainerPort(t *testing.T) {
+	for _, portOffset := range []int{0, testPortOffset} {
+		appSet := testAppSet(t, t.TempDir(), []string{string(apps.Profile1Cored), string(apps.ProfileExplorer)},
+			portOffset)
+
+		bigDipperApp, exists := lo.Find(appSet, func(app infra.App) bool {
+			return app.Type() == bigdipper.AppType
+		})
+		require.True(t, exists)
+		deployment := bigDipperApp.Deployment()
+		assert.Equal(t, bigdipper.DefaultPort+portOffset, deployment.Ports["web"])
+		assert.Equal(t, bigdipper.DefaultPort, deployment.ContainerPort("web"))
+	}
+}
+
+func TestFindPortConflicts(t *testing.T) {
+	appSet := testAppSet(t, t.TempDir(), apps.DefaultProfiles(), 0)
+	ports := deployments(appSet)["cored-00"].Ports
+	require.NotEmpty(t, ports)
+	port := ports["rpc"]
+
+	conflicts := findPortConflicts(appSet, testPortOffset, map[int]string{port + testPortOffset: "other"})
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, port+testPortOffset, conflicts[0].Port)
+	assert.Equal(t, "other", conflicts[0].Env)
+
+	conflicts = findPortConflicts(appSet, maxPort, nil)
+	require.Len(t, conflicts, len(ports))
+	for _, c := range conflicts {
+		assert.Equal(t, "out of range", c.Owner)
+	}
+}
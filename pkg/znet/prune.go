@@ -0,0 +1,94 @@
+package znet
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/targets"
+)
+
+// Prune removes docker containers, networks and volumes left by environments which home directories don't exist
+// anymore, e.g. because they were deleted manually or znet crashed while removing them. Docker daemon may be shared
+// by many hosts, so resources created by other hosts, or by older versions of znet not recording the host, are only
+// listed, unless all is set. Resources created by older versions of znet don't record their home directory,
+// so they are matched against the current home directory.
+func Prune(ctx context.Context, configF *infra.ConfigFactory, dryRun, all bool) error {
+	homeDir, err := filepath.Abs(configF.HomeDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if resolved, err := filepath.EvalSymlinks(homeDir); err == nil {
+		homeDir = resolved
+	}
+
+	resources, err := targets.ListEnvResources(ctx)
+	if err != nil {
+		return err
+	}
+	var orphans, foreign []targets.EnvResource
+	for _, resource := range resources {
+		if !all && !resource.IsLocal() {
+			foreign = append(foreign, resource)
+			continue
+		}
+		envDir := resource.HomeDir
+		if envDir == "" {
+			envDir = filepath.Join(homeDir, resource.Env)
+		}
+		if _, err := os.Stat(envDir); err == nil || !errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		orphans = append(orphans, resource)
+	}
+	if len(foreign) > 0 {
+		fmt.Println("Resources created by other hosts, skipped, use --all to prune them too:")
+		if err := printResources(foreign); err != nil {
+			return err
+		}
+	}
+	if len(orphans) == 0 {
+		fmt.Println("No orphaned resources found")
+		return nil
+	}
+
+	fmt.Println("Orphaned resources:")
+	if err := printResources(orphans); err != nil {
+		return err
+	}
+	if dryRun {
+		return nil
+	}
+
+	log := logger.Get(ctx)
+	// Resources are ordered by kind, so containers are removed before networks and volumes they use.
+	for _, orphan := range orphans {
+		log.Info("Removing orphaned resource", zap.String("kind", orphan.Kind), zap.String("name", orphan.Name),
+			zap.String("env", orphan.Env))
+		if err := targets.RemoveEnvResource(ctx, orphan); err != nil {
+			return err
+		}
+	}
+	log.Info("Orphaned resources removed", zap.Int("count", len(orphans)))
+	return nil
+}
+
+func printResources(resources []targets.EnvResource) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tENV\tHOST")
+	for _, resource := range resources {
+		host := resource.Host
+		if host == "" {
+			host = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", resource.Kind, resource.Name, resource.Env, host)
+	}
+	return errors.WithStack(w.Flush())
+}
@@ -0,0 +1,23 @@
+package znet
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/crust/infra"
+)
+
+// Pull pulls images of all the apps, so environment may be started later without access to the registry. Images
+// built by crust are not pulled, but it is verified that they exist.
+func Pull(ctx context.Context, appSet infra.AppSet) error {
+	images := appSet.Images()
+	log := logger.Get(ctx)
+	log.Info("Pulling images", zap.Strings("images", images))
+	if err := infra.PullImages(ctx, images); err != nil {
+		return err
+	}
+	log.Info("Images pulled")
+	return nil
+}
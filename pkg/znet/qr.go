@@ -0,0 +1,122 @@
+package znet
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/skip2/go-qrcode"
+
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+)
+
+// fundingAccount is the name under which the funding account may be passed to QR.
+const fundingAccount = "funding"
+
+// QRConfig configures the QR code rendered by QR.
+type QRConfig struct {
+	// Amount is put into the payment URI, plain address is encoded if it is empty
+	Amount sdk.Coins
+
+	// Mnemonic encodes mnemonic of the account instead of the address, so it may be imported by the wallet
+	Mnemonic bool
+
+	// Host is the host printed in the endpoints the wallet should connect to
+	Host string
+}
+
+// QR prints terminal QR code and payment URI of the account, which is either the address or the name of the account
+// imported into cored keyring.
+func QR(appSet infra.AppSet, account string, config QRConfig) error {
+	coredNode, err := qrNode(appSet)
+	if err != nil {
+		return err
+	}
+
+	address, mnemonic, err := resolveQRAccount(coredNode, account)
+	if err != nil {
+		return err
+	}
+
+	chainID := string(coredNode.Config().Network.ChainID())
+	content := paymentURI(address, chainID, config.Amount)
+	if config.Mnemonic {
+		if mnemonic == "" {
+			return errors.Errorf("mnemonic of %s is unknown, pass the name of the account instead of the address", account)
+		}
+		content = mnemonic
+	}
+
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	host := config.Host
+	if host == "" {
+		host = "localhost"
+	}
+	ports := coredNode.Config().Ports
+
+	fmt.Print(code.ToSmallString(false))
+	fmt.Println()
+	fmt.Printf("Address:  %s\n", address)
+	fmt.Printf("URI:      %s\n", paymentURI(address, chainID, config.Amount))
+	fmt.Printf("Chain ID: %s\n", chainID)
+	fmt.Printf("RPC:      %s\n", infra.JoinNetAddr("http", host, ports.RPC))
+	fmt.Printf("REST:     %s\n", infra.JoinNetAddr("http", host, ports.API))
+	fmt.Printf("gRPC:     %s\n", infra.JoinNetAddr("", host, ports.GRPC))
+	return nil
+}
+
+// QRAccounts returns names of the accounts which may be passed to QR.
+func QRAccounts(appSet infra.AppSet) []string {
+	coredNode, err := qrNode(appSet)
+	if err != nil {
+		return nil
+	}
+	accounts := append(lo.Keys(coredNode.Config().ImportedMnemonics), fundingAccount)
+	sort.Strings(accounts)
+	return accounts
+}
+
+func qrNode(appSet infra.AppSet) (cored.Cored, error) {
+	for _, app := range appSet {
+		if coredNode, ok := app.(cored.Cored); ok {
+			return coredNode, nil
+		}
+	}
+	return cored.Cored{}, errors.New("no cored app found")
+}
+
+func resolveQRAccount(coredNode cored.Cored, account string) (string, string, error) {
+	mnemonics := lo.Assign(coredNode.Config().ImportedMnemonics, map[string]string{
+		fundingAccount: coredNode.Config().FundingMnemonic,
+	})
+	if mnemonic, exists := mnemonics[account]; exists {
+		return importMnemonic(coredNode.ClientContext(), account, mnemonic).String(), mnemonic, nil
+	}
+	if _, err := sdk.AccAddressFromBech32(account); err != nil {
+		names := lo.Keys(mnemonics)
+		sort.Strings(names)
+		return "", "", errors.Errorf("%q is neither a valid address nor one of the accounts: %s", account,
+			strings.Join(names, ", "))
+	}
+	return account, "", nil
+}
+
+// paymentURI returns BIP21-style URI of the payment to the address. Cosmos doesn't standardize such URIs, so the
+// chain ID is always included, to prevent wallets from sending tokens on wrong chain.
+func paymentURI(address, chainID string, amount sdk.Coins) string {
+	query := url.Values{"chain_id": []string{chainID}}
+	if !amount.Empty() {
+		query.Set("amount", amount.String())
+	}
+	uri := url.URL{Scheme: "coreum", Opaque: address, RawQuery: query.Encode()}
+	return uri.String()
+}
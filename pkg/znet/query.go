@@ -0,0 +1,79 @@
+package znet
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+)
+
+// Query executes `cored query` against running cored node. If height is greater than 0, state at that height is
+// queried. Archive node is preferred because it keeps all the historical states, if there is none, any other node is
+// used, so only recent heights may be queried.
+func Query(ctx context.Context, appSet infra.AppSet, height int64, args []string) error {
+	coredNode, err := queryNode(appSet)
+	if err != nil {
+		return err
+	}
+
+	status, err := coredNode.ClientContext().RPCClient().Status(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	lowestHeight := status.SyncInfo.EarliestBlockHeight
+	latestHeight := status.SyncInfo.LatestBlockHeight
+
+	log := logger.Get(ctx).With(zap.String("node", coredNode.Name()))
+	log.Info("Querying node", zap.Bool("archive", coredNode.Config().IsArchive),
+		zap.Int64("lowestHeight", lowestHeight), zap.Int64("latestHeight", latestHeight))
+
+	if height > 0 && (height < lowestHeight || height > latestHeight) {
+		if !coredNode.Config().IsArchive {
+			return errors.Errorf("height %d is not available on node %s, available heights are %d-%d, "+
+				"use %q profile to run archive node keeping all the historical states", height, coredNode.Name(),
+				lowestHeight, latestHeight, apps.ProfileArchive)
+		}
+		return errors.Errorf("height %d is not available on node %s, available heights are %d-%d", height,
+			coredNode.Name(), lowestHeight, latestHeight)
+	}
+
+	coredConfig := coredNode.Config()
+	cmdArgs := append([]string{"query"}, args...)
+	cmdArgs = append(cmdArgs,
+		"--node", infra.JoinNetAddr("tcp", coredNode.Info().HostFromHost, coredConfig.Ports.RPC),
+		"--chain-id", string(coredConfig.Network.ChainID()),
+	)
+	if height > 0 {
+		cmdArgs = append(cmdArgs, "--height", strconv.FormatInt(height, 10))
+	}
+	return libexec.Exec(ctx, exec.Command(coredConfig.BinDir+"/cored", cmdArgs...))
+}
+
+// queryNode returns running archive cored node if there is one, any running cored node otherwise.
+func queryNode(appSet infra.AppSet) (cored.Cored, error) {
+	var found *cored.Cored
+	for _, app := range appSet {
+		coredNode, ok := app.(cored.Cored)
+		if !ok || coredNode.Info().Status != infra.AppStatusRunning {
+			continue
+		}
+		if coredNode.Config().IsArchive {
+			return coredNode, nil
+		}
+		if found == nil {
+			found = &coredNode
+		}
+	}
+	if found == nil {
+		return cored.Cored{}, errors.New("no running cored app found")
+	}
+	return *found, nil
+}
@@ -0,0 +1,280 @@
+package znet
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum-tools/pkg/parallel"
+	integrationtests "github.com/CoreumFoundation/coreum/integration-tests"
+	"github.com/CoreumFoundation/coreum/pkg/config/constant"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps"
+)
+
+const (
+	// DefaultServeAddress is the default address API server listens on.
+	DefaultServeAddress = "127.0.0.1:8095"
+
+	// serveTokenFile is the file in the directory of the environment where generated API token is stored.
+	serveTokenFile = "serve.token"
+)
+
+// server exposes environment management over HTTP API.
+type server struct {
+	configF *infra.ConfigFactory
+	token   string
+
+	// mu ensures that only one operation modifies the environment at a time.
+	mu sync.Mutex
+}
+
+// Serve starts HTTP API server exposing environment management. Requests must contain the token in `Authorization`
+// header. If token is empty, random one is generated and stored in the directory of the environment.
+func Serve(ctx context.Context, configF *infra.ConfigFactory, address, token string) error {
+	log := logger.Get(ctx)
+	if token == "" {
+		var err error
+		token, err = generateServeToken(configF)
+		if err != nil {
+			return err
+		}
+	}
+
+	s := &server{configF: configF, token: token}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/status", s.handler(ctx, http.MethodGet, false, s.status))
+	mux.HandleFunc("/api/v1/start", s.handler(ctx, http.MethodPost, true, s.start))
+	mux.HandleFunc("/api/v1/stop", s.handler(ctx, http.MethodPost, true, s.stop))
+	mux.HandleFunc("/api/v1/test", s.handler(ctx, http.MethodPost, true, s.test))
+	mux.HandleFunc("/api/v1/fund", s.handler(ctx, http.MethodPost, true, s.fund))
+	mux.HandleFunc("/healthz", s.monitorHandler(ctx, true))
+	mux.HandleFunc("/status", s.monitorHandler(ctx, false))
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	httpServer := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Info("API server started", zap.String("address", listener.Addr().String()))
+	return parallel.Run(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
+		spawn("server", parallel.Fail, func(ctx context.Context) error {
+			if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return errors.WithStack(err)
+			}
+			return nil
+		})
+		spawn("shutdown", parallel.Exit, func(ctx context.Context) error {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			return errors.WithStack(httpServer.Shutdown(shutdownCtx))
+		})
+		return nil
+	})
+}
+
+// generateServeToken generates random token and stores it in the directory of the environment, so clients
+// running on the same host may read it.
+func generateServeToken(configF *infra.ConfigFactory) (string, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", errors.WithStack(err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	dir := filepath.Join(configF.HomeDir, configF.EnvName)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", errors.WithStack(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, serveTokenFile), []byte(token), 0o600); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return token, nil
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// handler wraps API operation with authentication, method check, serialization of operations and encoding
+// of the response.
+func (s *server) handler(
+	ctx context.Context,
+	method string,
+	exclusive bool,
+	fn func(ctx context.Context, r *http.Request) (interface{}, error),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			writeJSON(w, http.StatusUnauthorized, apiError{Error: "invalid or missing token"})
+			return
+		}
+		if r.Method != method {
+			writeJSON(w, http.StatusMethodNotAllowed, apiError{Error: "method not allowed"})
+			return
+		}
+		if exclusive {
+			if !s.mu.TryLock() {
+				writeJSON(w, http.StatusConflict, apiError{Error: "another operation is in progress"})
+				return
+			}
+			defer s.mu.Unlock()
+		}
+
+		log := logger.Get(ctx).With(zap.String("path", r.URL.Path))
+		log.Info("API request received")
+
+		// Operation is not interrupted if client disconnects, so environment is not left in inconsistent state.
+		result, err := fn(logger.WithLogger(ctx, log), r)
+		if err != nil {
+			log.Error("API request failed", zap.Error(err))
+			writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
+			return
+		}
+		if result == nil {
+			result = struct{}{}
+		}
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+type healthzResponse struct {
+	Status string `json:"status"`
+}
+
+// monitorHandler serves status of the environment to uptime monitors, so token is not required. `503 Service
+// Unavailable` is returned if environment is not healthy. If brief is true, only the overall status is returned.
+func (s *server) monitorHandler(ctx context.Context, brief bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			writeJSON(w, http.StatusMethodNotAllowed, apiError{Error: "method not allowed"})
+			return
+		}
+
+		// Monitors poll frequently, so requests are not logged unless they fail.
+		status, err := EnvironmentStatus(ctx, s.configF)
+		if err != nil {
+			logger.Get(ctx).Error("Collecting status of the environment failed", zap.Error(err))
+			writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
+			return
+		}
+
+		code := http.StatusOK
+		if status.Status != EnvStatusHealthy {
+			code = http.StatusServiceUnavailable
+		}
+		if brief {
+			writeJSON(w, code, healthzResponse{Status: status.Status})
+			return
+		}
+		writeJSON(w, code, status)
+	}
+}
+
+func (s *server) authorized(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+func (s *server) status(ctx context.Context, r *http.Request) (interface{}, error) {
+	return infra.NewSpec(s.configF), nil
+}
+
+func (s *server) start(ctx context.Context, r *http.Request) (interface{}, error) {
+	spec := infra.NewSpec(s.configF)
+	return spec, Start(ctx, NewConfig(s.configF, spec), spec)
+}
+
+func (s *server) stop(ctx context.Context, r *http.Request) (interface{}, error) {
+	spec := infra.NewSpec(s.configF)
+	return spec, Stop(ctx, NewConfig(s.configF, spec), spec)
+}
+
+type testRequest struct {
+	Groups []string `json:"groups"`
+	Filter string   `json:"filter"`
+}
+
+func (s *server) test(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req testRequest
+	if err := decodeRequest(r, &req); err != nil {
+		return nil, err
+	}
+
+	configF := *s.configF
+	configF.Profiles = apps.IntegrationTestsProfiles()
+	configF.TestGroups = req.Groups
+	configF.TestFilter = req.Filter
+	spec := infra.NewSpec(&configF)
+	return nil, Test(ctx, &configF, NewConfig(&configF, spec), spec)
+}
+
+type fundRequest struct {
+	Address string `json:"address"`
+	Amount  string `json:"amount"`
+}
+
+type fundResponse struct {
+	TxHash string `json:"txHash"`
+}
+
+func (s *server) fund(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req fundRequest
+	if err := decodeRequest(r, &req); err != nil {
+		return nil, err
+	}
+	amount, err := sdk.ParseCoinsNormalized(req.Amount)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid amount %q", req.Amount)
+	}
+
+	spec := infra.NewSpec(s.configF)
+	networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+	if err != nil {
+		return nil, err
+	}
+	config := NewConfig(s.configF, spec)
+	appSet, err := apps.BuildAppSet(apps.NewFactory(config, spec, networkConfig), config.Profiles,
+		config.CoredVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	txHash, err := Fund(ctx, appSet, req.Address, amount)
+	if err != nil {
+		return nil, err
+	}
+	return fundResponse{TxHash: txHash}, nil
+}
+
+func decodeRequest(r *http.Request, req interface{}) error {
+	if r.ContentLength == 0 {
+		return nil
+	}
+	return errors.Wrap(json.NewDecoder(r.Body).Decode(req), "invalid request")
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -1,14 +1,27 @@
 package znet
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
 
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
 	"github.com/CoreumFoundation/coreum-tools/pkg/must"
+	"github.com/CoreumFoundation/crust/exec"
 	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps"
+	"github.com/CoreumFoundation/crust/infra/apps/faucet"
+	"github.com/CoreumFoundation/crust/infra/apps/gaiad"
+	"github.com/CoreumFoundation/crust/infra/apps/mockserver"
+	"github.com/CoreumFoundation/crust/infra/apps/relayercosmos"
+	"github.com/CoreumFoundation/crust/infra/targets"
 )
 
 // NewCmdFactory returns new CmdFactory.
@@ -25,13 +38,166 @@ type CmdFactory struct {
 
 // Cmd returns function compatible with RunE.
 func (f *CmdFactory) Cmd(cmdFunc func() error) func(cmd *cobra.Command, args []string) error {
-	return func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) (retErr error) {
+		start := time.Now()
+		defer func() {
+			// History is auxiliary, so failing to record it doesn't fail the executed command.
+			if err := recordHistory(f.configF, cmd, args, start, retErr); err != nil {
+				logger.Get(cmd.Context()).Warn("Recording command in history failed", zap.Error(err))
+			}
+		}()
+
 		f.configF.VerboseLogging = cmd.Flags().Lookup("verbose").Value.String() == "true"
 		f.configF.LogFormat = cmd.Flags().Lookup("log-format").Value.String()
+		if err := exec.SetContainerRuntime(f.configF.ContainerRuntime); err != nil {
+			return err
+		}
+		exec.SetRegistryMirror(f.configF.RegistryMirror)
+		targets.SetRemoteHome(f.configF.HomeDir, f.configF.RemoteHomeDir)
+		if f.configF.Validators != 0 {
+			profiles, err := apps.WithValidators(f.configF.Profiles, f.configF.Validators)
+			if err != nil {
+				return err
+			}
+			f.configF.Profiles = profiles
+		}
+		if err := apps.ValidateProfiles(f.configF.Profiles); err != nil {
+			return err
+		}
+		if err := apps.ValidateResources(f.configF.Resources); err != nil {
+			return err
+		}
+		if err := gaiad.ValidateVersion(f.configF.GaiaVersion); err != nil {
+			return err
+		}
+		if err := infra.ValidatePullPolicy(infra.PullPolicy(f.configF.ImagePullPolicy)); err != nil {
+			return err
+		}
+		if err := targets.ValidateNetworkName(f.configF.NetworkName); err != nil {
+			return err
+		}
+		if _, err := targets.ParseNetworkAliases(f.configF.NetworkAliases); err != nil {
+			return err
+		}
+		faucetBalance, err := apps.GenesisBalance(f.configF.GenesisDenoms, f.configF.FaucetDenom)
+		if err != nil {
+			return err
+		}
+		faucetTransferAmount := apps.FaucetTransferAmount(f.configF.Profiles, int64(f.configF.FaucetTransferAmount))
+		if err := faucet.ValidateSettings(faucetTransferAmount, f.configF.FaucetDenom,
+			f.configF.FaucetRateLimit, faucetBalance); err != nil {
+			return err
+		}
+		if err := mockserver.ValidateMappings(f.configF.MockServerMappings); err != nil {
+			return err
+		}
+		if err := relayercosmos.ValidateGasPriceMultiplier(f.configF.RelayerGasPriceMultiplier); err != nil {
+			return err
+		}
+		if err := relayercosmos.ValidateMemo(f.configF.RelayerMemo); err != nil {
+			return err
+		}
+		if _, err := relayercosmos.ParseChannels(f.configF.RelayerChannels); err != nil {
+			return err
+		}
 		return cmdFunc()
 	}
 }
 
+// LockedCmd returns function compatible with RunE, executing the command while holding the lock of the environment,
+// so commands modifying it fail instead of running concurrently.
+func (f *CmdFactory) LockedCmd(cmdFunc func() error) func(cmd *cobra.Command, args []string) error {
+	return f.Cmd(func() error {
+		unlock, err := LockEnv(f.configF)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		return cmdFunc()
+	})
+}
+
+// EnvAnnotation is the annotation of the flag defining the name of the environment variable which sets the option,
+// instead of the one derived from the names of the command and the flag.
+const EnvAnnotation = "crust_env"
+
+// ResolveConfig sets options of the config factory using environment variables, environment file and flags of
+// the executed command, see infra.ConfigFactory.Resolve.
+func ResolveConfig(configF *infra.ConfigFactory, cmd *cobra.Command) error {
+	var options []infra.ConfigOption
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		options = append(options, infra.ConfigOption{
+			Flag:    flag.Name,
+			Env:     flagEnv(cmd, flag),
+			Changed: flag.Changed,
+			Set: func(value string) error {
+				return setFlagValue(flag, value)
+			},
+		})
+	})
+	return configF.Resolve(options)
+}
+
+// setFlagValue sets value of the flag without marking it as changed, so it is still overridden by the environment
+// file.
+func setFlagValue(flag *pflag.Flag, value string) error {
+	// Set can't be used for slices, because then the value would be appended to the default one instead of
+	// replacing it.
+	if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return errors.WithStack(sliceValue.Replace(items))
+	}
+	if err := flag.Value.Set(value); err != nil {
+		return errors.Wrapf(err, "%s is expected", flag.Value.Type())
+	}
+	return nil
+}
+
+// DescribeEnv adds names of environment variables to the usage of flags of the command and all its subcommands.
+// Variable is named after the flag, prefixed with the path of the command defining it,
+// e.g. CRUST_ZNET_CHAOS_NETEM_DELAY, unless the flag is defined by the root command or its name is set explicitly
+// using EnvAnnotation.
+func DescribeEnv(cmd *cobra.Command) {
+	visited := map[*pflag.Flag]bool{}
+	describe := func(flag *pflag.Flag) {
+		if visited[flag] {
+			return
+		}
+		visited[flag] = true
+		flag.Usage += fmt.Sprintf(" (env: %s)", flagEnv(cmd, flag))
+	}
+	cmd.PersistentFlags().VisitAll(describe)
+	cmd.Flags().VisitAll(describe)
+
+	for _, subCmd := range cmd.Commands() {
+		DescribeEnv(subCmd)
+	}
+}
+
+// flagEnv returns the name of the environment variable setting the flag.
+func flagEnv(cmd *cobra.Command, flag *pflag.Flag) string {
+	if env := flag.Annotations[EnvAnnotation]; len(env) > 0 {
+		return env[0]
+	}
+	// Flags of the executed command include persistent flags inherited from its parents, those are named after
+	// the command defining them.
+	for parent := cmd; parent != nil; parent = parent.Parent() {
+		if parent.PersistentFlags().Lookup(flag.Name) == flag {
+			cmd = parent
+			break
+		}
+	}
+	// Name of the root command is skipped, because it is already the part of the prefix.
+	parts := append([]string{"CRUST_ZNET"}, strings.Fields(cmd.CommandPath())[1:]...)
+	parts = append(parts, flag.Name)
+	return strings.ToUpper(strings.ReplaceAll(strings.Join(parts, "_"), "-", "_"))
+}
+
 // NewConfig produces final config.
 func NewConfig(configF *infra.ConfigFactory, spec *infra.Spec) infra.Config {
 	must.OK(os.MkdirAll(configF.HomeDir, 0o700))
@@ -41,20 +207,83 @@ func NewConfig(configF *infra.ConfigFactory, spec *infra.Spec) infra.Config {
 	}
 
 	config := infra.Config{
-		EnvName:        configF.EnvName,
-		Profiles:       spec.Profiles,
-		CoredVersion:   configF.CoredVersion,
-		HomeDir:        homeDir,
-		AppDir:         homeDir + "/app",
-		WrapperDir:     homeDir + "/bin",
-		BinDir:         must.String(filepath.Abs(must.String(filepath.EvalSymlinks(configF.BinDir)))),
-		TestFilter:     configF.TestFilter,
-		VerboseLogging: configF.VerboseLogging,
-		LogFormat:      configF.LogFormat,
+		EnvName:         configF.EnvName,
+		Profiles:        spec.Profiles,
+		CoredVersion:    configF.CoredVersion,
+		GaiaVersion:     configF.GaiaVersion,
+		HomeDir:         homeDir,
+		AppDir:          homeDir + "/app",
+		WrapperDir:      homeDir + "/bin",
+		BinDir:          must.String(filepath.Abs(must.String(filepath.EvalSymlinks(configF.BinDir)))),
+		TestFilter:      configF.TestFilter,
+		ReportDir:       configF.ReportDir,
+		CoverageDir:     configF.CoverageDir,
+		RecordTraffic:   configF.RecordTraffic,
+		RecordBodyLimit: configF.RecordBodyLimit,
+		TestParallelism: configF.TestParallelism,
+		NetworkFile:     configF.NetworkFile,
+		VerboseLogging:  configF.VerboseLogging,
+		LogFormat:       configF.LogFormat,
+		Subnet:          configF.Subnet,
+		NetworkName:     configF.NetworkName,
+		IPv6:            configF.IPv6,
+		ImagePullPolicy: infra.PullPolicy(configF.ImagePullPolicy),
+		RelayerMemo:     configF.RelayerMemo,
+		AutoPorts:       configF.AutoPorts,
+		CoredSentries:   configF.CoredSentries,
+		AccountPoolSize: configF.AccountPoolSize,
+	}
+
+	// Overrides of resource limits are validated before command is executed.
+	resources, err := apps.Resources(spec.Profiles, configF.Resources)
+	must.OK(err)
+	config.Resources = resources
+
+	if config.NetworkName == "" {
+		config.NetworkName = config.EnvName
 	}
+	// Network aliases are validated before command is executed.
+	aliases, err := targets.ParseNetworkAliases(configF.NetworkAliases)
+	must.OK(err)
+	config.NetworkAliases = aliases
+	config.FaucetTransferAmount = int64(configF.FaucetTransferAmount)
+	config.FaucetDenom = configF.FaucetDenom
+	config.FaucetRateLimit = configF.FaucetRateLimit
+	config.RelayerGasPriceMultiplier = configF.RelayerGasPriceMultiplier
+	// Relayer channels are validated before command is executed.
+	relayerChannels, err := relayercosmos.ParseChannels(configF.RelayerChannels)
+	must.OK(err)
+	config.RelayerChannels = relayerChannels
 
 	// we use append to make a copy of the original list, so it is not passed by reference
 	config.TestGroups = append([]string{}, configF.TestGroups...)
+	config.GenesisDenoms = append([]string{}, configF.GenesisDenoms...)
+	config.GenesisParams = append([]string{}, configF.GenesisParams...)
+	for _, def := range configF.Contracts {
+		// paths are made absolute because commands executed in the environment are started in its home directory
+		deployment := ParseContractDeployment(def)
+		def = must.String(filepath.Abs(deployment.WASMFile))
+		if deployment.InstantiateMsgFile != "" {
+			def += ":" + must.String(filepath.Abs(deployment.InstantiateMsgFile))
+		}
+		config.Contracts = append(config.Contracts, def)
+	}
+	// paths are made absolute because commands executed in the environment are started in its home directory
+	if configF.KeyBackup != "" {
+		config.KeyBackup = must.String(filepath.Abs(configF.KeyBackup))
+	}
+	if configF.KeyRestore != "" {
+		config.KeyRestore = must.String(filepath.Abs(configF.KeyRestore))
+	}
+	if configF.GenesisPatch != "" {
+		// path is made absolute because commands executed in the environment are started in its home directory
+		config.GenesisPatch = must.String(filepath.Abs(configF.GenesisPatch))
+	}
+
+	if configF.MockServerMappings != "" {
+		// path is made absolute because commands executed in the environment are started in its home directory
+		config.MockServerMappings = must.String(filepath.Abs(configF.MockServerMappings))
+	}
 
 	createDirs(config)
 
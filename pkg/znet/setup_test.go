@@ -0,0 +1,84 @@
+package znet
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/CoreumFoundation/crust/infra"
+)
+
+func TestResolveConfig(t *testing.T) {
+	configF := &infra.ConfigFactory{}
+	var delay string
+
+	var executed *cobra.Command
+	rootCmd := &cobra.Command{
+		Use: "znet",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return ResolveConfig(configF, cmd)
+		},
+	}
+	rootCmd.PersistentFlags().StringVar(&configF.EnvName, "env", "znet", "")
+	rootCmd.PersistentFlags().StringSliceVar(&configF.Profiles, "profiles", []string{"1cored"}, "")
+	rootCmd.PersistentFlags().IntVar(&configF.Validators, "validators", 0, "")
+	rootCmd.PersistentFlags().StringVar(&configF.EnvFile, "env-file", "", "")
+	rootCmd.PersistentFlags().StringVar(&configF.Subnet, "subnet", "", "")
+	require.NoError(t, rootCmd.PersistentFlags().SetAnnotation("subnet", EnvAnnotation, []string{"CRUST_ZNET_NETWORK_SUBNET"}))
+
+	chaosCmd := &cobra.Command{Use: "chaos"}
+	netemCmd := &cobra.Command{
+		Use: "netem",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			executed = cmd
+			return nil
+		},
+	}
+	netemCmd.Flags().StringVar(&delay, "delay", "", "")
+	chaosCmd.AddCommand(netemCmd)
+	rootCmd.AddCommand(chaosCmd)
+
+	t.Setenv("CRUST_ZNET_ENV", "from-env")
+	t.Setenv("CRUST_ZNET_PROFILES", "3cored, faucet")
+	t.Setenv("CRUST_ZNET_VALIDATORS", "5")
+	t.Setenv("CRUST_ZNET_NETWORK_SUBNET", "172.30.0.0/16")
+	t.Setenv("CRUST_ZNET_CHAOS_NETEM_DELAY", "100ms")
+
+	rootCmd.SetArgs([]string{"chaos", "netem", "--profiles", "1cored,explorer"})
+	require.NoError(t, rootCmd.Execute())
+	require.Equal(t, netemCmd, executed)
+
+	assert.Equal(t, "from-env", configF.EnvName)
+	// Profiles passed explicitly are not replaced by the environment variable, and they select the cored profile,
+	// so number of validators taken from the environment variable is ignored.
+	assert.Equal(t, []string{"1cored", "explorer"}, configF.Profiles)
+	assert.Equal(t, 0, configF.Validators)
+	assert.Equal(t, "172.30.0.0/16", configF.Subnet)
+	assert.Equal(t, "100ms", delay)
+	// Values taken from environment variables are not recorded as passed explicitly.
+	assert.False(t, netemCmd.Flags().Lookup("env").Changed)
+}
+
+func TestResolveConfigReplacesSlice(t *testing.T) {
+	configF := &infra.ConfigFactory{}
+	cmd := &cobra.Command{Use: "znet"}
+	cmd.Flags().StringSliceVar(&configF.Profiles, "profiles", []string{"1cored"}, "")
+	t.Setenv("CRUST_ZNET_PROFILES", "3cored,,faucet")
+
+	require.NoError(t, ResolveConfig(configF, cmd))
+	assert.Equal(t, []string{"3cored", "faucet"}, configF.Profiles)
+}
+
+func TestResolveConfigRejectsInvalidValue(t *testing.T) {
+	configF := &infra.ConfigFactory{}
+	cmd := &cobra.Command{Use: "znet"}
+	cmd.Flags().IntVar(&configF.Validators, "validators", 0, "")
+	t.Setenv("CRUST_ZNET_VALIDATORS", "three")
+
+	err := ResolveConfig(configF, cmd)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CRUST_ZNET_VALIDATORS")
+	assert.Contains(t, err.Error(), "int is expected")
+}
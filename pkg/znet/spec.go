@@ -0,0 +1,285 @@
+package znet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/targets"
+)
+
+// Formats of the spec printed by `spec` command.
+const (
+	SpecOutputTable = "table"
+	SpecOutputRaw   = "raw"
+	SpecOutputJSON  = "json"
+	SpecOutputYAML  = "yaml"
+)
+
+// Health states of the apps reported by `spec` command.
+const (
+	SpecHealthHealthy   = "healthy"
+	SpecHealthUnhealthy = "unhealthy"
+)
+
+// specHealthCheckTimeout is the time after which the app is reported as unhealthy if health check hasn't finished.
+const specHealthCheckTimeout = 5 * time.Second
+
+// SpecSchemaVersion is the version of the schema of SpecDocument. It is increased whenever field is removed
+// or its meaning changes, adding fields doesn't change the version.
+const SpecSchemaVersion = 1
+
+// SpecOutputs returns supported formats of the spec.
+func SpecOutputs() []string {
+	return []string{SpecOutputTable, SpecOutputRaw, SpecOutputJSON, SpecOutputYAML}
+}
+
+// SpecFilter selects the apps included in the spec, all the apps are included if it is empty.
+type SpecFilter struct {
+	// Apps are the names of the apps to include
+	Apps []string
+
+	// Types are the types of the apps to include
+	Types []string
+}
+
+func (f SpecFilter) matches(app SpecApp) bool {
+	return (len(f.Apps) == 0 || lo.Contains(f.Apps, app.Name)) &&
+		(len(f.Types) == 0 || lo.Contains(f.Types, app.Type))
+}
+
+// SpecDocument describes topology of the environment for external tools.
+type SpecDocument struct {
+	// SchemaVersion is the version of the schema of this document
+	SchemaVersion int `json:"schemaVersion" yaml:"schemaVersion"`
+
+	// Env is the name of the environment
+	Env string `json:"env" yaml:"env"`
+
+	// Profiles is the list of deployed application profiles
+	Profiles []string `json:"profiles" yaml:"profiles"`
+
+	// FrozenAt is the time when environment was frozen, absent if it is not frozen
+	FrozenAt *time.Time `json:"frozenAt,omitempty" yaml:"frozenAt,omitempty"`
+
+	// Apps is the list of applications sorted by name
+	Apps []SpecApp `json:"apps" yaml:"apps"`
+}
+
+// SpecApp describes application of the environment.
+type SpecApp struct {
+	// Name is the name of the application
+	Name string `json:"name" yaml:"name"`
+
+	// Type is the type of the application, e.g. `cored`
+	Type string `json:"type" yaml:"type"`
+
+	// Status is the status of the application: notDeployed, stopped or running
+	Status string `json:"status" yaml:"status"`
+
+	// Health is the result of the health check of running application: healthy or unhealthy, absent if application
+	// is not running or it doesn't provide health check
+	Health string `json:"health,omitempty" yaml:"health,omitempty"`
+
+	// HealthError is the reason why application is unhealthy
+	HealthError string `json:"healthError,omitempty" yaml:"healthError,omitempty"`
+
+	// Container is the name of the docker container running the application
+	Container string `json:"container,omitempty" yaml:"container,omitempty"`
+
+	// ContainerID is the ID of the docker container, absent if container doesn't exist
+	ContainerID string `json:"containerID,omitempty" yaml:"containerID,omitempty"`
+
+	// Hostname is the hostname other containers use to connect to the application
+	Hostname string `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+
+	// IP is the IPv4 address of the container in docker network
+	IP string `json:"ip,omitempty" yaml:"ip,omitempty"`
+
+	// IPv6 is the IPv6 address of the container in docker network, absent if IPv6 is disabled
+	IPv6 string `json:"ipv6,omitempty" yaml:"ipv6,omitempty"`
+
+	// Ports maps names of the ports, e.g. `rpc`, to their numbers
+	Ports map[string]int `json:"ports,omitempty" yaml:"ports,omitempty"`
+
+	// Endpoints maps names of the ports to `<host>:<port>` addresses reachable from the host
+	Endpoints map[string]string `json:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+
+	// DependsOn is the list of applications which must be running before this one is started
+	DependsOn []string `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+
+	// IBC contains identifiers of the IBC client, connection and channel established by the relayer
+	IBC *infra.IBCPath `json:"ibc,omitempty" yaml:"ibc,omitempty"`
+}
+
+// Spec prints specification of running environment in the requested format. Apps from the app set are health
+// checked if they are running.
+func Spec(ctx context.Context, spec *infra.Spec, appSet infra.AppSet, output string, filter SpecFilter) error {
+	if err := refreshIBCPaths(ctx, spec, appSet); err != nil {
+		return err
+	}
+	if output == SpecOutputRaw {
+		fmt.Println(spec)
+		return nil
+	}
+	if !lo.Contains(SpecOutputs(), output) {
+		return errors.Errorf("unknown output %q, supported ones: %v", output, SpecOutputs())
+	}
+
+	doc, err := NewSpecDocument(ctx, spec)
+	if err != nil {
+		return err
+	}
+	doc.Apps = lo.Filter(doc.Apps, func(app SpecApp, _ int) bool {
+		return filter.matches(app)
+	})
+	checkSpecHealth(ctx, appSet, doc.Apps)
+
+	var encoded []byte
+	switch output {
+	case SpecOutputTable:
+		return printSpecTable(doc)
+	case SpecOutputJSON:
+		encoded, err = json.MarshalIndent(doc, "", "  ")
+		encoded = append(encoded, '\n')
+	default:
+		encoded, err = yaml.Marshal(doc)
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	fmt.Print(string(encoded))
+	return nil
+}
+
+// checkSpecHealth runs health checks of the running apps in parallel and stores the results in the spec apps.
+func checkSpecHealth(ctx context.Context, appSet infra.AppSet, specApps []SpecApp) {
+	ctx, cancel := context.WithTimeout(ctx, specHealthCheckTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := range specApps {
+		specApp := &specApps[i]
+		if specApp.Status != string(infra.AppStatusRunning) {
+			continue
+		}
+		app, exists := lo.Find(appSet, func(app infra.App) bool {
+			return app.Name() == specApp.Name
+		})
+		if !exists {
+			continue
+		}
+		healthCheckApp, ok := app.(infra.HealthCheckCapable)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := healthCheckApp.HealthCheck(ctx); err != nil {
+				specApp.Health = SpecHealthUnhealthy
+				specApp.HealthError = err.Error()
+				return
+			}
+			specApp.Health = SpecHealthHealthy
+		}()
+	}
+	wg.Wait()
+}
+
+func printSpecTable(doc SpecDocument) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APP\tTYPE\tSTATUS\tHEALTH\tENDPOINTS")
+	for _, app := range doc.Apps {
+		health := app.Health
+		if health == "" {
+			health = "-"
+		}
+		endpoints := make([]string, 0, len(app.Endpoints))
+		for name, endpoint := range app.Endpoints {
+			endpoints = append(endpoints, name+"="+endpoint)
+		}
+		sort.Strings(endpoints)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", app.Name, app.Type, app.Status, health, strings.Join(endpoints, " "))
+	}
+	printIBCPathsTable(w, doc)
+	if doc.FrozenAt != nil {
+		fmt.Fprintf(w, "\nEnvironment frozen at %s\n", doc.FrozenAt.Local().Format(time.DateTime))
+	}
+	return errors.WithStack(w.Flush())
+}
+
+// printIBCPathsTable prints identifiers of both ends of the IBC paths established by relayers.
+func printIBCPathsTable(w io.Writer, doc SpecDocument) {
+	header := false
+	for _, app := range doc.Apps {
+		if app.IBC == nil {
+			continue
+		}
+		if !header {
+			fmt.Fprintln(w, "\nRELAYER\tCHAIN\tCLIENT\tCONNECTION\tCHANNEL")
+			header = true
+		}
+		for _, end := range []infra.IBCPathEnd{app.IBC.Source, app.IBC.Destination} {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s/%s\n", app.Name, end.ChainID, end.ClientID, end.ConnectionID,
+				end.PortID, end.ChannelID)
+		}
+	}
+}
+
+// NewSpecDocument converts spec to the versioned document. IDs of the containers are taken from docker.
+func NewSpecDocument(ctx context.Context, spec *infra.Spec) (SpecDocument, error) {
+	containerIDs, err := targets.ContainerIDs(ctx, spec.Env)
+	if err != nil {
+		return SpecDocument{}, err
+	}
+
+	doc := SpecDocument{
+		SchemaVersion: SpecSchemaVersion,
+		Env:           spec.Env,
+		Profiles:      spec.Profiles,
+		FrozenAt:      spec.FrozenAt,
+		Apps:          make([]SpecApp, 0, len(spec.Apps)),
+	}
+	for name, app := range spec.Apps {
+		info := app.Info()
+		status := string(info.Status)
+		if info.Status == infra.AppStatusNotDeployed {
+			status = "notDeployed"
+		}
+		specApp := SpecApp{
+			Name:        name,
+			Type:        string(app.Type()),
+			Status:      status,
+			Container:   info.Container,
+			ContainerID: containerIDs[info.Container],
+			Hostname:    info.HostFromContainer,
+			IP:          info.IP,
+			IPv6:        info.IPv6,
+			Ports:       info.Ports,
+			DependsOn:   info.DependsOn,
+			IBC:         app.IBCPath(),
+		}
+		if info.HostFromHost != "" && len(info.Ports) > 0 {
+			specApp.Endpoints = map[string]string{}
+			for portName, port := range info.Ports {
+				specApp.Endpoints[portName] = infra.JoinNetAddr("", info.HostFromHost, port)
+			}
+		}
+		doc.Apps = append(doc.Apps, specApp)
+	}
+	sort.Slice(doc.Apps, func(i, j int) bool { return doc.Apps[i].Name < doc.Apps[j].Name })
+	return doc, nil
+}
@@ -0,0 +1,239 @@
+package znet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/targets"
+)
+
+// localImageTag is the tag of images built locally by `crust build images`.
+const localImageTag = ":znet"
+
+// Status prints status of the applications and warns about the ones running images older than their sources.
+func Status(ctx context.Context, appSet infra.AppSet) error {
+	sorted := append(infra.AppSet{}, appSet...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name() < sorted[j].Name() })
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APP\tTYPE\tSTATUS\tIMAGE")
+	for _, app := range sorted {
+		status := string(app.Info().Status)
+		if status == "" {
+			status = "not deployed"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", app.Name(), app.Type(), status, app.Deployment().Image)
+	}
+	if err := w.Flush(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	warnings, err := staleImageWarnings(ctx, sorted)
+	if err != nil {
+		return err
+	}
+	if len(warnings) == 0 {
+		return nil
+	}
+	fmt.Println()
+	for _, warning := range warnings {
+		fmt.Printf("WARNING: %s\n", warning)
+	}
+	fmt.Println("Use `rebuild <app>` to rebuild the image and recreate containers using it.")
+	return nil
+}
+
+// staleImageWarnings detects deployed apps using images built from older sources than the ones in the repositories,
+// and containers created from images which have been rebuilt since then.
+func staleImageWarnings(ctx context.Context, appSet infra.AppSet) ([]string, error) {
+	var warnings []string
+	images := map[string]*targets.ImageInfo{}
+	for _, app := range appSet {
+		if app.Info().Status == infra.AppStatusNotDeployed {
+			continue
+		}
+		image := app.Deployment().Image
+		if !strings.HasSuffix(image, localImageTag) {
+			continue
+		}
+
+		imageInfo, checked := images[image]
+		if !checked {
+			info, err := targets.InspectImage(ctx, image)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("image %s used by %s doesn't exist", image, app.Name()))
+				images[image] = nil
+				continue
+			}
+			imageInfo = &info
+			images[image] = imageInfo
+
+			if warning := sourceWarning(ctx, image, info); warning != "" {
+				warnings = append(warnings, warning)
+			}
+		}
+		if imageInfo == nil {
+			continue
+		}
+
+		containerImageID, err := targets.ContainerImageID(ctx, app.Info().Container)
+		if err != nil {
+			return nil, err
+		}
+		if containerImageID != "" && containerImageID != imageInfo.ID {
+			warnings = append(warnings, fmt.Sprintf("container of %s has been created before image %s was rebuilt",
+				app.Name(), image))
+		}
+	}
+	return warnings, nil
+}
+
+// sourceWarning compares the revision image has been built from with the current state of the source repository.
+func sourceWarning(ctx context.Context, image string, info targets.ImageInfo) string {
+	if info.Revision == "" || info.RepoPath == "" {
+		return ""
+	}
+	log := logger.Get(ctx).With(zap.String("image", image), zap.String("repo", info.RepoPath))
+
+	head, err := gitOutput(ctx, info.RepoPath, "rev-parse", "HEAD")
+	if err != nil {
+		log.Debug("Checking source repository failed", zap.Error(err))
+		return ""
+	}
+	if head != strings.TrimSuffix(info.Revision, "-dirty") {
+		return fmt.Sprintf("image %s has been built from commit %s, but %s is at %s", image, shortHash(info.Revision),
+			info.RepoPath, shortHash(head))
+	}
+
+	modified, err := lastUncommittedChange(ctx, info.RepoPath)
+	if err != nil {
+		log.Debug("Checking uncommitted changes failed", zap.Error(err))
+		return ""
+	}
+	if modified.After(info.Created) {
+		return fmt.Sprintf("%s contains uncommitted changes made after image %s has been built", info.RepoPath, image)
+	}
+	return ""
+}
+
+// lastUncommittedChange returns the latest modification time of the uncommitted files in the repository.
+func lastUncommittedChange(ctx context.Context, repoPath string) (time.Time, error) {
+	status, err := gitOutput(ctx, repoPath, "status", "--porcelain")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var latest time.Time
+	for _, line := range strings.Split(status, "\n") {
+		if len(line) < 4 {
+			continue
+		}
+		path := line[3:]
+		if _, renamed, ok := strings.Cut(path, " -> "); ok {
+			path = renamed
+		}
+		stat, err := os.Stat(filepath.Join(repoPath, strings.Trim(path, `"`)))
+		if errors.Is(err, os.ErrNotExist) {
+			// Deletion time is unknown, so file is considered as deleted now.
+			return time.Now(), nil
+		}
+		if err != nil {
+			return time.Time{}, errors.WithStack(err)
+		}
+		if stat.ModTime().After(latest) {
+			latest = stat.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func gitOutput(ctx context.Context, repoPath string, args ...string) (string, error) {
+	buf := &bytes.Buffer{}
+	cmd := osexec.Command("git", args...)
+	cmd.Dir = repoPath
+	cmd.Stdout = buf
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return "", errors.Wrap(err, "git command failed")
+	}
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// Rebuild rebuilds the image used by the app and recreates containers of all the apps using it. State stored
+// in the home directories of the apps is preserved.
+func Rebuild(ctx context.Context, config infra.Config, spec *infra.Spec, appSet infra.AppSet, appName string) error {
+	app, exists := lo.Find(appSet, func(app infra.App) bool { return app.Name() == appName })
+	if !exists {
+		return errors.Errorf("app %s doesn't exist", appName)
+	}
+	image := app.Deployment().Image
+	if !strings.HasSuffix(image, localImageTag) {
+		return errors.Errorf("image %s used by %s is not built by crust", image, appName)
+	}
+
+	if err := buildImage(ctx, config, image); err != nil {
+		return err
+	}
+	restart, err := removeContainers(ctx, spec, appSet, image)
+	if err != nil || !restart {
+		return err
+	}
+	return Start(ctx, config, spec)
+}
+
+// buildImage builds the image using crust build system.
+func buildImage(ctx context.Context, config infra.Config, image string) error {
+	buildTarget := "images/" + strings.TrimSuffix(image, localImageTag)
+	logger.Get(ctx).Info("Rebuilding image", zap.String("image", image), zap.String("target", buildTarget))
+	cmd := osexec.Command(filepath.Join(config.BinDir, "crust"), "build", buildTarget)
+	// Build system expects to be executed from the root of crust repository.
+	cmd.Dir = filepath.Dir(config.BinDir)
+	if err := libexec.Exec(ctx, cmd); err != nil {
+		return errors.Wrapf(err, "building image %s failed", image)
+	}
+	return nil
+}
+
+// removeContainers removes containers of the apps using the image and marks them as stopped, so they are created
+// again by Start, without preparing their home directories from scratch. It returns true if any of them was running.
+func removeContainers(ctx context.Context, spec *infra.Spec, appSet infra.AppSet, image string) (bool, error) {
+	var restart bool
+	for _, app := range appSet {
+		info := app.Info()
+		if app.Deployment().Image != image || info.Status == infra.AppStatusNotDeployed {
+			continue
+		}
+		logger.Get(ctx).Info("Removing container", zap.String("app", app.Name()))
+		if err := targets.RemoveContainer(ctx, info.Container); err != nil {
+			return false, err
+		}
+		restart = restart || info.Status == infra.AppStatusRunning
+		info.Status = infra.AppStatusStopped
+		spec.Apps[app.Name()].SetInfo(info)
+	}
+	if err := spec.Save(); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return restart, nil
+}
@@ -0,0 +1,287 @@
+package znet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/libexec"
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	integrationtests "github.com/CoreumFoundation/coreum/integration-tests"
+	"github.com/CoreumFoundation/coreum/pkg/config/constant"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+)
+
+// DefaultStateDiffBlocks is the default number of blocks produced after the scenario before state is exported.
+const DefaultStateDiffBlocks = 20
+
+// StateDiffConfig is the configuration of state comparison.
+type StateDiffConfig struct {
+	// BaseVersion is the version of cored used to produce the reference state, empty means the current one
+	BaseVersion string
+
+	// TargetVersion is the version of cored compared to the base one, empty means the current one
+	TargetVersion string
+
+	// Scenario is the path to the script executed in both environments, it may use all the znet commands
+	Scenario string
+
+	// Blocks is the number of blocks produced after the scenario, before state is exported
+	Blocks int
+
+	// OutputDir is the directory where exported states and the list of differences are stored
+	OutputDir string
+
+	// Ignore is the list of path prefixes, like `mint.minter`, excluded from the comparison
+	Ignore []string
+}
+
+// StateDiff runs the same scenario on two fresh environments using different versions of cored, exports state
+// of the chain from both and compares the states of the modules. Error is returned if they differ.
+func StateDiff(ctx context.Context, configF *infra.ConfigFactory, diffConfig StateDiffConfig) error {
+	if diffConfig.Scenario != "" {
+		scenario, err := filepath.Abs(diffConfig.Scenario)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		diffConfig.Scenario = scenario
+	}
+	if err := os.MkdirAll(diffConfig.OutputDir, 0o700); err != nil {
+		return errors.WithStack(err)
+	}
+
+	states := map[string]map[string]any{}
+	for _, side := range []struct {
+		Name    string
+		Version string
+	}{
+		{Name: "base", Version: diffConfig.BaseVersion},
+		{Name: "target", Version: diffConfig.TargetVersion},
+	} {
+		exported, err := runStateDiffEnv(ctx, configF, side.Name, side.Version, diffConfig)
+		if err != nil {
+			return errors.WithMessagef(err, "producing %s state failed", side.Name)
+		}
+		if err := os.WriteFile(filepath.Join(diffConfig.OutputDir, side.Name+"-state.json"), exported,
+			0o600); err != nil {
+			return errors.WithStack(err)
+		}
+
+		var genesis struct {
+			AppState map[string]any `json:"app_state"`
+		}
+		decoder := json.NewDecoder(bytes.NewReader(exported))
+		// Numbers are compared by their exact representation.
+		decoder.UseNumber()
+		if err := decoder.Decode(&genesis); err != nil {
+			return errors.Wrapf(err, "decoding %s state failed", side.Name)
+		}
+		states[side.Name] = genesis.AppState
+	}
+
+	var diffs []stateDiff
+	diffJSON("", states["base"], states["target"], func(d stateDiff) {
+		for _, prefix := range diffConfig.Ignore {
+			if d.Path == prefix || strings.HasPrefix(d.Path, prefix+".") || strings.HasPrefix(d.Path, prefix+"[") {
+				return
+			}
+		}
+		diffs = append(diffs, d)
+	})
+
+	report := &strings.Builder{}
+	modules := map[string]int{}
+	for _, d := range diffs {
+		module, _, _ := strings.Cut(d.Path, ".")
+		modules[module]++
+		fmt.Fprintf(report, "%s\n  base:   %s\n  target: %s\n", d.Path, d.Base, d.Target)
+	}
+	diffFile := filepath.Join(diffConfig.OutputDir, "diff.txt")
+	if err := os.WriteFile(diffFile, []byte(report.String()), 0o600); err != nil {
+		return errors.WithStack(err)
+	}
+
+	log := logger.Get(ctx)
+	if len(diffs) == 0 {
+		log.Info("States are equal", zap.String("outputDir", diffConfig.OutputDir))
+		return nil
+	}
+	moduleNames := make([]string, 0, len(modules))
+	for module, count := range modules {
+		log.Warn("State of module differs", zap.String("module", module), zap.Int("differences", count))
+		moduleNames = append(moduleNames, module)
+	}
+	sort.Strings(moduleNames)
+	return errors.Errorf("state differs in modules: %s, differences are listed in %s",
+		strings.Join(moduleNames, ", "), diffFile)
+}
+
+// runStateDiffEnv starts fresh environment using the version of cored, executes the scenario, stops the environment
+// and exports the state. Environment is removed afterwards.
+func runStateDiffEnv(
+	ctx context.Context,
+	configF *infra.ConfigFactory,
+	name, coredVersion string,
+	diffConfig StateDiffConfig,
+) (retState []byte, retErr error) {
+	envF := *configF
+	envF.EnvName = configF.EnvName + "-statediff-" + name
+	envF.CoredVersion = coredVersion
+	// Environment gets its own network, so it doesn't collide with the one of the base environment.
+	envF.Subnet = ""
+	envF.NetworkName = ""
+
+	log := logger.Get(ctx).With(zap.String("env", envF.EnvName), zap.String("coredVersion", coredVersion))
+
+	// Environment left by the previous run is removed, so chain starts from genesis.
+	staleSpec := infra.NewSpec(&envF)
+	if err := Remove(ctx, NewConfig(&envF, staleSpec), staleSpec); err != nil {
+		return nil, err
+	}
+	spec := infra.NewSpec(&envF)
+	config := NewConfig(&envF, spec)
+	defer func() {
+		cleanupCtx, cancel := infra.CleanupContext(ctx)
+		defer cancel()
+		if err := Remove(cleanupCtx, config, spec); retErr == nil {
+			retErr = err
+		}
+	}()
+
+	log.Info("Starting environment")
+	if err := Start(ctx, config, spec); err != nil {
+		return nil, err
+	}
+
+	networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+	if err != nil {
+		return nil, err
+	}
+	appSet, err := apps.BuildAppSet(apps.NewFactory(config, spec, networkConfig), config.Profiles,
+		config.CoredVersion)
+	if err != nil {
+		return nil, err
+	}
+	coredApp := appSet.FindRunningApp(cored.AppType, "cored-00")
+	if coredApp == nil {
+		return nil, errors.New("no running cored app found")
+	}
+	coredNode := coredApp.(cored.Cored)
+
+	if diffConfig.Scenario != "" {
+		log.Info("Running scenario", zap.String("scenario", diffConfig.Scenario))
+		saveWrappers(config)
+		cmd := osexec.Command("bash", diffConfig.Scenario)
+		cmd.Env = append(os.Environ(), envVars(&envF, config)...)
+		if err := libexec.Exec(ctx, cmd); err != nil {
+			return nil, errors.Wrap(err, "scenario failed")
+		}
+	}
+
+	clientCtx := coredNode.ClientContext()
+	latestBlock, err := tmservice.NewServiceClient(clientCtx).GetLatestBlock(ctx, &tmservice.GetLatestBlockRequest{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	log.Info("Waiting for blocks", zap.Int("blocks", diffConfig.Blocks))
+	if err := waitForHeight(ctx, clientCtx, latestBlock.Block.Header.Height+int64(diffConfig.Blocks)); err != nil {
+		return nil, err
+	}
+
+	if err := Stop(ctx, config, spec); err != nil {
+		return nil, err
+	}
+	log.Info("Exporting state")
+	return coredNode.ExportState(ctx)
+}
+
+type stateDiff struct {
+	Path   string
+	Base   string
+	Target string
+}
+
+// diffJSON compares decoded JSON values recursively and reports the differences, using paths like
+// `bank.balances[0].coins`.
+func diffJSON(path string, base, target any, report func(d stateDiff)) {
+	switch b := base.(type) {
+	case map[string]any:
+		t, ok := target.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := map[string]struct{}{}
+		for k := range b {
+			keys[k] = struct{}{}
+		}
+		for k := range t {
+			keys[k] = struct{}{}
+		}
+		sortedKeys := make([]string, 0, len(keys))
+		for k := range keys {
+			sortedKeys = append(sortedKeys, k)
+		}
+		sort.Strings(sortedKeys)
+		for _, k := range sortedKeys {
+			subPath := k
+			if path != "" {
+				subPath = path + "." + k
+			}
+			bv, bExists := b[k]
+			tv, tExists := t[k]
+			if !bExists || !tExists {
+				report(stateDiff{Path: subPath, Base: jsonValue(bv, bExists), Target: jsonValue(tv, tExists)})
+				continue
+			}
+			diffJSON(subPath, bv, tv, report)
+		}
+		return
+	case []any:
+		t, ok := target.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < len(b) || i < len(t); i++ {
+			subPath := fmt.Sprintf("%s[%d]", path, i)
+			if i >= len(b) {
+				report(stateDiff{Path: subPath, Base: jsonValue(nil, false), Target: jsonValue(t[i], true)})
+				continue
+			}
+			if i >= len(t) {
+				report(stateDiff{Path: subPath, Base: jsonValue(b[i], true), Target: jsonValue(nil, false)})
+				continue
+			}
+			diffJSON(subPath, b[i], t[i], report)
+		}
+		return
+	}
+
+	baseStr := jsonValue(base, true)
+	targetStr := jsonValue(target, true)
+	if baseStr != targetStr {
+		report(stateDiff{Path: path, Base: baseStr, Target: targetStr})
+	}
+}
+
+func jsonValue(v any, exists bool) string {
+	if !exists {
+		return "<missing>"
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(encoded)
+}
@@ -0,0 +1,87 @@
+package znet
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+
+	"github.com/CoreumFoundation/crust/infra"
+)
+
+// timingBarWidth is the width of the bar representing the whole startup in the timing report.
+const timingBarWidth = 60
+
+// timingSymbols are the symbols used to draw phases in the timing report.
+var timingSymbols = []struct {
+	Phase  string
+	Symbol byte
+}{
+	{Phase: infra.PhaseImage, Symbol: 'I'},
+	{Phase: infra.PhaseHealthWait, Symbol: 'W'},
+	{Phase: infra.PhasePrepare, Symbol: 'P'},
+	{Phase: infra.PhaseContainer, Symbol: 'C'},
+	{Phase: infra.PhaseConfigure, Symbol: 'F'},
+}
+
+// printStartupTiming prints the flame-style chart of time spent by each app in each phase of the startup.
+func printStartupTiming(timing *infra.StartupTiming) error {
+	apps := append([]*infra.AppTiming{}, timing.Apps...)
+	sort.Slice(apps, func(i, j int) bool {
+		return apps[i].EndSeconds() < apps[j].EndSeconds()
+	})
+
+	var total float64
+	for _, app := range apps {
+		total = math.Max(total, app.EndSeconds())
+	}
+	if total == 0 {
+		return nil
+	}
+	scale := timingBarWidth / total
+
+	legend := make([]string, 0, len(timingSymbols))
+	for _, s := range timingSymbols {
+		legend = append(legend, fmt.Sprintf("%c=%s", s.Symbol, s.Phase))
+	}
+	fmt.Printf("Startup timing (%s, '.' means waiting for a free slot or dependencies):\n",
+		strings.Join(legend, ", "))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APP\tTOTAL\tPHASES\tTIMELINE")
+	for _, app := range apps {
+		bar := []byte(strings.Repeat(" ", timingBarWidth))
+		phases := make([]string, 0, len(app.Phases))
+		for i, phase := range app.Phases {
+			symbol := timingSymbol(phase.Phase)
+			from := int(phase.StartSeconds * scale)
+			to := int(math.Ceil((phase.StartSeconds + phase.DurationSeconds) * scale))
+			if i > 0 {
+				// Gap between phases means waiting.
+				prev := app.Phases[i-1]
+				for j := int((prev.StartSeconds + prev.DurationSeconds) * scale); j < from && j < len(bar); j++ {
+					bar[j] = '.'
+				}
+			}
+			for j := from; j < to && j < len(bar); j++ {
+				bar[j] = symbol
+			}
+			phases = append(phases, fmt.Sprintf("%s:%.1fs", phase.Phase, phase.DurationSeconds))
+		}
+		fmt.Fprintf(w, "%s\t%.1fs\t%s\t|%s|\n", app.App, app.EndSeconds(), strings.Join(phases, " "), bar)
+	}
+	return errors.WithStack(w.Flush())
+}
+
+func timingSymbol(phase string) byte {
+	for _, s := range timingSymbols {
+		if s.Phase == phase {
+			return s.Symbol
+		}
+	}
+	return '?'
+}
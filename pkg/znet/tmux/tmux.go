@@ -24,7 +24,7 @@ func ShowContainerLogs(ctx context.Context, sessionName, windowName, container s
 		return err
 	}
 
-	cmd := []string{"docker", "logs", "-f", container}
+	cmd := []string{exec.ContainerRuntime(), "logs", "-f", container}
 	if hasSession {
 		return libexec.Exec(ctx, exec.TMux(append([]string{"new-window", "-d", "-n", windowName, "-t", sessionName + ":"}, cmd...)...))
 	}
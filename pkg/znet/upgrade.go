@@ -0,0 +1,177 @@
+package znet
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	upgradetypes "github.com/cosmos/cosmos-sdk/x/upgrade/types"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum-tools/pkg/retry"
+	integrationtests "github.com/CoreumFoundation/coreum/integration-tests"
+	"github.com/CoreumFoundation/coreum/pkg/client"
+	"github.com/CoreumFoundation/coreum/pkg/config/constant"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/apps"
+	"github.com/CoreumFoundation/crust/infra/apps/cored"
+	"github.com/CoreumFoundation/crust/infra/targets"
+)
+
+const (
+	// DefaultUpgradeFromVersion is the version of cored environment is started with before it is upgraded.
+	DefaultUpgradeFromVersion = "v0.1.1"
+
+	// DefaultUpgradeName is the name of the upgrade proposed by default.
+	DefaultUpgradeName = "v1"
+
+	// upgradeDelay is the number of blocks between the proposal and the upgrade, voting must complete before that.
+	upgradeDelay = 30
+
+	// upgradeVerifyBlocks is the number of blocks which must be produced after the upgrade to consider it successful.
+	upgradeVerifyBlocks = 3
+)
+
+// Upgrade starts the environment on the old version of cored, passes the software upgrade proposal and verifies
+// that cosmovisor switches the nodes to the new binary and chain keeps producing blocks.
+func Upgrade(ctx context.Context, config infra.Config, spec *infra.Spec, upgradeName string) error {
+	if err := spec.Verify(); err != nil {
+		return err
+	}
+
+	log := logger.Get(ctx).With(zap.String("upgrade", upgradeName))
+	log.Info("Starting environment", zap.String("coredVersion", config.CoredVersion))
+
+	target := targets.NewDocker(config, spec)
+	networkConfig, err := integrationtests.NewNetworkConfig(constant.ChainIDDev)
+	if err != nil {
+		return err
+	}
+	appF := apps.NewFactory(config, spec, networkConfig)
+	appSet, err := apps.BuildAppSet(appF, config.Profiles, config.CoredVersion)
+	if err != nil {
+		return err
+	}
+	if err := target.Deploy(ctx, appSet); err != nil {
+		return err
+	}
+
+	var (
+		coredNode       *cored.Cored
+		stakerMnemonics []string
+	)
+	healthChecks := []infra.HealthCheckCapable{}
+	for _, app := range appSet {
+		c, ok := app.(cored.Cored)
+		if !ok || !strings.HasPrefix(c.Name(), "cored-") {
+			continue
+		}
+		if err := c.SaveUpgradeBinary(upgradeName, "cored"); err != nil {
+			return err
+		}
+		if c.Config().IsValidator {
+			stakerMnemonics = append(stakerMnemonics, c.Config().StakerMnemonic)
+		}
+		if coredNode == nil {
+			coredNode = &c
+		}
+		healthChecks = append(healthChecks, c)
+	}
+	if coredNode == nil {
+		return errors.New("no cored app found")
+	}
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer waitCancel()
+	if err := infra.WaitUntilHealthy(waitCtx, healthChecks...); err != nil {
+		return err
+	}
+
+	clientCtx := coredNode.ClientContext()
+	tmQueryClient := tmservice.NewServiceClient(clientCtx)
+	infoBefore, err := tmQueryClient.GetNodeInfo(ctx, &tmservice.GetNodeInfoRequest{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	latestBlock, err := tmQueryClient.GetLatestBlock(ctx, &tmservice.GetLatestBlockRequest{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	upgradeHeight := latestBlock.Block.Header.Height + upgradeDelay
+
+	log = log.With(zap.Int64("upgradeHeight", upgradeHeight))
+	log.Info("Proposing upgrade", zap.String("versionBefore", infoBefore.ApplicationVersion.Version))
+
+	chainCtx := integrationtests.NewChainContext(clientCtx, networkConfig)
+	gov := integrationtests.NewGovernance(chainCtx, stakerMnemonics)
+	proposer := chainCtx.ImportMnemonic(coredNode.Config().FundingMnemonic)
+	err = gov.ProposeAndVote(ctx, proposer, upgradetypes.NewSoftwareUpgradeProposal(
+		"Upgrade to "+upgradeName,
+		"Software upgrade proposed by znet",
+		upgradetypes.Plan{
+			Name:   upgradeName,
+			Height: upgradeHeight,
+		},
+	), govtypes.OptionYes)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Upgrade proposal passed, waiting for the upgrade")
+	if err := waitForHeight(ctx, clientCtx, upgradeHeight+upgradeVerifyBlocks); err != nil {
+		return err
+	}
+
+	appliedPlan, err := upgradetypes.NewQueryClient(clientCtx).AppliedPlan(ctx, &upgradetypes.QueryAppliedPlanRequest{
+		Name: upgradeName,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if appliedPlan.Height != upgradeHeight {
+		return errors.Errorf("upgrade %q was expected to be applied at height %d, but applied height is %d", upgradeName,
+			upgradeHeight, appliedPlan.Height)
+	}
+
+	infoAfter, err := tmQueryClient.GetNodeInfo(ctx, &tmservice.GetNodeInfoRequest{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	log.Info("Chain upgraded and producing blocks", zap.String("versionBefore", infoBefore.ApplicationVersion.Version),
+		zap.String("versionAfter", infoAfter.ApplicationVersion.Version))
+	return nil
+}
+
+// waitForHeight waits until chain produces block at the height. Timeout is proportional to the number of remaining
+// blocks, so chain which stopped producing blocks, e.g. due to failed upgrade, is detected.
+func waitForHeight(ctx context.Context, clientCtx client.Context, height int64) error {
+	tmQueryClient := tmservice.NewServiceClient(clientCtx)
+	latestBlock, err := tmQueryClient.GetLatestBlock(ctx, &tmservice.GetLatestBlockRequest{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// 6 seconds per block is more than enough for cored running in znet, the same is assumed by upgrade tests.
+	waitCtx, waitCancel := context.WithTimeout(ctx,
+		time.Minute+6*time.Second*time.Duration(height-latestBlock.Block.Header.Height))
+	defer waitCancel()
+
+	return retry.Do(waitCtx, time.Second, func() error {
+		requestCtx, requestCancel := context.WithTimeout(waitCtx, 2*time.Second)
+		defer requestCancel()
+
+		latestBlock, err := tmQueryClient.GetLatestBlock(requestCtx, &tmservice.GetLatestBlockRequest{})
+		if err != nil {
+			return retry.Retryable(errors.WithStack(err))
+		}
+		if latestBlock.Block.Header.Height < height {
+			return retry.Retryable(errors.Errorf("waiting for block %d, current block: %d", height,
+				latestBlock.Block.Header.Height))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,236 @@
+package znet
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/CoreumFoundation/coreum-tools/pkg/logger"
+	"github.com/CoreumFoundation/coreum-tools/pkg/parallel"
+	"github.com/CoreumFoundation/crust/infra"
+	"github.com/CoreumFoundation/crust/infra/targets"
+)
+
+const (
+	// DefaultWebConsoleAddress is the default address web console listens on.
+	DefaultWebConsoleAddress = "127.0.0.1:8096"
+
+	// webConsoleLogTail is the number of recent log lines sent before new ones are streamed.
+	webConsoleLogTail = 1000
+
+	// webConsoleHeader is the header required in requests modifying the environment. Browsers don't send custom
+	// headers in cross-origin requests without preflight, which is never accepted, so other websites can't restart
+	// the apps.
+	webConsoleHeader = "X-Znet-Console"
+)
+
+//go:embed webconsole/index.html
+var webConsolePage []byte
+
+// webConsole serves web UI presenting logs, health and ports of the apps.
+type webConsole struct {
+	configF *infra.ConfigFactory
+	host    string
+}
+
+// WebConsole starts web UI, alternative to tmux console, streaming logs of the containers, showing health and
+// endpoints of the apps and allowing to restart them.
+func WebConsole(ctx context.Context, configF *infra.ConfigFactory, address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return errors.Wrapf(err, "invalid address %q", address)
+	}
+
+	c := &webConsole{configF: configF, host: host}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", c.handler(http.MethodGet, c.page))
+	mux.HandleFunc("/api/apps", c.handler(http.MethodGet, c.apps))
+	mux.HandleFunc("/api/logs", c.handler(http.MethodGet, c.logs))
+	mux.HandleFunc("/api/restart", c.handler(http.MethodPost, c.restart))
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	httpServer := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		// Requests inherit logger and cancellation of the command.
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	logger.Get(ctx).Info("Web console started, open it in the browser",
+		zap.String("url", "http://"+listener.Addr().String()))
+	return parallel.Run(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
+		spawn("server", parallel.Exit, func(ctx context.Context) error {
+			if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return errors.WithStack(err)
+			}
+			return nil
+		})
+		spawn("shutdown", parallel.Exit, func(ctx context.Context) error {
+			<-ctx.Done()
+			// Log streams never finish by themselves, so server is closed instead of being shut down gracefully.
+			return errors.WithStack(httpServer.Close())
+		})
+		return nil
+	})
+}
+
+// handler wraps the handler with checks protecting the console against requests made by other websites.
+func (c *webConsole) handler(method string, fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !c.allowedHost(r.Host) {
+			// Protects against DNS rebinding, when other website resolves its own domain to the local address.
+			writeJSON(w, http.StatusForbidden, apiError{Error: "host not allowed"})
+			return
+		}
+		if r.Method != method {
+			writeJSON(w, http.StatusMethodNotAllowed, apiError{Error: "method not allowed"})
+			return
+		}
+		if method != http.MethodGet && r.Header.Get(webConsoleHeader) == "" {
+			writeJSON(w, http.StatusForbidden, apiError{Error: webConsoleHeader + " header is required"})
+			return
+		}
+		fn(w, r)
+	}
+}
+
+func (c *webConsole) allowedHost(hostPort string) bool {
+	host, _, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		host = hostPort
+	}
+	host = strings.Trim(host, "[]")
+	switch host {
+	case "localhost", "127.0.0.1", "::1", c.host:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *webConsole) page(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(webConsolePage)
+}
+
+// apps returns the spec of the environment, with the results of health checks.
+func (c *webConsole) apps(w http.ResponseWriter, r *http.Request) {
+	spec, appSet, err := loadAppSet(c.configF)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
+		return
+	}
+	doc, err := NewSpecDocument(r.Context(), spec)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
+		return
+	}
+	checkSpecHealth(r.Context(), appSet, doc.Apps)
+	writeJSON(w, http.StatusOK, doc)
+}
+
+// logs streams logs of the app's container as server-sent events, one event per line.
+func (c *webConsole) logs(w http.ResponseWriter, r *http.Request) {
+	container, err := c.container(r.URL.Query().Get("app"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: err.Error()})
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: "streaming is not supported"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	events := &sseWriter{w: w, flusher: flusher}
+	if err := targets.StreamContainerLogs(r.Context(), container, webConsoleLogTail, events); err != nil {
+		logger.Get(r.Context()).Error("Streaming logs failed", zap.String("container", container), zap.Error(err))
+		events.event("failure", err.Error())
+	}
+}
+
+// restart restarts the app's container.
+func (c *webConsole) restart(w http.ResponseWriter, r *http.Request) {
+	app := r.URL.Query().Get("app")
+	container, err := c.container(app)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: err.Error()})
+		return
+	}
+
+	logger.Get(r.Context()).Info("Restarting app", zap.String("app", app))
+	if err := targets.RestartContainer(r.Context(), container); err != nil {
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+func (c *webConsole) container(appName string) (string, error) {
+	app, exists := infra.NewSpec(c.configF).Apps[appName]
+	if !exists {
+		return "", errors.Errorf("app %q does not exist", appName)
+	}
+	if app.Info().Status == infra.AppStatusNotDeployed {
+		return "", errors.Errorf("app %q is not deployed", appName)
+	}
+	return app.Info().Container, nil
+}
+
+// sseWriter sends every line written to it as the server-sent event. Both stdout and stderr of the container are
+// written concurrently, so writes are serialized.
+type sseWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	flusher http.Flusher
+	buf     []byte
+}
+
+func (s *sseWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buf = append(s.buf, p...)
+	for {
+		i := bytes.IndexByte(s.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := strings.TrimRight(string(s.buf[:i]), "\r")
+		s.buf = s.buf[i+1:]
+		if _, err := fmt.Fprintf(s.w, "data: %s\n\n", line); err != nil {
+			return 0, errors.WithStack(err)
+		}
+		s.flusher.Flush()
+	}
+}
+
+func (s *sseWriter) event(name, data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, _ = fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", name, strings.ReplaceAll(data, "\n", " "))
+	s.flusher.Flush()
+}